@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"taskboard/config"
+	"taskboard/controller/api"
+	"taskboard/migration"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Names of commands
+const (
+	commandServe        = "serve"
+	commandMigrate      = "migrate"
+	commandUser         = "user"
+	commandBoard        = "board"
+	commandExport       = "export"
+	commandImport       = "import"
+	commandImportTrello = "import-trello"
+	commandCheck        = "check"
+	commandRebuildIndex = "rebuild-index"
+)
+
+// command is a subcommand of taskboard binary
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(conf *config.Config, args []string) error
+}
+
+var commands = []command{
+	{commandServe, "serve", "Start api server (default)", serve},
+	{commandMigrate, migrateUsage, "Show or change version of database", runMigrate},
+	{commandUser, userUsage, "Manage users", runUser},
+	{commandBoard, boardUsage, "Manage boards", runBoard},
+	{commandExport, exportUsage, "Export users, boards and tasks as JSON", runExport},
+	{commandImport, importUsage, "Import users, boards and tasks from JSON", runImport},
+	{commandImportTrello, importTrelloUsage, "Import boards and tasks from Trello board export JSON", runImportTrello},
+	{commandCheck, checkUsage, "Check and repair order of boards and tasks and their references", runCheck},
+	{commandRebuildIndex, rebuildIndexUsage, "Rebuild full-text search index of tasks and comments", runRebuildIndex},
+}
+
+// runCommand executes command specified by arguments
+func runCommand(conf *config.Config, args []string) error {
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(conf, args[1:])
+		}
+	}
+	printUsage()
+	return errors.Errorf("unknown command [%s]", args[0])
+}
+
+// printUsage prints usage of commands
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] [command] [arguments]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-40s %s\n", c.usage, c.description)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun with -h to show flags.\n")
+}
+
+// printCommandError prints error and its details of service error
+func printCommandError(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if serr, ok := err.(*service.SvcError); ok {
+		for _, detail := range serr.Details {
+			fmt.Fprintf(os.Stderr, "  - %s\n", detail)
+		}
+	}
+}
+
+// newCommandFlags returns flag set of subcommand, usage is printed with flags
+func newCommandFlags(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseCommandFlags parses flags of subcommand and checks the number of remaining arguments
+func parseCommandFlags(flags *flag.FlagSet, args []string, argCount int) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != argCount {
+		flags.Usage()
+		return nil, errors.Errorf("%s requires %d argument(s) but %d given", flags.Name(), argCount, flags.NArg())
+	}
+	return flags.Args(), nil
+}
+
+// checkMigrated checks whether database has been migrated to the latest version
+func checkMigrated() error {
+	current, err := migration.CurrentVersion(orm.GetDB())
+	if err != nil {
+		return err
+	}
+	if current != migration.LatestVersion() {
+		return errors.Errorf("database version is %d but %d is required, run [migrate up] or [serve] first",
+			current, migration.LatestVersion())
+	}
+	return nil
+}
+
+// inTransaction executes fn in a transaction, commits if fn succeeds and rollbacks otherwise
+func inTransaction(fn func(tx *gorm.DB) error) error {
+	tx := orm.GetDB().Begin()
+	if err := fn(tx); err != nil {
+		api.Rollback(tx)
+		return err
+	}
+	return api.Commit(tx)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"taskboard/config"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Usages of backup commands
+const (
+	exportUsage = "export [-o file] [-password-hashes]"
+	importUsage = "import [-dry-run] [-remap-ids] [-v] [file]"
+)
+
+// runExport writes users, labels, projects, boards, tasks and comments as JSON to file or stdout
+func runExport(conf *config.Config, args []string) error {
+	flags := newCommandFlags(commandExport, exportUsage)
+	output := flags.String("o", "", "Output file, stdout if omitted")
+	passwordHashes := flags.Bool("password-hashes", false, "Export password hashes of users, keep the output secret")
+	if _, err := parseCommandFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if err := checkMigrated(); err != nil {
+		return err
+	}
+	options := service.ExportOptions{IncludePasswordHashes: *passwordHashes}
+	backup, err := service.NewBackupService(orm.GetDB(), nil).Export(options)
+	if err != nil {
+		return err
+	}
+
+	var writer io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer file.Close()
+		writer = file
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(backup); err != nil {
+		return errors.Wrap(err, "failed to write exported data")
+	}
+	if *output != "" {
+		fmt.Printf("Exported %d users, %d labels, %d projects, %d boards, %d tasks and %d comments to [%s].\n",
+			len(backup.Users), len(backup.Labels), len(backup.Projects), len(backup.Boards), len(backup.Tasks),
+			len(backup.Comments), *output)
+	}
+	return nil
+}
+
+// runImport reads exported JSON from file or stdin, and creates records in a transaction
+func runImport(conf *config.Config, args []string) error {
+	flags := newCommandFlags(commandImport, importUsage)
+	dryRun := flags.Bool("dry-run", false, "Report what would change without changing anything")
+	remapIDs := flags.Bool("remap-ids", false, "Generate new IDs instead of keeping IDs in the file")
+	verbose := flags.Bool("v", false, "Print each imported record")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return errors.New("import accepts only one file")
+	}
+	if err := checkMigrated(); err != nil {
+		return err
+	}
+
+	var reader io.Reader = os.Stdin
+	if flags.NArg() == 1 {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer file.Close()
+		reader = file
+	}
+	var backup service.Backup
+	if err := json.NewDecoder(reader).Decode(&backup); err != nil {
+		return errors.Wrap(err, "failed to read imported data")
+	}
+
+	var result *service.ImportResult
+	options := service.ImportOptions{RemapIDs: *remapIDs, DryRun: *dryRun}
+	err := inTransaction(func(tx *gorm.DB) error {
+		var serr error
+		result, serr = service.NewBackupService(tx, nil).Import(&backup, options)
+		return serr
+	})
+	if err != nil {
+		return err
+	}
+	printImportResult(result, *verbose || *dryRun)
+	return nil
+}
+
+// printImportResult prints the number of created and skipped records, and each record if verbose
+func printImportResult(result *service.ImportResult, verbose bool) {
+	if verbose {
+		for _, record := range result.Records {
+			fmt.Printf("%-6s %-8s %-40s %-40s %s", record.Action, record.Type, record.ID, record.NewID, record.Name)
+			if record.Reason != "" {
+				fmt.Printf(" (%s)", record.Reason)
+			}
+			fmt.Println()
+		}
+	}
+	format := "%-8s %d created, %d skipped\n"
+	if result.DryRun {
+		format = "%-8s %d to create, %d to skip\n"
+	}
+	for _, recordType := range service.ImportTypes {
+		fmt.Printf(format, recordType+":",
+			result.Count(recordType, service.ImportActionCreate),
+			result.Count(recordType, service.ImportActionSkip))
+	}
+	if result.DryRun {
+		fmt.Println("Nothing is changed because of dry run.")
+	} else if result.Count(service.ImportTypeUser, service.ImportActionCreate) > 0 {
+		fmt.Println("Imported users without password hash can not login until their password is reset by [user reset-password].")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"taskboard/config"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+const boardUsage = "board list|close"
+
+// runBoard manages boards
+func runBoard(conf *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: " + boardUsage)
+	}
+	if err := checkMigrated(); err != nil {
+		return err
+	}
+	switch args[0] {
+	case "list":
+		return listBoards(args[1:])
+	case "close":
+		return closeBoard(args[1:])
+	default:
+		return errors.New("usage: " + boardUsage)
+	}
+}
+
+func listBoards(args []string) error {
+	flags := newCommandFlags("board list", "board list")
+	if _, err := parseCommandFlags(flags, args, 0); err != nil {
+		return err
+	}
+	tx := orm.GetDB() // No transaction
+	boards, err := service.NewBoardService(tx, nil).FindBoards(&model.Board{}, []string{"rank"})
+	if err != nil {
+		return err
+	}
+	tasks, err := service.NewTaskService(tx, nil).FindTasks(&model.Task{}, nil, []string{"id"})
+	if err != nil {
+		return err
+	}
+	taskCounts := map[string]int{}
+	for _, task := range tasks {
+		taskCounts[task.BoardID]++
+	}
+	fmt.Printf("%-40s %-8s %-6s %-6s %5s %s\n", "ID", "RANK", "SYSTEM", "CLOSED", "TASKS", "NAME")
+	for _, board := range boards {
+		fmt.Printf("%-40s %-8s %-6t %-6t %5d %s\n",
+			board.ID, board.Rank, board.IsSystem, board.IsClosed, taskCounts[board.ID], board.Name)
+	}
+	return nil
+}
+
+func closeBoard(args []string) error {
+	flags := newCommandFlags("board close", "board close <id or name>")
+	values, err := parseCommandFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	var board *model.Board
+	err = inTransaction(func(tx *gorm.DB) error {
+		srvc := service.NewBoardService(tx, nil)
+		var serr error
+		board, serr = findBoardByIDOrName(srvc, values[0])
+		if serr != nil {
+			return serr
+		}
+		if board.IsClosed {
+			return service.NewSvcErrorf(service.ErrorCodeInvalidlStatus, nil, "Board [%s] is already closed", board.Name)
+		}
+		board.IsClosed = true
+		return srvc.UpdateBoard(board)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Board [%s] is closed.\n", board.Name)
+	return nil
+}
+
+// findBoardByIDOrName returns board whose ID or name is specified value
+func findBoardByIDOrName(srvc *service.BoardService, value string) (*model.Board, error) {
+	board, serr := srvc.FindBoard(&model.Board{ID: value})
+	if serr == nil {
+		return board, nil
+	}
+	return srvc.FindBoard(&model.Board{Name: value})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"taskboard/config"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/jinzhu/gorm"
+)
+
+const checkUsage = "check [-repair]"
+
+// runCheck checks order of boards and tasks and their references, and repairs them if requested
+func runCheck(conf *config.Config, args []string) error {
+	flags := newCommandFlags(commandCheck, checkUsage)
+	repair := flags.Bool("repair", false, "Repair issues in a transaction")
+	if _, err := parseCommandFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if err := checkMigrated(); err != nil {
+		return err
+	}
+	var report *service.IntegrityReport
+	var err error
+	if *repair {
+		err = inTransaction(func(tx *gorm.DB) error {
+			var serr error
+			report, serr = service.NewIntegrityService(tx, nil).Repair()
+			return serr
+		})
+	} else {
+		tx := orm.GetDB() // No transaction
+		report, err = service.NewIntegrityService(tx, nil).Check()
+	}
+	if err != nil {
+		return err
+	}
+	for _, issue := range report.Issues {
+		fmt.Printf("%-20s %-40s %s\n", issue.Type, issue.ID, issue.Message)
+	}
+	fmt.Printf("Checked %d boards and %d tasks, %d issue(s) found.\n",
+		report.BoardCount, report.TaskCount, len(report.Issues))
+	if report.Repaired && len(report.Issues) > 0 {
+		fmt.Println("Issues are repaired.")
+	} else if len(report.Issues) > 0 {
+		fmt.Println("Run with -repair to repair them.")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"taskboard/config"
+	"taskboard/migration"
+	"taskboard/orm"
+
+	"github.com/pkg/errors"
+)
+
+const migrateUsage = "migrate status|up|down|to <version>"
+
+// runMigrate shows or changes version of database
+func runMigrate(conf *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: " + migrateUsage)
+	}
+	db := orm.GetDB()
+	switch args[0] {
+	case "status":
+		states, err := migration.Status(db)
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			appliedDate := "pending"
+			if state.IsApplied {
+				appliedDate = "applied at " + state.AppliedDate.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%4d %-50s %s\n", state.Version, state.Name, appliedDate)
+		}
+		return nil
+	case "up":
+		return migration.Up(db)
+	case "down":
+		return migration.Down(db)
+	case "to":
+		if len(args) != 2 {
+			return errors.New("usage: " + migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.Errorf("invalid version [%s]", args[1])
+		}
+		return migration.To(db, version)
+	default:
+		return errors.New("usage: " + migrateUsage)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"taskboard/config"
+	"taskboard/service"
+
+	"github.com/jinzhu/gorm"
+)
+
+const rebuildIndexUsage = "rebuild-index"
+
+// runRebuildIndex recreates full-text search index from all tasks and comments
+func runRebuildIndex(conf *config.Config, args []string) error {
+	flags := newCommandFlags(commandRebuildIndex, rebuildIndexUsage)
+	if _, err := parseCommandFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if err := checkMigrated(); err != nil {
+		return err
+	}
+	err := inTransaction(func(tx *gorm.DB) error {
+		return service.NewSearchService(tx, nil).RebuildIndex()
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println("Search index is rebuilt.")
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"taskboard/config"
+	"taskboard/model"
+	"taskboard/service"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+const importTrelloUsage = "import-trello [-project id] [file]"
+
+// runImportTrello reads Trello board export from file or stdin, and creates boards and tasks of the project in a transaction
+func runImportTrello(conf *config.Config, args []string) error {
+	flags := newCommandFlags(commandImportTrello, importTrelloUsage)
+	projectID := flags.String("project", model.DefaultProjectID, "ID of project where boards are created")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return errors.New("import-trello accepts only one file")
+	}
+	if err := checkMigrated(); err != nil {
+		return err
+	}
+
+	var reader io.Reader = os.Stdin
+	if flags.NArg() == 1 {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer file.Close()
+		reader = file
+	}
+	var trello service.TrelloBoard
+	if err := json.NewDecoder(reader).Decode(&trello); err != nil {
+		return errors.Wrap(err, "failed to read Trello export")
+	}
+
+	var result *service.TrelloImportResult
+	err := inTransaction(func(tx *gorm.DB) error {
+		var serr error
+		result, serr = service.NewTrelloService(tx, nil).Import(*projectID, &trello)
+		return serr
+	})
+	if err != nil {
+		return err
+	}
+	for _, item := range result.Unmapped {
+		fmt.Printf("%-10s %-26s %-30s %s\n", item.Type, item.ID, item.Name, item.Reason)
+	}
+	fmt.Printf("Imported %d boards and %d tasks from Trello board [%s], %d item(s) not mapped.\n",
+		len(result.Boards), len(result.Tasks), trello.Name, len(result.Unmapped))
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"taskboard/common"
+	"taskboard/config"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+const userUsage = "user create|reset-password|delete|list"
+
+// runUser manages users
+func runUser(conf *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: " + userUsage)
+	}
+	if err := checkMigrated(); err != nil {
+		return err
+	}
+	switch args[0] {
+	case "create":
+		return createUser(args[1:])
+	case "reset-password":
+		return resetUserPassword(args[1:])
+	case "delete":
+		return deleteUser(args[1:])
+	case "list":
+		return listUsers(args[1:])
+	default:
+		return errors.New("usage: " + userUsage)
+	}
+}
+
+func createUser(args []string) error {
+	flags := newCommandFlags("user create", "user create [-role role] [-password password] [-avator avator] <name>")
+	role := flags.String("role", model.RoleMember, "Role of user (admin, member or viewer)")
+	password := flags.String("password", "", "Password of user, generated if omitted")
+	avator := flags.String("avator", "", "Avator of user")
+	values, err := parseCommandFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	isRandom := *password == ""
+	if isRandom {
+		*password = common.GenerateID()[:16]
+	}
+	user := model.NewUser(values[0], *password, *avator)
+	user.Role = *role
+	err = inTransaction(func(tx *gorm.DB) error {
+		return service.NewUserService(tx, nil).CreateUser(user)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("User [%s] is created. ID:%s Role:%s\n", user.Name, user.ID, user.Role)
+	if isRandom {
+		fmt.Printf("Password: %s\n", *password)
+	}
+	return nil
+}
+
+func resetUserPassword(args []string) error {
+	flags := newCommandFlags("user reset-password", "user reset-password [-password password] <name>")
+	password := flags.String("password", "", "New password of user, generated if omitted")
+	values, err := parseCommandFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	isRandom := *password == ""
+	if isRandom {
+		*password = common.GenerateID()[:16]
+	}
+	err = inTransaction(func(tx *gorm.DB) error {
+		srvc := service.NewUserService(tx, nil)
+		user, serr := srvc.FindUser(&model.User{Name: values[0]})
+		if serr != nil {
+			return serr
+		}
+		// Sessions logged in by old password are revoked
+		user.SetPassword(*password)
+		return srvc.UpdateUser(user)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Password of user [%s] is reset.\n", values[0])
+	if isRandom {
+		fmt.Printf("Password: %s\n", *password)
+	}
+	return nil
+}
+
+func deleteUser(args []string) error {
+	flags := newCommandFlags("user delete", "user delete <name>")
+	values, err := parseCommandFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	err = inTransaction(func(tx *gorm.DB) error {
+		srvc := service.NewUserService(tx, nil)
+		user, serr := srvc.FindUser(&model.User{Name: values[0]})
+		if serr != nil {
+			return serr
+		}
+		return srvc.DeleteUser(user)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("User [%s] is deleted.\n", values[0])
+	return nil
+}
+
+func listUsers(args []string) error {
+	flags := newCommandFlags("user list", "user list")
+	if _, err := parseCommandFlags(flags, args, 0); err != nil {
+		return err
+	}
+	users, err := service.NewUserService(orm.GetDB(), nil).FindUsers(&model.User{}, []string{"name"})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-40s %-8s %s\n", "ID", "ROLE", "NAME")
+	for _, user := range users {
+		fmt.Printf("%-40s %-8s %s\n", user.ID, user.Role, user.Name)
+	}
+	return nil
+}
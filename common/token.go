@@ -0,0 +1,44 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ErrorInvalidToken is an error when token is malformed or its signature does not match
+var ErrorInvalidToken = errors.New("invalid token")
+
+// SignToken returns token which consists of payload and its HMAC-SHA256 signature
+func SignToken(secret []byte, payload string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sign(secret, encoded))
+}
+
+// VerifyToken returns payload of token if its signature is valid
+func VerifyToken(secret []byte, token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return "", ErrorInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrorInvalidToken
+	}
+	if !hmac.Equal(signature, sign(secret, parts[0])) {
+		return "", ErrorInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrorInvalidToken
+	}
+	return string(payload), nil
+}
+
+func sign(secret []byte, value string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
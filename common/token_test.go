@@ -0,0 +1,39 @@
+package common
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyToken(t *testing.T) {
+	secret := []byte("secret")
+	token := SignToken(secret, "access:session:1")
+	payload, err := VerifyToken(secret, token)
+	assert.Nil(t, err)
+	assert.Equal(t, "access:session:1", payload)
+
+	parts := strings.Split(token, ".")
+	tampered := base64.RawURLEncoding.EncodeToString([]byte("access:other:1"))
+	tests := []struct {
+		name   string
+		secret []byte
+		token  string
+	}{
+		{"tampered payload", secret, tampered + "." + parts[1]},
+		{"tampered signature", secret, parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte("signature"))},
+		{"other secret", []byte("other"), token},
+		{"no signature", secret, parts[0]},
+		{"too many parts", secret, token + "." + parts[1]},
+		{"signature not encoded", secret, parts[0] + ".!!"},
+		{"empty", secret, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := VerifyToken(test.secret, test.token)
+			assert.Equal(t, ErrorInvalidToken, err)
+		})
+	}
+}
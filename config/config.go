@@ -0,0 +1,302 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Log levels
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// Config presents settings of the app.
+// Settings are loaded in order of defaults, config file, environment variables and command line flags,
+// later one overrides earlier one.
+type Config struct {
+	Database DatabaseConfig `yaml:"database"`
+	Server   ServerConfig   `yaml:"server"`
+	LogLevel string         `yaml:"logLevel"` // debug, info, warn or error
+}
+
+// DatabaseConfig presents settings of database
+type DatabaseConfig struct {
+	Path         string `yaml:"path"`
+	MaxOpenConns int    `yaml:"maxOpenConns"`
+	MaxIdleConns int    `yaml:"maxIdleConns"`
+}
+
+// ServerConfig presents settings of api server
+type ServerConfig struct {
+	Host        string   `yaml:"host"` // Empty means all interfaces
+	Port        int      `yaml:"port"`
+	TLSCertFile string   `yaml:"tlsCertFile"` // TLS is enabled if both of cert and key are set
+	TLSKeyFile  string   `yaml:"tlsKeyFile"`
+	CORSOrigins []string `yaml:"corsOrigins"` // "*" allows all origins
+	StaticDir   string   `yaml:"staticDir"`
+}
+
+// Names of environment variables
+const (
+	envConfigFile   = "TASKBOARD_CONFIG"
+	envDatabasePath = "TASKBOARD_DATABASE_PATH"
+	envMaxOpenConns = "TASKBOARD_DATABASE_MAX_OPEN_CONNS"
+	envMaxIdleConns = "TASKBOARD_DATABASE_MAX_IDLE_CONNS"
+	envHost         = "TASKBOARD_API_SERVER_HOST"
+	envPort         = "TASKBOARD_API_SERVER_PORT"
+	envTLSCertFile  = "TASKBOARD_TLS_CERT_FILE"
+	envTLSKeyFile   = "TASKBOARD_TLS_KEY_FILE"
+	envCORSOrigins  = "TASKBOARD_CORS_ORIGINS" // Comma separated
+	envStaticDir    = "TASKBOARD_STATIC_DIR"
+	envLogLevel     = "TASKBOARD_LOG_LEVEL"
+)
+
+// Default returns config which has default settings
+func Default() *Config {
+	return &Config{
+		Database: DatabaseConfig{
+			Path:         "./taskboard.sqlite3",
+			MaxOpenConns: 5,
+			MaxIdleConns: 5,
+		},
+		Server: ServerConfig{
+			Host:        "",
+			Port:        7000,
+			CORSOrigins: []string{"*"},
+			StaticDir:   "./static",
+		},
+		LogLevel: LogLevelInfo,
+	}
+}
+
+// Load returns validated config loaded from config file, environment variables and command line arguments.
+// Arguments remaining after flags are returned as well.
+func Load(name string, args []string) (*Config, []string, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv(envConfigFile), "Path of YAML config file")
+	databasePath := flags.String("database", "", "Path of database file")
+	maxOpenConns := flags.Int("max-open-conns", 0, "Max number of open connections to database")
+	maxIdleConns := flags.Int("max-idle-conns", 0, "Max number of idle connections to database")
+	host := flags.String("host", "", "Listening host of api server")
+	port := flags.Int("port", 0, "Listening port of api server")
+	tlsCertFile := flags.String("tls-cert", "", "Path of TLS certificate file")
+	tlsKeyFile := flags.String("tls-key", "", "Path of TLS key file")
+	corsOrigins := flags.String("cors-origins", "", "Comma separated origins allowed by CORS, * allows all")
+	staticDir := flags.String("static", "", "Directory of static files")
+	logLevel := flags.String("log-level", "", "Log level (debug, info, warn or error)")
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	config := Default()
+	if *configFile != "" {
+		if err := config.loadFile(*configFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := config.loadEnv(); err != nil {
+		return nil, nil, err
+	}
+
+	// Flags override only if specified
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "database":
+			config.Database.Path = *databasePath
+		case "max-open-conns":
+			config.Database.MaxOpenConns = *maxOpenConns
+		case "max-idle-conns":
+			config.Database.MaxIdleConns = *maxIdleConns
+		case "host":
+			config.Server.Host = *host
+		case "port":
+			config.Server.Port = *port
+		case "tls-cert":
+			config.Server.TLSCertFile = *tlsCertFile
+		case "tls-key":
+			config.Server.TLSKeyFile = *tlsKeyFile
+		case "cors-origins":
+			config.Server.CORSOrigins = splitList(*corsOrigins)
+		case "static":
+			config.Server.StaticDir = *staticDir
+		case "log-level":
+			config.LogLevel = *logLevel
+		}
+	})
+
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return config, flags.Args(), nil
+}
+
+// loadFile overrides settings by YAML file, settings not in the file are kept
+func (c *Config) loadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read config file [%s]", path)
+	}
+	if err = yaml.UnmarshalStrict(data, c); err != nil {
+		return errors.Wrapf(err, "failed to parse config file [%s]", path)
+	}
+	return nil
+}
+
+// loadEnv overrides settings by environment variables which are set
+func (c *Config) loadEnv() (err error) {
+	if value := os.Getenv(envDatabasePath); value != "" {
+		c.Database.Path = value
+	}
+	if c.Database.MaxOpenConns, err = getEnvInt(envMaxOpenConns, c.Database.MaxOpenConns); err != nil {
+		return
+	}
+	if c.Database.MaxIdleConns, err = getEnvInt(envMaxIdleConns, c.Database.MaxIdleConns); err != nil {
+		return
+	}
+	if value := os.Getenv(envHost); value != "" {
+		c.Server.Host = value
+	}
+	if c.Server.Port, err = getEnvInt(envPort, c.Server.Port); err != nil {
+		return
+	}
+	if value := os.Getenv(envTLSCertFile); value != "" {
+		c.Server.TLSCertFile = value
+	}
+	if value := os.Getenv(envTLSKeyFile); value != "" {
+		c.Server.TLSKeyFile = value
+	}
+	if value := os.Getenv(envCORSOrigins); value != "" {
+		c.Server.CORSOrigins = splitList(value)
+	}
+	if value := os.Getenv(envStaticDir); value != "" {
+		c.Server.StaticDir = value
+	}
+	if value := os.Getenv(envLogLevel); value != "" {
+		c.LogLevel = value
+	}
+	return nil
+}
+
+// Validate checks all settings used by every command, and returns error which describes all invalid settings
+func (c *Config) Validate() error {
+	invalids := []string{}
+	addInvalid := func(format string, values ...interface{}) {
+		invalids = append(invalids, fmt.Sprintf(format, values...))
+	}
+	if c.Database.Path == "" {
+		addInvalid("database path must not be empty")
+	}
+	if c.Database.MaxOpenConns < 1 {
+		addInvalid("max open connections must be 1 or more, but %d", c.Database.MaxOpenConns)
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		addInvalid("max idle connections must be between 0 and max open connections, but %d", c.Database.MaxIdleConns)
+	}
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		addInvalid("port must be between 1 and 65535, but %d", c.Server.Port)
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		addInvalid("both of TLS cert file and key file must be set to enable TLS")
+	}
+	if len(c.Server.CORSOrigins) == 0 {
+		addInvalid("one or more CORS origins must be set, use * to allow all")
+	}
+	for _, origin := range c.Server.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			addInvalid("CORS origin [%s] must be * or scheme://host[:port]", origin)
+		}
+	}
+	switch c.LogLevel {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	default:
+		addInvalid("log level must be %s, %s, %s or %s, but [%s]",
+			LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError, c.LogLevel)
+	}
+	return invalidError(invalids)
+}
+
+// ValidateServer checks files and directories which are used only by api server,
+// so that other commands can be run without them
+func (c *Config) ValidateServer() error {
+	invalids := []string{}
+	addInvalid := func(format string, values ...interface{}) {
+		invalids = append(invalids, fmt.Sprintf(format, values...))
+	}
+	for _, file := range []string{c.Server.TLSCertFile, c.Server.TLSKeyFile} {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			addInvalid("TLS file [%s] can not be read: %v", file, err)
+		}
+	}
+	if info, err := os.Stat(c.Server.StaticDir); err != nil || !info.IsDir() {
+		addInvalid("static dir [%s] must be an existing directory", c.Server.StaticDir)
+	}
+	return invalidError(invalids)
+}
+
+// ListeningAddress returns host:port of api server
+func (c *Config) ListeningAddress() string {
+	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
+}
+
+// IsTLS checks whether api server uses TLS
+func (c *Config) IsTLS() bool {
+	return c.Server.TLSCertFile != "" && c.Server.TLSKeyFile != ""
+}
+
+// AllowsAllOrigins checks whether CORS allows all origins
+func (c *Config) AllowsAllOrigins() bool {
+	for _, origin := range c.Server.CORSOrigins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidError returns error which describes all invalid settings, or nil if there are none
+func invalidError(invalids []string) error {
+	if len(invalids) > 0 {
+		return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(invalids, "\n  - "))
+	}
+	return nil
+}
+
+func getEnvInt(name string, defaultValue int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Errorf("environment variable [%s] must be number, but [%s]", name, value)
+	}
+	return number, nil
+}
+
+func splitList(value string) []string {
+	result := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setTestEnv sets environment variables and returns function to restore them
+func setTestEnv(t *testing.T, values map[string]string) func() {
+	names := []string{envConfigFile, envDatabasePath, envMaxOpenConns, envMaxIdleConns, envHost, envPort,
+		envTLSCertFile, envTLSKeyFile, envCORSOrigins, envStaticDir, envLogLevel}
+	saved := map[string]string{}
+	for _, name := range names {
+		saved[name] = os.Getenv(name)
+		if err := os.Setenv(name, values[name]); err != nil {
+			t.Fatalf("Failed to set environment variable: %+v", err)
+		}
+	}
+	return func() {
+		for name, value := range saved {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+func writeTestConfigFile(t *testing.T, dir, content string) string {
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %+v", err)
+	}
+	return path
+}
+
+func TestLoad_Default(t *testing.T) {
+	defer setTestEnv(t, nil)()
+
+	config, args, err := Load("taskboard", []string{"user", "list"})
+	if err != nil {
+		t.Fatalf("Failed to load config: %+v", err)
+	}
+	assert.Equal(t, Default(), config)
+	assert.Equal(t, []string{"user", "list"}, args)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "taskboard-config")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := writeTestConfigFile(t, dir, strings.Join([]string{
+		"database:",
+		"  path: file.sqlite3",
+		"  maxOpenConns: 10",
+		"server:",
+		"  host: file-host",
+		"  port: 7001",
+		"logLevel: warn",
+	}, "\n"))
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		args     []string
+		expected func(config *Config)
+	}{
+		{"File overrides default", map[string]string{envConfigFile: configFile}, nil, func(config *Config) {
+			config.Database.Path = "file.sqlite3"
+			config.Database.MaxOpenConns = 10
+			config.Server.Host = "file-host"
+			config.Server.Port = 7001
+			config.LogLevel = LogLevelWarn
+		}},
+		{"Env overrides file", map[string]string{
+			envConfigFile: configFile,
+			envHost:       "env-host",
+			envPort:       "7002",
+		}, nil, func(config *Config) {
+			config.Database.Path = "file.sqlite3"
+			config.Database.MaxOpenConns = 10
+			config.Server.Host = "env-host"
+			config.Server.Port = 7002
+			config.LogLevel = LogLevelWarn
+		}},
+		{"Flag overrides env", map[string]string{
+			envConfigFile: configFile,
+			envHost:       "env-host",
+			envPort:       "7002",
+		}, []string{"-port", "7003", "-log-level", "debug"}, func(config *Config) {
+			config.Database.Path = "file.sqlite3"
+			config.Database.MaxOpenConns = 10
+			config.Server.Host = "env-host"
+			config.Server.Port = 7003
+			config.LogLevel = LogLevelDebug
+		}},
+		{"Config flag overrides config env", map[string]string{envConfigFile: "not-exist.yaml"},
+			[]string{"-config", configFile}, func(config *Config) {
+				config.Database.Path = "file.sqlite3"
+				config.Database.MaxOpenConns = 10
+				config.Server.Host = "file-host"
+				config.Server.Port = 7001
+				config.LogLevel = LogLevelWarn
+			}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer setTestEnv(t, test.env)()
+
+			config, _, err := Load("taskboard", test.args)
+			if err != nil {
+				t.Fatalf("Failed to load config: %+v", err)
+			}
+			expected := Default()
+			test.expected(expected)
+			assert.Equal(t, expected, config)
+		})
+	}
+}
+
+func TestLoad_PortEnv(t *testing.T) {
+	defer setTestEnv(t, map[string]string{envPort: "8080"})()
+
+	config, _, err := Load("taskboard", nil)
+	if err != nil {
+		t.Fatalf("Failed to load config: %+v", err)
+	}
+	assert.Equal(t, 8080, config.Server.Port)
+	assert.Equal(t, ":8080", config.ListeningAddress())
+
+	os.Setenv(envPort, "http")
+	_, _, err = Load("taskboard", nil)
+	if assert.Error(t, err) {
+		assert.Equal(t, "environment variable [TASKBOARD_API_SERVER_PORT] must be number, but [http]", err.Error())
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	defer setTestEnv(t, map[string]string{envLogLevel: "trace"})()
+
+	// All invalid settings are reported together
+	_, _, err := Load("taskboard", []string{"-port", "0", "-max-open-conns", "0",
+		"-tls-cert", "cert.pem", "-cors-origins", "example.com"})
+	if assert.Error(t, err) {
+		assert.Equal(t, strings.Join([]string{
+			"invalid configuration:",
+			"  - max open connections must be 1 or more, but 0",
+			"  - max idle connections must be between 0 and max open connections, but 5",
+			"  - port must be between 1 and 65535, but 0",
+			"  - both of TLS cert file and key file must be set to enable TLS",
+			"  - CORS origin [example.com] must be * or scheme://host[:port]",
+			"  - log level must be debug, info, warn or error, but [trace]",
+		}, "\n"), err.Error())
+	}
+}
+
+func TestConfig_ValidateServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "taskboard-config")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile := writeTestConfigFile(t, dir, "")
+
+	config := Default()
+	config.Server.StaticDir = dir
+	config.Server.TLSCertFile = certFile
+	config.Server.TLSKeyFile = certFile
+	assert.Nil(t, config.ValidateServer())
+
+	// Files of api server are checked only by ValidateServer, other commands can be run without them
+	config.Server.StaticDir = filepath.Join(dir, "static")
+	config.Server.TLSKeyFile = filepath.Join(dir, "key.pem")
+	assert.Nil(t, config.Validate())
+	err = config.ValidateServer()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "TLS file ["+config.Server.TLSKeyFile+"] can not be read")
+		assert.Contains(t, err.Error(), "static dir ["+config.Server.StaticDir+"] must be an existing directory")
+	}
+}
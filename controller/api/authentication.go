@@ -0,0 +1,61 @@
+package api
+
+import (
+	"strings"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const bearerPrefix = "Bearer "
+
+// Authenticate is a middleware which rejects requests without valid access token in Authorization header,
+// and stores the user and session of the token to context.
+func Authenticate(c *gin.Context) {
+	authenticate(c, GetAccessToken(c))
+}
+
+// accessTokenParameter is query parameter of access token for clients which can not set headers (e.g. EventSource)
+const accessTokenParameter = "access_token"
+
+// AuthenticateStream is a middleware like Authenticate, but it also accepts access token in access_token query parameter.
+// This must be used only for streaming endpoints which EventSource connects to,
+// tokens in query parameters are exposed to logs and histories.
+func AuthenticateStream(c *gin.Context) {
+	token := GetAccessToken(c)
+	if token == "" && c.GetHeader("Authorization") == "" {
+		token = c.Query(accessTokenParameter)
+	}
+	authenticate(c, token)
+}
+
+// authenticate verifies access token, and stores the user and session of it to context
+func authenticate(c *gin.Context, token string) {
+	if token == "" {
+		SetErrorStatus(c, service.NewSvcError(service.ErrorCodeUnauthenticated, nil,
+			"Access token is not specified in Authorization header"))
+		c.Abort()
+		return
+	}
+	tx := orm.GetDB() // No transaction
+	srvc := service.NewSessionService(tx)
+	user, session, serr := srvc.Authenticate(token)
+	if serr != nil {
+		SetErrorStatus(c, serr)
+		c.Abort()
+		return
+	}
+	SetLoginUser(c, user)
+	SetLoginSession(c, session)
+	c.Next()
+}
+
+// GetAccessToken returns bearer token in Authorization header
+func GetAccessToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, bearerPrefix))
+}
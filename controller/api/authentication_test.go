@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate_AccessTokenParameter(t *testing.T) {
+	// Middlewares authenticate without transaction, so user and session are committed and deleted after test
+	db := orm.GetDB()
+	service.SetTokenSecret([]byte("authentication-test-secret"))
+	user := model.NewUser("authentication-user", "password", "")
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("Failed to create user: %+v", err)
+	}
+	defer db.Delete(user)
+	srvc := service.NewSessionService(db)
+	_, session, serr := srvc.Login(user.Name, "password")
+	if serr != nil {
+		t.Fatalf("Failed to login: %+v", serr)
+	}
+	defer db.Delete(session)
+	token := srvc.AccessToken(session)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.Group("/stream", AuthenticateStream).GET("/events", ok)
+	router.Group("/api", Authenticate).GET("/tasks", ok)
+
+	tests := []struct {
+		name     string
+		url      string
+		header   string
+		expected int
+	}{
+		{"Header", "/api/tasks", "Bearer " + token, http.StatusOK},
+		{"Parameter", "/api/tasks?access_token=" + token, "", http.StatusUnauthorized},
+		{"Stream header", "/stream/events", "Bearer " + token, http.StatusOK},
+		{"Stream parameter", "/stream/events?access_token=" + token, "", http.StatusOK},
+		// Parameter does not override invalid header
+		{"Stream invalid header", "/stream/events?access_token=" + token, "Basic " + token, http.StatusUnauthorized},
+		{"Stream without token", "/stream/events", "", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			if test.header != "" {
+				req.Header.Set("Authorization", test.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, test.expected, w.Code, w.Body.String())
+		})
+	}
+}
@@ -1,11 +1,20 @@
 package api
 
 import (
+	"io"
+	"strings"
+	"taskboard/model"
 	"taskboard/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Keys of values stored in gin.Context
+const (
+	contextKeyLoginUser    = "taskboard.loginUser"
+	contextKeyLoginSession = "taskboard.loginSession"
+)
+
 // GetPathParameter gets path parameter. If value doesn't exist, returns path parameter error.
 func GetPathParameter(c *gin.Context, key string) (string, error) {
 	value := c.Param(key)
@@ -14,3 +23,48 @@ func GetPathParameter(c *gin.Context, key string) (string, error) {
 	}
 	return value, nil
 }
+
+// GetUploadedFile returns content of the form file if request is multipart, otherwise returns request body.
+// Returned reader must be closed.
+func GetUploadedFile(c *gin.Context, formFile string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		return c.Request.Body, nil
+	}
+	header, err := c.FormFile(formFile)
+	if err != nil {
+		return nil, service.NewSvcErrorf(service.ErrorCodeBadRequest, err, "Form file [%s] is required", formFile)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, service.NewSvcError(service.ErrorCodeBadRequest, err, "Failed to open uploaded file")
+	}
+	return file, nil
+}
+
+// SetLoginUser stores authenticated user to context
+func SetLoginUser(c *gin.Context, user *model.User) {
+	c.Set(contextKeyLoginUser, user)
+}
+
+// GetLoginUser returns authenticated user, or nil if the request is not authenticated
+func GetLoginUser(c *gin.Context) *model.User {
+	value, ok := c.Get(contextKeyLoginUser)
+	if !ok {
+		return nil
+	}
+	return value.(*model.User)
+}
+
+// SetLoginSession stores session of authenticated user to context
+func SetLoginSession(c *gin.Context, session *model.Session) {
+	c.Set(contextKeyLoginSession, session)
+}
+
+// GetLoginSession returns session of authenticated user, or nil if the request is not authenticated
+func GetLoginSession(c *gin.Context) *model.Session {
+	value, ok := c.Get(contextKeyLoginSession)
+	if !ok {
+		return nil
+	}
+	return value.(*model.Session)
+}
@@ -43,6 +43,8 @@ func SetErrorStatus(c *gin.Context, err error) {
 		status = http.StatusPreconditionFailed
 	case service.ErrorCodeUnauthenticated:
 		status = http.StatusUnauthorized
+	case service.ErrorCodeForbidden:
+		status = http.StatusForbidden
 	}
 	errorResponse := &ErrorResponse{
 		Code:    string(serr.Code),
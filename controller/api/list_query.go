@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Query parameters of list endpoints
+const (
+	queryLimit  = "limit"
+	queryCursor = "cursor"
+	querySort   = "sort"
+	queryFields = "fields"
+)
+
+// Number of records returned by list endpoints
+const (
+	DefaultListLimit = 100
+	MaxListLimit     = 1000
+)
+
+// Headers of list responses
+const (
+	HeaderTotalCount = "X-Total-Count" // Number of all records matching the query
+	HeaderLink       = "Link"          // Link to the next page as rel="next", not set on the last page
+)
+
+// ListHeaders are headers of list responses which must be exposed to browsers
+var ListHeaders = []string{HeaderTotalCount, HeaderLink}
+
+// ListQuery presents range, order and fields of list specified by query parameters
+type ListQuery struct {
+	After      *orm.Keyset // Page starts after this record, nil for the first page
+	Limit      int
+	SortOrders []string
+	Fields     []string // All fields if empty
+}
+
+// SortKeys maps keys of sort query parameter to columns
+type SortKeys map[string]string
+
+// GetListQuery returns range, order and fields of list specified by query parameters.
+// limit is DefaultListLimit if not specified, cursor is taken from the link to the next page.
+// Cursor is the keyset of the last record of the previous page, so records created or deleted between requests
+// neither shift nor skip records of later pages.
+// sort is comma separated keys of sortKeys, key prefixed by "-" is descending, defaultSortOrders is used if not specified.
+// "id" is added to the end of sort orders so that pages neither overlap nor skip records of the same sort keys.
+// fields is comma separated json names of response, which is a struct of list element.
+func GetListQuery(c *gin.Context, sortKeys SortKeys, defaultSortOrders []string, response interface{}) (*ListQuery, error) {
+	query := &ListQuery{Limit: DefaultListLimit}
+	if limit := c.Query(queryLimit); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > MaxListLimit {
+			return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, err,
+				"Query parameter [%s] must be number between 1 and %d", queryLimit, MaxListLimit)
+		}
+		query.Limit = value
+	}
+
+	query.SortOrders = defaultSortOrders
+	if sortParam := c.Query(querySort); sortParam != "" {
+		sortOrders, serr := service.ParseSortOrders(sortParam, sortKeys)
+		if serr != nil {
+			return nil, serr
+		}
+		query.SortOrders = sortOrders
+	}
+	if len(query.SortOrders) == 0 || query.SortOrders[len(query.SortOrders)-1] != "id" {
+		query.SortOrders = append(append([]string{}, query.SortOrders...), "id")
+	}
+
+	if cursor := c.Query(queryCursor); cursor != "" {
+		after, err := orm.DecodeKeyset(cursor, query.SortOrders)
+		if err != nil {
+			return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, err,
+				"Query parameter [%s] is invalid, use the link to the next page with the same sort", queryCursor)
+		}
+		query.After = after
+	}
+
+	if fields := c.Query(queryFields); fields != "" {
+		names := jsonFieldNames(response)
+		for _, field := range strings.Split(fields, ",") {
+			if !names[field] {
+				return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, nil,
+					"Field [%s] of query parameter [%s] is invalid", field, queryFields)
+			}
+			query.Fields = append(query.Fields, field)
+		}
+	}
+	return query, nil
+}
+
+// FetchLimit returns the number of records to find, which is one more than limit to know whether the next page exists
+func (q *ListQuery) FetchLimit() int {
+	return q.Limit + 1
+}
+
+// WriteList writes a page of list with the total count and the link to the next page in headers.
+// records must be a slice of records found by FetchLimit of query, and res must be a slice of their responses in the same order.
+// Record over limit is not written, it means the next page exists which starts after the last record of this page.
+// Only fields of query are written if specified.
+func WriteList(c *gin.Context, query *ListQuery, totalCount int, records, res interface{}) {
+	c.Header(HeaderTotalCount, strconv.Itoa(totalCount))
+	if reflect.ValueOf(records).Len() > query.Limit {
+		last := reflect.ValueOf(records).Index(query.Limit - 1).Interface()
+		after, err := orm.NewKeyset(last, query.SortOrders)
+		if err != nil {
+			SetErrorStatus(c, service.NewSvcError(service.ErrorCodeUnexpected, err, "Failed to create cursor of the next page"))
+			return
+		}
+		res = reflect.ValueOf(res).Slice(0, query.Limit).Interface()
+		url := *c.Request.URL
+		values := url.Query()
+		values.Set(queryCursor, after.Encode())
+		url.RawQuery = values.Encode()
+		c.Header(HeaderLink, fmt.Sprintf("<%s>; rel=\"next\"", url.RequestURI()))
+	}
+	if len(query.Fields) == 0 {
+		c.IndentedJSON(http.StatusOK, res)
+		return
+	}
+	selected, err := selectFields(res, query.Fields)
+	if err != nil {
+		SetErrorStatus(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, selected)
+}
+
+// selectFields returns elements of res which have only specified fields
+func selectFields(res interface{}, fields []string) ([]map[string]json.RawMessage, error) {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	var elements []map[string]json.RawMessage
+	if err = json.Unmarshal(data, &elements); err != nil {
+		return nil, err
+	}
+	selected := make([]map[string]json.RawMessage, 0, len(elements))
+	for _, element := range elements {
+		values := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			values[field] = element[field]
+		}
+		selected = append(selected, values)
+	}
+	return selected, nil
+}
+
+// jsonFieldNames returns json names of fields of response struct
+func jsonFieldNames(response interface{}) map[string]bool {
+	names := map[string]bool{}
+	t := reflect.TypeOf(response)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+var nextLinkPattern = regexp.MustCompile(`^<(.+)>; rel="next"$`)
+
+// newListTestRouter returns router of list endpoints of labels and tasks which find records in tx
+func newListTestRouter(tx *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/labels", func(c *gin.Context) {
+		query, serr := GetListQuery(c, SortKeys{"name": "name"}, []string{"name"}, model.Label{})
+		if serr != nil {
+			SetErrorStatus(c, serr)
+			return
+		}
+		labels, count, serr := service.NewLabelService(tx, nil).FindLabelsPage(
+			&model.Label{Color: "list-test"}, query.After, query.FetchLimit(), query.SortOrders)
+		if serr != nil {
+			SetErrorStatus(c, serr)
+			return
+		}
+		WriteList(c, query, count, labels, labels)
+	})
+	router.GET("/tasks", func(c *gin.Context) {
+		query, serr := GetListQuery(c, SortKeys(service.TaskSortKeys), service.TaskDefaultSortOrders, model.Task{})
+		if serr != nil {
+			SetErrorStatus(c, serr)
+			return
+		}
+		tasks, count, serr := service.NewTaskService(tx, nil).FindTasksPage(
+			&model.Task{Description: "list-test"}, nil, query.After, query.FetchLimit(), query.SortOrders)
+		if serr != nil {
+			SetErrorStatus(c, serr)
+			return
+		}
+		WriteList(c, query, count, tasks, tasks)
+	})
+	return router
+}
+
+// getListPage requests a page of list, and returns names of records, total count and the link to the next page
+func getListPage(t *testing.T, router *gin.Engine, url string) ([]string, int, string) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status = %d, but got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var records []struct{ Name string }
+	if err := json.Unmarshal(w.Body.Bytes(), &records); err != nil {
+		t.Fatalf("Failed to parse response: %+v", err)
+	}
+	names := []string{}
+	for _, record := range records {
+		names = append(names, record.Name)
+	}
+	totalCount, err := strconv.Atoi(w.Header().Get(HeaderTotalCount))
+	if err != nil {
+		t.Fatalf("Failed to parse total count: %+v", err)
+	}
+	next := ""
+	if link := w.Header().Get(HeaderLink); link != "" {
+		match := nextLinkPattern.FindStringSubmatch(link)
+		if match == nil {
+			t.Fatalf("Link is invalid: %s", link)
+		}
+		next = match[1]
+	}
+	return names, totalCount, next
+}
+
+func createListTestLabels(t *testing.T, tx *gorm.DB, names ...string) {
+	for _, name := range names {
+		if err := tx.Create(model.NewLabel(name, "list-test", time.Now().UTC())).Error; err != nil {
+			t.Fatalf("Failed to create label: %+v", err)
+		}
+	}
+}
+
+func TestListQuery_PageByCursor(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+	router := newListTestRouter(tx)
+
+	createListTestLabels(t, tx, "b", "d", "f", "h")
+	names, totalCount, next := getListPage(t, router, "/labels?limit=2")
+	assert.Equal(t, []string{"b", "d"}, names)
+	assert.Equal(t, 4, totalCount)
+
+	// Records created before the cursor do not shift later pages, records created after it are found
+	createListTestLabels(t, tx, "a", "c", "e")
+	names, totalCount, next = getListPage(t, router, next)
+	assert.Equal(t, []string{"e", "f"}, names)
+	assert.Equal(t, 7, totalCount)
+
+	// Deleting the last record of previous page neither skips nor repeats records
+	if err := tx.Where(&model.Label{Name: "f", Color: "list-test"}).Delete(&model.Label{}).Error; err != nil {
+		t.Fatalf("Failed to delete label: %+v", err)
+	}
+	names, _, next = getListPage(t, router, next)
+	assert.Equal(t, []string{"h"}, names)
+	assert.Equal(t, "", next)
+
+	// The last page which is full has no link to the next page
+	names, _, next = getListPage(t, router, "/labels?limit=6")
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "h"}, names)
+	assert.Equal(t, "", next)
+}
+
+func TestListQuery_DefaultLimit(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+	router := newListTestRouter(tx)
+
+	names := []string{}
+	for i := 0; i <= DefaultListLimit; i++ {
+		names = append(names, fmt.Sprintf("label%03d", i))
+	}
+	createListTestLabels(t, tx, names...)
+	page, totalCount, next := getListPage(t, router, "/labels")
+	assert.Equal(t, names[:DefaultListLimit], page)
+	assert.Equal(t, DefaultListLimit+1, totalCount)
+	page, _, next = getListPage(t, router, next)
+	assert.Equal(t, names[DefaultListLimit:], page)
+	assert.Equal(t, "", next)
+}
+
+func TestListQuery_PageByNullableColumn(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+	router := newListTestRouter(tx)
+	if serr := service.NewWorkflowService(tx, nil).SyncSystemBoards(); serr != nil {
+		t.Fatalf("Failed to create system boards: %+v", serr)
+	}
+
+	// Tasks without due date are sorted first in ascending and last in descending order
+	dueDate := time.Date(2019, 1, 10, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"due1", "none1", "due2", "none2", "due1-2"} {
+		task := model.NewTask(name, "list-test", false, time.Now().UTC())
+		if name[:3] == "due" {
+			due := dueDate.AddDate(0, 0, int(name[3]-'0'))
+			task.DueDate = &due
+		}
+		task.EstimateSize = i
+		if serr := service.NewTaskService(tx, nil).CreateTask(task); serr != nil {
+			t.Fatalf("Failed to create task: %+v", serr)
+		}
+	}
+	tests := []struct {
+		sort     string
+		expected []string
+	}{
+		{"dueDate,name", []string{"none1", "none2", "due1", "due1-2", "due2"}},
+		{"-dueDate,name", []string{"due2", "due1", "due1-2", "none1", "none2"}},
+		{"-dueDate,-estimateSize", []string{"due2", "due1-2", "due1", "none2", "none1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.sort, func(t *testing.T) {
+			names := []string{}
+			next := "/tasks?limit=1&sort=" + test.sort
+			for next != "" {
+				var page []string
+				page, _, next = getListPage(t, router, next)
+				names = append(names, page...)
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestListQuery_InvalidCursor(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+	router := newListTestRouter(tx)
+
+	createListTestLabels(t, tx, "a", "b")
+	_, _, next := getListPage(t, router, "/labels?limit=1")
+	for _, url := range []string{
+		"/labels?cursor=invalid",
+		next + "&sort=-name", // Cursor of other sort
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		assert.Equal(t, http.StatusNotAcceptable, w.Code, url)
+	}
+}
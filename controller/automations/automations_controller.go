@@ -0,0 +1,198 @@
+package automations
+
+import (
+	"net/http"
+	"taskboard/controller/api"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	automations  string
+	automationid string
+	executions   string
+}
+
+// EndPoint presents automations endpoint
+var EndPoint = endPoint{
+	automations:  "/automations",
+	automationid: "automationid",
+	executions:   "/executions",
+}
+
+// RegisterRoute registers API endpoints for automation rules
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.GET(p.automations, list)
+	route.POST(p.automations, create)
+	route.GET(p.automations+"/:"+p.automationid, get)
+	route.PUT(p.automations+"/:"+p.automationid, update)
+	route.DELETE(p.automations+"/:"+p.automationid, delete)
+	route.GET(p.automations+"/:"+p.automationid+p.executions, listExecutions)
+	return
+}
+
+// automationSortKeys are keys of sort query parameter of automation rules
+var automationSortKeys = api.SortKeys{
+	"name":       "name",
+	"isActive":   "is_active",
+	"createDate": "created_date",
+}
+
+// executionSortKeys are keys of sort query parameter of executions
+var executionSortKeys = api.SortKeys{
+	"status":     "status",
+	"taskID":     "task_id",
+	"createDate": "created_date",
+}
+
+// find all automation rules
+func list(c *gin.Context) {
+	query, serr := api.GetListQuery(c, automationSortKeys, []string{"created_date"}, automationResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	srvc := service.NewAutomationService(tx, api.GetLoginUser(c))
+	rules, count, serr := srvc.FindAutomationRulesPage(&model.AutomationRule{}, query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListAutomationResponse(rules)
+	api.WriteList(c, query, count, rules, res)
+}
+
+func create(c *gin.Context) {
+	rule, serr := getAutomationByCreateRequest(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// create automation rule
+	tx := orm.GetDB().Begin()
+	srvc := service.NewAutomationService(tx, api.GetLoginUser(c))
+	serr = srvc.CreateAutomationRule(rule)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertAutomationResponse(rule)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// get an automation rule
+func get(c *gin.Context) {
+	tx := orm.GetDB() // No transaction
+	srvc := service.NewAutomationService(tx, api.GetLoginUser(c))
+	find, err := findAutomationByPathParameter(c, srvc)
+	if err != nil {
+		return
+	}
+	res := convertAutomationResponse(find)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func findAutomationByPathParameter(c *gin.Context, srvc *service.AutomationService) (find *model.AutomationRule, serr error) {
+	ruleID, serr := api.GetPathParameter(c, EndPoint.automationid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	find, serr = srvc.FindAutomationRule(&model.AutomationRule{ID: ruleID})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	return
+}
+
+// update automation rule
+func update(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewAutomationService(tx, api.GetLoginUser(c))
+	find, err := findAutomationByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	rule, serr := getAutomationByUpdateRequest(c, find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// update automation rule
+	serr = srvc.UpdateAutomationRule(rule)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertAutomationResponse(rule)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// delete automation rule
+func delete(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewAutomationService(tx, api.GetLoginUser(c))
+	find, err := findAutomationByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	// delete automation rule
+	serr := srvc.DeleteAutomationRule(find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// find executions of the automation rule, newest first
+func listExecutions(c *gin.Context) {
+	query, serr := api.GetListQuery(c, executionSortKeys, []string{"created_date desc"}, executionResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	srvc := service.NewAutomationService(tx, api.GetLoginUser(c))
+	find, err := findAutomationByPathParameter(c, srvc)
+	if err != nil {
+		return
+	}
+	executions, count, serr := srvc.FindAutomationExecutionsPage(find.ID, query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListExecutionResponse(executions)
+	api.WriteList(c, query, count, executions, res)
+}
@@ -0,0 +1,161 @@
+package automations
+
+import (
+	"taskboard/model"
+	"taskboard/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ID          string    `gorm:"primary_key;size:32"`
+// Name        string    `gorm:"unique;not null;size:255"`
+// Trigger     string    `gorm:"not null;size:16;index"`
+// Condition   string    `gorm:"not null;size:2000"` // Task query language, empty matches all tasks
+// Actions     string    `gorm:"not null;size:8000"` // JSON encoded actions run in order
+// IsActive    bool      `gorm:"not null"`
+// CreatedDate time.Time `gorm:"not null"`
+// Version     int       `gorm:"not null"` // Version for optimistic lock
+
+type automationResponse struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Trigger     string            `json:"trigger"`
+	Condition   string            `json:"condition"`
+	Actions     []*actionResponse `json:"actions"`
+	IsActive    bool              `json:"isActive"`
+	CreatedDate string            `json:"createDate"`
+	Version     int               `json:"version"`
+}
+
+type actionResponse struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type actionRequest struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type createRequest struct {
+	Name      string          `json:"name"`
+	Trigger   string          `json:"trigger"`
+	Condition string          `json:"condition"`
+	Actions   []actionRequest `json:"actions"`
+	IsActive  bool            `json:"isActive"`
+}
+
+type updateRequest struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Trigger   string          `json:"trigger"`
+	Condition string          `json:"condition"`
+	Actions   []actionRequest `json:"actions"`
+	IsActive  bool            `json:"isActive"`
+	Version   int             `json:"version"`
+}
+
+// ID          string    `gorm:"primary_key;size:32"`
+// RuleID      string    `gorm:"not null;size:32;index"`
+// TaskID      string    `gorm:"not null;size:32;index"`
+// Trigger     string    `gorm:"not null;size:16"`
+// Status      string    `gorm:"not null;size:16"`
+// Message     string    `gorm:"size:1000"` // Reason of failure or skip
+// Depth       int       `gorm:"not null"`  // 0 if triggered by user or worker, or depth of rules which triggered it
+// CreatedDate time.Time `gorm:"not null"`
+
+type executionResponse struct {
+	ID          string `json:"id"`
+	RuleID      string `json:"ruleID"`
+	TaskID      string `json:"taskID"`
+	Trigger     string `json:"trigger"`
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+	Depth       int    `json:"depth"`
+	CreatedDate string `json:"createDate"`
+}
+
+func convertAutomationResponse(rule *model.AutomationRule) *automationResponse {
+	actions := rule.GetActions()
+	res := make([]*actionResponse, 0, len(actions))
+	for _, action := range actions {
+		res = append(res, &actionResponse{Type: action.Type, Value: action.Value})
+	}
+	return &automationResponse{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		Trigger:     rule.Trigger,
+		Condition:   rule.Condition,
+		Actions:     res,
+		IsActive:    rule.IsActive,
+		CreatedDate: rule.CreatedDate.Format(time.RFC3339),
+		Version:     rule.Version,
+	}
+}
+
+func convertListAutomationResponse(rules []model.AutomationRule) (res []*automationResponse) {
+	res = make([]*automationResponse, 0, len(rules))
+	for _, rule := range rules {
+		res = append(res, convertAutomationResponse(&rule))
+	}
+	return
+}
+
+func convertExecutionResponse(execution *model.AutomationExecution) *executionResponse {
+	return &executionResponse{
+		ID:          execution.ID,
+		RuleID:      execution.RuleID,
+		TaskID:      execution.TaskID,
+		Trigger:     execution.Trigger,
+		Status:      execution.Status,
+		Message:     execution.Message,
+		Depth:       execution.Depth,
+		CreatedDate: execution.CreatedDate.Format(time.RFC3339),
+	}
+}
+
+func convertListExecutionResponse(executions []model.AutomationExecution) (res []*executionResponse) {
+	res = make([]*executionResponse, 0, len(executions))
+	for _, execution := range executions {
+		res = append(res, convertExecutionResponse(&execution))
+	}
+	return
+}
+
+func convertActionRequests(reqs []actionRequest) []model.AutomationAction {
+	actions := make([]model.AutomationAction, 0, len(reqs))
+	for _, req := range reqs {
+		actions = append(actions, model.AutomationAction{Type: req.Type, Value: req.Value})
+	}
+	return actions
+}
+
+func getAutomationByCreateRequest(c *gin.Context) (*model.AutomationRule, error) {
+	var req createRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return model.NewAutomationRule(req.Name, req.Trigger, req.Condition, convertActionRequests(req.Actions),
+		req.IsActive, time.Now().UTC()), nil
+}
+
+func getAutomationByUpdateRequest(c *gin.Context, find *model.AutomationRule) (*model.AutomationRule, error) {
+	var req updateRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	rule := &model.AutomationRule{
+		ID:          find.ID,
+		Name:        req.Name,
+		Trigger:     req.Trigger,
+		Condition:   req.Condition,
+		IsActive:    req.IsActive,
+		CreatedDate: find.CreatedDate,
+		Version:     req.Version,
+	}
+	rule.SetActions(convertActionRequests(req.Actions))
+	return rule, nil
+}
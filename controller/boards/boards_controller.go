@@ -3,6 +3,7 @@ package boards
 import (
 	"net/http"
 	"taskboard/controller/api"
+	"taskboard/event"
 	"taskboard/model"
 	"taskboard/orm"
 	"taskboard/service"
@@ -11,18 +12,22 @@ import (
 )
 
 type endPoint struct {
-	boards      string
-	boardid     string
-	boardtasks  string
-	boardorders string
-	taskorders  string
+	boards       string
+	boardid      string
+	projectid    string
+	boardtasks   string
+	boardorders  string
+	taskorders   string
+	importtrello string
 }
 
 // EndPoint presents boards endpoint
 var EndPoint = endPoint{
-	boards:      "/boards",
-	boardorders: "/boardorders",
-	boardid:     "boardid",
+	boards:       "/boards",
+	boardorders:  "/boardorders",
+	boardid:      "boardid",
+	projectid:    "projectid",
+	importtrello: "/import/trello",
 }
 
 // RegisterRoute registers API endpoints for boards
@@ -33,20 +38,34 @@ func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
 	route.PUT(p.boards+"/:"+p.boardid, update)
 	route.DELETE(p.boards+"/:"+p.boardid, delete)
 	route.PUT(p.boardorders, updateBoardOrders)
+	route.POST(p.boards+p.importtrello, importTrello)
 	return
 }
 
-// find all boards
+// boardSortKeys are keys of sort query parameter of boards
+var boardSortKeys = api.SortKeys{
+	"name":       "name",
+	"rank":       "rank",
+	"createDate": "created_date",
+	"isClosed":   "is_closed",
+}
+
+// find boards
 func list(c *gin.Context) {
+	query, serr := api.GetListQuery(c, boardSortKeys, []string{"rank"}, boardResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
 	tx := orm.GetDB() // No transction
-	srvc := service.NewBoardService(tx)
-	boards, serr := srvc.FindBoards(&model.Board{}, []string{"disp_order, created_date"})
+	srvc := service.NewBoardService(tx, api.GetLoginUser(c))
+	boards, count, serr := srvc.FindBoardsPage(&model.Board{ProjectID: c.Query(EndPoint.projectid)}, query.After, query.FetchLimit(), query.SortOrders)
 	if serr != nil {
 		api.SetErrorStatus(c, serr)
 		return
 	}
 	res := convertListBoardResponse(boards)
-	c.IndentedJSON(http.StatusOK, res)
+	api.WriteList(c, query, count, boards, res)
 }
 
 func create(c *gin.Context) {
@@ -58,7 +77,7 @@ func create(c *gin.Context) {
 
 	// create board
 	tx := orm.GetDB().Begin()
-	srvc := service.NewBoardService(tx)
+	srvc := service.NewBoardService(tx, api.GetLoginUser(c))
 	serr = srvc.CreateBoard(board)
 	if serr != nil {
 		api.Rollback(tx)
@@ -72,16 +91,16 @@ func create(c *gin.Context) {
 	}
 
 	res := convertBoardResponse(board)
+	event.Publish(event.TypeBoardCreated, []string{board.ID}, res)
 	c.IndentedJSON(http.StatusOK, res)
 }
 
 // get a board
 func get(c *gin.Context) {
 	tx := orm.GetDB() // No transaction
-	srvc := service.NewBoardService(tx)
+	srvc := service.NewBoardService(tx, api.GetLoginUser(c))
 	find, err := findBoardByPathParameter(c, srvc)
 	if err != nil {
-		api.Rollback(tx)
 		return
 	}
 	res := convertBoardResponse(find)
@@ -105,7 +124,7 @@ func findBoardByPathParameter(c *gin.Context, srvc *service.BoardService) (find
 // update board
 func update(c *gin.Context) {
 	tx := orm.GetDB().Begin()
-	srvc := service.NewBoardService(tx)
+	srvc := service.NewBoardService(tx, api.GetLoginUser(c))
 	find, err := findBoardByPathParameter(c, srvc)
 	if err != nil {
 		api.Rollback(tx)
@@ -132,20 +151,28 @@ func update(c *gin.Context) {
 	}
 
 	res := convertBoardResponse(board)
+	event.Publish(event.TypeBoardUpdated, []string{board.ID}, res)
 	c.IndentedJSON(http.StatusOK, res)
 }
 
 // delete board
 func delete(c *gin.Context) {
 	tx := orm.GetDB().Begin()
-	srvc := service.NewBoardService(tx)
+	srvc := service.NewBoardService(tx, api.GetLoginUser(c))
 	find, err := findBoardByPathParameter(c, srvc)
 	if err != nil {
 		api.Rollback(tx)
 		return
 	}
+	// Tasks of deleted board are moved to inbox board of the project
+	inbox, serr := srvc.FindInboxBoard(find.ProjectID)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
 	// delete board
-	serr := srvc.DeleteBoard(find)
+	serr = srvc.DeleteBoard(find)
 	if serr != nil {
 		api.Rollback(tx)
 		api.SetErrorStatus(c, serr)
@@ -156,19 +183,27 @@ func delete(c *gin.Context) {
 		api.SetErrorStatus(c, serr)
 		return
 	}
+	// Tasks of deleted board are moved to inbox board
+	event.Publish(event.TypeBoardDeleted, []string{find.ID, inbox.ID}, convertBoardResponse(find))
 	c.Status(http.StatusOK)
 }
 
-// update order of all boards
+// move a board to specified position
 func updateBoardOrders(c *gin.Context) {
-	req, serr := getUpdateBoardOrdersRequest(c)
+	req, serr := getMoveBoardRequest(c)
 	if serr != nil {
 		api.SetErrorStatus(c, serr)
 		return
 	}
 	tx := orm.GetDB().Begin()
-	srvc := service.NewBoardService(tx)
-	serr = srvc.UpdateBoardOrders(req.BoardIDs)
+	srvc := service.NewBoardService(tx, api.GetLoginUser(c))
+	board, serr := srvc.MoveBoard(req.BoardID, req.AfterBoardID, req.BeforeBoardID)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	boards, serr := srvc.FindBoards(&model.Board{ProjectID: board.ProjectID}, []string{})
 	if serr != nil {
 		api.Rollback(tx)
 		api.SetErrorStatus(c, serr)
@@ -179,5 +214,46 @@ func updateBoardOrders(c *gin.Context) {
 		api.SetErrorStatus(c, serr)
 		return
 	}
-	c.Status(http.StatusOK)
+	res := convertBoardResponse(board)
+	// Order of boards relates to all boards of the project
+	boardIDs := make([]string, 0, len(boards))
+	for _, b := range boards {
+		boardIDs = append(boardIDs, b.ID)
+	}
+	event.Publish(event.TypeBoardReordered, boardIDs, res)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// import boards and tasks from Trello export into the project, default project if not specified
+func importTrello(c *gin.Context) {
+	trello, serr := getTrelloBoard(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	projectID := c.Query(EndPoint.projectid)
+	if projectID == "" {
+		projectID = model.DefaultProjectID
+	}
+	tx := orm.GetDB().Begin()
+	srvc := service.NewTrelloService(tx, api.GetLoginUser(c))
+	result, serr := srvc.Import(projectID, trello)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertTrelloImportResponse(result)
+	// Tasks are loaded with created boards
+	for _, board := range res.Boards {
+		event.Publish(event.TypeBoardCreated, []string{board.ID}, board)
+	}
+	c.IndentedJSON(http.StatusOK, res)
 }
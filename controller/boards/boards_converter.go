@@ -1,6 +1,8 @@
 package boards
 
 import (
+	"encoding/json"
+	"taskboard/controller/api"
 	"taskboard/model"
 	"taskboard/service"
 	"time"
@@ -9,26 +11,37 @@ import (
 )
 
 // ID          string       `gorm:"primary_key;size:32"`
-// Name        string       `gorm:"unique;size:255"`
-// DispOrder   int          `gorm:"not null"`
+// ProjectID   string       `gorm:"not null;size:32;unique_index:idx_boards_project_id_name"`
+// Name        string       `gorm:"size:255;unique_index:idx_boards_project_id_name"` // Unique in project
+// Rank        string       `gorm:"not null;size:255"` // Key to order boards, unique
 // IsSystem    bool         `gorm:"not null"`
-// IsClosed    bool         `gorm:"not null"`
+// SystemKind  string       `gorm:"not null;size:16"` // ID of workflow state of system board, empty for normal board
+// IsClosed    bool         `gorm:"not null"`         // Tasks moved to closed board are closed
+// WIPLimit    int          `gorm:"column:wip_limit;not null"`        // Max number of open tasks, no limit if 0
+// WIPMode     string       `gorm:"column:wip_mode;not null;size:16"` // How to enforce WIP limit, WIPModeWarn or WIPModeBlock
 // CreatedDate time.Time    `gorm:"not null"`
 // Version     int          `gorm:"not null"` // Version for optimistic lock
 
 type boardResponse struct {
 	ID          string `json:"id"`
+	ProjectID   string `json:"projectID"`
 	Name        string `json:"name"`
-	DispOrder   int    `json:"dispOrder"`
+	Rank        string `json:"rank"`
 	IsSystem    bool   `json:"isSystem"`
+	SystemKind  string `json:"systemKind"`
 	IsClosed    bool   `json:"isClosed"`
+	WIPLimit    int    `json:"wipLimit"`
+	WIPMode     string `json:"wipMode"`
 	CreatedDate string `json:"createDate"`
 	Version     int    `json:"version"`
 }
 
 type createRequest struct {
-	Name     string `json:"name"`
-	IsClosed bool   `json:"isClosed"`
+	ProjectID string `json:"projectID"` // Default project if empty
+	Name      string `json:"name"`
+	IsClosed  bool   `json:"isClosed"`
+	WIPLimit  int    `json:"wipLimit"`
+	WIPMode   string `json:"wipMode"`
 }
 
 type updateRequest struct {
@@ -36,20 +49,41 @@ type updateRequest struct {
 	Name     string `json:"name"`
 	IsSystem bool   `json:"isSystem"`
 	IsClosed bool   `json:"isClosed"`
+	WIPLimit int    `json:"wipLimit"`
+	WIPMode  string `json:"wipMode"`
 	Version  int    `json:"version"`
 }
 
-type updateBoardOrdersRequest struct {
-	BoardIDs []string `json:"boardIDs"`
+type moveBoardRequest struct {
+	BoardID       string `json:"boardID"`
+	AfterBoardID  string `json:"afterBoardID"`  // Board is placed right after this if specified
+	BeforeBoardID string `json:"beforeBoardID"` // Board is placed right before this if specified
+}
+
+type trelloUnmappedResponse struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+type trelloImportResponse struct {
+	Boards    []*boardResponse          `json:"boards"`
+	TaskCount int                       `json:"taskCount"`
+	Unmapped  []*trelloUnmappedResponse `json:"unmapped"`
 }
 
 func convertBoardResponse(board *model.Board) *boardResponse {
 	return &boardResponse{
 		ID:          board.ID,
+		ProjectID:   board.ProjectID,
 		Name:        board.Name,
-		DispOrder:   board.DispOrder,
+		Rank:        board.Rank,
 		IsSystem:    board.IsSystem,
+		SystemKind:  board.SystemKind,
 		IsClosed:    board.IsClosed,
+		WIPLimit:    board.WIPLimit,
+		WIPMode:     board.WIPMode,
 		CreatedDate: board.CreatedDate.Format(time.RFC3339),
 		Version:     board.Version,
 	}
@@ -75,6 +109,9 @@ func getBoardByCreateRequest(c *gin.Context) (*model.Board, error) {
 		req.IsClosed,
 		time.Now().UTC(),
 	)
+	board.ProjectID = req.ProjectID
+	board.WIPLimit = req.WIPLimit
+	board.WIPMode = req.WIPMode
 	return board, nil
 }
 
@@ -85,19 +122,60 @@ func getBoardByUpdateRequest(c *gin.Context, find *model.Board) (*model.Board, e
 		return nil, service.NewBadRequestError(err)
 	}
 	return &model.Board{
-		ID:       find.ID,
-		Name:     req.Name,
-		IsSystem: req.IsSystem,
-		IsClosed: req.IsClosed,
-		Version:  req.Version,
+		ID:         find.ID,
+		ProjectID:  find.ProjectID,
+		Name:       req.Name,
+		Rank:       find.Rank,
+		IsSystem:   req.IsSystem,
+		SystemKind: find.SystemKind,
+		IsClosed:   req.IsClosed,
+		WIPLimit:   req.WIPLimit,
+		WIPMode:    req.WIPMode,
+		Version:    req.Version,
 	}, nil
 }
 
-func getUpdateBoardOrdersRequest(c *gin.Context) (*updateBoardOrdersRequest, error) {
-	var req *updateBoardOrdersRequest
+func getMoveBoardRequest(c *gin.Context) (*moveBoardRequest, error) {
+	var req *moveBoardRequest
 	err := c.ShouldBindJSON(&req)
 	if err != nil {
 		return nil, service.NewBadRequestError(err)
 	}
 	return req, nil
 }
+
+// trelloUploadFile is the form field name of uploaded Trello export
+const trelloUploadFile = "file"
+
+func getTrelloBoard(c *gin.Context) (*service.TrelloBoard, error) {
+	reader, serr := api.GetUploadedFile(c, trelloUploadFile)
+	if serr != nil {
+		return nil, serr
+	}
+	defer reader.Close()
+	trello := &service.TrelloBoard{}
+	if err := json.NewDecoder(reader).Decode(trello); err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return trello, nil
+}
+
+func convertTrelloImportResponse(result *service.TrelloImportResult) *trelloImportResponse {
+	res := &trelloImportResponse{
+		Boards:    make([]*boardResponse, 0, len(result.Boards)),
+		TaskCount: len(result.Tasks),
+		Unmapped:  make([]*trelloUnmappedResponse, 0, len(result.Unmapped)),
+	}
+	for _, board := range result.Boards {
+		res.Boards = append(res.Boards, convertBoardResponse(board))
+	}
+	for _, item := range result.Unmapped {
+		res.Unmapped = append(res.Unmapped, &trelloUnmappedResponse{
+			Type:   item.Type,
+			ID:     item.ID,
+			Name:   item.Name,
+			Reason: item.Reason,
+		})
+	}
+	return res
+}
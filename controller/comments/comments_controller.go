@@ -0,0 +1,189 @@
+package comments
+
+import (
+	"net/http"
+	"taskboard/controller/api"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	tasks     string
+	taskid    string
+	comments  string
+	commentid string
+}
+
+// EndPoint presents comments endpoint
+var EndPoint = endPoint{
+	tasks:     "/tasks",
+	taskid:    "taskid",
+	comments:  "/comments",
+	commentid: "commentid",
+}
+
+// RegisterRoute registers API endpoints for comments of task
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	path := p.tasks + "/:" + p.taskid + p.comments
+	route.GET(path, list)
+	route.POST(path, create)
+	route.GET(path+"/:"+p.commentid, get)
+	route.PUT(path+"/:"+p.commentid, update)
+	route.DELETE(path+"/:"+p.commentid, delete)
+	return
+}
+
+// find all comments of the task
+// commentSortKeys are keys of sort query parameter of comments
+var commentSortKeys = api.SortKeys{
+	"createDate": "created_date",
+	"editedDate": "edited_date",
+}
+
+func list(c *gin.Context) {
+	taskID, serr := api.GetPathParameter(c, EndPoint.taskid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	query, serr := api.GetListQuery(c, commentSortKeys, []string{"created_date"}, commentResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	// Comments of tasks which login user can not see are not listed
+	_, serr = service.NewTaskService(tx, api.GetLoginUser(c)).FindTask(&model.Task{ID: taskID})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	srvc := service.NewCommentService(tx, api.GetLoginUser(c))
+	comments, count, serr := srvc.FindCommentsPage(&model.Comment{TaskID: taskID},
+		query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListCommentResponse(comments)
+	api.WriteList(c, query, count, comments, res)
+}
+
+func create(c *gin.Context) {
+	taskID, serr := api.GetPathParameter(c, EndPoint.taskid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	comment, serr := getCommentByCreateRequest(c, taskID, api.GetLoginUser(c))
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// create comment
+	tx := orm.GetDB().Begin()
+	srvc := service.NewCommentService(tx, api.GetLoginUser(c))
+	serr = srvc.CreateComment(comment)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertCommentResponse(comment)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func get(c *gin.Context) {
+	tx := orm.GetDB() // No transaction
+	srvc := service.NewCommentService(tx, api.GetLoginUser(c))
+	find, err := findCommentByPathParameter(c, srvc)
+	if err != nil {
+		return
+	}
+	res := convertCommentResponse(find)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func findCommentByPathParameter(c *gin.Context, srvc *service.CommentService) (find *model.Comment, serr error) {
+	taskID, serr := api.GetPathParameter(c, EndPoint.taskid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	commentID, serr := api.GetPathParameter(c, EndPoint.commentid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	find, serr = srvc.FindComment(&model.Comment{ID: commentID, TaskID: taskID})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	return
+}
+
+func update(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewCommentService(tx, api.GetLoginUser(c))
+	find, err := findCommentByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	comment, serr := getCommentByUpdateRequest(c, find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// update comment
+	serr = srvc.UpdateComment(comment)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertCommentResponse(comment)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func delete(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewCommentService(tx, api.GetLoginUser(c))
+	find, err := findCommentByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	// delete comment
+	serr := srvc.DeleteComment(find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
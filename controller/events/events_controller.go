@@ -0,0 +1,84 @@
+package events
+
+import (
+	"io"
+	"strings"
+	"taskboard/controller/api"
+	"taskboard/event"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	events  string
+	boardid string
+}
+
+// EndPoint presents events endpoint
+var EndPoint = endPoint{
+	events:  "/events",
+	boardid: "boardid",
+}
+
+// keepAliveInterval is interval of ping not to be disconnected by proxies while no events
+const keepAliveInterval = 30 * time.Second
+
+// RegisterRoute registers API endpoints for events
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.GET(p.events, subscribe)
+	return
+}
+
+// subscribe streams events of boards and tasks as server-sent events.
+// Events can be filtered by boardid query parameter, which accepts comma separated board ids.
+// Only events of boards which login user can see are sent.
+func subscribe(c *gin.Context) {
+	srvc := service.NewBoardService(orm.GetDB(), api.GetLoginUser(c)) // No transaction
+	boardIDs := []string{}
+	if boardID := c.Query(EndPoint.boardid); boardID != "" {
+		boardIDs = strings.Split(boardID, ",")
+	}
+	for _, boardID := range boardIDs {
+		if boardID == "" {
+			continue
+		}
+		if _, serr := srvc.FindBoard(&model.Board{ID: boardID}); serr != nil {
+			api.SetErrorStatus(c, serr)
+			return
+		}
+	}
+	subscription := event.Subscribe(boardIDs, event.BoardTypes)
+	defer event.Unsubscribe(subscription)
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e, ok := <-subscription.Events:
+			if !ok {
+				// Subscription is closed because client is too slow, client should reconnect and reload
+				return false
+			}
+			// Visibility is checked for each event, because membership of projects may change while streaming
+			visible, serr := srvc.IsAnyBoardVisible(e.BoardIDs)
+			if serr != nil {
+				return false
+			}
+			if visible {
+				c.SSEvent(e.Type, e)
+			}
+			return true
+		case <-ticker.C:
+			c.SSEvent("ping", time.Now().UTC().Format(time.RFC3339))
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package histories
+
+import (
+	"taskboard/controller/api"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	tasks   string
+	taskid  string
+	history string
+}
+
+// EndPoint presents histories endpoint
+var EndPoint = endPoint{
+	tasks:   "/tasks",
+	taskid:  "taskid",
+	history: "/history",
+}
+
+// RegisterRoute registers API endpoints for histories of task
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.GET(p.tasks+"/:"+p.taskid+p.history, list)
+	return
+}
+
+// historySortKeys are keys of sort query parameter of histories
+var historySortKeys = api.SortKeys{
+	"revision":   "revision",
+	"field":      "field",
+	"createDate": "created_date",
+}
+
+// find histories of the task
+func list(c *gin.Context) {
+	taskID, serr := api.GetPathParameter(c, EndPoint.taskid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	query, serr := api.GetListQuery(c, historySortKeys, []string{"revision", "created_date"}, historyResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	srvc := service.NewTaskService(tx, api.GetLoginUser(c))
+	// Histories of deleted task are kept, but they are not shown
+	_, serr = srvc.FindTask(&model.Task{ID: taskID})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	histories, count, serr := srvc.FindTaskHistoriesPage(taskID, query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListHistoryResponse(histories)
+	api.WriteList(c, query, count, histories, res)
+}
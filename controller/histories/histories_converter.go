@@ -0,0 +1,47 @@
+package histories
+
+import (
+	"taskboard/model"
+	"time"
+)
+
+// ID          string         `gorm:"primary_key;size:32"`
+// TaskID      string         `gorm:"not null;size:32;index"`
+// Revision    int            `gorm:"not null"` // Version of task after the change
+// ActorUserID sql.NullString `gorm:"size:32"`  // Null if changed by system
+// Field       string         `gorm:"not null;size:32"`
+// OldValue    string         `gorm:"size:8000"`
+// NewValue    string         `gorm:"size:8000"`
+// CreatedDate time.Time      `gorm:"not null"`
+
+type historyResponse struct {
+	ID          string `json:"id"`
+	TaskID      string `json:"taskID"`
+	Revision    int    `json:"revision"`
+	ActorUserID string `json:"actorUserID"`
+	Field       string `json:"field"`
+	OldValue    string `json:"oldValue"`
+	NewValue    string `json:"newValue"`
+	CreatedDate string `json:"createDate"`
+}
+
+func convertHistoryResponse(history *model.TaskHistory) *historyResponse {
+	return &historyResponse{
+		ID:          history.ID,
+		TaskID:      history.TaskID,
+		Revision:    history.Revision,
+		ActorUserID: history.ActorUserID.String,
+		Field:       history.Field,
+		OldValue:    history.OldValue,
+		NewValue:    history.NewValue,
+		CreatedDate: history.CreatedDate.Format(time.RFC3339),
+	}
+}
+
+func convertListHistoryResponse(histories []model.TaskHistory) (res []*historyResponse) {
+	res = make([]*historyResponse, 0, len(histories))
+	for _, history := range histories {
+		res = append(res, convertHistoryResponse(&history))
+	}
+	return
+}
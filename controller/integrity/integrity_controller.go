@@ -0,0 +1,66 @@
+package integrity
+
+import (
+	"net/http"
+	"taskboard/controller/api"
+	"taskboard/event"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	integrity string
+	repair    string
+}
+
+// EndPoint presents integrity endpoint
+var EndPoint = endPoint{
+	integrity: "/integrity",
+	repair:    "/repair",
+}
+
+// RegisterRoute registers API endpoints for checking and repairing integrity of boards and tasks
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.GET(p.integrity, check)
+	route.POST(p.integrity+p.repair, repair)
+	return
+}
+
+// check integrity without changing anything
+func check(c *gin.Context) {
+	tx := orm.GetDB() // No transction
+	srvc := service.NewIntegrityService(tx, api.GetLoginUser(c))
+	report, serr := srvc.Check()
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertReportResponse(report)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// repair issues in a transaction
+func repair(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewIntegrityService(tx, api.GetLoginUser(c))
+	report, serr := srvc.Repair()
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertReportResponse(report)
+	if len(report.Issues) > 0 {
+		// Repair may change order and tasks of any boards, event without boards is sent only to admin
+		event.Publish(event.TypeBoardReordered, nil, res)
+	}
+	c.IndentedJSON(http.StatusOK, res)
+}
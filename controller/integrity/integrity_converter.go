@@ -0,0 +1,38 @@
+package integrity
+
+import (
+	"taskboard/service"
+	"time"
+)
+
+type issueResponse struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+type reportResponse struct {
+	CheckedDate string           `json:"checkedDate"`
+	BoardCount  int              `json:"boardCount"`
+	TaskCount   int              `json:"taskCount"`
+	Issues      []*issueResponse `json:"issues"`
+	Repaired    bool             `json:"repaired"`
+}
+
+func convertReportResponse(report *service.IntegrityReport) *reportResponse {
+	res := &reportResponse{
+		CheckedDate: report.CheckedDate.Format(time.RFC3339),
+		BoardCount:  report.BoardCount,
+		TaskCount:   report.TaskCount,
+		Issues:      make([]*issueResponse, 0, len(report.Issues)),
+		Repaired:    report.Repaired,
+	}
+	for _, issue := range report.Issues {
+		res.Issues = append(res.Issues, &issueResponse{
+			Type:    issue.Type,
+			ID:      issue.ID,
+			Message: issue.Message,
+		})
+	}
+	return res
+}
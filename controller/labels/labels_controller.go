@@ -0,0 +1,199 @@
+package labels
+
+import (
+	"net/http"
+	"taskboard/controller/api"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	labels  string
+	labelid string
+	tasks   string
+	taskid  string
+}
+
+// EndPoint presents labels endpoint
+var EndPoint = endPoint{
+	labels:  "/labels",
+	labelid: "labelid",
+	tasks:   "/tasks",
+	taskid:  "taskid",
+}
+
+// RegisterRoute registers API endpoints for labels
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.GET(p.labels, list)
+	route.POST(p.labels, create)
+	route.GET(p.labels+"/:"+p.labelid, get)
+	route.PUT(p.labels+"/:"+p.labelid, update)
+	route.DELETE(p.labels+"/:"+p.labelid, delete)
+	route.PUT(p.tasks+"/:"+p.taskid+p.labels, updateTaskLabels)
+	return
+}
+
+// find all labels
+// labelSortKeys are keys of sort query parameter of labels
+var labelSortKeys = api.SortKeys{
+	"name":       "name",
+	"color":      "color",
+	"createDate": "created_date",
+}
+
+func list(c *gin.Context) {
+	query, serr := api.GetListQuery(c, labelSortKeys, []string{"name"}, labelResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	srvc := service.NewLabelService(tx, api.GetLoginUser(c))
+	labels, count, serr := srvc.FindLabelsPage(&model.Label{}, query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListLabelResponse(labels)
+	api.WriteList(c, query, count, labels, res)
+}
+
+func create(c *gin.Context) {
+	label, serr := getLabelByCreateRequest(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// create label
+	tx := orm.GetDB().Begin()
+	srvc := service.NewLabelService(tx, api.GetLoginUser(c))
+	serr = srvc.CreateLabel(label)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertLabelResponse(label)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// get a label
+func get(c *gin.Context) {
+	tx := orm.GetDB() // No transaction
+	srvc := service.NewLabelService(tx, api.GetLoginUser(c))
+	find, err := findLabelByPathParameter(c, srvc)
+	if err != nil {
+		return
+	}
+	res := convertLabelResponse(find)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func findLabelByPathParameter(c *gin.Context, srvc *service.LabelService) (find *model.Label, serr error) {
+	labelID, serr := api.GetPathParameter(c, EndPoint.labelid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	find, serr = srvc.FindLabel(&model.Label{ID: labelID})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	return
+}
+
+// update label
+func update(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewLabelService(tx, api.GetLoginUser(c))
+	find, err := findLabelByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	label, serr := getLabelByUpdateRequest(c, find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// update label
+	serr = srvc.UpdateLabel(label)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertLabelResponse(label)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// delete label
+func delete(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewLabelService(tx, api.GetLoginUser(c))
+	find, err := findLabelByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	// delete label
+	serr := srvc.DeleteLabel(find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// replace labels of a task
+func updateTaskLabels(c *gin.Context) {
+	taskID, serr := api.GetPathParameter(c, EndPoint.taskid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	req, serr := getUpdateTaskLabelsRequest(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB().Begin()
+	srvc := service.NewLabelService(tx, api.GetLoginUser(c))
+	serr = srvc.SetTaskLabels(taskID, req.LabelIDs)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,90 @@
+package labels
+
+import (
+	"taskboard/model"
+	"taskboard/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ID          string    `gorm:"primary_key;size:32"`
+// Name        string    `gorm:"unique;not null;size:255"`
+// Color       string    `gorm:"not null;size:7"` // Format is #rrggbb
+// CreatedDate time.Time `gorm:"not null"`
+// Version     int       `gorm:"not null"` // Version for optimistic lock
+
+type labelResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	CreatedDate string `json:"createDate"`
+	Version     int    `json:"version"`
+}
+
+type createRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type updateRequest struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Color   string `json:"color"`
+	Version int    `json:"version"`
+}
+
+type updateTaskLabelsRequest struct {
+	LabelIDs []string `json:"labelIDs"`
+}
+
+func convertLabelResponse(label *model.Label) *labelResponse {
+	return &labelResponse{
+		ID:          label.ID,
+		Name:        label.Name,
+		Color:       label.Color,
+		CreatedDate: label.CreatedDate.Format(time.RFC3339),
+		Version:     label.Version,
+	}
+}
+
+func convertListLabelResponse(labels []model.Label) (res []*labelResponse) {
+	res = make([]*labelResponse, 0, len(labels))
+	for _, label := range labels {
+		res = append(res, convertLabelResponse(&label))
+	}
+	return
+}
+
+func getLabelByCreateRequest(c *gin.Context) (*model.Label, error) {
+	var req createRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return model.NewLabel(req.Name, req.Color, time.Now().UTC()), nil
+}
+
+func getLabelByUpdateRequest(c *gin.Context, find *model.Label) (*model.Label, error) {
+	var req updateRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return &model.Label{
+		ID:          find.ID,
+		Name:        req.Name,
+		Color:       req.Color,
+		CreatedDate: find.CreatedDate,
+		Version:     req.Version,
+	}, nil
+}
+
+func getUpdateTaskLabelsRequest(c *gin.Context) (*updateTaskLabelsRequest, error) {
+	var req updateTaskLabelsRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return &req, nil
+}
@@ -0,0 +1,214 @@
+package projects
+
+import (
+	"net/http"
+	"taskboard/controller/api"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	projects  string
+	projectid string
+	members   string
+}
+
+// EndPoint presents projects endpoint
+var EndPoint = endPoint{
+	projects:  "/projects",
+	projectid: "projectid",
+	members:   "/members",
+}
+
+// RegisterRoute registers API endpoints for projects
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.GET(p.projects, list)
+	route.POST(p.projects, create)
+	route.GET(p.projects+"/:"+p.projectid, get)
+	route.PUT(p.projects+"/:"+p.projectid, update)
+	route.DELETE(p.projects+"/:"+p.projectid, delete)
+	route.GET(p.projects+"/:"+p.projectid+p.members, listMembers)
+	route.PUT(p.projects+"/:"+p.projectid+p.members, updateMembers)
+	return
+}
+
+// projectSortKeys are keys of sort query parameter of projects
+var projectSortKeys = api.SortKeys{
+	"name":       "name",
+	"createDate": "created_date",
+}
+
+// find projects which login user is a member of
+func list(c *gin.Context) {
+	query, serr := api.GetListQuery(c, projectSortKeys, []string{"name"}, projectResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	srvc := service.NewProjectService(tx, api.GetLoginUser(c))
+	projects, count, serr := srvc.FindProjectsPage(query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListProjectResponse(projects)
+	api.WriteList(c, query, count, projects, res)
+}
+
+func create(c *gin.Context) {
+	project, serr := getProjectByCreateRequest(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// create project with its system boards
+	tx := orm.GetDB().Begin()
+	srvc := service.NewProjectService(tx, api.GetLoginUser(c))
+	serr = srvc.CreateProject(project)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertProjectResponse(project)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// get a project
+func get(c *gin.Context) {
+	tx := orm.GetDB() // No transaction
+	srvc := service.NewProjectService(tx, api.GetLoginUser(c))
+	find, err := findProjectByPathParameter(c, srvc)
+	if err != nil {
+		return
+	}
+	res := convertProjectResponse(find)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func findProjectByPathParameter(c *gin.Context, srvc *service.ProjectService) (find *model.Project, serr error) {
+	projectID, serr := api.GetPathParameter(c, EndPoint.projectid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	find, serr = srvc.FindProject(&model.Project{ID: projectID})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	return
+}
+
+// update project
+func update(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewProjectService(tx, api.GetLoginUser(c))
+	find, err := findProjectByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	project, serr := getProjectByUpdateRequest(c, find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// update project
+	serr = srvc.UpdateProject(project)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertProjectResponse(project)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// delete project
+func delete(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewProjectService(tx, api.GetLoginUser(c))
+	find, err := findProjectByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	// delete project
+	serr := srvc.DeleteProject(find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// find members of a project
+func listMembers(c *gin.Context) {
+	projectID, serr := api.GetPathParameter(c, EndPoint.projectid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transaction
+	srvc := service.NewProjectService(tx, api.GetLoginUser(c))
+	userIDs, serr := srvc.FindProjectMemberIDs(projectID)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, &membersResponse{UserIDs: userIDs})
+}
+
+// replace members of a project
+func updateMembers(c *gin.Context) {
+	projectID, serr := api.GetPathParameter(c, EndPoint.projectid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	req, serr := getUpdateMembersRequest(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB().Begin()
+	srvc := service.NewProjectService(tx, api.GetLoginUser(c))
+	serr = srvc.SetProjectMembers(projectID, req.UserIDs)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
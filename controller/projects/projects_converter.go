@@ -0,0 +1,88 @@
+package projects
+
+import (
+	"taskboard/model"
+	"taskboard/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ID          string    `gorm:"primary_key;size:32"`
+// Name        string    `gorm:"unique;not null;size:255"`
+// CreatedDate time.Time `gorm:"not null"`
+// Version     int       `gorm:"not null"` // Version for optimistic lock
+
+type projectResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	CreatedDate string `json:"createDate"`
+	Version     int    `json:"version"`
+}
+
+type createRequest struct {
+	Name string `json:"name"`
+}
+
+type updateRequest struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+type membersResponse struct {
+	UserIDs []string `json:"userIDs"`
+}
+
+type updateMembersRequest struct {
+	UserIDs []string `json:"userIDs"`
+}
+
+func convertProjectResponse(project *model.Project) *projectResponse {
+	return &projectResponse{
+		ID:          project.ID,
+		Name:        project.Name,
+		CreatedDate: project.CreatedDate.Format(time.RFC3339),
+		Version:     project.Version,
+	}
+}
+
+func convertListProjectResponse(projects []model.Project) (res []*projectResponse) {
+	res = make([]*projectResponse, 0, len(projects))
+	for _, project := range projects {
+		res = append(res, convertProjectResponse(&project))
+	}
+	return
+}
+
+func getProjectByCreateRequest(c *gin.Context) (*model.Project, error) {
+	var req createRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return model.NewProject(req.Name, time.Now().UTC()), nil
+}
+
+func getProjectByUpdateRequest(c *gin.Context, find *model.Project) (*model.Project, error) {
+	var req updateRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return &model.Project{
+		ID:          find.ID,
+		Name:        req.Name,
+		CreatedDate: find.CreatedDate,
+		Version:     req.Version,
+	}, nil
+}
+
+func getUpdateMembersRequest(c *gin.Context) (*updateMembersRequest, error) {
+	var req updateMembersRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return &req, nil
+}
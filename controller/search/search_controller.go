@@ -0,0 +1,46 @@
+package search
+
+import (
+	"taskboard/controller/api"
+	"taskboard/orm"
+	"taskboard/repository"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	search string
+	query  string
+}
+
+// EndPoint presents search endpoint
+var EndPoint = endPoint{
+	search: "/search",
+	query:  "q",
+}
+
+// RegisterRoute registers API endpoints for full-text search
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.GET(p.search, search)
+	return
+}
+
+// search tasks and comments, hits are ordered by relevance
+func search(c *gin.Context) {
+	// Hits can not be sorted by other keys
+	query, serr := api.GetListQuery(c, api.SortKeys{}, repository.SearchSortOrders, searchHitResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	srvc := service.NewSearchService(tx, api.GetLoginUser(c))
+	hits, count, serr := srvc.Search(c.Query(EndPoint.query), query.After, query.FetchLimit())
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListSearchHitResponse(hits)
+	api.WriteList(c, query, count, hits, res)
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"taskboard/repository"
+)
+
+// Kind     string
+// ID       string // ID of task or comment
+// TaskID   string // ID of task, or task of comment
+// TaskName string
+// Snippet  string  // Text around matched words which are marked by SearchHighlightStart and SearchHighlightEnd
+// Score    float64 // Relevance by bm25, larger is more relevant
+
+type searchHitResponse struct {
+	Type     string  `json:"type"` // "task" or "comment"
+	ID       string  `json:"id"`
+	TaskID   string  `json:"taskID"`
+	TaskName string  `json:"taskName"`
+	Snippet  string  `json:"snippet"` // HTML escaped, matched words are enclosed by <mark>
+	Score    float64 `json:"score"`
+}
+
+// snippetHighlighter replaces markers of matched words with html tags
+var snippetHighlighter = strings.NewReplacer(
+	repository.SearchHighlightStart, "<mark>",
+	repository.SearchHighlightEnd, "</mark>",
+)
+
+func convertSearchHitResponse(hit *repository.SearchHit) *searchHitResponse {
+	return &searchHitResponse{
+		Type:     hit.Kind,
+		ID:       hit.ID,
+		TaskID:   hit.TaskID,
+		TaskName: hit.TaskName,
+		Snippet:  snippetHighlighter.Replace(html.EscapeString(hit.Snippet)),
+		Score:    hit.Score,
+	}
+}
+
+func convertListSearchHitResponse(hits []repository.SearchHit) (res []*searchHitResponse) {
+	res = make([]*searchHitResponse, 0, len(hits))
+	for _, hit := range hits {
+		res = append(res, convertSearchHitResponse(&hit))
+	}
+	return
+}
@@ -0,0 +1,195 @@
+package sessions
+
+import (
+	"net/http"
+	"taskboard/controller/api"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	login     string
+	refresh   string
+	logout    string
+	sessions  string
+	sessionid string
+}
+
+// EndPoint presents sessions endpoint
+var EndPoint = endPoint{
+	login:     "/login",
+	refresh:   "/refresh",
+	logout:    "/logout",
+	sessions:  "/sessions",
+	sessionid: "sessionid",
+}
+
+// RegisterPublicRoute registers API endpoints for sessions which can be called without login
+func (p *endPoint) RegisterPublicRoute(route *gin.RouterGroup) (err error) {
+	route.POST(p.login, login)
+	route.POST(p.refresh, refresh)
+	return
+}
+
+// RegisterRoute registers API endpoints for sessions
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.POST(p.logout, logout)
+	route.GET(p.sessions, list)
+	route.DELETE(p.sessions, deleteAll)
+	route.DELETE(p.sessions+"/:"+p.sessionid, delete)
+	return
+}
+
+func login(c *gin.Context) {
+	req, serr := getLoginRequest(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB().Begin()
+	srvc := service.NewSessionService(tx)
+	user, session, serr := srvc.Login(req.Name, req.Password)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertLoginResponse(srvc, user, session)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func refresh(c *gin.Context) {
+	req, serr := getRefreshRequest(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB().Begin()
+	srvc := service.NewSessionService(tx)
+	session, serr := srvc.Refresh(req.RefreshToken)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertTokenResponse(srvc, session)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// revoke the session of current access token
+func logout(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewSessionService(tx)
+	serr := srvc.RevokeSession(api.GetLoginSession(c))
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// sessionSortKeys are keys of sort query parameter of sessions
+var sessionSortKeys = api.SortKeys{
+	"createDate":  "created_date",
+	"expiredDate": "expired_date",
+}
+
+// find sessions of login user
+func list(c *gin.Context) {
+	query, serr := api.GetListQuery(c, sessionSortKeys, []string{"created_date desc"}, sessionResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	srvc := service.NewSessionService(tx)
+	loginUser := api.GetLoginUser(c)
+	sessions, count, serr := srvc.FindSessionsPage(&model.Session{UserID: loginUser.ID},
+		query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListSessionResponse(sessions)
+	api.WriteList(c, query, count, sessions, res)
+}
+
+func findSessionByPathParameter(c *gin.Context, srvc *service.SessionService) (find *model.Session, serr error) {
+	sessionID, serr := api.GetPathParameter(c, EndPoint.sessionid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	// Only admin can find sessions of other users
+	condition := &model.Session{ID: sessionID}
+	loginUser := api.GetLoginUser(c)
+	if !loginUser.HasRole(model.RoleAdmin) {
+		condition.UserID = loginUser.ID
+	}
+	find, serr = srvc.FindSession(condition)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	return
+}
+
+// revoke specified session, this is used to kill stolen token
+func delete(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewSessionService(tx)
+	find, err := findSessionByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	serr := srvc.RevokeSession(find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// revoke all sessions of login user
+func deleteAll(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewSessionService(tx)
+	serr := srvc.RevokeUserSessions(api.GetLoginUser(c).ID)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,110 @@
+package sessions
+
+import (
+	"taskboard/model"
+	"taskboard/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ID                 string    `gorm:"primary_key;size:32"`
+// UserID             string    `gorm:"not null;size:32;index"`
+// ExpiredDate        time.Time `gorm:"not null"` // Expiration of latest access token
+// RefreshExpiredDate time.Time `gorm:"not null"` // Expiration of refresh token
+// IsRevoked          bool      `gorm:"not null"`
+// CreatedDate        time.Time `gorm:"not null"`
+// Version            int       `gorm:"not null"` // Version for optimistic lock
+
+type loginRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type tokenResponse struct {
+	SessionID          string `json:"sessionID"`
+	AccessToken        string `json:"accessToken"`
+	RefreshToken       string `json:"refreshToken"`
+	ExpiredDate        string `json:"expiredDate"`
+	RefreshExpiredDate string `json:"refreshExpiredDate"`
+}
+
+type loginResponse struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	Avator  string         `json:"avator"`
+	Version int            `json:"version"`
+	Token   *tokenResponse `json:"token"`
+}
+
+type sessionResponse struct {
+	ID                 string `json:"id"`
+	UserID             string `json:"userID"`
+	ExpiredDate        string `json:"expiredDate"`
+	RefreshExpiredDate string `json:"refreshExpiredDate"`
+	IsRevoked          bool   `json:"isRevoked"`
+	CreatedDate        string `json:"createDate"`
+	Version            int    `json:"version"`
+}
+
+func getLoginRequest(c *gin.Context) (*loginRequest, error) {
+	var req loginRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return &req, nil
+}
+
+func getRefreshRequest(c *gin.Context) (*refreshRequest, error) {
+	var req refreshRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return &req, nil
+}
+
+func convertTokenResponse(srvc *service.SessionService, session *model.Session) *tokenResponse {
+	return &tokenResponse{
+		SessionID:          session.ID,
+		AccessToken:        srvc.AccessToken(session),
+		RefreshToken:       srvc.RefreshToken(session),
+		ExpiredDate:        session.ExpiredDate.Format(time.RFC3339),
+		RefreshExpiredDate: session.RefreshExpiredDate.Format(time.RFC3339),
+	}
+}
+
+func convertLoginResponse(srvc *service.SessionService, user *model.User, session *model.Session) *loginResponse {
+	return &loginResponse{
+		ID:      user.ID,
+		Name:    user.Name,
+		Avator:  user.Avator,
+		Version: user.Version,
+		Token:   convertTokenResponse(srvc, session),
+	}
+}
+
+func convertSessionResponse(session *model.Session) *sessionResponse {
+	return &sessionResponse{
+		ID:                 session.ID,
+		UserID:             session.UserID,
+		ExpiredDate:        session.ExpiredDate.Format(time.RFC3339),
+		RefreshExpiredDate: session.RefreshExpiredDate.Format(time.RFC3339),
+		IsRevoked:          session.IsRevoked,
+		CreatedDate:        session.CreatedDate.Format(time.RFC3339),
+		Version:            session.Version,
+	}
+}
+
+func convertListSessionResponse(sessions []model.Session) (res []*sessionResponse) {
+	res = make([]*sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, convertSessionResponse(&session))
+	}
+	return
+}
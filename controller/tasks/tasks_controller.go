@@ -2,10 +2,15 @@ package tasks
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 	"taskboard/controller/api"
+	"taskboard/event"
 	"taskboard/model"
 	"taskboard/orm"
+	"taskboard/repository"
 	"taskboard/service"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,7 +19,20 @@ type endPoint struct {
 	tasks      string
 	taskorders string
 	taskid     string
+	revert     string
 	boardid    string
+	projectid  string
+	labels     string
+	labelmatch string
+	overdue    string
+	duebefore  string
+	duewithin  string
+	exportcsv  string
+	importcsv  string
+	columns    string
+	query      string
+	views      string
+	viewid     string
 }
 
 // EndPoint presents boards endpoint
@@ -22,48 +40,230 @@ var EndPoint = endPoint{
 	tasks:      "/tasks",
 	taskorders: "/taskorders",
 	taskid:     "taskid",
+	revert:     "/revert",
 	boardid:    "boardid",
+	projectid:  "projectid",
+	labels:     "labels",
+	labelmatch: "labelmatch",
+	overdue:    "overdue",
+	duebefore:  "duebefore",
+	duewithin:  "duewithin",
+	exportcsv:  "export.csv",
+	importcsv:  "import",
+	columns:    "columns",
+	query:      "q",
+	views:      "/views",
+	viewid:     "viewid",
 }
 
+// Values of labelmatch query parameter
+const (
+	labelMatchAll = "all"
+	labelMatchAny = "any"
+)
+
 // RegisterRoute registers API endpoints for tasks
 func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
 	route.GET(p.tasks, list)
 	route.POST(p.tasks, create)
-	route.GET(p.tasks+"/:"+p.taskid, get)
+	// Static paths can not be registered at the position of :taskid, so export and import are dispatched by handlers
+	route.GET(p.tasks+"/:"+p.taskid, getOrExport)
+	route.POST(p.tasks+"/:"+p.taskid, importTasks)
 	route.PUT(p.tasks+"/:"+p.taskid, update)
 	route.DELETE(p.tasks+"/:"+p.taskid, delete)
+	route.POST(p.tasks+"/:"+p.taskid+p.revert, revert)
 	route.PUT(p.taskorders, updateTaskOrders)
+	route.GET(p.views+"/:"+p.viewid+p.tasks, executeView)
 	return
 }
 
+// taskSortKeys are keys of sort query parameter of tasks
+var taskSortKeys = api.SortKeys(service.TaskSortKeys)
+
 func list(c *gin.Context) {
+	query, serr := api.GetListQuery(c, taskSortKeys, service.TaskDefaultSortOrders, taskResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	condition, filter, serr := getTaskCondition(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
 	tx := orm.GetDB() // No transction
-	srvc := service.NewTaskService(tx)
-	boardID := c.Query(EndPoint.boardid)
-	condition := &model.Task{}
-	if boardID != "" {
-		condition = &model.Task{BoardID: boardID}
+	srvc := service.NewTaskService(tx, api.GetLoginUser(c))
+	tasks, count, serr := srvc.FindTasksPage(condition, filter, query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	taskIDs := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
 	}
-	tasks, serr := srvc.FindTasks(condition, []string{"disp_order, created_date, name"})
+	taskLabelIDs, serr := service.NewLabelService(tx, api.GetLoginUser(c)).FindTaskLabelIDs(taskIDs)
 	if serr != nil {
 		api.SetErrorStatus(c, serr)
 		return
 	}
-	res := convertListTaskResponse(tasks)
-	c.IndentedJSON(http.StatusOK, res)
+	res := convertListTaskResponse(tasks, taskLabelIDs)
+	api.WriteList(c, query, count, tasks, res)
+}
+
+// find tasks matching a saved view, sorted by the sort of the view unless sort is specified
+func executeView(c *gin.Context) {
+	viewID, serr := api.GetPathParameter(c, EndPoint.viewid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	srvc := service.NewSavedViewService(tx, api.GetLoginUser(c))
+	view, serr := srvc.FindSavedView(&model.SavedView{ID: viewID})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	viewSortOrders, serr := service.SavedViewSortOrders(view)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	query, serr := api.GetListQuery(c, taskSortKeys, viewSortOrders, taskResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tasks, count, serr := srvc.ExecuteSavedViewPage(view, query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	taskIDs := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+	taskLabelIDs, serr := service.NewLabelService(tx, api.GetLoginUser(c)).FindTaskLabelIDs(taskIDs)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListTaskResponse(tasks, taskLabelIDs)
+	api.WriteList(c, query, count, tasks, res)
+}
+
+// findTasksByQuery returns all tasks matching board and filter specified by query parameters
+func findTasksByQuery(c *gin.Context, srvc *service.TaskService) ([]model.Task, error) {
+	condition, filter, serr := getTaskCondition(c)
+	if serr != nil {
+		return nil, serr
+	}
+	return srvc.FindTasks(condition, filter, service.TaskDefaultSortOrders)
+}
+
+// getTaskCondition returns condition of board and filter specified by query parameters
+func getTaskCondition(c *gin.Context) (*model.Task, *repository.TaskFilter, error) {
+	condition := &model.Task{BoardID: c.Query(EndPoint.boardid)}
+	filter, serr := getTaskFilter(c)
+	if serr != nil {
+		return nil, nil, serr
+	}
+	return condition, filter, nil
+}
+
+// getTaskFilter returns filter of tasks specified by query parameters
+func getTaskFilter(c *gin.Context) (*repository.TaskFilter, error) {
+	filter := &repository.TaskFilter{}
+	if projectID := c.Query(EndPoint.projectid); projectID != "" {
+		filter.ProjectIDs = []string{projectID}
+	}
+	if labels := c.Query(EndPoint.labels); labels != "" {
+		filter.LabelIDs = strings.Split(labels, ",")
+	}
+	switch labelMatch := c.Query(EndPoint.labelmatch); labelMatch {
+	case labelMatchAll:
+		filter.LabelMatchAll = true
+	case labelMatchAny, "":
+		filter.LabelMatchAll = false
+	default:
+		return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, nil,
+			"Query parameter [%s] must be [%s] or [%s]", EndPoint.labelmatch, labelMatchAll, labelMatchAny)
+	}
+	if text := c.Query(EndPoint.query); text != "" {
+		query, serr := service.ParseTaskQuery(text, api.GetLoginUser(c))
+		if serr != nil {
+			return nil, serr
+		}
+		filter.Query = query
+	}
+	now := time.Now().UTC()
+	// overdue and duewithin are for views of open tasks, closed tasks are excluded
+	if overdue := c.Query(EndPoint.overdue); overdue != "" {
+		isOverdue, err := strconv.ParseBool(overdue)
+		if err != nil {
+			return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, err,
+				"Query parameter [%s] must be boolean", EndPoint.overdue)
+		}
+		if isOverdue {
+			setDueBefore(filter, now)
+			filter.IsOpenOnly = true
+		}
+	}
+	if dueBefore := c.Query(EndPoint.duebefore); dueBefore != "" {
+		date, err := time.Parse(time.RFC3339, dueBefore)
+		if err != nil {
+			return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, err,
+				"Query parameter [%s] must be RFC3339 date", EndPoint.duebefore)
+		}
+		setDueBefore(filter, date.UTC())
+	}
+	if dueWithin := c.Query(EndPoint.duewithin); dueWithin != "" {
+		days, err := strconv.Atoi(dueWithin)
+		if err != nil || days < 0 {
+			return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, err,
+				"Query parameter [%s] must be number of days", EndPoint.duewithin)
+		}
+		filter.DueFrom = &now
+		setDueBefore(filter, now.AddDate(0, 0, days))
+		filter.IsOpenOnly = true
+	}
+	return filter, nil
+}
+
+// setDueBefore narrows due date range of filter
+func setDueBefore(filter *repository.TaskFilter, date time.Time) {
+	if filter.DueBefore == nil || date.Before(*filter.DueBefore) {
+		filter.DueBefore = &date
+	}
+}
+
+// findTaskLabelIDs returns label ids of specified task
+func findTaskLabelIDs(srvc *service.LabelService, taskID string) ([]string, error) {
+	taskLabelIDs, serr := srvc.FindTaskLabelIDs([]string{taskID})
+	if serr != nil {
+		return nil, serr
+	}
+	return taskLabelIDs[taskID], nil
 }
 
 func create(c *gin.Context) {
-	task, serr := getTaskByCreateRequest(c)
+	task, labelIDs, projectID, serr := getTaskByCreateRequest(c)
 	if serr != nil {
 		api.SetErrorStatus(c, serr)
 		return
 	}
 
-	// create task
+	// create task with its labels, automation rules may change it
 	tx := orm.GetDB().Begin()
-	srvc := service.NewTaskService(tx)
-	serr = srvc.CreateTask(task)
+	srvc := service.NewTaskService(tx, api.GetLoginUser(c))
+	serr = srvc.CreateTaskWithLabels(projectID, task, labelIDs)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	labelIDs, serr = findTaskLabelIDs(service.NewLabelService(tx, api.GetLoginUser(c)), task.ID)
 	if serr != nil {
 		api.Rollback(tx)
 		api.SetErrorStatus(c, serr)
@@ -75,19 +275,34 @@ func create(c *gin.Context) {
 		return
 	}
 
-	res := convertTaskResponse(task)
+	res := convertTaskResponse(task, labelIDs)
+	res.Warnings = srvc.Warnings()
+	event.Publish(event.TypeTaskCreated, []string{task.BoardID}, res)
 	c.IndentedJSON(http.StatusOK, res)
 }
 
+// getOrExport exports tasks for /tasks/export.csv, otherwise gets a task
+func getOrExport(c *gin.Context) {
+	if c.Param(EndPoint.taskid) == EndPoint.exportcsv {
+		exportTasks(c)
+		return
+	}
+	get(c)
+}
+
 func get(c *gin.Context) {
 	tx := orm.GetDB() // No transaction
-	srvc := service.NewTaskService(tx)
+	srvc := service.NewTaskService(tx, api.GetLoginUser(c))
 	find, err := findTaskByPathParameter(c, srvc)
 	if err != nil {
-		api.Rollback(tx)
 		return
 	}
-	res := convertTaskResponse(find)
+	labelIDs, serr := findTaskLabelIDs(service.NewLabelService(tx, api.GetLoginUser(c)), find.ID)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertTaskResponse(find, labelIDs)
 	c.IndentedJSON(http.StatusOK, res)
 }
 
@@ -107,7 +322,7 @@ func findTaskByPathParameter(c *gin.Context, srvc *service.TaskService) (find *m
 
 func update(c *gin.Context) {
 	tx := orm.GetDB().Begin()
-	srvc := service.NewTaskService(tx)
+	srvc := service.NewTaskService(tx, api.GetLoginUser(c))
 	find, err := findTaskByPathParameter(c, srvc)
 	if err != nil {
 		api.Rollback(tx)
@@ -127,19 +342,27 @@ func update(c *gin.Context) {
 		api.SetErrorStatus(c, serr)
 		return
 	}
+	labelIDs, serr := findTaskLabelIDs(service.NewLabelService(tx, api.GetLoginUser(c)), task.ID)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
 	serr = api.Commit(tx)
 	if serr != nil {
 		api.SetErrorStatus(c, serr)
 		return
 	}
 
-	res := convertTaskResponse(task)
+	res := convertTaskResponse(task, labelIDs)
+	res.Warnings = srvc.Warnings()
+	event.Publish(event.TypeTaskUpdated, []string{find.BoardID, task.BoardID}, res)
 	c.IndentedJSON(http.StatusOK, res)
 }
 
 func delete(c *gin.Context) {
 	tx := orm.GetDB().Begin()
-	srvc := service.NewTaskService(tx)
+	srvc := service.NewTaskService(tx, api.GetLoginUser(c))
 	find, err := findTaskByPathParameter(c, srvc)
 	if err != nil {
 		api.Rollback(tx)
@@ -157,21 +380,150 @@ func delete(c *gin.Context) {
 		api.SetErrorStatus(c, serr)
 		return
 	}
+	event.Publish(event.TypeTaskDeleted, []string{find.BoardID}, convertTaskResponse(find, nil))
 	c.Status(http.StatusOK)
 }
 
-// update order of tasks
+// export tasks matching query parameters as csv
+func exportTasks(c *gin.Context) {
+	columns, serr := getCSVColumns(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transaction
+	loginUser := api.GetLoginUser(c)
+	tasks, serr := findTasksByQuery(c, service.NewTaskService(tx, loginUser))
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	boards, serr := service.NewBoardService(tx, loginUser).FindBoards(&model.Board{}, []string{"id"})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	users, serr := service.NewUserService(tx, loginUser).FindUsers(&model.User{}, []string{"id"})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	boardNames := make(map[string]string, len(boards))
+	for _, board := range boards {
+		boardNames[board.ID] = board.Name
+	}
+	userNames := make(map[string]string, len(users))
+	for _, user := range users {
+		userNames[user.ID] = user.Name
+	}
+	data, err := convertTasksCSV(tasks, columns, boardNames, userNames)
+	if err != nil {
+		api.SetErrorStatus(c, err)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="tasks.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// import tasks from csv for /tasks/import, rows which can not be imported are reported
+func importTasks(c *gin.Context) {
+	if c.Param(EndPoint.taskid) != EndPoint.importcsv {
+		api.SetErrorStatus(c, service.NewSvcErrorf(service.ErrorCodeNotFound, nil,
+			"Path not found. Path:%s", c.Request.URL.Path))
+		return
+	}
+	rows, failures, serr := getTaskImportRows(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	tx := orm.GetDB().Begin()
+	srvc := service.NewTaskService(tx, api.GetLoginUser(c))
+	projectID := c.Query(EndPoint.projectid)
+	if projectID == "" {
+		projectID = model.DefaultProjectID
+	}
+	results, serr := srvc.ImportTasks(projectID, rows)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	for _, result := range results {
+		if result.Task != nil {
+			event.Publish(event.TypeTaskCreated, []string{result.Task.BoardID}, convertTaskResponse(result.Task, nil))
+		}
+	}
+	res := convertImportResponse(append(results, failures...), srvc.Warnings())
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// revert task to the revision
+func revert(c *gin.Context) {
+	req, serr := getRevertRequest(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB().Begin()
+	srvc := service.NewTaskService(tx, api.GetLoginUser(c))
+	find, err := findTaskByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	task, serr := srvc.RevertTask(find.ID, req.Revision, req.Version)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	labelIDs, serr := findTaskLabelIDs(service.NewLabelService(tx, api.GetLoginUser(c)), task.ID)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertTaskResponse(task, labelIDs)
+	res.Warnings = srvc.Warnings()
+	event.Publish(event.TypeTaskUpdated, []string{find.BoardID, task.BoardID}, res)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// move a task to specified position
 func updateTaskOrders(c *gin.Context) {
-	req, serr := getUpdateTaskOrdersRequest(c)
+	req, serr := getMoveTaskRequest(c)
 	if serr != nil {
 		api.SetErrorStatus(c, serr)
 		return
 	}
 	tx := orm.GetDB().Begin()
-	srvc := service.NewTaskService(tx)
-	serr = srvc.UpdateTaskOrders(
-		req.TaskID, req.FromBoardID, req.FromDispOrder, req.ToBoardID, req.ToDispOrder,
-	)
+	srvc := service.NewTaskService(tx, api.GetLoginUser(c))
+	find, serr := srvc.FindTask(&model.Task{ID: req.TaskID})
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	task, serr := srvc.MoveTask(req.TaskID, req.ToBoardID, req.AfterTaskID, req.BeforeTaskID)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	labelIDs, serr := findTaskLabelIDs(service.NewLabelService(tx, api.GetLoginUser(c)), task.ID)
 	if serr != nil {
 		api.Rollback(tx)
 		api.SetErrorStatus(c, serr)
@@ -182,5 +534,8 @@ func updateTaskOrders(c *gin.Context) {
 		api.SetErrorStatus(c, serr)
 		return
 	}
-	c.Status(http.StatusOK)
+	res := convertTaskResponse(task, labelIDs)
+	res.Warnings = srvc.Warnings()
+	event.Publish(event.TypeTaskMoved, []string{find.BoardID, task.BoardID}, res)
+	c.IndentedJSON(http.StatusOK, res)
 }
@@ -13,34 +13,47 @@ import (
 // Name           string         `gorm:"not null;size:255"`
 // Description    string         `gorm:"size:8000"`
 // AssigneeUserID sql.NullString `gorm:"size:32"`           // Null or String
-// BoardID        string         `gorm:"not null; size:32"` // Default is IceboxBoardID
-// DispOrder      int            `gorm:"not null"`
+// BoardID        string         `gorm:"not null; size:32"` // Default is inbox board of default project
+// Rank           string         `gorm:"not null;size:255"` // Key to order tasks, unique in board
 // CreatedDate    time.Time      `gorm:"not null"`
 // IsClosed       bool           `gorm:"not null"`
 // Version        int            `gorm:"not null"` // Version for optimistic lock
-// EsitmateSize   int
+// EstimateSize   int
+// StartDate      *time.Time // Null if not planned
+// DueDate        *time.Time `gorm:"index"` // Null if not planned
 
 type taskResponse struct {
-	ID             string `json:"id"`
-	Name           string `json:"name"`
-	Description    string `json:"description"`
-	AssigneeUserID string `json:"assigneeUserID"`
-	BoardID        string `json:"boardID"`
-	DispOrder      int    `json:"dispOrder"`
-	CreatedDate    string `json:"createDate"`
-	IsClosed       bool   `json:"isClosed"`
-	Version        int    `json:"version"`
-	EsitmateSize   int    `json:"esitmateSize"`
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	AssigneeUserID string   `json:"assigneeUserID"`
+	BoardID        string   `json:"boardID"`
+	Rank           string   `json:"rank"`
+	CreatedDate    string   `json:"createDate"`
+	IsClosed       bool     `json:"isClosed"`
+	Version        int      `json:"version"`
+	EstimateSize   int      `json:"estimateSize"`
+	EsitmateSize   int      `json:"esitmateSize"` // Deprecated: misspelled name kept for old clients
+	LabelIDs       []string `json:"labelIDs"`
+	StartDate      string   `json:"startDate"`
+	DueDate        string   `json:"dueDate"`
+	IsOverdue      bool     `json:"isOverdue"`
+	Warnings       []string `json:"warnings,omitempty"` // Set when task entered board over WIP limit
 }
 
 type createRequest struct {
-	Name           string `json:"name"`
-	Description    string `json:"description"`
-	AssigneeUserID string `json:"assigneeUserID"`
-	BoardID        string `json:"boardID"`
-	CreatedDate    string `json:"createDate"`
-	IsClosed       bool   `json:"isClosed"`
-	EsitmateSize   int    `json:"esitmateSize"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	AssigneeUserID string   `json:"assigneeUserID"`
+	BoardID        string   `json:"boardID"`   // Inbox board of the project if empty
+	ProjectID      string   `json:"projectID"` // Default project if empty, board must be in it if both are set
+	CreatedDate    string   `json:"createDate"`
+	IsClosed       bool     `json:"isClosed"`
+	EstimateSize   *int     `json:"estimateSize"`
+	EsitmateSize   *int     `json:"esitmateSize"` // Deprecated: used if estimateSize is not set
+	StartDate      string   `json:"startDate"`
+	DueDate        string   `json:"dueDate"`
+	LabelIDs       []string `json:"labelIDs"`
 }
 
 type updateRequest struct {
@@ -51,45 +64,75 @@ type updateRequest struct {
 	BoardID        string `json:"boardID"`
 	IsClosed       bool   `json:"isClosed"`
 	Version        int    `json:"version"`
-	EsitmateSize   int    `json:"esitmateSize"`
+	EstimateSize   *int   `json:"estimateSize"`
+	EsitmateSize   *int   `json:"esitmateSize"` // Deprecated: used if estimateSize is not set
+	StartDate      string `json:"startDate"`
+	DueDate        string `json:"dueDate"`
 }
 
-type updateTaskOrdersRequest struct {
-	TaskID        string `json:"taskID"`
-	FromBoardID   string `json:"fromBoardID"`
-	FromDispOrder int    `json:"fromDispOrder"`
-	ToBoardID     string `json:"toBoardID"`
-	ToDispOrder   int    `json:"toDispOrder"`
+type revertRequest struct {
+	Revision int `json:"revision"`
+	Version  int `json:"version"`
 }
 
-func convertTaskResponse(task *model.Task) *taskResponse {
+type moveTaskRequest struct {
+	TaskID       string `json:"taskID"`
+	ToBoardID    string `json:"toBoardID"`    // Current board if empty
+	AfterTaskID  string `json:"afterTaskID"`  // Task is placed right after this if specified
+	BeforeTaskID string `json:"beforeTaskID"` // Task is placed right before this if specified
+}
+
+func convertTaskResponse(task *model.Task, labelIDs []string) *taskResponse {
+	if labelIDs == nil {
+		labelIDs = []string{}
+	}
 	return &taskResponse{
 		ID:             task.ID,
 		Name:           task.Name,
 		Description:    task.Description,
 		AssigneeUserID: task.AssigneeUserID.String,
 		BoardID:        task.BoardID,
-		DispOrder:      task.DispOrder,
+		Rank:           task.Rank,
 		CreatedDate:    task.CreatedDate.Format(time.RFC3339),
 		IsClosed:       task.IsClosed,
 		Version:        task.Version,
-		EsitmateSize:   task.EsitmateSize,
+		EstimateSize:   task.EstimateSize,
+		EsitmateSize:   task.EstimateSize,
+		LabelIDs:       labelIDs,
+		StartDate:      formatDate(task.StartDate),
+		DueDate:        formatDate(task.DueDate),
+		IsOverdue:      task.IsOverdue(time.Now().UTC()),
 	}
 }
 
-func convertListTaskResponse(tasks []model.Task) (res []*taskResponse) {
+// ConvertTaskEvent returns task in the shape of task api responses,
+// which is payload of task events published outside of this package
+func ConvertTaskEvent(task *model.Task, labelIDs []string) interface{} {
+	return convertTaskResponse(task, labelIDs)
+}
+
+func convertListTaskResponse(tasks []model.Task, taskLabelIDs map[string][]string) (res []*taskResponse) {
 	res = make([]*taskResponse, 0, len(tasks))
 	for _, task := range tasks {
-		res = append(res, convertTaskResponse(&task))
+		res = append(res, convertTaskResponse(&task, taskLabelIDs[task.ID]))
 	}
 	return
 }
 
-func getTaskByCreateRequest(c *gin.Context) (*model.Task, error) {
-	var req *createRequest
-	err := c.ShouldBindJSON(req)
+// getTaskByCreateRequest returns created task, its label IDs and ID of the project where it is created
+func getTaskByCreateRequest(c *gin.Context) (*model.Task, []string, string, error) {
+	var req createRequest
+	err := c.ShouldBindJSON(&req)
 	if err != nil {
-		return nil, service.NewBadRequestError(err)
+		return nil, nil, "", service.NewBadRequestError(err)
+	}
+	startDate, err := parseDate(req.StartDate, "startDate")
+	if err != nil {
+		return nil, nil, "", err
+	}
+	dueDate, err := parseDate(req.DueDate, "dueDate")
+	if err != nil {
+		return nil, nil, "", err
 	}
 	task := model.NewTask(
 		req.Name,
@@ -99,8 +142,10 @@ func getTaskByCreateRequest(c *gin.Context) (*model.Task, error) {
 	)
 	task.SetAssigneeUserID(req.AssigneeUserID)
 	task.SetBoardID(req.BoardID)
-	task.EsitmateSize = req.EsitmateSize
-	return task, nil
+	task.EstimateSize = getEstimateSize(req.EstimateSize, req.EsitmateSize)
+	task.StartDate = startDate
+	task.DueDate = dueDate
+	return task, req.LabelIDs, req.ProjectID, nil
 }
 
 func getTaskByUpdateRequest(c *gin.Context, find *model.Task) (*model.Task, error) {
@@ -109,6 +154,14 @@ func getTaskByUpdateRequest(c *gin.Context, find *model.Task) (*model.Task, erro
 	if err != nil {
 		return nil, service.NewBadRequestError(err)
 	}
+	startDate, err := parseDate(req.StartDate, "startDate")
+	if err != nil {
+		return nil, err
+	}
+	dueDate, err := parseDate(req.DueDate, "dueDate")
+	if err != nil {
+		return nil, err
+	}
 	newAssigneeUserID := sql.NullString{}
 	if req.AssigneeUserID != "" {
 		// Set only if not empty
@@ -121,21 +174,64 @@ func getTaskByUpdateRequest(c *gin.Context, find *model.Task) (*model.Task, erro
 		Description:    req.Description,
 		AssigneeUserID: newAssigneeUserID,
 		BoardID:        req.BoardID,
-		DispOrder:      find.DispOrder,
+		Rank:           find.Rank,
 		CreatedDate:    find.CreatedDate,
 		IsClosed:       req.IsClosed,
 		Version:        req.Version,
-		EsitmateSize:   req.EsitmateSize,
+		EstimateSize:   getEstimateSize(req.EstimateSize, req.EsitmateSize),
+		StartDate:      startDate,
+		DueDate:        dueDate,
 	}
 	task.SetAssigneeUserID(req.AssigneeUserID)
 	return task, nil
 }
 
-func getUpdateTaskOrdersRequest(c *gin.Context) (*updateTaskOrdersRequest, error) {
-	var req updateTaskOrdersRequest
+// getEstimateSize returns estimate size of request, the misspelled legacy field is used if new one is not set
+func getEstimateSize(estimateSize, legacyEstimateSize *int) int {
+	if estimateSize != nil {
+		return *estimateSize
+	}
+	if legacyEstimateSize != nil {
+		return *legacyEstimateSize
+	}
+	return 0
+}
+
+func getRevertRequest(c *gin.Context) (*revertRequest, error) {
+	var req revertRequest
 	err := c.ShouldBindJSON(&req)
 	if err != nil {
 		return nil, service.NewBadRequestError(err)
 	}
 	return &req, nil
 }
+
+func getMoveTaskRequest(c *gin.Context) (*moveTaskRequest, error) {
+	var req moveTaskRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return &req, nil
+}
+
+// formatDate returns RFC3339 string of date, or empty string when date is not set
+func formatDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(time.RFC3339)
+}
+
+// parseDate parses RFC3339 string of request, empty string means date is not set
+func parseDate(value, name string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, err, "[%s] must be RFC3339 date", name)
+	}
+	date = date.UTC()
+	return &date, nil
+}
@@ -0,0 +1,279 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/csv"
+	"sort"
+	"strconv"
+	"strings"
+	"taskboard/controller/api"
+	"taskboard/model"
+	"taskboard/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Columns of csv
+const (
+	csvColumnName        = "name"
+	csvColumnDescription = "description"
+	csvColumnBoard       = "board"    // Name of board
+	csvColumnAssignee    = "assignee" // Name of assignee user
+	csvColumnEstimate    = "estimate"
+	csvColumnClosed      = "closed"
+	csvColumnCreatedDate = "createdDate" // RFC3339
+)
+
+// csvColumns is all columns in default order
+var csvColumns = []string{
+	csvColumnName,
+	csvColumnDescription,
+	csvColumnBoard,
+	csvColumnAssignee,
+	csvColumnEstimate,
+	csvColumnClosed,
+	csvColumnCreatedDate,
+}
+
+// csvFormulaPrefixes are the first characters which make a cell run as formula in spreadsheets
+const csvFormulaPrefixes = "=+-@"
+
+// csvEscapePrefix is prepended to text cells which would run as formula, spreadsheets show them as text
+const csvEscapePrefix = "'"
+
+// csvUploadFile is the form field name of uploaded csv file
+const csvUploadFile = "file"
+
+// Status of imported row
+const (
+	importStatusCreated = "created"
+	importStatusError   = "error"
+)
+
+type importRowResponse struct {
+	Row     int    `json:"row"` // Row number in csv, header is row 1
+	Status  string `json:"status"`
+	TaskID  string `json:"taskID,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type importResponse struct {
+	CreatedCount int                  `json:"createdCount"`
+	ErrorCount   int                  `json:"errorCount"`
+	Rows         []*importRowResponse `json:"rows"`
+	Warnings     []string             `json:"warnings,omitempty"` // Set when tasks entered boards over WIP limit
+}
+
+// getCSVColumns returns columns specified by query parameter, all columns if not specified
+func getCSVColumns(c *gin.Context) ([]string, error) {
+	param := c.Query(EndPoint.columns)
+	if param == "" {
+		return csvColumns, nil
+	}
+	columns := strings.Split(param, ",")
+	for _, column := range columns {
+		if !isCSVColumn(column) {
+			return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, nil,
+				"Column [%s] is invalid, must be one of [%s]", column, strings.Join(csvColumns, ","))
+		}
+	}
+	return columns, nil
+}
+
+func isCSVColumn(column string) bool {
+	for _, c := range csvColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// convertTasksCSV returns csv of tasks with header, boardNames and userNames are maps from ID to name
+func convertTasksCSV(tasks []model.Task, columns []string, boardNames, userNames map[string]string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+	record := make([]string, len(columns))
+	for _, task := range tasks {
+		for i, column := range columns {
+			switch column {
+			case csvColumnName:
+				record[i] = escapeCSVText(task.Name)
+			case csvColumnDescription:
+				record[i] = escapeCSVText(task.Description)
+			case csvColumnBoard:
+				record[i] = escapeCSVText(boardNames[task.BoardID])
+			case csvColumnAssignee:
+				record[i] = escapeCSVText(userNames[task.AssigneeUserID.String])
+			case csvColumnEstimate:
+				record[i] = strconv.Itoa(task.EstimateSize)
+			case csvColumnClosed:
+				record[i] = strconv.FormatBool(task.IsClosed)
+			case csvColumnCreatedDate:
+				record[i] = task.CreatedDate.Format(time.RFC3339)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// escapeCSVText prefixes text which would run as formula in spreadsheets.
+// Text which starts with the prefix followed by them is prefixed as well, so that unescapeCSVText restores it.
+func escapeCSVText(text string) string {
+	if isCSVFormula(text) || isCSVEscaped(text) {
+		return csvEscapePrefix + text
+	}
+	return text
+}
+
+// unescapeCSVText removes the prefix added by escapeCSVText, other text is returned as is
+func unescapeCSVText(text string) string {
+	if isCSVEscaped(text) {
+		return strings.TrimPrefix(text, csvEscapePrefix)
+	}
+	return text
+}
+
+func isCSVFormula(text string) bool {
+	return text != "" && strings.ContainsAny(text[:1], csvFormulaPrefixes)
+}
+
+// isCSVEscaped checks whether text starts with the prefix followed by formula or another prefix
+func isCSVEscaped(text string) bool {
+	if !strings.HasPrefix(text, csvEscapePrefix) {
+		return false
+	}
+	rest := strings.TrimPrefix(text, csvEscapePrefix)
+	return isCSVFormula(rest) || strings.HasPrefix(rest, csvEscapePrefix)
+}
+
+// getTaskImportRows reads csv from uploaded file or request body.
+// Rows whose cells can not be parsed are returned as failed results.
+func getTaskImportRows(c *gin.Context) ([]*service.TaskImportRow, []*service.TaskImportResult, error) {
+	reader, serr := api.GetUploadedFile(c, csvUploadFile)
+	if serr != nil {
+		return nil, nil, serr
+	}
+	defer reader.Close()
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1 // Checked for each row not to fail all rows
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, nil, service.NewSvcError(service.ErrorCodeBadRequest, err, "Failed to read csv: "+err.Error())
+	}
+	if len(records) == 0 {
+		return nil, nil, service.NewSvcError(service.ErrorCodeInvalidArguments, nil, "Header row of csv is required")
+	}
+
+	header := records[0]
+	if len(header) > 0 {
+		// Spreadsheets may add byte order mark
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	hasName := false
+	for _, column := range header {
+		if !isCSVColumn(column) {
+			return nil, nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, nil,
+				"Column [%s] in header is invalid, must be one of [%s]", column, strings.Join(csvColumns, ","))
+		}
+		hasName = hasName || column == csvColumnName
+	}
+	if !hasName {
+		return nil, nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, nil,
+			"Column [%s] is required in header", csvColumnName)
+	}
+
+	rows := make([]*service.TaskImportRow, 0, len(records)-1)
+	failures := []*service.TaskImportResult{}
+	for i, record := range records[1:] {
+		rowNumber := i + 2 // Row number starts from 1 including header
+		row, serr := convertTaskImportRow(header, record, rowNumber)
+		if serr != nil {
+			failures = append(failures, &service.TaskImportResult{Row: rowNumber, Error: serr})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, failures, nil
+}
+
+// convertTaskImportRow converts a record of csv to row
+func convertTaskImportRow(header, record []string, rowNumber int) (*service.TaskImportRow, error) {
+	if len(record) != len(header) {
+		return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, nil,
+			"Row has %d columns but header has %d columns", len(record), len(header))
+	}
+	row := &service.TaskImportRow{Row: rowNumber}
+	for i, column := range header {
+		value := record[i]
+		switch column {
+		case csvColumnName:
+			row.Name = unescapeCSVText(value)
+		case csvColumnDescription:
+			row.Description = unescapeCSVText(value)
+		case csvColumnBoard:
+			row.BoardName = unescapeCSVText(value)
+		case csvColumnAssignee:
+			row.AssigneeName = unescapeCSVText(value)
+		case csvColumnEstimate:
+			if value == "" {
+				continue
+			}
+			size, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, err,
+					"Column [%s] must be number but got [%s]", column, value)
+			}
+			row.EstimateSize = size
+		case csvColumnClosed:
+			if value == "" {
+				continue
+			}
+			isClosed, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, err,
+					"Column [%s] must be boolean but got [%s]", column, value)
+			}
+			row.IsClosed = isClosed
+		case csvColumnCreatedDate:
+			if value == "" {
+				continue
+			}
+			date, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, service.NewSvcErrorf(service.ErrorCodeInvalidArguments, err,
+					"Column [%s] must be RFC3339 date but got [%s]", column, value)
+			}
+			row.CreatedDate = &date
+		}
+	}
+	return row, nil
+}
+
+// convertImportResponse returns report of all rows in order of row number
+func convertImportResponse(results []*service.TaskImportResult, warnings []string) *importResponse {
+	res := &importResponse{Rows: make([]*importRowResponse, 0, len(results)), Warnings: warnings}
+	for _, result := range results {
+		row := &importRowResponse{Row: result.Row}
+		if result.Error != nil {
+			row.Status = importStatusError
+			row.Message = result.Error.Error()
+			res.ErrorCount++
+		} else {
+			row.Status = importStatusCreated
+			row.TaskID = result.Task.ID
+			res.CreatedCount++
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	sort.SliceStable(res.Rows, func(i, j int) bool { return res.Rows[i].Row < res.Rows[j].Row })
+	return res
+}
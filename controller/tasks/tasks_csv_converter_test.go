@@ -0,0 +1,68 @@
+package tasks
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"strings"
+	"taskboard/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeCSVText(t *testing.T) {
+	tests := []struct {
+		text    string
+		escaped string
+	}{
+		{`=HYPERLINK("http://example.com")`, `'=HYPERLINK("http://example.com")`},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"plain", "plain"},
+		{"a=b", "a=b"},
+		{"", ""},
+		// Prefix which is not followed by formula is kept as is
+		{"'quoted'", "'quoted'"},
+		// Text which looks escaped already is escaped again to be restored
+		{"'=1", "''=1"},
+		{"''", "'''"},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			escaped := escapeCSVText(test.text)
+			assert.Equal(t, test.escaped, escaped)
+			assert.Equal(t, test.text, unescapeCSVText(escaped))
+		})
+	}
+}
+
+func TestConvertTasksCSV_Formula(t *testing.T) {
+	task := model.NewTask("=1+1", "@cmd", false, time.Date(2019, 1, 10, 0, 0, 0, 0, time.UTC))
+	task.BoardID = "boardID"
+	task.AssigneeUserID = sql.NullString{String: "userID", Valid: true}
+	task.EstimateSize = -1
+	data, err := convertTasksCSV([]model.Task{*task}, csvColumns,
+		map[string]string{"boardID": "-board"}, map[string]string{"userID": "+user"})
+	if err != nil {
+		t.Fatalf("Failed to convert csv: %+v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read csv: %+v", err)
+	}
+	// Text cells are escaped, numbers are not
+	assert.Equal(t, []string{"'=1+1", "'@cmd", "'-board", "'+user", "-1", "false", "2019-01-10T00:00:00Z"}, records[1])
+
+	// Imported row has the original text
+	row, serr := convertTaskImportRow(records[0], records[1], 2)
+	if serr != nil {
+		t.Fatalf("Failed to convert row: %+v", serr)
+	}
+	assert.Equal(t, "=1+1", row.Name)
+	assert.Equal(t, "@cmd", row.Description)
+	assert.Equal(t, "-board", row.BoardName)
+	assert.Equal(t, "+user", row.AssigneeName)
+	assert.Equal(t, -1, row.EstimateSize)
+}
@@ -0,0 +1,156 @@
+package transitions
+
+import (
+	"net/http"
+	"taskboard/controller/api"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	transitions  string
+	transitionid string
+	fromboardid  string
+}
+
+// EndPoint presents transitions endpoint
+var EndPoint = endPoint{
+	transitions:  "/transitions",
+	transitionid: "transitionid",
+	fromboardid:  "fromboardid",
+}
+
+// RegisterRoute registers API endpoints for transition rules
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.GET(p.transitions, list)
+	route.POST(p.transitions, create)
+	route.GET(p.transitions+"/:"+p.transitionid, get)
+	route.PUT(p.transitions+"/:"+p.transitionid, update)
+	route.DELETE(p.transitions+"/:"+p.transitionid, delete)
+	return
+}
+
+// find transition rules, only rules from the board if fromboardid query parameter is specified
+func list(c *gin.Context) {
+	tx := orm.GetDB() // No transction
+	srvc := service.NewTransitionRuleService(tx, api.GetLoginUser(c))
+	rules, serr := srvc.FindTransitionRules(c.Query(EndPoint.fromboardid))
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListTransitionResponse(rules)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func create(c *gin.Context) {
+	rule, serr := getTransitionByCreateRequest(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// create transition rule
+	tx := orm.GetDB().Begin()
+	srvc := service.NewTransitionRuleService(tx, api.GetLoginUser(c))
+	serr = srvc.CreateTransitionRule(rule)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertTransitionResponse(rule)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// get a transition rule
+func get(c *gin.Context) {
+	tx := orm.GetDB() // No transaction
+	srvc := service.NewTransitionRuleService(tx, api.GetLoginUser(c))
+	find, err := findTransitionByPathParameter(c, srvc)
+	if err != nil {
+		return
+	}
+	res := convertTransitionResponse(find)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func findTransitionByPathParameter(c *gin.Context, srvc *service.TransitionRuleService) (find *model.TransitionRule, serr error) {
+	ruleID, serr := api.GetPathParameter(c, EndPoint.transitionid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	find, serr = srvc.FindTransitionRule(&model.TransitionRule{ID: ruleID})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	return
+}
+
+// update required role and fields of transition rule
+func update(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewTransitionRuleService(tx, api.GetLoginUser(c))
+	find, err := findTransitionByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	rule, serr := getTransitionByUpdateRequest(c, find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// update transition rule
+	serr = srvc.UpdateTransitionRule(rule)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertTransitionResponse(rule)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// delete transition rule
+func delete(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewTransitionRuleService(tx, api.GetLoginUser(c))
+	find, err := findTransitionByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	// delete transition rule
+	serr := srvc.DeleteTransitionRule(find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,89 @@
+package transitions
+
+import (
+	"taskboard/model"
+	"taskboard/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ID             string    `gorm:"primary_key;size:32"`
+// FromBoardID    string    `gorm:"not null;size:32;unique_index:idx_transition_rules_boards"`
+// ToBoardID      string    `gorm:"not null;size:32;unique_index:idx_transition_rules_boards"`
+// RequiredRole   string    `gorm:"not null;size:16"`  // Role of user who can move tasks, empty if any editor can
+// RequiredFields string    `gorm:"not null;size:255"` // Comma separated fields which moved tasks must have
+// CreatedDate    time.Time `gorm:"not null"`
+// Version        int       `gorm:"not null"` // Version for optimistic lock
+
+type transitionResponse struct {
+	ID             string   `json:"id"`
+	FromBoardID    string   `json:"fromBoardID"`
+	ToBoardID      string   `json:"toBoardID"`
+	RequiredRole   string   `json:"requiredRole"`
+	RequiredFields []string `json:"requiredFields"`
+	CreatedDate    string   `json:"createDate"`
+	Version        int      `json:"version"`
+}
+
+type createRequest struct {
+	FromBoardID    string   `json:"fromBoardID"`
+	ToBoardID      string   `json:"toBoardID"`
+	RequiredRole   string   `json:"requiredRole"`
+	RequiredFields []string `json:"requiredFields"`
+}
+
+type updateRequest struct {
+	ID             string   `json:"id"`
+	RequiredRole   string   `json:"requiredRole"`
+	RequiredFields []string `json:"requiredFields"`
+	Version        int      `json:"version"`
+}
+
+func convertTransitionResponse(rule *model.TransitionRule) *transitionResponse {
+	return &transitionResponse{
+		ID:             rule.ID,
+		FromBoardID:    rule.FromBoardID,
+		ToBoardID:      rule.ToBoardID,
+		RequiredRole:   rule.RequiredRole,
+		RequiredFields: rule.GetRequiredFields(),
+		CreatedDate:    rule.CreatedDate.Format(time.RFC3339),
+		Version:        rule.Version,
+	}
+}
+
+func convertListTransitionResponse(rules []model.TransitionRule) (res []*transitionResponse) {
+	res = make([]*transitionResponse, 0, len(rules))
+	for _, rule := range rules {
+		res = append(res, convertTransitionResponse(&rule))
+	}
+	return
+}
+
+func getTransitionByCreateRequest(c *gin.Context) (*model.TransitionRule, error) {
+	var req createRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return model.NewTransitionRule(req.FromBoardID, req.ToBoardID, req.RequiredRole, req.RequiredFields, time.Now().UTC()), nil
+}
+
+// Boards of transition rule can not be changed, delete and create rule instead
+func getTransitionByUpdateRequest(c *gin.Context, find *model.TransitionRule) (*model.TransitionRule, error) {
+	var req updateRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	rule := &model.TransitionRule{
+		ID:           find.ID,
+		FromBoardID:  find.FromBoardID,
+		ToBoardID:    find.ToBoardID,
+		RequiredRole: req.RequiredRole,
+		CreatedDate:  find.CreatedDate,
+		Version:      req.Version,
+	}
+	rule.SetRequiredFields(req.RequiredFields)
+	return rule, nil
+}
@@ -3,6 +3,7 @@ package users
 import (
 	"net/http"
 	"taskboard/controller/api"
+	"taskboard/event"
 	"taskboard/model"
 	"taskboard/orm"
 	"taskboard/service"
@@ -11,21 +12,18 @@ import (
 )
 
 type endPoint struct {
-	login  string
 	users  string
 	userid string
 }
 
 // EndPoint presents boards endpoint
 var EndPoint = endPoint{
-	login:  "/login",
 	users:  "/users",
 	userid: "userid",
 }
 
 // RegisterRoute registers API endpoints for users
 func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
-	route.POST(p.login, login)
 	route.GET(p.users, list)
 	route.POST(p.users, create)
 	route.GET(p.users+"/:"+p.userid, get)
@@ -34,33 +32,27 @@ func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
 	return
 }
 
-func login(c *gin.Context) {
-	tx := orm.GetDB() // No transction
-	req, serr := getLoginRequest(c)
-	if serr != nil {
-		api.SetErrorStatus(c, serr)
-		return
-	}
-	srvc := service.NewUserService(tx)
-	user, serr := srvc.Login(req.Name, req.Password)
+// userSortKeys are keys of sort query parameter of users
+var userSortKeys = api.SortKeys{
+	"name": "name",
+	"role": "role",
+}
+
+func list(c *gin.Context) {
+	query, serr := api.GetListQuery(c, userSortKeys, []string{"name"}, userResponse{})
 	if serr != nil {
 		api.SetErrorStatus(c, serr)
 		return
 	}
-	res := convertUserResponse(user)
-	c.IndentedJSON(http.StatusOK, res)
-}
-
-func list(c *gin.Context) {
 	tx := orm.GetDB() // No transction
-	srvc := service.NewUserService(tx)
-	users, serr := srvc.FindUsers(&model.User{}, []string{"name"})
+	srvc := service.NewUserService(tx, api.GetLoginUser(c))
+	users, count, serr := srvc.FindUsersPage(&model.User{}, query.After, query.FetchLimit(), query.SortOrders)
 	if serr != nil {
 		api.SetErrorStatus(c, serr)
 		return
 	}
 	res := convertListUserResponse(users)
-	c.IndentedJSON(http.StatusOK, res)
+	api.WriteList(c, query, count, users, res)
 }
 
 func create(c *gin.Context) {
@@ -72,7 +64,7 @@ func create(c *gin.Context) {
 
 	// create user
 	tx := orm.GetDB().Begin()
-	srvc := service.NewUserService(tx)
+	srvc := service.NewUserService(tx, api.GetLoginUser(c))
 	serr = srvc.CreateUser(user)
 	if serr != nil {
 		api.Rollback(tx)
@@ -86,15 +78,15 @@ func create(c *gin.Context) {
 	}
 
 	res := convertUserResponse(user)
+	event.Publish(event.TypeUserCreated, []string{}, res)
 	c.IndentedJSON(http.StatusOK, res)
 }
 
 func get(c *gin.Context) {
 	tx := orm.GetDB() // No transaction
-	srvc := service.NewUserService(tx)
+	srvc := service.NewUserService(tx, api.GetLoginUser(c))
 	find, err := findUserByPathParameter(c, srvc)
 	if err != nil {
-		api.Rollback(tx)
 		return
 	}
 	res := convertUserResponse(find)
@@ -117,7 +109,7 @@ func findUserByPathParameter(c *gin.Context, srvc *service.UserService) (find *m
 
 func update(c *gin.Context) {
 	tx := orm.GetDB().Begin()
-	srvc := service.NewUserService(tx)
+	srvc := service.NewUserService(tx, api.GetLoginUser(c))
 	find, err := findUserByPathParameter(c, srvc)
 	if err != nil {
 		api.Rollback(tx)
@@ -144,12 +136,13 @@ func update(c *gin.Context) {
 	}
 
 	res := convertUserResponse(user)
+	event.Publish(event.TypeUserUpdated, []string{}, res)
 	c.IndentedJSON(http.StatusOK, res)
 }
 
 func delete(c *gin.Context) {
 	tx := orm.GetDB().Begin()
-	srvc := service.NewUserService(tx)
+	srvc := service.NewUserService(tx, api.GetLoginUser(c))
 	find, err := findUserByPathParameter(c, srvc)
 	if err != nil {
 		api.Rollback(tx)
@@ -167,5 +160,6 @@ func delete(c *gin.Context) {
 		api.SetErrorStatus(c, serr)
 		return
 	}
+	event.Publish(event.TypeUserDeleted, []string{}, convertUserResponse(find))
 	c.Status(http.StatusOK)
 }
@@ -11,17 +11,14 @@ import (
 // Name         string `gorm:"size:255;not null;unique"`
 // PasswordHash string `gorm:"size:255;not null;"`
 // Avator       string `gorm:"size:255"`
+// Role         string `gorm:"size:16;not null;default:'member'"`
 // Version      int    `gorm:"not null"` // Version for optimistic lock
 
-type loginRequest struct {
-	Name     string `json:"name"`
-	Password string `json:"password"`
-}
-
 type userResponse struct {
 	ID      string `json:"id"`
 	Name    string `json:"name"`
 	Avator  string `json:"avator"`
+	Role    string `json:"role"`
 	Version int    `json:"version"`
 }
 
@@ -29,6 +26,7 @@ type createRequest struct {
 	Name     string `json:"name"`
 	Password string `json:"password"`
 	Avator   string `json:"avator"`
+	Role     string `json:"role"`
 }
 
 type updateRequest struct {
@@ -36,23 +34,16 @@ type updateRequest struct {
 	Name     string `json:"name"`
 	Password string `json:"password"`
 	Avator   string `json:"avator"`
+	Role     string `json:"role"`
 	Version  int    `json:"version"`
 }
 
-func getLoginRequest(c *gin.Context) (*loginRequest, error) {
-	var req loginRequest
-	err := c.ShouldBindJSON(&req)
-	if err != nil {
-		return nil, service.NewBadRequestError(err)
-	}
-	return &req, nil
-}
-
 func convertUserResponse(user *model.User) *userResponse {
 	return &userResponse{
 		ID:      user.ID,
 		Name:    user.Name,
 		Avator:  user.Avator,
+		Role:    user.Role,
 		Version: user.Version,
 	}
 }
@@ -71,7 +62,11 @@ func getUserByCreateRequest(c *gin.Context) (*model.User, error) {
 	if err != nil {
 		return nil, service.NewBadRequestError(err)
 	}
-	return model.NewUser(req.Name, req.Password, req.Avator), nil
+	user := model.NewUser(req.Name, req.Password, req.Avator)
+	if req.Role != "" {
+		user.Role = req.Role
+	}
+	return user, nil
 }
 
 func getUserByUpdateRequest(c *gin.Context, find *model.User) (*model.User, error) {
@@ -84,8 +79,15 @@ func getUserByUpdateRequest(c *gin.Context, find *model.User) (*model.User, erro
 		ID:      find.ID,
 		Name:    req.Name,
 		Avator:  req.Avator,
+		Role:    find.Role,
 		Version: req.Version,
 	}
-	user.SetPassword(req.Password)
+	if req.Role != "" {
+		user.Role = req.Role
+	}
+	if req.Password != "" {
+		// Change password only if specified
+		user.SetPassword(req.Password)
+	}
 	return user, nil
 }
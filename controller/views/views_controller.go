@@ -0,0 +1,166 @@
+package views
+
+import (
+	"net/http"
+	"taskboard/controller/api"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	views  string
+	viewid string
+}
+
+// EndPoint presents saved views endpoint
+var EndPoint = endPoint{
+	views:  "/views",
+	viewid: "viewid",
+}
+
+// RegisterRoute registers API endpoints for saved views
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.GET(p.views, list)
+	route.POST(p.views, create)
+	route.GET(p.views+"/:"+p.viewid, get)
+	route.PUT(p.views+"/:"+p.viewid, update)
+	route.DELETE(p.views+"/:"+p.viewid, delete)
+	return
+}
+
+// viewSortKeys are keys of sort query parameter of saved views
+var viewSortKeys = api.SortKeys{
+	"name":        "name",
+	"ownerUserID": "owner_user_id",
+	"createDate":  "created_date",
+}
+
+// find own and shared views
+func list(c *gin.Context) {
+	query, serr := api.GetListQuery(c, viewSortKeys, []string{"name"}, viewResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	srvc := service.NewSavedViewService(tx, api.GetLoginUser(c))
+	views, count, serr := srvc.FindSavedViewsPage(query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListViewResponse(views)
+	api.WriteList(c, query, count, views, res)
+}
+
+func create(c *gin.Context) {
+	view, serr := getViewByCreateRequest(c, api.GetLoginUser(c))
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// create view
+	tx := orm.GetDB().Begin()
+	srvc := service.NewSavedViewService(tx, api.GetLoginUser(c))
+	serr = srvc.CreateSavedView(view)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertViewResponse(view)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// get a view
+func get(c *gin.Context) {
+	tx := orm.GetDB() // No transaction
+	srvc := service.NewSavedViewService(tx, api.GetLoginUser(c))
+	find, err := findViewByPathParameter(c, srvc)
+	if err != nil {
+		return
+	}
+	res := convertViewResponse(find)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func findViewByPathParameter(c *gin.Context, srvc *service.SavedViewService) (find *model.SavedView, serr error) {
+	viewID, serr := api.GetPathParameter(c, EndPoint.viewid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	find, serr = srvc.FindSavedView(&model.SavedView{ID: viewID})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	return
+}
+
+// update view
+func update(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewSavedViewService(tx, api.GetLoginUser(c))
+	find, err := findViewByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	view, serr := getViewByUpdateRequest(c, find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// update view
+	serr = srvc.UpdateSavedView(view)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertViewResponse(view)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// delete view
+func delete(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewSavedViewService(tx, api.GetLoginUser(c))
+	find, err := findViewByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	// delete view
+	serr := srvc.DeleteSavedView(find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
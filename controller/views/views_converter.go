@@ -0,0 +1,99 @@
+package views
+
+import (
+	"taskboard/model"
+	"taskboard/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ID          string    `gorm:"primary_key;size:32"`
+// OwnerUserID string    `gorm:"not null;size:32;unique_index:idx_saved_views_owner_name"`
+// Name        string    `gorm:"not null;size:255;unique_index:idx_saved_views_owner_name"` // Unique for owner
+// Query       string    `gorm:"not null;size:2000"`                                        // Task query language, all tasks if empty
+// Sort        string    `gorm:"not null;size:255"`                                         // Comma separated sort keys, default order if empty
+// BoardID     string    `gorm:"not null;size:32"`                                          // Board of tasks, all boards if empty
+// IsShared    bool      `gorm:"not null"`                                                  // Shared view can be found by all users
+// CreatedDate time.Time `gorm:"not null"`
+// Version     int       `gorm:"not null"` // Version for optimistic lock
+
+type viewResponse struct {
+	ID          string `json:"id"`
+	OwnerUserID string `json:"ownerUserID"`
+	Name        string `json:"name"`
+	Query       string `json:"query"`
+	Sort        string `json:"sort"`
+	BoardID     string `json:"boardID"`
+	IsShared    bool   `json:"isShared"`
+	CreatedDate string `json:"createDate"`
+	Version     int    `json:"version"`
+}
+
+type createRequest struct {
+	Name     string `json:"name"`
+	Query    string `json:"query"`
+	Sort     string `json:"sort"`
+	BoardID  string `json:"boardID"`
+	IsShared bool   `json:"isShared"`
+}
+
+type updateRequest struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Query    string `json:"query"`
+	Sort     string `json:"sort"`
+	BoardID  string `json:"boardID"`
+	IsShared bool   `json:"isShared"`
+	Version  int    `json:"version"`
+}
+
+func convertViewResponse(view *model.SavedView) *viewResponse {
+	return &viewResponse{
+		ID:          view.ID,
+		OwnerUserID: view.OwnerUserID,
+		Name:        view.Name,
+		Query:       view.Query,
+		Sort:        view.Sort,
+		BoardID:     view.BoardID,
+		IsShared:    view.IsShared,
+		CreatedDate: view.CreatedDate.Format(time.RFC3339),
+		Version:     view.Version,
+	}
+}
+
+func convertListViewResponse(views []model.SavedView) (res []*viewResponse) {
+	res = make([]*viewResponse, 0, len(views))
+	for _, view := range views {
+		res = append(res, convertViewResponse(&view))
+	}
+	return
+}
+
+func getViewByCreateRequest(c *gin.Context, loginUser *model.User) (*model.SavedView, error) {
+	var req createRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return model.NewSavedView(loginUser.ID, req.Name, req.Query, req.Sort, req.BoardID, req.IsShared, time.Now().UTC()), nil
+}
+
+func getViewByUpdateRequest(c *gin.Context, find *model.SavedView) (*model.SavedView, error) {
+	var req updateRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return &model.SavedView{
+		ID:          find.ID,
+		OwnerUserID: find.OwnerUserID,
+		Name:        req.Name,
+		Query:       req.Query,
+		Sort:        req.Sort,
+		BoardID:     req.BoardID,
+		IsShared:    req.IsShared,
+		CreatedDate: find.CreatedDate,
+		Version:     req.Version,
+	}, nil
+}
@@ -0,0 +1,242 @@
+package webhooks
+
+import (
+	"net/http"
+	"taskboard/controller/api"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+	"taskboard/worker"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	webhooks   string
+	webhookid  string
+	deliveries string
+	deliveryid string
+	redeliver  string
+}
+
+// EndPoint presents webhooks endpoint
+var EndPoint = endPoint{
+	webhooks:   "/webhooks",
+	webhookid:  "webhookid",
+	deliveries: "/deliveries",
+	deliveryid: "deliveryid",
+	redeliver:  "/redeliver",
+}
+
+// RegisterRoute registers API endpoints for webhooks
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.GET(p.webhooks, list)
+	route.POST(p.webhooks, create)
+	route.GET(p.webhooks+"/:"+p.webhookid, get)
+	route.PUT(p.webhooks+"/:"+p.webhookid, update)
+	route.DELETE(p.webhooks+"/:"+p.webhookid, delete)
+	path := p.webhooks + "/:" + p.webhookid + p.deliveries
+	route.GET(path, listDeliveries)
+	route.POST(path+"/:"+p.deliveryid+p.redeliver, redeliver)
+	return
+}
+
+// find all webhooks
+// webhookSortKeys are keys of sort query parameter of webhooks
+var webhookSortKeys = api.SortKeys{
+	"url":        "url",
+	"isActive":   "is_active",
+	"createDate": "created_date",
+}
+
+// deliverySortKeys are keys of sort query parameter of deliveries
+var deliverySortKeys = api.SortKeys{
+	"status":          "status",
+	"nextAttemptDate": "next_attempt_date",
+	"createDate":      "created_date",
+}
+
+func list(c *gin.Context) {
+	query, serr := api.GetListQuery(c, webhookSortKeys, []string{"created_date"}, webhookResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	srvc := service.NewWebhookService(tx, api.GetLoginUser(c))
+	webhooks, count, serr := srvc.FindWebhooksPage(&model.Webhook{}, query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListWebhookResponse(webhooks)
+	api.WriteList(c, query, count, webhooks, res)
+}
+
+func create(c *gin.Context) {
+	webhook, serr := getWebhookByCreateRequest(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// create webhook
+	tx := orm.GetDB().Begin()
+	srvc := service.NewWebhookService(tx, api.GetLoginUser(c))
+	serr = srvc.CreateWebhook(webhook)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertWebhookResponse(webhook)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// get a webhook
+func get(c *gin.Context) {
+	tx := orm.GetDB() // No transaction
+	srvc := service.NewWebhookService(tx, api.GetLoginUser(c))
+	find, err := findWebhookByPathParameter(c, srvc)
+	if err != nil {
+		return
+	}
+	res := convertWebhookResponse(find)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func findWebhookByPathParameter(c *gin.Context, srvc *service.WebhookService) (find *model.Webhook, serr error) {
+	webhookID, serr := api.GetPathParameter(c, EndPoint.webhookid)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	find, serr = srvc.FindWebhook(&model.Webhook{ID: webhookID})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return nil, serr
+	}
+	return
+}
+
+// update webhook
+func update(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewWebhookService(tx, api.GetLoginUser(c))
+	find, err := findWebhookByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	webhook, serr := getWebhookByUpdateRequest(c, find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	// update webhook
+	serr = srvc.UpdateWebhook(webhook)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := convertWebhookResponse(webhook)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// delete webhook
+func delete(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewWebhookService(tx, api.GetLoginUser(c))
+	find, err := findWebhookByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	// delete webhook
+	serr := srvc.DeleteWebhook(find)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// find deliveries of the webhook, newest first
+func listDeliveries(c *gin.Context) {
+	query, serr := api.GetListQuery(c, deliverySortKeys, []string{"created_date desc"}, deliveryResponse{})
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB() // No transction
+	srvc := service.NewWebhookService(tx, api.GetLoginUser(c))
+	find, err := findWebhookByPathParameter(c, srvc)
+	if err != nil {
+		return
+	}
+	deliveries, count, serr := srvc.FindWebhookDeliveriesPage(find.ID, query.After, query.FetchLimit(), query.SortOrders)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListDeliveryResponse(deliveries)
+	api.WriteList(c, query, count, deliveries, res)
+}
+
+// send the delivery again as a new delivery
+func redeliver(c *gin.Context) {
+	tx := orm.GetDB().Begin()
+	srvc := service.NewWebhookService(tx, api.GetLoginUser(c))
+	find, err := findWebhookByPathParameter(c, srvc)
+	if err != nil {
+		api.Rollback(tx)
+		return
+	}
+	deliveryID, serr := api.GetPathParameter(c, EndPoint.deliveryid)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	delivery, serr := srvc.FindWebhookDelivery(&model.WebhookDelivery{ID: deliveryID, WebhookID: find.ID})
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	redelivery, serr := srvc.Redeliver(delivery)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	worker.NotifyWebhookWorker()
+	res := convertDeliveryResponse(redelivery)
+	c.IndentedJSON(http.StatusOK, res)
+}
@@ -0,0 +1,152 @@
+package webhooks
+
+import (
+	"taskboard/model"
+	"taskboard/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ID          string    `gorm:"primary_key;size:32"`
+// URL         string    `gorm:"not null;size:2000"`
+// Secret      string    `gorm:"not null;size:255"`  // Key to sign payloads
+// EventTypes  string    `gorm:"not null;size:1000"` // Comma separated event types
+// IsActive    bool      `gorm:"not null"`
+// CreatedDate time.Time `gorm:"not null"`
+// Version     int       `gorm:"not null"` // Version for optimistic lock
+
+// Secret is never responded
+type webhookResponse struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"eventTypes"`
+	IsActive    bool     `json:"isActive"`
+	CreatedDate string   `json:"createDate"`
+	Version     int      `json:"version"`
+}
+
+type createRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"eventTypes"`
+	IsActive   bool     `json:"isActive"`
+}
+
+type updateRequest struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"` // Not changed if empty
+	EventTypes []string `json:"eventTypes"`
+	IsActive   bool     `json:"isActive"`
+	Version    int      `json:"version"`
+}
+
+// ID              string     `gorm:"primary_key;size:32"`
+// WebhookID       string     `gorm:"not null;size:32;index"`
+// EventID         string     `gorm:"not null;size:32"`
+// EventType       string     `gorm:"not null;size:32"`
+// Payload         string     `gorm:"not null"`
+// Status          string     `gorm:"not null;size:16;index"`
+// Attempts        int        `gorm:"not null"`
+// NextAttemptDate time.Time  `gorm:"not null"`
+// LastStatusCode  int        `gorm:"not null"` // 0 if no response
+// LastError       string     `gorm:"size:1000"`
+// CreatedDate     time.Time  `gorm:"not null"`
+// DeliveredDate   *time.Time // Null if not delivered
+// Version         int        `gorm:"not null"` // Version for optimistic lock
+
+type deliveryResponse struct {
+	ID              string `json:"id"`
+	WebhookID       string `json:"webhookID"`
+	EventID         string `json:"eventID"`
+	EventType       string `json:"eventType"`
+	Payload         string `json:"payload"`
+	Status          string `json:"status"`
+	Attempts        int    `json:"attempts"`
+	NextAttemptDate string `json:"nextAttemptDate"`
+	LastStatusCode  int    `json:"lastStatusCode"`
+	LastError       string `json:"lastError"`
+	CreatedDate     string `json:"createDate"`
+	DeliveredDate   string `json:"deliveredDate"`
+	Version         int    `json:"version"`
+}
+
+func convertWebhookResponse(webhook *model.Webhook) *webhookResponse {
+	return &webhookResponse{
+		ID:          webhook.ID,
+		URL:         webhook.URL,
+		EventTypes:  webhook.GetEventTypes(),
+		IsActive:    webhook.IsActive,
+		CreatedDate: webhook.CreatedDate.Format(time.RFC3339),
+		Version:     webhook.Version,
+	}
+}
+
+func convertListWebhookResponse(webhooks []model.Webhook) (res []*webhookResponse) {
+	res = make([]*webhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		res = append(res, convertWebhookResponse(&webhook))
+	}
+	return
+}
+
+func convertDeliveryResponse(delivery *model.WebhookDelivery) *deliveryResponse {
+	deliveredDate := ""
+	if delivery.DeliveredDate != nil {
+		deliveredDate = delivery.DeliveredDate.Format(time.RFC3339)
+	}
+	return &deliveryResponse{
+		ID:              delivery.ID,
+		WebhookID:       delivery.WebhookID,
+		EventID:         delivery.EventID,
+		EventType:       delivery.EventType,
+		Payload:         delivery.Payload,
+		Status:          delivery.Status,
+		Attempts:        delivery.Attempts,
+		NextAttemptDate: delivery.NextAttemptDate.Format(time.RFC3339),
+		LastStatusCode:  delivery.LastStatusCode,
+		LastError:       delivery.LastError,
+		CreatedDate:     delivery.CreatedDate.Format(time.RFC3339),
+		DeliveredDate:   deliveredDate,
+		Version:         delivery.Version,
+	}
+}
+
+func convertListDeliveryResponse(deliveries []model.WebhookDelivery) (res []*deliveryResponse) {
+	res = make([]*deliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		res = append(res, convertDeliveryResponse(&delivery))
+	}
+	return
+}
+
+func getWebhookByCreateRequest(c *gin.Context) (*model.Webhook, error) {
+	var req createRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	return model.NewWebhook(req.URL, req.Secret, req.EventTypes, req.IsActive, time.Now().UTC()), nil
+}
+
+func getWebhookByUpdateRequest(c *gin.Context, find *model.Webhook) (*model.Webhook, error) {
+	var req updateRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, service.NewBadRequestError(err)
+	}
+	webhook := &model.Webhook{
+		ID:          find.ID,
+		URL:         req.URL,
+		Secret:      find.Secret,
+		IsActive:    req.IsActive,
+		CreatedDate: find.CreatedDate,
+		Version:     req.Version,
+	}
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	webhook.SetEventTypes(req.EventTypes)
+	return webhook, nil
+}
@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"net/http"
+	"taskboard/controller/api"
+	"taskboard/orm"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type endPoint struct {
+	states string
+}
+
+// EndPoint presents workflow endpoint
+var EndPoint = endPoint{
+	states: "/workflow/states",
+}
+
+// RegisterRoute registers API endpoints for workflow
+func (p *endPoint) RegisterRoute(route *gin.RouterGroup) (err error) {
+	route.GET(p.states, list)
+	route.PUT(p.states, update)
+	return
+}
+
+// find workflow states in order of workflow
+func list(c *gin.Context) {
+	tx := orm.GetDB() // No transction
+	srvc := service.NewWorkflowService(tx, api.GetLoginUser(c))
+	states, serr := srvc.FindWorkflowStates()
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	res := convertListStateResponse(states)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// replace workflow, system boards of all projects follow it
+func update(c *gin.Context) {
+	states, version, serr := getStatesByUpdateRequest(c)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	tx := orm.GetDB().Begin()
+	srvc := service.NewWorkflowService(tx, api.GetLoginUser(c))
+	serr = srvc.SetWorkflowStates(states, version)
+	if serr != nil {
+		api.Rollback(tx)
+		api.SetErrorStatus(c, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		api.SetErrorStatus(c, serr)
+		return
+	}
+
+	res := make([]*stateResponse, 0, len(states))
+	for _, state := range states {
+		res = append(res, convertStateResponse(state))
+	}
+	c.IndentedJSON(http.StatusOK, res)
+}
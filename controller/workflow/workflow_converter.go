@@ -0,0 +1,66 @@
+package workflow
+
+import (
+	"taskboard/model"
+	"taskboard/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ID       string `gorm:"primary_key;size:16"`      // Key of state, kept in SystemKind of system boards
+// Name     string `gorm:"unique;not null;size:255"` // Name of system boards
+// Position int    `gorm:"not null"`                 // Order of states in workflow
+// IsInbox  bool   `gorm:"not null"`                 // Tasks are placed here if board is not specified or deleted, only one state is inbox
+// IsDone   bool   `gorm:"not null"`                 // System boards of this state are closed, tasks moved to them are closed
+// Version  int    `gorm:"not null"`                 // Version for optimistic lock
+
+type stateResponse struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	IsInbox bool   `json:"isInbox"`
+	IsDone  bool   `json:"isDone"`
+	Version int    `json:"version"` // Version of workflow, the same in all states
+}
+
+type stateRequest struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	IsInbox bool   `json:"isInbox"`
+	IsDone  bool   `json:"isDone"`
+}
+
+type updateRequest struct {
+	States  []stateRequest `json:"states"`  // In order of workflow
+	Version int            `json:"version"` // Version of workflow which states are based on
+}
+
+func convertStateResponse(state *model.WorkflowState) *stateResponse {
+	return &stateResponse{
+		ID:      state.ID,
+		Name:    state.Name,
+		IsInbox: state.IsInbox,
+		IsDone:  state.IsDone,
+		Version: state.Version,
+	}
+}
+
+func convertListStateResponse(states []model.WorkflowState) (res []*stateResponse) {
+	res = make([]*stateResponse, 0, len(states))
+	for _, state := range states {
+		res = append(res, convertStateResponse(&state))
+	}
+	return
+}
+
+func getStatesByUpdateRequest(c *gin.Context) ([]*model.WorkflowState, int, error) {
+	var req updateRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		return nil, 0, service.NewBadRequestError(err)
+	}
+	states := make([]*model.WorkflowState, 0, len(req.States))
+	for _, s := range req.States {
+		states = append(states, model.NewWorkflowState(s.ID, s.Name, s.IsInbox, s.IsDone))
+	}
+	return states, req.Version, nil
+}
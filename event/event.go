@@ -0,0 +1,196 @@
+package event
+
+import (
+	"sync"
+	"taskboard/common"
+	"time"
+)
+
+// Types of event
+const (
+	TypeBoardCreated   = "board.created"
+	TypeBoardUpdated   = "board.updated"
+	TypeBoardDeleted   = "board.deleted"
+	TypeBoardReordered = "board.reordered"
+	TypeTaskCreated    = "task.created"
+	TypeTaskUpdated    = "task.updated"
+	TypeTaskDeleted    = "task.deleted"
+	TypeTaskMoved      = "task.moved"
+	TypeUserCreated    = "user.created"
+	TypeUserUpdated    = "user.updated"
+	TypeUserDeleted    = "user.deleted"
+)
+
+// TypeAutomationFired is the type of event sent by fireWebhook action of automation rule.
+// It is sent only to the webhook of the action, so it is not in Types.
+const TypeAutomationFired = "automation.fired"
+
+// BoardTypes is the types of events which change boards and tasks on them
+var BoardTypes = []string{
+	TypeBoardCreated,
+	TypeBoardUpdated,
+	TypeBoardDeleted,
+	TypeBoardReordered,
+	TypeTaskCreated,
+	TypeTaskUpdated,
+	TypeTaskDeleted,
+	TypeTaskMoved,
+}
+
+// Types is all types of events
+var Types = append(append([]string{}, BoardTypes...),
+	TypeUserCreated,
+	TypeUserUpdated,
+	TypeUserDeleted,
+)
+
+// IsValidType checks whether eventType is one of Types
+func IsValidType(eventType string) bool {
+	for _, t := range Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptionBufferSize is the number of events which can be queued for a subscriber
+const subscriptionBufferSize = 64
+
+// Event presents a change of boards or tasks which has been committed
+type Event struct {
+	ID          string      `json:"id"`
+	Type        string      `json:"type"`
+	BoardIDs    []string    `json:"boardIDs"` // Boards related to the event, empty if it relates to all boards
+	Data        interface{} `json:"data"`     // Response of api which caused the event
+	CreatedDate string      `json:"createDate"`
+}
+
+// Subscription receives published events until it is closed
+type Subscription struct {
+	Events   <-chan *Event
+	events   chan *Event
+	boardIDs map[string]bool
+	types    map[string]bool
+	closed   bool
+}
+
+// Listener is a function called for each published event in the goroutine of publisher
+type Listener func(e *Event)
+
+var (
+	lock          = &sync.Mutex{}
+	subscriptions = map[*Subscription]bool{}
+	listeners     = []Listener{}
+)
+
+// NewEvent returns created new event which is not published
+func NewEvent(eventType string, boardIDs []string, data interface{}) *Event {
+	return &Event{
+		ID:          "event_" + common.GenerateID(),
+		Type:        eventType,
+		BoardIDs:    compactBoardIDs(boardIDs),
+		Data:        data,
+		CreatedDate: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// Publish sends event to subscribers and listeners.
+// Call this only after transaction is committed, clients must not see rolled back changes.
+func Publish(eventType string, boardIDs []string, data interface{}) {
+	e := NewEvent(eventType, boardIDs, data)
+	lock.Lock()
+	for subscription := range subscriptions {
+		if !subscription.accepts(e) {
+			continue
+		}
+		select {
+		case subscription.events <- e:
+		default:
+			// Subscriber is too slow, it is closed to let client reload
+			subscription.close()
+		}
+	}
+	// Listeners are called without lock, they may take time
+	called := listeners
+	lock.Unlock()
+	for _, listener := range called {
+		listener(e)
+	}
+}
+
+// Subscribe starts subscription of events of specified types related to specified boards.
+// All boards are subscribed if boardIDs is empty.
+func Subscribe(boardIDs []string, types []string) *Subscription {
+	events := make(chan *Event, subscriptionBufferSize)
+	subscription := &Subscription{
+		Events:   events,
+		events:   events,
+		boardIDs: map[string]bool{},
+		types:    map[string]bool{},
+	}
+	for _, boardID := range compactBoardIDs(boardIDs) {
+		subscription.boardIDs[boardID] = true
+	}
+	for _, t := range types {
+		subscription.types[t] = true
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	subscriptions[subscription] = true
+	return subscription
+}
+
+// Unsubscribe stops subscription and closes its channel
+func Unsubscribe(subscription *Subscription) {
+	lock.Lock()
+	defer lock.Unlock()
+	subscription.close()
+}
+
+// AddListener registers function which is called for each published event
+func AddListener(listener Listener) {
+	lock.Lock()
+	defer lock.Unlock()
+	// Copy not to change slice which is being called by Publish
+	listeners = append(append([]Listener{}, listeners...), listener)
+}
+
+// accepts checks whether event is the type and relates to boards of subscription
+func (s *Subscription) accepts(e *Event) bool {
+	if !s.types[e.Type] {
+		return false
+	}
+	if len(s.boardIDs) == 0 || len(e.BoardIDs) == 0 {
+		return true
+	}
+	for _, boardID := range e.BoardIDs {
+		if s.boardIDs[boardID] {
+			return true
+		}
+	}
+	return false
+}
+
+// close must be called with lock
+func (s *Subscription) close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(subscriptions, s)
+	close(s.events)
+}
+
+// compactBoardIDs removes empty and duplicated board ids
+func compactBoardIDs(boardIDs []string) []string {
+	result := make([]string, 0, len(boardIDs))
+	exists := map[string]bool{}
+	for _, boardID := range boardIDs {
+		if boardID != "" && !exists[boardID] {
+			exists[boardID] = true
+			result = append(result, boardID)
+		}
+	}
+	return result
+}
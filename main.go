@@ -1,97 +1,224 @@
 package main
 
 import (
+	"crypto/rand"
+	"flag"
 	"fmt"
 	"os"
-	"strconv"
+	"taskboard/common"
+	"taskboard/config"
 	"taskboard/controller/api"
+	"taskboard/controller/automations"
 	"taskboard/controller/boards"
+	"taskboard/controller/comments"
+	"taskboard/controller/events"
+	"taskboard/controller/histories"
+	"taskboard/controller/integrity"
+	"taskboard/controller/labels"
+	"taskboard/controller/projects"
+	"taskboard/controller/search"
+	"taskboard/controller/sessions"
 	"taskboard/controller/tasks"
+	"taskboard/controller/transitions"
 	"taskboard/controller/users"
-	"taskboard/model"
+	"taskboard/controller/views"
+	"taskboard/controller/webhooks"
+	"taskboard/controller/workflow"
+	"taskboard/migration"
 	"taskboard/orm"
 	"taskboard/service"
+	"taskboard/worker"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
 )
 
 func main() {
+	// Load configuration
+	conf, args, err := config.Load(os.Args[0], os.Args[1:])
+	if err == flag.ErrHelp {
+		printUsage()
+		return
+	}
+	if err != nil {
+		fmt.Printf("Failed to load configuration. error:%v\n", err)
+		os.Exit(2)
+	}
+
 	// Init database
-	fmt.Println("Initializing database...")
-	databasePath := "./taskboard.sqlite3"
-	err := orm.Init(databasePath)
+	err = orm.Init(conf.Database.Path)
 	if err != nil {
 		fmt.Printf("Failed to initialize database. error:%+v\n", err)
-		return
+		os.Exit(1)
+	}
+	orm.SetPoolSize(conf.Database.MaxOpenConns, conf.Database.MaxIdleConns)
+	orm.SetLogMode(conf.LogLevel == config.LogLevelDebug)
+
+	// Server is started if command is omitted
+	if len(args) == 0 {
+		args = []string{commandServe}
+	}
+	if err = runCommand(conf, args); err != nil {
+		printCommandError(err)
+		os.Exit(1)
+	}
+}
+
+// serve prepares database and starts api server
+func serve(conf *config.Config, args []string) error {
+	if len(args) > 0 {
+		return errors.Errorf("serve does not accept arguments %v", args)
+	}
+	if err := conf.ValidateServer(); err != nil {
+		return err
 	}
 
 	// Create or Update tables
-	err = orm.Migrate(
-		&model.User{},
-		&model.Task{},
-		&model.Board{},
-	)
+	fmt.Println("Initializing database...")
+	if err := migration.Up(orm.GetDB()); err != nil {
+		return errors.Wrap(err, "failed to migrate database")
+	}
+
+	// Create system boards of workflow states in all projects
+	err := inTransaction(func(tx *gorm.DB) error {
+		return service.NewWorkflowService(tx, nil).SyncSystemBoards()
+	})
 	if err != nil {
-		fmt.Printf("Failed to update tables. error:%+v\n", err)
-		return
+		return errors.Wrap(err, "failed to create system boards")
 	}
 
-	// Create system boards(Icebox, Todo, Doing, Done)
-	tx := orm.GetDB().Begin()
-	srvc := service.NewBoardService(tx)
-	if err = srvc.CreateSystemBoards(); err != nil {
-		fmt.Printf("Failed to create system boards. error:%+v\n", err)
-		api.Rollback(tx)
-		return
+	// Search api fails until index is created
+	enabled, err := service.NewSearchService(orm.GetDB(), nil).IsIndexEnabled()
+	if err != nil {
+		return errors.Wrap(err, "failed to check search index")
 	}
-	if err = api.Commit(tx); err != nil {
-		return
+	if !enabled {
+		fmt.Printf("Full-text search is disabled. Build with tag sqlite_fts5 and execute [%s] command to enable it.\n",
+			commandRebuildIndex)
 	}
 
+	// Create admin user if not exist
+	if err = createAdminUser(); err != nil {
+		return errors.Wrap(err, "failed to create admin user")
+	}
+
+	// Set secret key to sign session tokens
+	if err = setTokenSecret(); err != nil {
+		return errors.Wrap(err, "failed to set token secret")
+	}
+
+	// Start delivering webhooks of events
+	worker.StartWebhookWorker()
+
+	// Start running automation rules of overdue tasks
+	worker.StartAutomationWorker()
+
 	// Init router of REST apis
-	router := gin.Default()
-	//config := cors.DefaultConfig()
-	//config.AllowAllOrigins = true
-	//config.AllowHeaders = []string{"Content-Type"}
-	//router.Use(cors.New(config))
-	router.Use(cors.Default())
+	router := newRouter(conf)
 	// Include static/avators
-	router.Static("/taskboard/static", "./static")
+	router.Static("/taskboard/static", conf.Server.StaticDir)
 
-	// Register api path
+	// Register api path which can be called without login
 	routeGroup := router.Group("/taskboard")
+	sessions.EndPoint.RegisterPublicRoute(routeGroup)
+
+	// Register api path of EventSource which can not set headers, it accepts access token in query parameter
+	streamGroup := router.Group("/taskboard")
+	streamGroup.Use(api.AuthenticateStream)
+	events.EndPoint.RegisterRoute(streamGroup)
+
+	// Register api path which requires valid access token
+	routeGroup.Use(api.Authenticate)
+	sessions.EndPoint.RegisterRoute(routeGroup)
 	users.EndPoint.RegisterRoute(routeGroup)
 	boards.EndPoint.RegisterRoute(routeGroup)
 	tasks.EndPoint.RegisterRoute(routeGroup)
+	comments.EndPoint.RegisterRoute(routeGroup)
+	labels.EndPoint.RegisterRoute(routeGroup)
+	histories.EndPoint.RegisterRoute(routeGroup)
+	webhooks.EndPoint.RegisterRoute(routeGroup)
+	integrity.EndPoint.RegisterRoute(routeGroup)
+	search.EndPoint.RegisterRoute(routeGroup)
+	views.EndPoint.RegisterRoute(routeGroup)
+	projects.EndPoint.RegisterRoute(routeGroup)
+	workflow.EndPoint.RegisterRoute(routeGroup)
+	transitions.EndPoint.RegisterRoute(routeGroup)
+	automations.EndPoint.RegisterRoute(routeGroup)
 
-	// Set listening host:port
-	url := getListeningURL()
 	// Start server
-	fmt.Printf("Taskboard api server is starting... listening %s\n", url)
-	err = router.Run(url)
+	address := conf.ListeningAddress()
+	if conf.IsTLS() {
+		fmt.Printf("Taskboard api server is starting... listening %s (TLS)\n", address)
+		err = router.RunTLS(address, conf.Server.TLSCertFile, conf.Server.TLSKeyFile)
+	} else {
+		fmt.Printf("Taskboard api server is starting... listening %s\n", address)
+		err = router.Run(address)
+	}
+	return errors.Wrap(err, "failed to start api server")
+}
+
+func createAdminUser() error {
+	password := os.Getenv("TASKBOARD_ADMIN_PASSWORD")
+	isRandom := password == ""
+	if isRandom {
+		password = common.GenerateID()[:16]
+	}
+	tx := orm.GetDB().Begin()
+	srvc := service.NewUserService(tx, nil)
+	user, err := srvc.CreateAdminUser("admin", password)
 	if err != nil {
-		fmt.Printf("Failed to start api server. error:%+v\n", err)
-		return
+		api.Rollback(tx)
+		return err
+	}
+	if err = api.Commit(tx); err != nil {
+		return err
+	}
+	if user != nil && isRandom {
+		fmt.Printf("Admin user [%s] is created with password [%s], please change it after login.\n", user.Name, password)
 	}
+	return nil
 }
 
-func getListeningURL() string {
-	host := os.Getenv("TASKBOARD_API_SERVER_HOST")
-	if host == "" {
-		fmt.Println("Environment variable [TASKBOARD_API_SERVER_HOST] is not set or invalid. localhost is used as default")
+func setTokenSecret() error {
+	secret := os.Getenv("TASKBOARD_TOKEN_SECRET")
+	if secret != "" {
+		service.SetTokenSecret([]byte(secret))
+		return nil
 	}
-	portEnv := os.Getenv("TASKBOARD_API_SERVER_PORT")
-	port := 0
-	if portEnv != "" {
-		port, err := strconv.Atoi(portEnv)
-		if err != nil || port < 0 {
-			port = 0
-		}
+	fmt.Println("Environment variable [TASKBOARD_TOKEN_SECRET] is not set. Random secret is used, issued tokens are invalidated when server restarts.")
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return err
 	}
-	if port == 0 {
-		fmt.Println("Environment variable [TASKBOARD_API_SERVER_PORT] is not set or invalid, 7000 port is used as defalt.")
-		port = 7000
+	service.SetTokenSecret(random)
+	return nil
+}
+
+// newRouter returns router whose logging and CORS are set by config
+func newRouter(conf *config.Config) *gin.Engine {
+	switch conf.LogLevel {
+	case config.LogLevelDebug:
+		gin.SetMode(gin.DebugMode)
+	default:
+		gin.SetMode(gin.ReleaseMode)
+	}
+	router := gin.New()
+	if conf.LogLevel == config.LogLevelDebug || conf.LogLevel == config.LogLevelInfo {
+		// Access log
+		router.Use(gin.Logger())
+	}
+	router.Use(gin.Recovery())
+
+	corsConfig := cors.DefaultConfig()
+	if conf.AllowsAllOrigins() {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = conf.Server.CORSOrigins
 	}
-	return fmt.Sprintf("%s:%d", host, port)
+	corsConfig.AddAllowHeaders("Authorization")
+	corsConfig.AddExposeHeaders(api.ListHeaders...)
+	router.Use(cors.New(corsConfig))
+	return router
 }
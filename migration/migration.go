@@ -0,0 +1,219 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Migration presents a versioned change of database schema or data.
+// Up applies the change and Down reverts it, both are executed in a transaction.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// State presents whether a migration has been applied
+type State struct {
+	Version     int
+	Name        string
+	IsApplied   bool
+	AppliedDate *time.Time // Null if not applied
+}
+
+// schemaMigration is a record of applied migration
+type schemaMigration struct {
+	Version     int       `gorm:"primary_key;auto_increment:false"`
+	Name        string    `gorm:"not null;size:255"`
+	AppliedDate time.Time `gorm:"not null"`
+}
+
+// TableName returns name of the table which records applied migrations
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// SQL returns migration step which executes specified statements in order
+func SQL(statements ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return errors.Wrapf(err, "failed to execute sql [%s]", statement)
+			}
+		}
+		return nil
+	}
+}
+
+// Migrations returns all migrations in order of version
+func Migrations() []Migration {
+	result := append([]Migration{}, migrations...)
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result
+}
+
+// LatestVersion returns version of the last migration
+func LatestVersion() int {
+	all := Migrations()
+	if len(all) == 0 {
+		return 0
+	}
+	return all[len(all)-1].Version
+}
+
+// Status returns states of all migrations
+func Status(db *gorm.DB) ([]State, error) {
+	applied, err := findApplied(db)
+	if err != nil {
+		return nil, err
+	}
+	states := []State{}
+	for _, m := range Migrations() {
+		state := State{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			state.IsApplied = true
+			appliedDate := record.AppliedDate
+			state.AppliedDate = &appliedDate
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// CurrentVersion returns version of the last applied migration, 0 if nothing is applied
+func CurrentVersion(db *gorm.DB) (int, error) {
+	applied, err := findApplied(db)
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	return current, nil
+}
+
+// Up applies all pending migrations
+func Up(db *gorm.DB) error {
+	return To(db, LatestVersion())
+}
+
+// Down reverts the last applied migration
+func Down(db *gorm.DB) error {
+	current, err := CurrentVersion(db)
+	if err != nil {
+		return err
+	}
+	if current == 0 {
+		return nil
+	}
+	previous := 0
+	for _, m := range Migrations() {
+		if m.Version < current {
+			previous = m.Version
+		}
+	}
+	return To(db, previous)
+}
+
+// To applies or reverts migrations until specified version, 0 reverts all migrations
+func To(db *gorm.DB, version int) error {
+	if version != 0 && findMigration(version) == nil {
+		return errors.Errorf("migration version %d does not exist", version)
+	}
+	applied, err := findApplied(db)
+	if err != nil {
+		return err
+	}
+	all := Migrations()
+	// Apply pending migrations up to version in ascending order
+	for _, m := range all {
+		if _, ok := applied[m.Version]; ok || m.Version > version {
+			continue
+		}
+		if err = run(db, m, true); err != nil {
+			return err
+		}
+	}
+	// Revert applied migrations after version in descending order
+	for i := len(all) - 1; i >= 0; i-- {
+		m := all[i]
+		if _, ok := applied[m.Version]; !ok || m.Version <= version {
+			continue
+		}
+		if err = run(db, m, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run executes a step of migration and records it in a transaction
+func run(db *gorm.DB, m Migration, isUp bool) (err error) {
+	direction := "down"
+	step := m.Down
+	if isUp {
+		direction = "up"
+		step = m.Up
+	}
+	if step == nil {
+		return errors.Errorf("migration %d [%s] can not be migrated %s", m.Version, m.Name, direction)
+	}
+	tx := db.Begin()
+	if err = tx.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	if err = step(tx); err != nil {
+		return errors.Wrapf(err, "failed to migrate %s %d [%s]", direction, m.Version, m.Name)
+	}
+	if isUp {
+		err = tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedDate: time.Now().UTC()}).Error
+	} else {
+		err = tx.Where("version = ?", m.Version).Delete(&schemaMigration{}).Error
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to record migration %d [%s]", m.Version, m.Name)
+	}
+	if err = tx.Commit().Error; err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Printf("Migrated %s %d [%s]\n", direction, m.Version, m.Name)
+	return nil
+}
+
+// findApplied returns records of applied migrations by version, creates the table if not exists
+func findApplied(db *gorm.DB) (map[int]schemaMigration, error) {
+	if err := db.AutoMigrate(&schemaMigration{}).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to create migrations table")
+	}
+	var records []schemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to find applied migrations")
+	}
+	result := map[int]schemaMigration{}
+	for _, record := range records {
+		result[record.Version] = record
+	}
+	return result, nil
+}
+
+func findMigration(version int) *Migration {
+	for _, m := range Migrations() {
+		if m.Version == version {
+			return &m
+		}
+	}
+	return nil
+}
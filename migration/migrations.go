@@ -0,0 +1,81 @@
+package migration
+
+// migrations is all migrations of the app. Add new migration to the end with next version,
+// and never change migrations which have been released.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_baseline_tables",
+		Up:      createBaselineTables,
+		Down:    dropBaselineTables,
+	},
+	{
+		Version: 2,
+		Name:    "rename_esitmate_size_to_estimate_size",
+		Up: SQL(
+			"alter table tasks rename column esitmate_size to estimate_size",
+			"update task_histories set field = 'estimateSize' where field = 'esitmateSize'",
+		),
+		Down: SQL(
+			"update task_histories set field = 'esitmateSize' where field = 'estimateSize'",
+			"alter table tasks rename column estimate_size to esitmate_size",
+		),
+	},
+	{
+		Version: 3,
+		Name:    "replace_disp_order_with_rank",
+		Up:      replaceDispOrderWithRank,
+		Down:    replaceRankWithDispOrder,
+	},
+	{
+		Version: 4,
+		Name:    "create_search_index",
+		Up:      createSearchIndex,
+		Down:    SQL("drop table if exists search_index"),
+	},
+	{
+		Version: 5,
+		Name:    "create_saved_views",
+		Up:      createSavedViews,
+		Down:    dropSavedViews,
+	},
+	{
+		Version: 6,
+		Name:    "add_board_wip_limit",
+		Up: SQL(
+			"alter table boards add column wip_limit integer not null default 0",
+			"alter table boards add column wip_mode varchar(16) not null default ''",
+		),
+		Down: dropBoardWIPLimit,
+	},
+	{
+		Version: 7,
+		Name:    "create_projects",
+		Up:      createProjects,
+		Down:    dropProjects,
+	},
+	{
+		Version: 8,
+		Name:    "create_workflow_states",
+		Up:      createWorkflowStates,
+		Down:    dropWorkflowStates,
+	},
+	{
+		Version: 9,
+		Name:    "create_transition_rules",
+		Up:      createTransitionRules,
+		Down:    dropTransitionRules,
+	},
+	{
+		Version: 10,
+		Name:    "create_automation_rules",
+		Up:      createAutomationRules,
+		Down:    dropAutomationRules,
+	},
+	{
+		Version: 11,
+		Name:    "key_search_index_by_rowid",
+		Up:      keySearchIndexByRowid,
+		Down:    keySearchIndexByColumns,
+	},
+}
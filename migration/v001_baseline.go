@@ -0,0 +1,160 @@
+package migration
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Snapshots of models when migrations were introduced. Tables which had been created by AutoMigrate
+// are kept as they are, so this can be applied to existing databases.
+// Do not change these structs, add new migration to change tables.
+
+type baselineUser struct {
+	ID           string `gorm:"primary_key;size:32"`
+	Name         string `gorm:"size:255;not null;unique"`
+	PasswordHash string `gorm:"size:255;not null;"`
+	Avator       string `gorm:"size:255"`
+	Role         string `gorm:"size:16;not null;default:'member'"`
+	Version      int    `gorm:"not null"`
+}
+
+func (baselineUser) TableName() string { return "users" }
+
+type baselineTask struct {
+	ID             string         `gorm:"primary_key;size:32"`
+	Name           string         `gorm:"not null;size:255"`
+	Description    string         `gorm:"size:8000"`
+	AssigneeUserID sql.NullString `gorm:"size:32"`
+	BoardID        string         `gorm:"not null; size:32"`
+	DispOrder      int            `gorm:"not null"`
+	CreatedDate    time.Time      `gorm:"not null"`
+	IsClosed       bool           `gorm:"not null"`
+	Version        int            `gorm:"not null"`
+	EsitmateSize   int
+	StartDate      *time.Time
+	DueDate        *time.Time `gorm:"index"`
+}
+
+func (baselineTask) TableName() string { return "tasks" }
+
+type baselineBoard struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	Name        string    `gorm:"unique;size:255"`
+	DispOrder   int       `gorm:"not null"`
+	IsSystem    bool      `gorm:"not null"`
+	IsClosed    bool      `gorm:"not null"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"`
+}
+
+func (baselineBoard) TableName() string { return "boards" }
+
+type baselineSession struct {
+	ID                 string    `gorm:"primary_key;size:32"`
+	UserID             string    `gorm:"not null;size:32;index"`
+	ExpiredDate        time.Time `gorm:"not null"`
+	RefreshExpiredDate time.Time `gorm:"not null"`
+	IsRevoked          bool      `gorm:"not null"`
+	CreatedDate        time.Time `gorm:"not null"`
+	Version            int       `gorm:"not null"`
+}
+
+func (baselineSession) TableName() string { return "sessions" }
+
+type baselineComment struct {
+	ID           string    `gorm:"primary_key;size:32"`
+	TaskID       string    `gorm:"not null;size:32;index"`
+	AuthorUserID string    `gorm:"not null;size:32"`
+	Body         string    `gorm:"not null;size:8000"`
+	CreatedDate  time.Time `gorm:"not null"`
+	EditedDate   *time.Time
+	Version      int `gorm:"not null"`
+}
+
+func (baselineComment) TableName() string { return "comments" }
+
+type baselineLabel struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	Name        string    `gorm:"unique;not null;size:255"`
+	Color       string    `gorm:"not null;size:7"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"`
+}
+
+func (baselineLabel) TableName() string { return "labels" }
+
+type baselineTaskLabel struct {
+	TaskID  string `gorm:"primary_key;size:32"`
+	LabelID string `gorm:"primary_key;size:32;index"`
+}
+
+func (baselineTaskLabel) TableName() string { return "task_labels" }
+
+type baselineTaskHistory struct {
+	ID          string         `gorm:"primary_key;size:32"`
+	TaskID      string         `gorm:"not null;size:32;index"`
+	Revision    int            `gorm:"not null"`
+	ActorUserID sql.NullString `gorm:"size:32"`
+	Field       string         `gorm:"not null;size:32"`
+	OldValue    string         `gorm:"size:8000"`
+	NewValue    string         `gorm:"size:8000"`
+	CreatedDate time.Time      `gorm:"not null"`
+}
+
+func (baselineTaskHistory) TableName() string { return "task_histories" }
+
+type baselineWebhook struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	URL         string    `gorm:"not null;size:2000"`
+	Secret      string    `gorm:"not null;size:255"`
+	EventTypes  string    `gorm:"not null;size:1000"`
+	IsActive    bool      `gorm:"not null"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"`
+}
+
+func (baselineWebhook) TableName() string { return "webhooks" }
+
+type baselineWebhookDelivery struct {
+	ID              string    `gorm:"primary_key;size:32"`
+	WebhookID       string    `gorm:"not null;size:32;index"`
+	EventID         string    `gorm:"not null;size:32"`
+	EventType       string    `gorm:"not null;size:32"`
+	Payload         string    `gorm:"not null"`
+	Status          string    `gorm:"not null;size:16;index"`
+	Attempts        int       `gorm:"not null"`
+	NextAttemptDate time.Time `gorm:"not null"`
+	LastStatusCode  int       `gorm:"not null"`
+	LastError       string    `gorm:"size:1000"`
+	CreatedDate     time.Time `gorm:"not null"`
+	DeliveredDate   *time.Time
+	Version         int `gorm:"not null"`
+}
+
+func (baselineWebhookDelivery) TableName() string { return "webhook_deliveries" }
+
+func baselineModels() []interface{} {
+	return []interface{}{
+		&baselineUser{},
+		&baselineTask{},
+		&baselineBoard{},
+		&baselineSession{},
+		&baselineComment{},
+		&baselineLabel{},
+		&baselineTaskLabel{},
+		&baselineTaskHistory{},
+		&baselineWebhook{},
+		&baselineWebhookDelivery{},
+	}
+}
+
+func createBaselineTables(tx *gorm.DB) error {
+	return errors.WithStack(tx.AutoMigrate(baselineModels()...).Error)
+}
+
+func dropBaselineTables(tx *gorm.DB) error {
+	return errors.WithStack(tx.DropTableIfExists(baselineModels()...).Error)
+}
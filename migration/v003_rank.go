@@ -0,0 +1,196 @@
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"taskboard/rank"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Snapshots of models when display orders were replaced with rank keys.
+// Do not change these structs, add new migration to change tables.
+
+type rankedTask struct {
+	ID             string         `gorm:"primary_key;size:32"`
+	Name           string         `gorm:"not null;size:255"`
+	Description    string         `gorm:"size:8000"`
+	AssigneeUserID sql.NullString `gorm:"size:32"`
+	BoardID        string         `gorm:"not null;size:32"`
+	Rank           string         `gorm:"not null;size:255"`
+	CreatedDate    time.Time      `gorm:"not null"`
+	IsClosed       bool           `gorm:"not null"`
+	Version        int            `gorm:"not null"`
+	EstimateSize   int
+	StartDate      *time.Time
+	DueDate        *time.Time `gorm:"index"`
+}
+
+func (rankedTask) TableName() string { return "tasks" }
+
+// orderedTask is a task of version 2, whose estimate size has been renamed
+type orderedTask struct {
+	ID             string         `gorm:"primary_key;size:32"`
+	Name           string         `gorm:"not null;size:255"`
+	Description    string         `gorm:"size:8000"`
+	AssigneeUserID sql.NullString `gorm:"size:32"`
+	BoardID        string         `gorm:"not null; size:32"`
+	DispOrder      int            `gorm:"not null"`
+	CreatedDate    time.Time      `gorm:"not null"`
+	IsClosed       bool           `gorm:"not null"`
+	Version        int            `gorm:"not null"`
+	EstimateSize   int
+	StartDate      *time.Time
+	DueDate        *time.Time `gorm:"index"`
+}
+
+func (orderedTask) TableName() string { return "tasks" }
+
+type rankedBoard struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	Name        string    `gorm:"unique;size:255"`
+	Rank        string    `gorm:"not null;size:255"`
+	IsSystem    bool      `gorm:"not null"`
+	IsClosed    bool      `gorm:"not null"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"`
+}
+
+func (rankedBoard) TableName() string { return "boards" }
+
+const (
+	taskColumns  = "id, name, description, assignee_user_id, board_id, created_date, is_closed, version, estimate_size, start_date, due_date"
+	boardColumns = "id, name, is_system, is_closed, created_date, version"
+)
+
+func replaceDispOrderWithRank(tx *gorm.DB) error {
+	err := rebuildTable(tx, "tasks", &rankedTask{}, taskColumns, "rank", "''", "idx_tasks_due_date")
+	if err != nil {
+		return err
+	}
+	err = assignOrders(tx, "tasks", "board_id", "disp_order, created_date, id", "rank", func(count int) []interface{} {
+		return rankValues(count)
+	})
+	if err != nil {
+		return err
+	}
+	err = rebuildTable(tx, "boards", &rankedBoard{}, boardColumns, "rank", "''")
+	if err != nil {
+		return err
+	}
+	err = assignOrders(tx, "boards", "", "disp_order, created_date, id", "rank", func(count int) []interface{} {
+		return rankValues(count)
+	})
+	if err != nil {
+		return err
+	}
+	return SQL(
+		"drop table tasks_old",
+		"drop table boards_old",
+		"create unique index idx_tasks_board_id_rank on tasks(board_id, rank)",
+		"create unique index idx_boards_rank on boards(rank)",
+	)(tx)
+}
+
+func replaceRankWithDispOrder(tx *gorm.DB) error {
+	err := SQL("drop index idx_tasks_board_id_rank", "drop index idx_boards_rank")(tx)
+	if err != nil {
+		return err
+	}
+	err = rebuildTable(tx, "tasks", &orderedTask{}, taskColumns, "disp_order", "0", "idx_tasks_due_date")
+	if err != nil {
+		return err
+	}
+	// Display orders of tasks start from 1, and of boards start from 0
+	err = assignOrders(tx, "tasks", "board_id", "rank, id", "disp_order", func(count int) []interface{} {
+		return orderValues(count, 1)
+	})
+	if err != nil {
+		return err
+	}
+	err = rebuildTable(tx, "boards", &baselineBoard{}, boardColumns, "disp_order", "0")
+	if err != nil {
+		return err
+	}
+	err = assignOrders(tx, "boards", "", "rank, id", "disp_order", func(count int) []interface{} {
+		return orderValues(count, 0)
+	})
+	if err != nil {
+		return err
+	}
+	return SQL("drop table tasks_old", "drop table boards_old")(tx)
+}
+
+// rebuildTable creates new table of model and copies records from old one, which is renamed to "<table>_old".
+// SQLite can not drop columns, so tables are rebuilt to replace columns.
+// newColumns are comma separated columns set to newValues, no columns are added if empty.
+func rebuildTable(tx *gorm.DB, table string, model interface{}, columns, newColumns, newValues string, indexes ...string) error {
+	statements := []string{fmt.Sprintf("alter table %s rename to %s_old", table, table)}
+	for _, index := range indexes {
+		// Index names must be unique in database, they are created again by AutoMigrate
+		statements = append(statements, "drop index if exists "+index)
+	}
+	if err := SQL(statements...)(tx); err != nil {
+		return err
+	}
+	if err := tx.AutoMigrate(model).Error; err != nil {
+		return errors.Wrapf(err, "failed to create table %s", table)
+	}
+	insertColumns, selectValues := columns, columns
+	if newColumns != "" {
+		insertColumns += ", " + newColumns
+		selectValues += ", " + newValues
+	}
+	return SQL(fmt.Sprintf("insert into %s (%s) select %s from %s_old",
+		table, insertColumns, selectValues, table))(tx)
+}
+
+// assignOrders sets values of column in order of old table, values are generated for each group
+func assignOrders(tx *gorm.DB, table, groupColumn, orderBy, column string, values func(count int) []interface{}) error {
+	group := "''"
+	if groupColumn != "" {
+		group = groupColumn
+	}
+	rows, err := tx.Raw(fmt.Sprintf("select id, %s from %s_old order by %s, %s", group, table, group, orderBy)).Rows()
+	if err != nil {
+		return errors.Wrapf(err, "failed to find records of %s", table)
+	}
+	groups := map[string][]string{}
+	for rows.Next() {
+		var id, key string
+		if err = rows.Scan(&id, &key); err != nil {
+			rows.Close()
+			return errors.Wrapf(err, "failed to scan record of %s", table)
+		}
+		groups[key] = append(groups[key], id)
+	}
+	rows.Close()
+
+	for _, ids := range groups {
+		for i, value := range values(len(ids)) {
+			err = tx.Exec(fmt.Sprintf("update %s set %s = ? where id = ?", table, column), value, ids[i]).Error
+			if err != nil {
+				return errors.Wrapf(err, "failed to update %s of %s", column, table)
+			}
+		}
+	}
+	return nil
+}
+
+func rankValues(count int) []interface{} {
+	result := make([]interface{}, 0, count)
+	for _, key := range rank.Sequence(count) {
+		result = append(result, key)
+	}
+	return result
+}
+
+func orderValues(count, start int) []interface{} {
+	result := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		result = append(result, start+i)
+	}
+	return result
+}
@@ -0,0 +1,21 @@
+package migration
+
+import (
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+// createSearchIndex creates full-text search index of tasks and comments if sqlite supports FTS5.
+// Otherwise nothing is changed, and the index can be created by rebuild-index command later.
+func createSearchIndex(tx *gorm.DB) error {
+	if !orm.IsFTS5Supported(tx) {
+		return nil
+	}
+	return SQL(
+		"create virtual table search_index"+
+			" using fts5(kind unindexed, id unindexed, task_id unindexed, name, body, tokenize = 'unicode61')",
+		"insert into search_index (kind, id, task_id, name, body) select 'task', id, id, name, description from tasks",
+		"insert into search_index (kind, id, task_id, name, body) select 'comment', id, task_id, '', body from comments",
+	)(tx)
+}
@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Snapshot of saved view when it was introduced.
+// Do not change this struct, add new migration to change the table.
+
+type savedView struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	OwnerUserID string    `gorm:"not null;size:32;unique_index:idx_saved_views_owner_name"`
+	Name        string    `gorm:"not null;size:255;unique_index:idx_saved_views_owner_name"`
+	Query       string    `gorm:"not null;size:2000"`
+	Sort        string    `gorm:"not null;size:255"`
+	BoardID     string    `gorm:"not null;size:32"`
+	IsShared    bool      `gorm:"not null"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"`
+}
+
+func (savedView) TableName() string { return "saved_views" }
+
+func createSavedViews(tx *gorm.DB) error {
+	return errors.WithStack(tx.AutoMigrate(&savedView{}).Error)
+}
+
+func dropSavedViews(tx *gorm.DB) error {
+	return errors.WithStack(tx.DropTableIfExists(&savedView{}).Error)
+}
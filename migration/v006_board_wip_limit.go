@@ -0,0 +1,17 @@
+package migration
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// dropBoardWIPLimit rebuilds boards of version 5, because SQLite can not drop columns
+func dropBoardWIPLimit(tx *gorm.DB) error {
+	err := rebuildTable(tx, "boards", &rankedBoard{}, boardColumns, "rank", "rank", "idx_boards_rank")
+	if err != nil {
+		return err
+	}
+	return SQL(
+		"drop table boards_old",
+		"create unique index idx_boards_rank on boards(rank)",
+	)(tx)
+}
@@ -0,0 +1,108 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Snapshots of models when projects were introduced.
+// Do not change these structs, add new migration to change tables.
+
+type project struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	Name        string    `gorm:"unique;not null;size:255"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"`
+}
+
+func (project) TableName() string { return "projects" }
+
+type projectMember struct {
+	ProjectID string `gorm:"primary_key;size:32"`
+	UserID    string `gorm:"primary_key;size:32;index"`
+}
+
+func (projectMember) TableName() string { return "project_members" }
+
+type projectBoard struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	ProjectID   string    `gorm:"not null;size:32;unique_index:idx_boards_project_id_name"`
+	Name        string    `gorm:"size:255;unique_index:idx_boards_project_id_name"`
+	Rank        string    `gorm:"not null;size:255"`
+	IsSystem    bool      `gorm:"not null"`
+	SystemKind  string    `gorm:"not null;size:16"`
+	IsClosed    bool      `gorm:"not null"`
+	WIPLimit    int       `gorm:"column:wip_limit;not null"`
+	WIPMode     string    `gorm:"column:wip_mode;not null;size:16"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"`
+}
+
+func (projectBoard) TableName() string { return "boards" }
+
+// wipLimitedBoard is a board of version 6, whose name is unique in all boards
+type wipLimitedBoard struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	Name        string    `gorm:"unique;size:255"`
+	Rank        string    `gorm:"not null;size:255"`
+	IsSystem    bool      `gorm:"not null"`
+	IsClosed    bool      `gorm:"not null"`
+	WIPLimit    int       `gorm:"column:wip_limit;not null"`
+	WIPMode     string    `gorm:"column:wip_mode;not null;size:16"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"`
+}
+
+func (wipLimitedBoard) TableName() string { return "boards" }
+
+const wipLimitedBoardColumns = "id, name, rank, is_system, is_closed, wip_limit, wip_mode, created_date, version"
+
+// createProjects moves all boards to default project whose members are all users,
+// and makes board names unique in project instead of all boards
+func createProjects(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&project{}, &projectMember{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	err := rebuildTable(tx, "boards", &projectBoard{}, wipLimitedBoardColumns,
+		"project_id, system_kind", "'project_default', ''", "idx_boards_rank")
+	if err != nil {
+		return err
+	}
+	return SQL(
+		"drop table boards_old",
+		"create unique index idx_boards_rank on boards(rank)",
+		"insert into projects (id, name, created_date, version) values ('project_default', 'Default', datetime('now'), 1)",
+		"insert into project_members (project_id, user_id) select 'project_default', id from users",
+		"update boards set system_kind = 'icebox' where id = 'board_icebox'",
+		"update boards set system_kind = 'todo' where id = 'board_todo'",
+		"update boards set system_kind = 'doing' where id = 'board_doing'",
+		"update boards set system_kind = 'done' where id = 'board_done'",
+	)(tx)
+}
+
+// dropProjects moves boards of all projects back into one list.
+// Boards of other projects than default become normal boards qualified by project name, to keep names unique.
+func dropProjects(tx *gorm.DB) error {
+	err := SQL(
+		"update boards set is_system = 0, name = name || ' (' || " +
+			"(select name from projects where projects.id = boards.project_id) || ')' " +
+			"where project_id <> 'project_default'",
+	)(tx)
+	if err != nil {
+		return err
+	}
+	err = rebuildTable(tx, "boards", &wipLimitedBoard{}, wipLimitedBoardColumns, "", "", "idx_boards_rank")
+	if err != nil {
+		return err
+	}
+	err = SQL(
+		"drop table boards_old",
+		"create unique index idx_boards_rank on boards(rank)",
+	)(tx)
+	if err != nil {
+		return err
+	}
+	return errors.WithStack(tx.DropTableIfExists(&projectMember{}, &project{}).Error)
+}
@@ -0,0 +1,38 @@
+package migration
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Snapshot of workflow state when it was introduced.
+// Do not change this struct, add new migration to change the table.
+
+type workflowState struct {
+	ID       string `gorm:"primary_key;size:16"`
+	Name     string `gorm:"unique;not null;size:255"`
+	Position int    `gorm:"not null"`
+	IsInbox  bool   `gorm:"not null"`
+	IsDone   bool   `gorm:"not null"`
+	Version  int    `gorm:"not null"`
+}
+
+func (workflowState) TableName() string { return "workflow_states" }
+
+// createWorkflowStates creates workflow which has the same columns as system boards before,
+// boards of done state are closed to follow the workflow
+func createWorkflowStates(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&workflowState{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return SQL(
+		"insert into workflow_states (id, name, position, is_inbox, is_done, version) values "+
+			"('icebox', 'Icebox', 0, 1, 0, 1), ('todo', 'Todo', 1, 0, 0, 1), "+
+			"('doing', 'Doing', 2, 0, 0, 1), ('done', 'Done', 3, 0, 1, 1)",
+		"update boards set is_closed = 1 where is_system = 1 and system_kind = 'done'",
+	)(tx)
+}
+
+func dropWorkflowStates(tx *gorm.DB) error {
+	return errors.WithStack(tx.DropTableIfExists(&workflowState{}).Error)
+}
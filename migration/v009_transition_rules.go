@@ -0,0 +1,31 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Snapshot of transition rule when it was introduced.
+// Do not change this struct, add new migration to change the table.
+
+type transitionRule struct {
+	ID             string    `gorm:"primary_key;size:32"`
+	FromBoardID    string    `gorm:"not null;size:32;unique_index:idx_transition_rules_boards"`
+	ToBoardID      string    `gorm:"not null;size:32;unique_index:idx_transition_rules_boards"`
+	RequiredRole   string    `gorm:"not null;size:16"`
+	RequiredFields string    `gorm:"not null;size:255"`
+	CreatedDate    time.Time `gorm:"not null"`
+	Version        int       `gorm:"not null"`
+}
+
+func (transitionRule) TableName() string { return "transition_rules" }
+
+func createTransitionRules(tx *gorm.DB) error {
+	return errors.WithStack(tx.AutoMigrate(&transitionRule{}).Error)
+}
+
+func dropTransitionRules(tx *gorm.DB) error {
+	return errors.WithStack(tx.DropTableIfExists(&transitionRule{}).Error)
+}
@@ -0,0 +1,45 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Snapshot of automation rule and its execution when they were introduced.
+// Do not change these structs, add new migration to change the tables.
+
+type automationRule struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	Name        string    `gorm:"unique;not null;size:255"`
+	Trigger     string    `gorm:"not null;size:16;index"`
+	Condition   string    `gorm:"not null;size:2000"`
+	Actions     string    `gorm:"not null;size:8000"`
+	IsActive    bool      `gorm:"not null"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"`
+}
+
+func (automationRule) TableName() string { return "automation_rules" }
+
+type automationExecution struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	RuleID      string    `gorm:"not null;size:32;index"`
+	TaskID      string    `gorm:"not null;size:32;index"`
+	Trigger     string    `gorm:"not null;size:16"`
+	Status      string    `gorm:"not null;size:16"`
+	Message     string    `gorm:"size:1000"`
+	Depth       int       `gorm:"not null"`
+	CreatedDate time.Time `gorm:"not null"`
+}
+
+func (automationExecution) TableName() string { return "automation_executions" }
+
+func createAutomationRules(tx *gorm.DB) error {
+	return errors.WithStack(tx.AutoMigrate(&automationRule{}, &automationExecution{}).Error)
+}
+
+func dropAutomationRules(tx *gorm.DB) error {
+	return errors.WithStack(tx.DropTableIfExists(&automationExecution{}, &automationRule{}).Error)
+}
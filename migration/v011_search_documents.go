@@ -0,0 +1,41 @@
+package migration
+
+import (
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+// keySearchIndexByRowid recreates search index so that tasks and comments are mapped to its rowids by search_documents.
+// Nothing is changed if sqlite does not support FTS5, the index can be created by rebuild-index command later.
+func keySearchIndexByRowid(tx *gorm.DB) error {
+	if !orm.IsFTS5Supported(tx) {
+		return nil
+	}
+	return SQL(
+		"drop table if exists search_index",
+		"create table search_documents"+
+			" (doc_id integer primary key, kind varchar(16) not null, id varchar(32) not null, task_id varchar(32) not null)",
+		"create unique index idx_search_documents_kind_id on search_documents (kind, id)",
+		"create index idx_search_documents_task_id on search_documents (task_id)",
+		"insert into search_documents (kind, id, task_id) select 'task', id, id from tasks",
+		"insert into search_documents (kind, id, task_id) select 'comment', id, task_id from comments",
+		"create virtual table search_index using fts5(name, body, tokenize = 'unicode61')",
+		"insert into search_index (rowid, name, body)"+
+			" select d.doc_id, t.name, t.description from search_documents d join tasks t on t.id = d.id where d.kind = 'task'",
+		"insert into search_index (rowid, name, body)"+
+			" select d.doc_id, '', c.body from search_documents d join comments c on c.id = d.id where d.kind = 'comment'",
+	)(tx)
+}
+
+// keySearchIndexByColumns restores search index of version 4 which has kind, id and task_id columns
+func keySearchIndexByColumns(tx *gorm.DB) error {
+	err := SQL(
+		"drop table if exists search_index",
+		"drop table if exists search_documents",
+	)(tx)
+	if err != nil {
+		return err
+	}
+	return createSearchIndex(tx)
+}
@@ -0,0 +1,149 @@
+package model
+
+import (
+	"encoding/json"
+	"taskboard/common"
+	"time"
+)
+
+// Triggers of automation rule, the task which caused the trigger is the target of actions
+const (
+	AutomationTriggerCreated   = "created"   // Task is created
+	AutomationTriggerMoved     = "moved"     // Task is moved to another board
+	AutomationTriggerAssigned  = "assigned"  // Task is assigned to a user
+	AutomationTriggerClosed    = "closed"    // Task is closed
+	AutomationTriggerDuePassed = "duePassed" // Due date of open task has passed
+)
+
+// AutomationTriggers is all triggers of automation rule
+var AutomationTriggers = []string{
+	AutomationTriggerCreated,
+	AutomationTriggerMoved,
+	AutomationTriggerAssigned,
+	AutomationTriggerClosed,
+	AutomationTriggerDuePassed,
+}
+
+// Types of automation action
+const (
+	AutomationActionMoveBoard   = "moveBoard"   // Value is ID of board
+	AutomationActionSetAssignee = "setAssignee" // Value is ID of user, empty to unassign
+	AutomationActionSetClosed   = "setClosed"   // Value is true or false
+	AutomationActionAddComment  = "addComment"  // Value is body of comment
+	AutomationActionFireWebhook = "fireWebhook" // Value is ID of webhook
+)
+
+// AutomationActionTypes is all types of automation action
+var AutomationActionTypes = []string{
+	AutomationActionMoveBoard,
+	AutomationActionSetAssignee,
+	AutomationActionSetClosed,
+	AutomationActionAddComment,
+	AutomationActionFireWebhook,
+}
+
+// Status of automation execution
+const (
+	ExecutionStatusSucceeded = "succeeded"
+	ExecutionStatusFailed    = "failed"  // Action was rejected, e.g. by transition rule or WIP limit
+	ExecutionStatusSkipped   = "skipped" // Rule was not run to stop loop
+)
+
+// AutomationRule presents actions which are run for a task when trigger occurs and the task matches condition
+type AutomationRule struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	Name        string    `gorm:"unique;not null;size:255"`
+	Trigger     string    `gorm:"not null;size:16;index"`
+	Condition   string    `gorm:"not null;size:2000"` // Task query language, empty matches all tasks
+	Actions     string    `gorm:"not null;size:8000"` // JSON encoded actions run in order
+	IsActive    bool      `gorm:"not null"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"` // Version for optimistic lock
+}
+
+// AutomationAction presents an action of automation rule
+type AutomationAction struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// AutomationExecution presents a run of automation rule for a task, and the result of it
+type AutomationExecution struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	RuleID      string    `gorm:"not null;size:32;index"`
+	TaskID      string    `gorm:"not null;size:32;index"`
+	Trigger     string    `gorm:"not null;size:16"`
+	Status      string    `gorm:"not null;size:16"`
+	Message     string    `gorm:"size:1000"` // Reason of failure or skip
+	Depth       int       `gorm:"not null"`  // 0 if triggered by user or worker, or depth of rules which triggered it
+	CreatedDate time.Time `gorm:"not null"`
+}
+
+// IsValidAutomationTrigger checks whether trigger is one of AutomationTriggers
+func IsValidAutomationTrigger(trigger string) bool {
+	return containsString(AutomationTriggers, trigger)
+}
+
+// IsValidAutomationActionType checks whether actionType is one of AutomationActionTypes
+func IsValidAutomationActionType(actionType string) bool {
+	return containsString(AutomationActionTypes, actionType)
+}
+
+// NewAutomationRule returns created new automation rule
+func NewAutomationRule(name, trigger, condition string, actions []AutomationAction, isActive bool, now time.Time) *AutomationRule {
+	rule := &AutomationRule{
+		ID:          "automation_" + common.GenerateID(),
+		Name:        name,
+		Trigger:     trigger,
+		Condition:   condition,
+		IsActive:    isActive,
+		CreatedDate: now,
+		Version:     1,
+	}
+	rule.SetActions(actions)
+	return rule
+}
+
+// GetActions returns actions of rule, invalid JSON is treated as no actions
+func (r *AutomationRule) GetActions() []AutomationAction {
+	actions := []AutomationAction{}
+	if r.Actions == "" {
+		return actions
+	}
+	if err := json.Unmarshal([]byte(r.Actions), &actions); err != nil {
+		return []AutomationAction{}
+	}
+	return actions
+}
+
+// SetActions updates actions of rule
+func (r *AutomationRule) SetActions(actions []AutomationAction) {
+	if actions == nil {
+		actions = []AutomationAction{}
+	}
+	encoded, _ := json.Marshal(actions)
+	r.Actions = string(encoded)
+}
+
+// NewAutomationExecution returns created new automation execution
+func NewAutomationExecution(ruleID, taskID, trigger, status, message string, depth int, now time.Time) *AutomationExecution {
+	return &AutomationExecution{
+		ID:          "execution_" + common.GenerateID(),
+		RuleID:      ruleID,
+		TaskID:      taskID,
+		Trigger:     trigger,
+		Status:      status,
+		Message:     message,
+		Depth:       depth,
+		CreatedDate: now,
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
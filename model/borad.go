@@ -8,63 +8,41 @@ import (
 // Board presents a board which has plural tasks
 type Board struct {
 	ID          string    `gorm:"primary_key;size:32"`
-	Name        string    `gorm:"unique;size:255"`
-	DispOrder   int       `gorm:"not null"`
+	ProjectID   string    `gorm:"not null;size:32;unique_index:idx_boards_project_id_name"`
+	Name        string    `gorm:"size:255;unique_index:idx_boards_project_id_name"` // Unique in project
+	Rank        string    `gorm:"not null;size:255"`                                // Key to order boards, unique
 	IsSystem    bool      `gorm:"not null"`
-	IsClosed    bool      `gorm:"not null"`
+	SystemKind  string    `gorm:"not null;size:16"`                 // ID of workflow state of system board, empty for normal board
+	IsClosed    bool      `gorm:"not null"`                         // Tasks moved to closed board are closed
+	WIPLimit    int       `gorm:"column:wip_limit;not null"`        // Max number of open tasks, no limit if 0
+	WIPMode     string    `gorm:"column:wip_mode;not null;size:16"` // How to enforce WIP limit, WIPModeWarn or WIPModeBlock
 	CreatedDate time.Time `gorm:"not null"`
 	Version     int       `gorm:"not null"` // Version for optimistic lock
 }
 
-// SystemBoardIcebox is a system board
-var SystemBoardIcebox = &Board{
-	ID:          "board_icebox",
-	Name:        "Icebox",
-	DispOrder:   0,
-	IsSystem:    true,
-	CreatedDate: time.Now().UTC(),
-	Version:     1,
-}
-
-// SystemBoardTodo is a system board id
-var SystemBoardTodo = &Board{
-	ID:          "board_todo",
-	Name:        "Todo",
-	DispOrder:   1,
-	IsSystem:    true,
-	CreatedDate: time.Now().UTC(),
-	Version:     1,
-}
-
-// SystemBoardDoing is a system board id
-var SystemBoardDoing = &Board{
-	ID:          "board_doing",
-	Name:        "Doing",
-	DispOrder:   2,
-	IsSystem:    true,
-	CreatedDate: time.Now().UTC(),
-	Version:     1,
-}
-
-// SystemBoardDone is a system board id
-var SystemBoardDone = &Board{
-	ID:          "board_done",
-	Name:        "Done",
-	DispOrder:   3,
-	IsSystem:    true,
-	CreatedDate: time.Now().UTC(),
-	Version:     1,
-}
+// Modes to enforce WIP limit of board
+const (
+	WIPModeWarn  = "warn"  // Task can enter the full board with warning
+	WIPModeBlock = "block" // Task can not enter the full board
+)
 
 // NewBoard returns created new board
 func NewBoard(name string, isSystem, isClosed bool, now time.Time) *Board {
 	return &Board{
 		ID:          "board_" + common.GenerateID(),
 		Name:        name,
-		DispOrder:   0,
 		IsSystem:    isSystem,
 		IsClosed:    isClosed,
 		CreatedDate: now,
 		Version:     1,
 	}
 }
+
+// NewSystemBoard returns created new system board of the workflow state in project
+func NewSystemBoard(projectID string, state *WorkflowState, now time.Time) *Board {
+	board := NewBoard(state.Name, true, state.IsDone, now)
+	board.ID = state.SystemBoardID(projectID)
+	board.ProjectID = projectID
+	board.SystemKind = state.ID
+	return board
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"taskboard/common"
+	"time"
+)
+
+// Comment presents a comment written on a task by a user
+type Comment struct {
+	ID           string     `gorm:"primary_key;size:32"`
+	TaskID       string     `gorm:"not null;size:32;index"`
+	AuthorUserID string     `gorm:"not null;size:32"`
+	Body         string     `gorm:"not null;size:8000"`
+	CreatedDate  time.Time  `gorm:"not null"`
+	EditedDate   *time.Time // Null if not edited
+	Version      int        `gorm:"not null"` // Version for optimistic lock
+}
+
+// NewComment returns created new comment
+func NewComment(taskID, authorUserID, body string, now time.Time) *Comment {
+	return &Comment{
+		ID:           "comment_" + common.GenerateID(),
+		TaskID:       taskID,
+		AuthorUserID: authorUserID,
+		Body:         body,
+		CreatedDate:  now,
+		EditedDate:   nil,
+		Version:      1,
+	}
+}
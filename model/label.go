@@ -0,0 +1,32 @@
+package model
+
+import (
+	"taskboard/common"
+	"time"
+)
+
+// Label presents a label to categorize tasks across boards
+type Label struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	Name        string    `gorm:"unique;not null;size:255"`
+	Color       string    `gorm:"not null;size:7"` // Format is #rrggbb
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"` // Version for optimistic lock
+}
+
+// TaskLabel presents a link between a task and a label
+type TaskLabel struct {
+	TaskID  string `gorm:"primary_key;size:32"`
+	LabelID string `gorm:"primary_key;size:32;index"`
+}
+
+// NewLabel returns created new label
+func NewLabel(name, color string, now time.Time) *Label {
+	return &Label{
+		ID:          "label_" + common.GenerateID(),
+		Name:        name,
+		Color:       color,
+		CreatedDate: now,
+		Version:     1,
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"taskboard/common"
+	"time"
+)
+
+// DefaultProjectID is ID of the project which has boards created before projects were introduced
+const DefaultProjectID = "project_default"
+
+// Project presents a workspace which has its own boards and members
+type Project struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	Name        string    `gorm:"unique;not null;size:255"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"` // Version for optimistic lock
+}
+
+// ProjectMember presents a user who can see a project
+type ProjectMember struct {
+	ProjectID string `gorm:"primary_key;size:32"`
+	UserID    string `gorm:"primary_key;size:32;index"`
+}
+
+// NewProject returns created new project
+func NewProject(name string, now time.Time) *Project {
+	return &Project{
+		ID:          "project_" + common.GenerateID(),
+		Name:        name,
+		CreatedDate: now,
+		Version:     1,
+	}
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"taskboard/common"
+	"time"
+)
+
+// SavedView presents a filter of tasks saved by a user to find them again
+type SavedView struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	OwnerUserID string    `gorm:"not null;size:32;unique_index:idx_saved_views_owner_name"`
+	Name        string    `gorm:"not null;size:255;unique_index:idx_saved_views_owner_name"` // Unique for owner
+	Query       string    `gorm:"not null;size:2000"`                                        // Task query language, all tasks if empty
+	Sort        string    `gorm:"not null;size:255"`                                         // Comma separated sort keys, default order if empty
+	BoardID     string    `gorm:"not null;size:32"`                                          // Board of tasks, all boards if empty
+	IsShared    bool      `gorm:"not null"`                                                  // Shared view can be found by all users
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"` // Version for optimistic lock
+}
+
+// NewSavedView returns created new saved view
+func NewSavedView(ownerUserID, name, query, sort, boardID string, isShared bool, now time.Time) *SavedView {
+	return &SavedView{
+		ID:          "view_" + common.GenerateID(),
+		OwnerUserID: ownerUserID,
+		Name:        name,
+		Query:       query,
+		Sort:        sort,
+		BoardID:     boardID,
+		IsShared:    isShared,
+		CreatedDate: now,
+		Version:     1,
+	}
+}
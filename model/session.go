@@ -0,0 +1,50 @@
+package model
+
+import (
+	"taskboard/common"
+	"time"
+)
+
+// SessionExpiration is lifetime of access token issued by login or refresh
+const SessionExpiration = 1 * time.Hour
+
+// SessionRefreshExpiration is lifetime of refresh token issued by login
+const SessionRefreshExpiration = 30 * 24 * time.Hour
+
+// Session presents a login session of user.
+type Session struct {
+	ID                 string    `gorm:"primary_key;size:32"`
+	UserID             string    `gorm:"not null;size:32;index"`
+	ExpiredDate        time.Time `gorm:"not null"` // Expiration of latest access token
+	RefreshExpiredDate time.Time `gorm:"not null"` // Expiration of refresh token
+	IsRevoked          bool      `gorm:"not null"`
+	CreatedDate        time.Time `gorm:"not null"`
+	Version            int       `gorm:"not null"` // Version for optimistic lock
+}
+
+// NewSession returns created new session
+func NewSession(userID string, now time.Time) *Session {
+	return &Session{
+		ID:                 "session_" + common.GenerateID(),
+		UserID:             userID,
+		ExpiredDate:        now.Add(SessionExpiration),
+		RefreshExpiredDate: now.Add(SessionRefreshExpiration),
+		IsRevoked:          false,
+		CreatedDate:        now,
+		Version:            1,
+	}
+}
+
+// IsActive checks whether session can be used at specified time
+func (s *Session) IsActive(now time.Time) bool {
+	return !s.IsRevoked && now.Before(s.RefreshExpiredDate)
+}
+
+// Extend extends expiration of access token from specified time
+func (s *Session) Extend(now time.Time) {
+	s.ExpiredDate = now.Add(SessionExpiration)
+	if s.ExpiredDate.After(s.RefreshExpiredDate) {
+		// Access token never outlives refresh token
+		s.ExpiredDate = s.RefreshExpiredDate
+	}
+}
@@ -12,12 +12,14 @@ type Task struct {
 	Name           string         `gorm:"not null;size:255"`
 	Description    string         `gorm:"size:8000"`
 	AssigneeUserID sql.NullString `gorm:"size:32"`           // Null or String
-	BoardID        string         `gorm:"not null; size:32"` // Default is IceboxBoardID
-	DispOrder      int            `gorm:"not null"`
+	BoardID        string         `gorm:"not null;size:32"`  // Default is inbox board of default project
+	Rank           string         `gorm:"not null;size:255"` // Key to order tasks, unique in board
 	CreatedDate    time.Time      `gorm:"not null"`
 	IsClosed       bool           `gorm:"not null"`
 	Version        int            `gorm:"not null"` // Version for optimistic lock
-	EsitmateSize   int
+	EstimateSize   int
+	StartDate      *time.Time // Null if not planned
+	DueDate        *time.Time `gorm:"index"` // Null if not planned
 }
 
 // NewTask returns created new task
@@ -27,9 +29,7 @@ func NewTask(name, description string, isClosed bool, now time.Time) *Task {
 		Name:           name,
 		Description:    description,
 		IsClosed:       isClosed,
-		BoardID:        SystemBoardIcebox.ID,
 		AssigneeUserID: sql.NullString{Valid: false},
-		DispOrder:      0,
 		CreatedDate:    now,
 		Version:        1,
 	}
@@ -50,3 +50,8 @@ func (t *Task) SetBoardID(boardID string) {
 		t.BoardID = boardID
 	}
 }
+
+// IsOverdue checks whether task is not closed after due date
+func (t *Task) IsOverdue(now time.Time) bool {
+	return !t.IsClosed && t.DueDate != nil && t.DueDate.Before(now)
+}
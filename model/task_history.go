@@ -0,0 +1,126 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"taskboard/common"
+	"time"
+)
+
+// Fields of task which are recorded in history
+const (
+	TaskFieldName           = "name"
+	TaskFieldDescription    = "description"
+	TaskFieldAssigneeUserID = "assigneeUserID"
+	TaskFieldBoardID        = "boardID"
+	TaskFieldIsClosed       = "isClosed"
+	TaskFieldEstimateSize   = "estimateSize"
+	TaskFieldStartDate      = "startDate"
+	TaskFieldDueDate        = "dueDate"
+)
+
+// TaskHistoryFields is the fields of task recorded in history, in the order of recording
+var TaskHistoryFields = []string{
+	TaskFieldName,
+	TaskFieldDescription,
+	TaskFieldAssigneeUserID,
+	TaskFieldBoardID,
+	TaskFieldIsClosed,
+	TaskFieldEstimateSize,
+	TaskFieldStartDate,
+	TaskFieldDueDate,
+}
+
+// TaskHistory presents a change of a field of task. It is never updated nor deleted.
+type TaskHistory struct {
+	ID          string         `gorm:"primary_key;size:32"`
+	TaskID      string         `gorm:"not null;size:32;index"`
+	Revision    int            `gorm:"not null"` // Version of task after the change
+	ActorUserID sql.NullString `gorm:"size:32"`  // Null if changed by system
+	Field       string         `gorm:"not null;size:32"`
+	OldValue    string         `gorm:"size:8000"`
+	NewValue    string         `gorm:"size:8000"`
+	CreatedDate time.Time      `gorm:"not null"`
+}
+
+// NewTaskHistory returns created new task history
+func NewTaskHistory(taskID string, revision int, actorUserID, field, oldValue, newValue string, now time.Time) *TaskHistory {
+	return &TaskHistory{
+		ID:          "taskhistory_" + common.GenerateID(),
+		TaskID:      taskID,
+		Revision:    revision,
+		ActorUserID: sql.NullString{String: actorUserID, Valid: actorUserID != ""},
+		Field:       field,
+		OldValue:    oldValue,
+		NewValue:    newValue,
+		CreatedDate: now,
+	}
+}
+
+// FieldValue returns value of specified field as string
+func (t *Task) FieldValue(field string) string {
+	switch field {
+	case TaskFieldName:
+		return t.Name
+	case TaskFieldDescription:
+		return t.Description
+	case TaskFieldAssigneeUserID:
+		return t.AssigneeUserID.String
+	case TaskFieldBoardID:
+		return t.BoardID
+	case TaskFieldIsClosed:
+		return strconv.FormatBool(t.IsClosed)
+	case TaskFieldEstimateSize:
+		return strconv.Itoa(t.EstimateSize)
+	case TaskFieldStartDate:
+		return formatNullableDate(t.StartDate)
+	case TaskFieldDueDate:
+		return formatNullableDate(t.DueDate)
+	}
+	return ""
+}
+
+// SetFieldValue updates specified field by string value returned by FieldValue
+func (t *Task) SetFieldValue(field, value string) (err error) {
+	switch field {
+	case TaskFieldName:
+		t.Name = value
+	case TaskFieldDescription:
+		t.Description = value
+	case TaskFieldAssigneeUserID:
+		t.AssigneeUserID = sql.NullString{String: value, Valid: value != ""}
+	case TaskFieldBoardID:
+		t.BoardID = value
+	case TaskFieldIsClosed:
+		t.IsClosed, err = strconv.ParseBool(value)
+	case TaskFieldEstimateSize:
+		t.EstimateSize, err = strconv.Atoi(value)
+	case TaskFieldStartDate:
+		t.StartDate, err = parseNullableDate(value)
+	case TaskFieldDueDate:
+		t.DueDate, err = parseNullableDate(value)
+	default:
+		err = fmt.Errorf("unknown field of task: %s", field)
+	}
+	return
+}
+
+func formatNullableDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.UTC().Format(time.RFC3339)
+}
+
+func parseNullableDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	date = date.UTC()
+	return &date, nil
+}
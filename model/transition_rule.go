@@ -0,0 +1,90 @@
+package model
+
+import (
+	"strings"
+	"taskboard/common"
+	"time"
+)
+
+// Definition of fields which transition rule can require
+const (
+	RequiredFieldAssignee    = "assignee"
+	RequiredFieldEstimate    = "estimate"
+	RequiredFieldStartDate   = "startDate"
+	RequiredFieldDueDate     = "dueDate"
+	RequiredFieldDescription = "description"
+)
+
+// TransitionRule presents that tasks can be moved from a board to another board.
+// Once a board has rules, tasks on it can be moved only to boards which its rules allow.
+type TransitionRule struct {
+	ID             string    `gorm:"primary_key;size:32"`
+	FromBoardID    string    `gorm:"not null;size:32;unique_index:idx_transition_rules_boards"`
+	ToBoardID      string    `gorm:"not null;size:32;unique_index:idx_transition_rules_boards"`
+	RequiredRole   string    `gorm:"not null;size:16"`  // Role of user who can move tasks, empty if any editor can
+	RequiredFields string    `gorm:"not null;size:255"` // Comma separated fields which moved tasks must have
+	CreatedDate    time.Time `gorm:"not null"`
+	Version        int       `gorm:"not null"` // Version for optimistic lock
+}
+
+// IsValidRequiredField returns whether transition rule can require the field
+func IsValidRequiredField(field string) bool {
+	switch field {
+	case RequiredFieldAssignee, RequiredFieldEstimate, RequiredFieldStartDate, RequiredFieldDueDate, RequiredFieldDescription:
+		return true
+	}
+	return false
+}
+
+// NewTransitionRule returns created new transition rule
+func NewTransitionRule(fromBoardID, toBoardID, requiredRole string, requiredFields []string, now time.Time) *TransitionRule {
+	rule := &TransitionRule{
+		ID:           "transition_" + common.GenerateID(),
+		FromBoardID:  fromBoardID,
+		ToBoardID:    toBoardID,
+		RequiredRole: requiredRole,
+		CreatedDate:  now,
+		Version:      1,
+	}
+	rule.SetRequiredFields(requiredFields)
+	return rule
+}
+
+// GetRequiredFields returns fields which moved tasks must have
+func (r *TransitionRule) GetRequiredFields() []string {
+	if r.RequiredFields == "" {
+		return []string{}
+	}
+	return strings.Split(r.RequiredFields, ",")
+}
+
+// SetRequiredFields updates fields which moved tasks must have
+func (r *TransitionRule) SetRequiredFields(fields []string) {
+	r.RequiredFields = strings.Join(fields, ",")
+}
+
+// MissingFields returns required fields which the task does not have
+func (r *TransitionRule) MissingFields(task *Task) []string {
+	missing := []string{}
+	for _, field := range r.GetRequiredFields() {
+		var has bool
+		switch field {
+		case RequiredFieldAssignee:
+			has = task.AssigneeUserID.Valid && task.AssigneeUserID.String != ""
+		case RequiredFieldEstimate:
+			has = task.EstimateSize > 0
+		case RequiredFieldStartDate:
+			has = task.StartDate != nil
+		case RequiredFieldDueDate:
+			has = task.DueDate != nil
+		case RequiredFieldDescription:
+			has = strings.TrimSpace(task.Description) != ""
+		default:
+			has = true // Unknown field is not checked
+		}
+		if !has {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
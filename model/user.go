@@ -6,12 +6,20 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Roles of user
+const (
+	RoleAdmin  = "admin"  // Can manage users and system boards in addition to member
+	RoleMember = "member" // Can create, update and delete boards and tasks
+	RoleViewer = "viewer" // Can only read boards and tasks
+)
+
 // User is user of the app.
 type User struct {
 	ID           string `gorm:"primary_key;size:32"`
 	Name         string `gorm:"size:255;not null;unique"`
 	PasswordHash string `gorm:"size:255;not null;"`
 	Avator       string `gorm:"size:255"`
+	Role         string `gorm:"size:16;not null;default:'member'"`
 	Version      int    `gorm:"not null"` // Version for optimistic lock
 }
 
@@ -21,12 +29,28 @@ func NewUser(name, rawpassword, avator string) *User {
 		ID:      "user_" + common.GenerateID(),
 		Name:    name,
 		Avator:  avator,
+		Role:    RoleMember,
 		Version: 1,
 	}
 	result.SetPassword(rawpassword)
 	return result
 }
 
+// IsValidRole checks whether specified role is defined
+func IsValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleMember || role == RoleViewer
+}
+
+// HasRole checks whether user has one of specified roles
+func (user *User) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if user.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
 // SetPassword sets the hash of specified password to user
 func (user *User) SetPassword(password string) {
 	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"strings"
+	"taskboard/common"
+	"time"
+)
+
+// Status of webhook delivery
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// Webhook presents a registration of url which receives events
+type Webhook struct {
+	ID          string    `gorm:"primary_key;size:32"`
+	URL         string    `gorm:"not null;size:2000"`
+	Secret      string    `gorm:"not null;size:255"`  // Key to sign payloads
+	EventTypes  string    `gorm:"not null;size:1000"` // Comma separated event types
+	IsActive    bool      `gorm:"not null"`
+	CreatedDate time.Time `gorm:"not null"`
+	Version     int       `gorm:"not null"` // Version for optimistic lock
+}
+
+// WebhookDelivery presents a delivery of an event to a webhook, and the result of it
+type WebhookDelivery struct {
+	ID              string     `gorm:"primary_key;size:32"`
+	WebhookID       string     `gorm:"not null;size:32;index"`
+	EventID         string     `gorm:"not null;size:32"`
+	EventType       string     `gorm:"not null;size:32"`
+	Payload         string     `gorm:"not null"`
+	Status          string     `gorm:"not null;size:16;index"`
+	Attempts        int        `gorm:"not null"`
+	NextAttemptDate time.Time  `gorm:"not null"`
+	LastStatusCode  int        `gorm:"not null"` // 0 if no response
+	LastError       string     `gorm:"size:1000"`
+	CreatedDate     time.Time  `gorm:"not null"`
+	DeliveredDate   *time.Time // Null if not delivered
+	Version         int        `gorm:"not null"` // Version for optimistic lock
+}
+
+// NewWebhook returns created new webhook
+func NewWebhook(url, secret string, eventTypes []string, isActive bool, now time.Time) *Webhook {
+	webhook := &Webhook{
+		ID:          "webhook_" + common.GenerateID(),
+		URL:         url,
+		Secret:      secret,
+		IsActive:    isActive,
+		CreatedDate: now,
+		Version:     1,
+	}
+	webhook.SetEventTypes(eventTypes)
+	return webhook
+}
+
+// GetEventTypes returns event types which webhook receives
+func (w *Webhook) GetEventTypes() []string {
+	if w.EventTypes == "" {
+		return []string{}
+	}
+	return strings.Split(w.EventTypes, ",")
+}
+
+// SetEventTypes updates event types which webhook receives
+func (w *Webhook) SetEventTypes(eventTypes []string) {
+	w.EventTypes = strings.Join(eventTypes, ",")
+}
+
+// Accepts checks whether webhook receives specified type of event
+func (w *Webhook) Accepts(eventType string) bool {
+	if !w.IsActive {
+		return false
+	}
+	for _, t := range w.GetEventTypes() {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// NewWebhookDelivery returns created new delivery which will be sent as soon as possible
+func NewWebhookDelivery(webhookID, eventID, eventType, payload string, now time.Time) *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:              "delivery_" + common.GenerateID(),
+		WebhookID:       webhookID,
+		EventID:         eventID,
+		EventType:       eventType,
+		Payload:         payload,
+		Status:          DeliveryStatusPending,
+		Attempts:        0,
+		NextAttemptDate: now,
+		CreatedDate:     now,
+		Version:         1,
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"regexp"
+	"taskboard/common"
+)
+
+// WorkflowState presents a column of workflow, every project has a system board of each state
+type WorkflowState struct {
+	ID       string `gorm:"primary_key;size:16"`      // Key of state, kept in SystemKind of system boards
+	Name     string `gorm:"unique;not null;size:255"` // Name of system boards
+	Position int    `gorm:"not null"`                 // Order of states in workflow
+	IsInbox  bool   `gorm:"not null"`                 // Tasks are placed here if board is not specified or deleted, only one state is inbox
+	IsDone   bool   `gorm:"not null"`                 // System boards of this state are closed, tasks moved to them are closed
+	Version  int    `gorm:"not null"`                 // Version for optimistic lock
+}
+
+// workflowStateIDPattern is pattern of ID of workflow state
+var workflowStateIDPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,15}$`)
+
+// IsValidWorkflowStateID returns whether ID of workflow state consists of lower case letters, digits, _ and -
+// and starts with a letter
+func IsValidWorkflowStateID(id string) bool {
+	return workflowStateIDPattern.MatchString(id)
+}
+
+// NewWorkflowState returns created new workflow state
+func NewWorkflowState(id, name string, isInbox, isDone bool) *WorkflowState {
+	return &WorkflowState{
+		ID:      id,
+		Name:    name,
+		IsInbox: isInbox,
+		IsDone:  isDone,
+		Version: 1,
+	}
+}
+
+// SystemBoardID returns ID of the system board of this state in specified project.
+// Boards of default project have fixed IDs, board_icebox for icebox state for example.
+func (s *WorkflowState) SystemBoardID(projectID string) string {
+	if projectID == DefaultProjectID {
+		return "board_" + s.ID
+	}
+	return "board_" + common.GenerateID()
+}
@@ -0,0 +1,181 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Keyset is the position of a record in a list sorted by sort orders, which are column names followed by optional asc or desc.
+// Records after the keyset are found by comparing columns of sort orders with values of the record,
+// so that records created or deleted between pages neither shift nor skip records of later pages.
+// The last sort order must be unique like id to keep records of the same values in fixed order.
+type Keyset struct {
+	SortOrders []string      `json:"sortOrders"`
+	Values     []interface{} `json:"values"` // Values of columns as sqlite stores them
+}
+
+// keysetColumn is a column of sort orders
+type keysetColumn struct {
+	name string
+	desc bool
+}
+
+// NewKeyset returns keyset of record in list sorted by sortOrders, record is a struct whose fields are the columns
+func NewKeyset(record interface{}, sortOrders []string) (*Keyset, error) {
+	columns, err := parseKeysetColumns(sortOrders)
+	if err != nil {
+		return nil, err
+	}
+	scope := GetDB().NewScope(record)
+	values := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		field, ok := scope.FieldByName(column.name)
+		if !ok {
+			return nil, fmt.Errorf("column [%s] of sort orders is not a field of %T", column.name, record)
+		}
+		value, err := keysetValue(field.Field.Interface())
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return &Keyset{SortOrders: sortOrders, Values: values}, nil
+}
+
+// DecodeKeyset returns keyset encoded by Encode, it must be the keyset of the same sortOrders
+func DecodeKeyset(encoded string, sortOrders []string) (*Keyset, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	keyset := &Keyset{}
+	if err = json.Unmarshal(data, keyset); err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(keyset.SortOrders, sortOrders) {
+		return nil, fmt.Errorf("keyset is sorted by %v, but %v", keyset.SortOrders, sortOrders)
+	}
+	columns, err := parseKeysetColumns(sortOrders)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyset.Values) != len(columns) {
+		return nil, fmt.Errorf("keyset has %d values, but %d columns", len(keyset.Values), len(columns))
+	}
+	return keyset, nil
+}
+
+// Encode returns keyset as opaque string
+func (k *Keyset) Encode() string {
+	// Values are strings, numbers, booleans or null, which are always encoded
+	data, _ := json.Marshal(k)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Where returns condition of records after keyset and its values.
+// Record is after keyset if its columns are equal to the keyset until a column which is larger in ascending order
+// or smaller in descending order. Null is smaller than any value as sqlite sorts it.
+func (k *Keyset) Where() (string, []interface{}) {
+	columns, _ := parseKeysetColumns(k.SortOrders) // Validated when keyset is created
+	afters := []string{}
+	values := []interface{}{}
+	equals := ""
+	equalValues := []interface{}{}
+	for i, column := range columns {
+		value := k.Values[i]
+		after := ""
+		switch {
+		case value == nil && column.desc:
+			// Nothing is after null in descending order
+		case value == nil:
+			after = column.name + " is not null"
+		case column.desc:
+			after = "(" + column.name + " < ? or " + column.name + " is null)"
+		default:
+			after = column.name + " > ?"
+		}
+		if after != "" {
+			afters = append(afters, "("+equals+after+")")
+			values = append(values, equalValues...)
+			if value != nil {
+				values = append(values, value)
+			}
+		}
+		if value == nil {
+			equals += column.name + " is null and "
+		} else {
+			equals += column.name + " = ? and "
+			equalValues = append(equalValues, value)
+		}
+	}
+	if len(afters) == 0 {
+		return "1 = 0", values
+	}
+	return strings.Join(afters, " or "), values
+}
+
+// WhereAfter returns db which finds only records after keyset, db is returned as is if keyset is nil
+func WhereAfter(db *gorm.DB, keyset *Keyset) *gorm.DB {
+	if keyset == nil {
+		return db
+	}
+	where, values := keyset.Where()
+	return db.Where(where, values...)
+}
+
+// parseKeysetColumns returns columns of sort orders, a sort order can have plural columns separated by comma
+func parseKeysetColumns(sortOrders []string) ([]keysetColumn, error) {
+	columns := []keysetColumn{}
+	for _, sortOrder := range sortOrders {
+		for _, part := range strings.Split(sortOrder, ",") {
+			words := strings.Fields(part)
+			if len(words) == 0 || len(words) > 2 {
+				return nil, fmt.Errorf("sort order [%s] must be column followed by optional asc or desc", sortOrder)
+			}
+			column := keysetColumn{name: words[0]}
+			if len(words) == 2 {
+				switch strings.ToLower(words[1]) {
+				case "asc":
+				case "desc":
+					column.desc = true
+				default:
+					return nil, fmt.Errorf("sort order [%s] must be column followed by optional asc or desc", sortOrder)
+				}
+			}
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("one or more sort orders are required")
+	}
+	return columns, nil
+}
+
+// keysetValue converts value of field to the value which sqlite stores,
+// so that it is compared with the column in the same way after encoded to JSON
+func keysetValue(value interface{}) (interface{}, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		var err error
+		if value, err = valuer.Value(); err != nil {
+			return nil, err
+		}
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		value = v.Elem().Interface()
+	}
+	if date, ok := value.(time.Time); ok {
+		return date.Format(sqlite3.SQLiteTimestampFormats[0]), nil
+	}
+	return value, nil
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"math"
 	"reflect"
+	"sync"
 
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/sqlite" //to load dialect
+	"github.com/mattn/go-sqlite3"
 )
 
 var instance *gorm.DB
@@ -29,6 +31,17 @@ func Init(databasePath string) (err error) {
 	return
 }
 
+// SetPoolSize sets max number of open and idle connections of opened database
+func SetPoolSize(maxOpenConns, maxIdleConns int) {
+	instance.DB().SetMaxOpenConns(maxOpenConns)
+	instance.DB().SetMaxIdleConns(maxIdleConns)
+}
+
+// SetLogMode enables or disables logging sql of opened database
+func SetLogMode(enable bool) {
+	instance.LogMode(enable)
+}
+
 // GetDB returns opend database of gorm
 func GetDB() *gorm.DB {
 	return instance
@@ -66,3 +79,24 @@ func ColumnNames(model interface{}) []string {
 func IsRecordNotFoundError(err error) bool {
 	return gorm.IsRecordNotFoundError(err)
 }
+
+// IsUniqueConstraintError checks whether err is due to violation of unique constraint
+func IsUniqueConstraintError(err error) bool {
+	sqliteErr, ok := err.(sqlite3.Error)
+	return ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+var fts5Supported struct {
+	once      sync.Once
+	supported bool
+}
+
+// IsFTS5Supported checks whether sqlite is built with FTS5 extension, which is enabled by build tag sqlite_fts5
+func IsFTS5Supported(db *gorm.DB) bool {
+	fts5Supported.once.Do(func() {
+		var row struct{ Used bool }
+		err := db.Raw("select sqlite_compileoption_used('ENABLE_FTS5') as used").Scan(&row).Error
+		fts5Supported.supported = err == nil && row.Used
+	})
+	return fts5Supported.supported
+}
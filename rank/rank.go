@@ -0,0 +1,248 @@
+// Package rank generates keys to order items by comparing them as strings.
+//
+// A key consists of an integer part and a fraction part of base62 digits.
+// The first character of integer part shows its length, 'a' is 1 digit, 'b' is 2 digits, ...
+// and 'Z' is 1 digit of negative number, 'Y' is 2 digits, ... so keys appended to the end or
+// inserted to the beginning stay short. A key between two keys extends the fraction part,
+// it gets longer when items are inserted at the same place many times, then keys should be rebalanced.
+package rank
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// digits are ordered by ASCII code, so keys can be compared as strings
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// MaxLength is length of key which should be rebalanced if exceeded
+const MaxLength = 32
+
+const (
+	zeroKey         = "a0"
+	smallestInteger = "A00000000000000000000000000"
+)
+
+// Between returns a key which is greater than prev and less than next.
+// Empty prev means the beginning and empty next means the end.
+func Between(prev, next string) (string, error) {
+	if prev != "" {
+		if err := validate(prev); err != nil {
+			return "", err
+		}
+	}
+	if next != "" {
+		if err := validate(next); err != nil {
+			return "", err
+		}
+	}
+	if prev != "" && next != "" && prev >= next {
+		return "", errors.Errorf("key [%s] must be less than [%s]", prev, next)
+	}
+
+	switch {
+	case prev == "" && next == "":
+		return zeroKey, nil
+	case prev == "":
+		integer := integerPart(next)
+		fraction := next[len(integer):]
+		if integer == smallestInteger {
+			return integer + midpoint("", fraction), nil
+		}
+		if integer < next {
+			return integer, nil
+		}
+		decremented, ok := decrementInteger(integer)
+		if !ok {
+			return "", errors.New("can not decrement any more")
+		}
+		return decremented, nil
+	case next == "":
+		integer := integerPart(prev)
+		fraction := prev[len(integer):]
+		incremented, ok := incrementInteger(integer)
+		if !ok {
+			return integer + midpoint(fraction, ""), nil
+		}
+		return incremented, nil
+	}
+
+	prevInteger := integerPart(prev)
+	prevFraction := prev[len(prevInteger):]
+	nextInteger := integerPart(next)
+	nextFraction := next[len(nextInteger):]
+	if prevInteger == nextInteger {
+		return prevInteger + midpoint(prevFraction, nextFraction), nil
+	}
+	incremented, ok := incrementInteger(prevInteger)
+	if !ok {
+		return "", errors.New("can not increment any more")
+	}
+	if incremented < next {
+		return incremented, nil
+	}
+	return prevInteger + midpoint(prevFraction, ""), nil
+}
+
+// Sequence returns count of short keys in ascending order, which are used for rebalancing
+func Sequence(count int) []string {
+	result := make([]string, 0, count)
+	key := zeroKey
+	for i := 0; i < count; i++ {
+		result = append(result, key)
+		key, _ = incrementInteger(key)
+	}
+	return result
+}
+
+// IsValid checks whether key is well-formed
+func IsValid(key string) bool {
+	return validate(key) == nil
+}
+
+// NeedsRebalance checks whether key is too long
+func NeedsRebalance(key string) bool {
+	return len(key) > MaxLength
+}
+
+func validate(key string) error {
+	if key == smallestInteger {
+		return errors.Errorf("key [%s] is reserved", key)
+	}
+	length := integerLength(key[0])
+	if length == 0 || len(key) < length {
+		return errors.Errorf("key [%s] has invalid integer part", key)
+	}
+	for i := 1; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return errors.Errorf("key [%s] has invalid character", key)
+		}
+	}
+	if len(key) > length && key[len(key)-1] == digits[0] {
+		return errors.Errorf("fraction part of key [%s] must not end with zero", key)
+	}
+	return nil
+}
+
+// integerLength returns length of integer part including head, 0 if head is invalid
+func integerLength(head byte) int {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2
+	}
+	return 0
+}
+
+func integerPart(key string) string {
+	return key[:integerLength(key[0])]
+}
+
+// midpoint returns fraction between prev and next, empty next means the end
+func midpoint(prev, next string) string {
+	if next != "" {
+		// Common prefix is kept, prev is padded by zero
+		n := 0
+		for n < len(next) && digitAt(prev, n) == next[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(prev) {
+				rest = prev[n:]
+			}
+			return next[:n] + midpoint(rest, next[n:])
+		}
+	}
+	prevDigit := 0
+	if prev != "" {
+		prevDigit = strings.IndexByte(digits, prev[0])
+	}
+	nextDigit := len(digits)
+	if next != "" {
+		nextDigit = strings.IndexByte(digits, next[0])
+	}
+	if nextDigit-prevDigit > 1 {
+		return string(digits[(prevDigit+nextDigit+1)/2])
+	}
+	// Digits are consecutive
+	if len(next) > 1 {
+		return next[:1]
+	}
+	rest := ""
+	if prev != "" {
+		rest = prev[1:]
+	}
+	return string(digits[prevDigit]) + midpoint(rest, "")
+}
+
+func digitAt(value string, i int) byte {
+	if i < len(value) {
+		return value[i]
+	}
+	return digits[0]
+}
+
+func incrementInteger(integer string) (string, bool) {
+	head := integer[0]
+	values := []byte(integer[1:])
+	carry := true
+	for i := len(values) - 1; carry && i >= 0; i-- {
+		d := strings.IndexByte(digits, values[i]) + 1
+		if d == len(digits) {
+			values[i] = digits[0]
+		} else {
+			values[i] = digits[d]
+			carry = false
+		}
+	}
+	if !carry {
+		return string(head) + string(values), true
+	}
+	switch head {
+	case 'Z':
+		return zeroKey, true
+	case 'z':
+		return "", false
+	}
+	head++
+	if head > 'a' {
+		values = append(values, digits[0])
+	} else {
+		values = values[:len(values)-1]
+	}
+	return string(head) + string(values), true
+}
+
+func decrementInteger(integer string) (string, bool) {
+	head := integer[0]
+	values := []byte(integer[1:])
+	borrow := true
+	for i := len(values) - 1; borrow && i >= 0; i-- {
+		d := strings.IndexByte(digits, values[i]) - 1
+		if d == -1 {
+			values[i] = digits[len(digits)-1]
+		} else {
+			values[i] = digits[d]
+			borrow = false
+		}
+	}
+	if !borrow {
+		return string(head) + string(values), true
+	}
+	switch head {
+	case 'a':
+		return "Z" + digits[len(digits)-1:], true
+	case 'A':
+		return "", false
+	}
+	head--
+	if head < 'Z' {
+		values = append(values, digits[len(digits)-1])
+	} else {
+		values = values[:len(values)-1]
+	}
+	return string(head) + string(values), true
+}
@@ -0,0 +1,157 @@
+package rank
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertBetween(t *testing.T, prev, next string) string {
+	key, err := Between(prev, next)
+	if err != nil {
+		t.Fatalf("Failed to get key between [%s] and [%s]: %+v", prev, next, err)
+	}
+	if !IsValid(key) {
+		t.Fatalf("Key [%s] between [%s] and [%s] is invalid", key, prev, next)
+	}
+	if (prev != "" && key <= prev) || (next != "" && key >= next) {
+		t.Fatalf("Key [%s] is not between [%s] and [%s]", key, prev, next)
+	}
+	return key
+}
+
+func TestBetween_Empty(t *testing.T) {
+	assert.Equal(t, "a0", assertBetween(t, "", ""))
+	assert.Equal(t, "a1", assertBetween(t, "a0", ""))
+	assert.Equal(t, "Zz", assertBetween(t, "", "a0"))
+	// Fraction part is dropped at the both ends
+	assert.Equal(t, "a1", assertBetween(t, "a0V", ""))
+	assert.Equal(t, "a0", assertBetween(t, "", "a0V"))
+}
+
+func TestBetween_Adjacent(t *testing.T) {
+	assert.Equal(t, "a0V", assertBetween(t, "a0", "a1"))
+	assert.Equal(t, "a0l", assertBetween(t, "a0V", "a1"))
+	assert.Equal(t, "a0G", assertBetween(t, "a0", "a0V"))
+	assert.Equal(t, "a01V", assertBetween(t, "a01", "a02"))
+	assert.Equal(t, "azV", assertBetween(t, "az", "b00"))
+
+	// Keys stay between after many inserts at the same place
+	prev, next := "a0", "a1"
+	for i := 0; i < 100; i++ {
+		key := assertBetween(t, prev, next)
+		if i%2 == 0 {
+			prev = key
+		} else {
+			next = key
+		}
+	}
+}
+
+func TestBetween_AppendUntilCarry(t *testing.T) {
+	key := "a0"
+	for i := 0; i < len(digits)-1; i++ {
+		key = assertBetween(t, key, "")
+	}
+	assert.Equal(t, "az", key)
+	key = assertBetween(t, key, "")
+	assert.Equal(t, "b00", key)
+	key = assertBetween(t, key, "")
+	assert.Equal(t, "b01", key)
+}
+
+func TestBetween_InsertHeadUntilCarry(t *testing.T) {
+	key := "a0"
+	key = assertBetween(t, "", key)
+	assert.Equal(t, "Zz", key)
+	for i := 0; i < len(digits)-1; i++ {
+		key = assertBetween(t, "", key)
+	}
+	assert.Equal(t, "Z0", key)
+	key = assertBetween(t, "", key)
+	assert.Equal(t, "Yzz", key)
+	key = assertBetween(t, "", key)
+	assert.Equal(t, "Yzy", key)
+}
+
+func TestBetween_InvalidKeys(t *testing.T) {
+	_, err := Between("a1", "a0")
+	assert.Error(t, err)
+	_, err = Between("a0", "a0")
+	assert.Error(t, err)
+	_, err = Between("a0", "!")
+	assert.Error(t, err)
+	_, err = Between("a00", "")
+	assert.Error(t, err)
+	_, err = Between(smallestInteger, "")
+	assert.Error(t, err)
+}
+
+func TestMidpoint(t *testing.T) {
+	assert.Equal(t, "V", midpoint("", ""))
+	assert.Equal(t, "l", midpoint("V", ""))
+	assert.Equal(t, "G", midpoint("", "V"))
+	assert.Equal(t, "1V", midpoint("1", "2"))
+	assert.Equal(t, "1", midpoint("", "2"))
+	assert.Equal(t, "01", midpoint("", "02"))
+	assert.Equal(t, "0V", midpoint("", "1"))
+	assert.Equal(t, "zV", midpoint("z", ""))
+	assert.Equal(t, "12V", midpoint("12", "13"))
+}
+
+func TestIncrementInteger(t *testing.T) {
+	tests := []struct {
+		integer  string
+		expected string
+		ok       bool
+	}{
+		{"a0", "a1", true},
+		{"az", "b00", true},
+		{"b0z", "b10", true},
+		{"Zz", "a0", true},
+		{"Yzz", "Z0", true},
+		{"z" + strings.Repeat("z", 26), "", false},
+	}
+	for _, test := range tests {
+		actual, ok := incrementInteger(test.integer)
+		assert.Equal(t, test.ok, ok, test.integer)
+		assert.Equal(t, test.expected, actual, test.integer)
+	}
+}
+
+func TestDecrementInteger(t *testing.T) {
+	tests := []struct {
+		integer  string
+		expected string
+		ok       bool
+	}{
+		{"a1", "a0", true},
+		{"a0", "Zz", true},
+		{"b00", "az", true},
+		{"b10", "b0z", true},
+		{"Z0", "Yzz", true},
+		{smallestInteger, "", false},
+	}
+	for _, test := range tests {
+		actual, ok := decrementInteger(test.integer)
+		assert.Equal(t, test.ok, ok, test.integer)
+		assert.Equal(t, test.expected, actual, test.integer)
+	}
+}
+
+func TestNeedsRebalance(t *testing.T) {
+	assert.False(t, NeedsRebalance("a0"))
+	assert.False(t, NeedsRebalance("a"+strings.Repeat("1", MaxLength-1)))
+	assert.True(t, NeedsRebalance("a"+strings.Repeat("1", MaxLength)))
+
+	// Inserting right after the same key makes keys long
+	prev, next := "a0", "a1"
+	count := 0
+	for !NeedsRebalance(next) {
+		next = assertBetween(t, prev, next)
+		count++
+	}
+	assert.Equal(t, MaxLength+1, len(next))
+	assert.True(t, count > MaxLength)
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"taskboard/model"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// AutomationExecutionRepository is repository of automation execution table.
+// Executions are log, so this has no apis to update them.
+type AutomationExecutionRepository struct {
+	tx *gorm.DB
+}
+
+// NewAutomationExecutionRepository returns new instance of AutomationExecutionRepository
+func NewAutomationExecutionRepository(tx *gorm.DB) *AutomationExecutionRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &AutomationExecutionRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstAutomationExecution returns first AutomationExecution matching with specified condition
+func (repo *AutomationExecutionRepository) FindFirstAutomationExecution(condition interface{}, sortOrders []string) (result model.AutomationExecution, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindAutomationExecutions returns AutomationExecutions matching with specified condition
+func (repo *AutomationExecutionRepository) FindAutomationExecutions(condition interface{}, offset int, limit int, sortOrders []string) (result []model.AutomationExecution, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountAutomationExecutions returns the number of AutomationExecutions matching specfied condition
+func (repo *AutomationExecutionRepository) CountAutomationExecutions(condition interface{}) (count int, err error) {
+	var automationExecutions []model.AutomationExecution
+	err = repo.tx.Where(condition).Find(&automationExecutions).Count(&count).Error
+	return
+}
+
+// CreateAutomationExecution inserts new AutomationExecution record
+func (repo *AutomationExecutionRepository) CreateAutomationExecution(automationExecution *model.AutomationExecution) error {
+	return repo.CreateAutomationExecutions([]*model.AutomationExecution{automationExecution})
+}
+
+// CreateAutomationExecutions inserts new AutomationExecution records.
+func (repo *AutomationExecutionRepository) CreateAutomationExecutions(automationExecutions []*model.AutomationExecution) (err error) {
+	for _, automationExecution := range automationExecutions {
+		err = repo.tx.Create(automationExecution).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// CountAutomationExecutionsSince returns the number of executions of rule for task created at or after specified time
+func (repo *AutomationExecutionRepository) CountAutomationExecutionsSince(ruleID, taskID string, since time.Time) (count int, err error) {
+	err = repo.tx.Model(&model.AutomationExecution{}).
+		Where("rule_id = ? and task_id = ? and created_date >= ?", ruleID, taskID, since).Count(&count).Error
+	return
+}
+
+// DeleteRuleAutomationExecutions deletes all executions of specified rule
+func (repo *AutomationExecutionRepository) DeleteRuleAutomationExecutions(ruleID string) error {
+	if ruleID == "" {
+		return nil // To avoid deleting all due to gorm warning, return here.
+	}
+	return repo.tx.Where("rule_id = ?", ruleID).Delete(&model.AutomationExecution{}).Error
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndAutomationExecutionRepository() (tx *gorm.DB, repo *AutomationExecutionRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewAutomationExecutionRepository(tx)
+	return
+}
+
+////
+/// Other fuctions' test should be written in below
+//
+func TestAutomationExecutionRepository_AutomationExecutions(t *testing.T) {
+	tx, repo := newTxAndAutomationExecutionRepository()
+	defer tx.Rollback()
+
+	due := time.Date(2030, 1, 10, 0, 0, 0, 0, time.UTC)
+	executions := []*model.AutomationExecution{
+		model.NewAutomationExecution("ruleID-execution-001", "taskID-execution-001", model.AutomationTriggerDuePassed,
+			model.ExecutionStatusSucceeded, "", 0, due.Add(-time.Hour)),
+		model.NewAutomationExecution("ruleID-execution-001", "taskID-execution-002", model.AutomationTriggerDuePassed,
+			model.ExecutionStatusFailed, "Board not found", 0, due.Add(time.Hour)),
+		model.NewAutomationExecution("ruleID-execution-002", "taskID-execution-001", model.AutomationTriggerMoved,
+			model.ExecutionStatusSkipped, "Loop", 1, due),
+	}
+	if err := repo.CreateAutomationExecutions(executions); err != nil {
+		t.Fatalf("Failed to create automation executions: %+v", err)
+	}
+
+	// Executions at or after the time are counted
+	count, err := repo.CountAutomationExecutionsSince("ruleID-execution-001", "taskID-execution-001", due)
+	if err != nil {
+		t.Fatalf("Failed to count automation executions: %+v", err)
+	}
+	assert.Equal(t, 0, count)
+	count, err = repo.CountAutomationExecutionsSince("ruleID-execution-001", "taskID-execution-002", due)
+	if err != nil {
+		t.Fatalf("Failed to count automation executions: %+v", err)
+	}
+	assert.Equal(t, 1, count)
+	count, err = repo.CountAutomationExecutionsSince("ruleID-execution-002", "taskID-execution-001", due)
+	if err != nil {
+		t.Fatalf("Failed to count automation executions: %+v", err)
+	}
+	assert.Equal(t, 1, count)
+
+	// Executions of the rule are deleted
+	err = repo.DeleteRuleAutomationExecutions("ruleID-execution-001")
+	if err != nil {
+		t.Fatalf("Failed to delete automation executions: %+v", err)
+	}
+	remains, err := repo.FindAutomationExecutions("rule_id like 'ruleID-execution-%'", 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to find automation executions: %+v", err)
+	}
+	assert.Equal(t, 1, len(remains))
+	assert.Equal(t, "ruleID-execution-002", remains[0].RuleID)
+
+	// Empty rule ID deletes nothing
+	err = repo.DeleteRuleAutomationExecutions("")
+	if err != nil {
+		t.Fatalf("Failed to delete automation executions: %+v", err)
+	}
+	count, err = repo.CountAutomationExecutions("rule_id like 'ruleID-execution-%'")
+	if err != nil {
+		t.Fatalf("Failed to count automation executions: %+v", err)
+	}
+	assert.Equal(t, 1, count)
+}
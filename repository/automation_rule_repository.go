@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"sync"
+	"taskboard/model"
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+var lockAutomationRule = &sync.Mutex{}
+
+// AutomationRuleRepository is repository of automation_rule table
+type AutomationRuleRepository struct {
+	tx *gorm.DB
+}
+
+// NewAutomationRuleRepository returns new instance of AutomationRuleRepository
+func NewAutomationRuleRepository(tx *gorm.DB) *AutomationRuleRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &AutomationRuleRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstAutomationRule returns first AutomationRule matching with specified condition
+func (repo *AutomationRuleRepository) FindFirstAutomationRule(condition interface{}, sortOrders []string) (result model.AutomationRule, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindAutomationRules returns AutomationRules matching with specified condition
+func (repo *AutomationRuleRepository) FindAutomationRules(condition interface{}, offset int, limit int, sortOrders []string) (result []model.AutomationRule, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, automationRule := range sortOrders {
+		query = query.Order(automationRule)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountAutomationRules returns the number of AutomationRules matching specfied condition
+func (repo *AutomationRuleRepository) CountAutomationRules(condition interface{}) (count int, err error) {
+	var automationRules []model.AutomationRule
+	err = repo.tx.Where(condition).Find(&automationRules).Count(&count).Error
+	return
+}
+
+// CreateAutomationRule inserts new AutomationRule record
+func (repo *AutomationRuleRepository) CreateAutomationRule(automationRule *model.AutomationRule) error {
+	return repo.CreateAutomationRules([]*model.AutomationRule{automationRule})
+}
+
+// UpdateAutomationRule updates AutomationRule record
+func (repo *AutomationRuleRepository) UpdateAutomationRule(automationRule *model.AutomationRule) error {
+	return repo.UpdateAutomationRules([]*model.AutomationRule{automationRule})
+}
+
+// DeleteAutomationRule deletes AutomationRule record
+func (repo *AutomationRuleRepository) DeleteAutomationRule(automationRule *model.AutomationRule) error {
+	return repo.DeleteAutomationRules([]*model.AutomationRule{automationRule})
+}
+
+// CreateAutomationRules inserts new AutomationRule records.
+func (repo *AutomationRuleRepository) CreateAutomationRules(automationRules []*model.AutomationRule) (err error) {
+	for _, automationRule := range automationRules {
+		err = repo.tx.Create(automationRule).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateAutomationRules updates automationRule records
+func (repo *AutomationRuleRepository) UpdateAutomationRules(automationRules []*model.AutomationRule) (err error) {
+	lockAutomationRule.Lock()
+	defer lockAutomationRule.Unlock()
+
+	for _, automationRule := range automationRules {
+		oldVersion := automationRule.Version
+		automationRule.Version++
+		db := repo.tx.Model(&model.AutomationRule{}).Where("version = ?", oldVersion).Updates(automationRule)
+		count := db.RowsAffected
+		err = db.Error
+		// return ErrorRecordNotFoud as optimistic lock error
+		if err == nil && count == 0 {
+			return orm.ErrorRecordNotFound
+		}
+		if err != nil {
+			return
+		}
+		// Updates() skips blank fields, so fields which can be blank are updated explicitly to be cleared
+		err = repo.tx.Model(&model.AutomationRule{}).Where("id = ?", automationRule.ID).
+			Updates(map[string]interface{}{
+				"condition": automationRule.Condition,
+				"is_active": automationRule.IsActive,
+			}).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteAutomationRules deletes AutomationRule records
+func (repo *AutomationRuleRepository) DeleteAutomationRules(automationRules []*model.AutomationRule) (err error) {
+	for _, automationRule := range automationRules {
+		if automationRule.ID == "" {
+			continue // To avoid deleting all due to gorm warning, continue here.
+		}
+		err = repo.tx.Delete(automationRule).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"fmt"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndAutomationRuleRepository() (tx *gorm.DB, repo *AutomationRuleRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewAutomationRuleRepository(tx)
+	return
+}
+
+func createAutomationRuleTestData(tx *gorm.DB, idFormat string, count int) []*model.AutomationRule {
+	result := make([]*model.AutomationRule, 0, count)
+	for i := 0; i < count; i++ {
+		rule := model.NewAutomationRule(
+			fmt.Sprintf("name-%s-%03d", idFormat, i),
+			model.AutomationTriggerMoved,
+			"board:Done",
+			[]model.AutomationAction{
+				{Type: model.AutomationActionSetClosed, Value: "true"},
+				{Type: model.AutomationActionSetAssignee, Value: ""},
+			},
+			true,
+			time.Now().UTC(),
+		)
+		rule.ID = fmt.Sprintf("%s-%03d", idFormat, i)
+		result = append(result, rule)
+	}
+	return result
+}
+
+func insertAutomationRuleTestData(tx *gorm.DB, rules []*model.AutomationRule) (err error) {
+	for _, rule := range rules {
+		err = tx.Create(rule).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+////
+/// Common repository functions' test
+//
+func TestAutomationRuleRepository_FindAutomationRules(t *testing.T) {
+	tx, repo := newTxAndAutomationRuleRepository()
+	defer tx.Rollback()
+
+	firstRules := createAutomationRuleTestData(tx, "automationID-find", 5)
+	secondRules := createAutomationRuleTestData(tx, "automationID-not-find", 4)
+	for _, rule := range secondRules {
+		rule.Trigger = model.AutomationTriggerCreated
+	}
+	err := insertAutomationRuleTestData(tx, append(firstRules, secondRules...))
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	// Get 3 data from 2nd(index=1) position
+	condition := &model.AutomationRule{Trigger: model.AutomationTriggerMoved, IsActive: true}
+	rules, err := repo.FindAutomationRules(condition, 1, 3, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find: %+v", err)
+	}
+	if len(rules) != 3 {
+		t.Errorf("Expected result size = %d, but got %d", 3, len(rules))
+		return
+	}
+	assert.Equal(t, "automationID-find-001", rules[0].ID)
+	assert.Equal(t, []model.AutomationAction{
+		{Type: model.AutomationActionSetClosed, Value: "true"},
+		{Type: model.AutomationActionSetAssignee, Value: ""},
+	}, rules[0].GetActions())
+
+	count, err := repo.CountAutomationRules(condition)
+	if err != nil {
+		t.Fatalf("failed to count AutomationRule: %+v", err)
+	}
+	assert.Equal(t, 5, count)
+}
+
+func TestAutomationRuleRepository_UpdateAutomationRule(t *testing.T) {
+	tx, repo := newTxAndAutomationRuleRepository()
+	defer tx.Rollback()
+
+	rules := createAutomationRuleTestData(tx, "automationID-update", 1)
+	if err := repo.CreateAutomationRule(rules[0]); err != nil {
+		t.Fatalf("Failed to create automation rule: %+v", err)
+	}
+
+	// Blank condition and inactive are updated
+	updated := rules[0]
+	updated.Condition = ""
+	updated.IsActive = false
+	updated.SetActions([]model.AutomationAction{{Type: model.AutomationActionAddComment, Value: "Moved"}})
+	if err := repo.UpdateAutomationRule(updated); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+	find, err := repo.FindFirstAutomationRule(&model.AutomationRule{ID: updated.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find automation rule: %+v", err)
+	}
+	assert.Equal(t, *updated, find)
+	assert.Equal(t, 2, find.Version)
+}
+
+func TestAutomationRuleRepository_DeleteAutomationRule(t *testing.T) {
+	tx, repo := newTxAndAutomationRuleRepository()
+	defer tx.Rollback()
+
+	rules := createAutomationRuleTestData(tx, "automationID-delete", 1)
+	err := insertAutomationRuleTestData(tx, rules)
+	if err != nil {
+		t.Fatalf("Failed to create AutomationRule: %+v", err)
+	}
+	if err := repo.DeleteAutomationRule(rules[0]); err != nil {
+		t.Errorf("Failed to delete: %+v", err)
+	}
+	_, err = repo.FindFirstAutomationRule(&model.AutomationRule{ID: rules[0].ID}, []string{})
+	if !orm.IsRecordNotFoundError(err) {
+		t.Errorf("Record must be deleted, but got: %+v", err)
+	}
+}
@@ -1,15 +1,12 @@
 package repository
 
 import (
-	"sync"
 	"taskboard/model"
 	"taskboard/orm"
 
 	"github.com/jinzhu/gorm"
 )
 
-var lockBoard = &sync.Mutex{}
-
 // BoardRepository is repository of board table
 type BoardRepository struct {
 	tx *gorm.DB
@@ -68,6 +65,16 @@ func (repo *BoardRepository) CountBoards(condition interface{}) (count int, err
 	return
 }
 
+// FindProjectBoards returns Boards matching with specified condition in specified projects
+func (repo *BoardRepository) FindProjectBoards(condition interface{}, projectIDs []string, offset int, limit int, sortOrders []string) (result []model.Board, err error) {
+	return NewBoardRepository(repo.tx.Where("project_id in (?)", projectIDs)).FindBoards(condition, offset, limit, sortOrders)
+}
+
+// CountProjectBoards returns the number of Boards matching specfied condition in specified projects
+func (repo *BoardRepository) CountProjectBoards(condition interface{}, projectIDs []string) (count int, err error) {
+	return NewBoardRepository(repo.tx.Where("project_id in (?)", projectIDs)).CountBoards(condition)
+}
+
 // CreateBoard inserts new Board record
 func (repo *BoardRepository) CreateBoard(board *model.Board) error {
 	return repo.CreateBoards([]*model.Board{board})
@@ -84,18 +91,16 @@ func (repo *BoardRepository) DeleteBoard(board *model.Board) error {
 }
 
 // CreateBoards inserts new Board records.
+// Boards whose rank is empty are appended to the end.
 func (repo *BoardRepository) CreateBoards(boards []*model.Board) (err error) {
-	lockBoard.Lock()
-	defer lockBoard.Unlock()
-
-	count, err := repo.CountBoards(&model.Board{})
-	if err != nil {
-		return
-	}
 	for _, board := range boards {
+		if board.Rank == "" {
+			board.Rank, err = lastRank(repo.allBoards)
+			if err != nil {
+				return
+			}
+		}
 		err = repo.tx.Create(board).Error
-		count++
-		board.DispOrder = count
 		if err != nil {
 			return
 		}
@@ -103,11 +108,9 @@ func (repo *BoardRepository) CreateBoards(boards []*model.Board) (err error) {
 	return
 }
 
-// UpdateBoards updates board records
+// UpdateBoards updates board records.
+// Concurrent updates are rejected by version check and unique rank, so they are not serialized here.
 func (repo *BoardRepository) UpdateBoards(boards []*model.Board) (err error) {
-	lockBoard.Lock()
-	defer lockBoard.Unlock()
-
 	for _, board := range boards {
 		oldVersion := board.Version
 		board.Version++
@@ -121,6 +124,18 @@ func (repo *BoardRepository) UpdateBoards(boards []*model.Board) (err error) {
 		if err != nil {
 			return
 		}
+		// Updates() skips blank fields, so fields which can be blank are updated explicitly to be cleared
+		err = repo.tx.Model(&model.Board{}).Where("id = ?", board.ID).
+			Updates(map[string]interface{}{
+				"is_system":   board.IsSystem,
+				"system_kind": board.SystemKind,
+				"is_closed":   board.IsClosed,
+				"wip_limit":   board.WIPLimit,
+				"wip_mode":    board.WIPMode,
+			}).Error
+		if err != nil {
+			return
+		}
 	}
 	return
 }
@@ -139,14 +154,32 @@ func (repo *BoardRepository) DeleteBoards(boards []*model.Board) (err error) {
 	return
 }
 
-// UpdateBoardOrders changes display orders of boards
-func (repo *BoardRepository) UpdateBoardOrders(boardIDs []string) (err error) {
-	for i, boardID := range boardIDs {
-		err = repo.tx.Model(&model.Board{}).Where("id = ?", boardID).
-			Update(model.Board{DispOrder: i}).Error
-		if err != nil {
-			return
-		}
-	}
-	return nil
+// PreviousBoardRank returns the largest rank less than specified one, empty rank means the end.
+// Board of excludeBoardID is ignored, returns empty if no board exists.
+func (repo *BoardRepository) PreviousBoardRank(before, excludeBoardID string) (string, error) {
+	return previousRank(repo.allBoards, before, excludeBoardID)
+}
+
+// NextBoardRank returns the smallest rank greater than specified one, empty rank means the beginning.
+// Board of excludeBoardID is ignored, returns empty if no board exists.
+func (repo *BoardRepository) NextBoardRank(after, excludeBoardID string) (string, error) {
+	return nextRank(repo.allBoards, after, excludeBoardID)
+}
+
+// MoveBoard changes rank of a board, other boards are not changed.
+func (repo *BoardRepository) MoveBoard(boardID, rank string) error {
+	return repo.tx.Model(&model.Board{}).Where("id = ?", boardID).
+		Updates(map[string]interface{}{
+			"rank":    rank,
+			"version": gorm.Expr("version + 1"),
+		}).Error
+}
+
+// RebalanceBoardRanks replaces ranks of all boards with short ones keeping the order
+func (repo *BoardRepository) RebalanceBoardRanks() error {
+	return rebalanceRanks(repo.allBoards)
+}
+
+func (repo *BoardRepository) allBoards() *gorm.DB {
+	return repo.tx.Model(&model.Board{})
 }
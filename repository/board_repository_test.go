@@ -31,6 +31,7 @@ func createBoardTestData(tx *gorm.DB, idFormat string, findIdentify bool, count
 			time.Now().UTC(),
 		)
 		board.ID = fmt.Sprintf("%s-%03d", idFormat, i)
+		board.Rank = board.ID // Must be unique
 		result = append(result, board)
 	}
 	return result
@@ -315,3 +316,144 @@ func deleteCommitedBoardData(t *testing.T, data *model.Board) {
 ////
 /// Other fuctions' test should be written in below
 //
+func TestBoardRepository_MoveBoard(t *testing.T) {
+	tx, repo := newTxAndBoardRepository()
+	defer tx.Rollback()
+
+	// Ranks are compared with all boards, so boards committed by other tests are deleted in this transaction
+	if err := tx.Exec("delete from boards").Error; err != nil {
+		t.Fatalf("Failed to delete boards: %+v", err)
+	}
+	// Create 3 records appended in order
+	insertBoards := createBoardTestData(tx, "boardID-order", false, 3)
+	for _, board := range insertBoards {
+		board.Rank = ""
+		if err := repo.CreateBoard(board); err != nil {
+			t.Fatalf("Failed to create board: %+v", err)
+		}
+	}
+	assert.Equal(t, []string{"a0", "a1", "a2"},
+		[]string{insertBoards[0].Rank, insertBoards[1].Rank, insertBoards[2].Rank})
+
+	// a b c => b a c
+	prev, err := repo.PreviousBoardRank(insertBoards[2].Rank, insertBoards[0].ID)
+	if err != nil {
+		t.Fatalf("Failed to find rank: %+v", err)
+	}
+	assert.Equal(t, insertBoards[1].Rank, prev)
+	next, err := repo.NextBoardRank(insertBoards[1].Rank, insertBoards[0].ID)
+	if err != nil {
+		t.Fatalf("Failed to find rank: %+v", err)
+	}
+	assert.Equal(t, insertBoards[2].Rank, next)
+	err = repo.MoveBoard(insertBoards[0].ID, "a1V")
+	if err != nil {
+		t.Fatalf("Failed to move board: %+v", err)
+	}
+	err = repo.RebalanceBoardRanks()
+	if err != nil {
+		t.Fatalf("Failed to rebalance ranks: %+v", err)
+	}
+	findBoards, err := repo.FindBoards(&model.Board{}, 0, orm.NoLimit, []string{"rank"})
+	if err != nil {
+		t.Fatalf("Failed to find boards: %+v", err)
+	}
+	if assert.Len(t, findBoards, 3) {
+		assert.Equal(t, insertBoards[1].ID, findBoards[0].ID)
+		assert.Equal(t, "a0", findBoards[0].Rank)
+		assert.Equal(t, insertBoards[0].ID, findBoards[1].ID)
+		assert.Equal(t, "a1", findBoards[1].Rank)
+		assert.Equal(t, insertBoards[0].Version+1, findBoards[1].Version)
+		assert.Equal(t, insertBoards[2].ID, findBoards[2].ID)
+		assert.Equal(t, "a2", findBoards[2].Rank)
+	}
+}
+
+func TestBoardRepository_UpdateBoardWIPLimitToBlank(t *testing.T) {
+	tx, repo := newTxAndBoardRepository()
+	defer tx.Rollback()
+
+	insertBoards := createBoardTestData(tx, "boardID-wip", false, 1)
+	board := insertBoards[0]
+	board.WIPLimit = 3
+	board.WIPMode = model.WIPModeBlock
+	if err := insertBoardTestData(tx, insertBoards); err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	// WIP limit is removed
+	board.WIPLimit = 0
+	board.WIPMode = ""
+	if err := repo.UpdateBoard(board); err != nil {
+		t.Fatalf("Failed to update board: %+v", err)
+	}
+	find, err := repo.FindFirstBoard(&model.Board{ID: board.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find board: %+v", err)
+	}
+	assert.Equal(t, 0, find.WIPLimit)
+	assert.Equal(t, "", find.WIPMode)
+	assert.Equal(t, board.Version, find.Version)
+}
+
+func TestBoardRepository_FindProjectBoards(t *testing.T) {
+	tx, repo := newTxAndBoardRepository()
+	defer tx.Rollback()
+
+	insertBoards := createBoardTestData(tx, "boardID-project", false, 4)
+	insertBoards[0].ProjectID = "projectID-board-000"
+	insertBoards[1].ProjectID = "projectID-board-001"
+	insertBoards[2].ProjectID = "projectID-board-001"
+	insertBoards[3].ProjectID = "projectID-board-002"
+	if err := insertBoardTestData(tx, insertBoards); err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	projectIDs := []string{"projectID-board-000", "projectID-board-001"}
+	boards, err := repo.FindProjectBoards(&model.Board{}, projectIDs, 1, orm.NoLimit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to find boards: %+v", err)
+	}
+	if assert.Len(t, boards, 2) {
+		assert.Equal(t, insertBoards[1].ID, boards[0].ID)
+		assert.Equal(t, insertBoards[2].ID, boards[1].ID)
+	}
+	count, err := repo.CountProjectBoards(&model.Board{}, projectIDs)
+	if err != nil {
+		t.Fatalf("Failed to count boards: %+v", err)
+	}
+	assert.Equal(t, 3, count)
+
+	// No boards are found without projects
+	count, err = repo.CountProjectBoards(&model.Board{}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to count boards: %+v", err)
+	}
+	assert.Equal(t, 0, count)
+}
+
+func TestBoardRepository_UpdateSystemBoardToNormal(t *testing.T) {
+	tx, repo := newTxAndBoardRepository()
+	defer tx.Rollback()
+
+	insertBoards := createBoardTestData(tx, "boardID-system", true, 1)
+	board := insertBoards[0]
+	board.SystemKind = "done"
+	board.IsClosed = true
+	if err := insertBoardTestData(tx, insertBoards); err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	// System board becomes normal open board
+	board.IsSystem = false
+	board.SystemKind = ""
+	board.IsClosed = false
+	if err := repo.UpdateBoard(board); err != nil {
+		t.Fatalf("Failed to update board: %+v", err)
+	}
+	find, err := repo.FindFirstBoard(&model.Board{ID: board.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find board: %+v", err)
+	}
+	assert.Equal(t, *board, find)
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"sync"
+	"taskboard/model"
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+var lockComment = &sync.Mutex{}
+
+// CommentRepository is repository of comment table
+type CommentRepository struct {
+	tx *gorm.DB
+}
+
+// NewCommentRepository returns new instance of CommentRepository
+func NewCommentRepository(tx *gorm.DB) *CommentRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &CommentRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstComment returns first Comment matching with specified condition
+func (repo *CommentRepository) FindFirstComment(condition interface{}, sortOrders []string) (result model.Comment, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindComments returns Comments matching with specified condition
+func (repo *CommentRepository) FindComments(condition interface{}, offset int, limit int, sortOrders []string) (result []model.Comment, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, comment := range sortOrders {
+		query = query.Order(comment)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountComments returns the number of Comments matching specfied condition
+func (repo *CommentRepository) CountComments(condition interface{}) (count int, err error) {
+	var comments []model.Comment
+	err = repo.tx.Where(condition).Find(&comments).Count(&count).Error
+	return
+}
+
+// CreateComment inserts new Comment record
+func (repo *CommentRepository) CreateComment(comment *model.Comment) error {
+	return repo.CreateComments([]*model.Comment{comment})
+}
+
+// UpdateComment updates Comment record
+func (repo *CommentRepository) UpdateComment(comment *model.Comment) error {
+	return repo.UpdateComments([]*model.Comment{comment})
+}
+
+// DeleteComment deletes Comment record
+func (repo *CommentRepository) DeleteComment(comment *model.Comment) error {
+	return repo.DeleteComments([]*model.Comment{comment})
+}
+
+// CreateComments inserts new Comment records.
+func (repo *CommentRepository) CreateComments(comments []*model.Comment) (err error) {
+	for _, comment := range comments {
+		err = repo.tx.Create(comment).Error
+		if err != nil {
+			return
+		}
+		err = NewSearchRepository(repo.tx).indexComment(comment.ID)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateComments updates comment records
+func (repo *CommentRepository) UpdateComments(comments []*model.Comment) (err error) {
+	lockComment.Lock()
+	defer lockComment.Unlock()
+
+	for _, comment := range comments {
+		oldVersion := comment.Version
+		comment.Version++
+		db := repo.tx.Model(&model.Comment{}).Where("version = ?", oldVersion).Updates(comment)
+		count := db.RowsAffected
+		err = db.Error
+		// return ErrorRecordNotFoud as optimistic lock error
+		if err == nil && count == 0 {
+			return orm.ErrorRecordNotFound
+		}
+		if err != nil {
+			return
+		}
+		err = NewSearchRepository(repo.tx).indexComment(comment.ID)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteComments deletes Comment records
+func (repo *CommentRepository) DeleteComments(comments []*model.Comment) (err error) {
+	for _, comment := range comments {
+		if comment.ID == "" {
+			continue // To avoid deleting all due to gorm warning, continue here.
+		}
+		err = repo.tx.Delete(comment).Error
+		if err != nil {
+			return
+		}
+		err = NewSearchRepository(repo.tx).removeComment(comment.ID)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteTaskComments deletes all Comment records of specified task
+func (repo *CommentRepository) DeleteTaskComments(taskID string) error {
+	if taskID == "" {
+		return nil // To avoid deleting all due to gorm warning, return here.
+	}
+	err := repo.tx.Where("task_id = ?", taskID).Delete(&model.Comment{}).Error
+	if err != nil {
+		return err
+	}
+	return NewSearchRepository(repo.tx).removeTaskComments(taskID)
+}
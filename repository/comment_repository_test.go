@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"fmt"
+	"taskboard/common"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndCommentRepository() (tx *gorm.DB, repo *CommentRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewCommentRepository(tx)
+	return
+}
+
+func createCommentTestData(tx *gorm.DB, idFormat string, findIdentify string, count int) []*model.Comment {
+	result := make([]*model.Comment, 0, count)
+	for i := 0; i < count; i++ {
+		comment := model.NewComment(
+			findIdentify,
+			"authorUserID",
+			"body"+common.GenerateID(),
+			time.Now().UTC(),
+		)
+		comment.ID = fmt.Sprintf("%s-%03d", idFormat, i)
+		result = append(result, comment)
+	}
+	return result
+}
+
+func insertCommentTestData(tx *gorm.DB, comments []*model.Comment) (err error) {
+	for _, comment := range comments {
+		err = tx.Create(comment).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+////
+/// Common repository functions' test
+//
+func TestCommentRepository_FindFirstComment(t *testing.T) {
+	tx, repo := newTxAndCommentRepository()
+	defer tx.Rollback()
+
+	firstComments := createCommentTestData(tx, "commentID-find", "findTaskID", 5)
+	secondComments := createCommentTestData(tx, "commentID-not-find", "notFindTaskID", 4)
+	insertComments := append(firstComments, secondComments...)
+	err := insertCommentTestData(tx, insertComments)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+	// Sort by id
+	comment, err := repo.FindFirstComment(&model.Comment{TaskID: "findTaskID"}, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find first: %+v", err)
+	}
+	expected := "commentID-find-000"
+	if comment.ID != expected {
+		t.Errorf("expected comment ID is %s, but got %s", expected, comment.ID)
+	}
+}
+
+func TestCommentRepository_FindComments(t *testing.T) {
+	tx, repo := newTxAndCommentRepository()
+	defer tx.Rollback()
+
+	firstComments := createCommentTestData(tx, "commentID-find", "findTaskID", 5)
+	secondComments := createCommentTestData(tx, "commentID-not-find", "notFindTaskID", 4)
+	insertComments := append(firstComments, secondComments...)
+	err := insertCommentTestData(tx, insertComments)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	// Get 3 data from 2nd(index=1) position
+	const (
+		offset = 1
+		limit  = 3
+	)
+	comments, err := repo.FindComments(&model.Comment{TaskID: "findTaskID"}, offset, limit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find: %+v", err)
+	}
+
+	// Length of data must be limit
+	if len(comments) != limit {
+		t.Errorf("Expected result size = %d, but got %d", limit, len(comments))
+		return
+	}
+	// Head must be 001
+	head := comments[0]
+	headExpected := "commentID-find-001"
+	if head.ID != headExpected {
+		t.Errorf("head ID must be %s, but got %s", headExpected, head.ID)
+	}
+	// Tail must be 003
+	tail := comments[len(comments)-1]
+	tailExpected := "commentID-find-003"
+	if tail.ID != tailExpected {
+		t.Errorf("tail ID must be %s, but got %s", tailExpected, tail.ID)
+	}
+}
+
+func TestAddressRepository_CountComments(t *testing.T) {
+	tx, repo := newTxAndCommentRepository()
+	defer tx.Rollback()
+
+	expected := 5
+	firstComments := createCommentTestData(tx, "commentID-find", "findTaskID", 5)
+	secondComments := createCommentTestData(tx, "commentID-not-find", "notFindTaskID", 4)
+	insertComments := append(firstComments, secondComments...)
+	err := insertCommentTestData(tx, insertComments)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	count, err := repo.CountComments(&model.Comment{TaskID: "findTaskID"})
+	if err != nil {
+		t.Fatalf("failed to count Comment: %+v", err)
+	}
+	if expected != count {
+		t.Errorf("expected %d records, but got %d record", expected, count)
+	}
+}
+
+func TestCommentRepository_CreateComment(t *testing.T) {
+	tx, repo := newTxAndCommentRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertComments := createCommentTestData(tx, "commentID-create", "createTaskID", 1)
+	created := insertComments[0]
+	if err := repo.CreateComment(created); err != nil {
+		t.Fatalf("Failed to create comment: %+v", err)
+	}
+
+	// Find by ID
+	var find = model.Comment{}
+	if err := tx.Where(&model.Comment{ID: created.ID}).First(&find).Error; err != nil {
+		t.Fatalf("Failed to find comment: %+v", err)
+	}
+	// Verify created equals find
+	if !assert.Equal(t, find, *created) {
+		t.Errorf("expected: %+v, but got %+v", created.ID, find.ID)
+	}
+}
+
+func TestCommentRepository_UpdateComment(t *testing.T) {
+	tx, repo := newTxAndCommentRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertComments := createCommentTestData(tx, "commentID-create", "createTaskID", 1)
+	created := insertComments[0]
+	if err := repo.CreateComment(created); err != nil {
+		t.Fatalf("Failed to create comment: %+v", err)
+	}
+
+	// Update the record
+	updated := insertComments[0]
+	updated.TaskID = "updatedTaskID"
+	if err := repo.UpdateComment(updated); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+
+	// Find by ID
+	var find = model.Comment{}
+	if err := tx.Where(&model.Comment{ID: updated.ID}).First(&find).Error; err != nil {
+		t.Fatalf("Failed to find comment: %+v", err)
+	}
+
+	// Verify updated equals find
+	if !assert.Equal(t, find, *updated) {
+		t.Errorf("expected %v, but got %v", find, updated)
+	}
+}
+
+func TestCommentRepository_DeleteComment(t *testing.T) {
+	tx, repo := newTxAndCommentRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertComments := createCommentTestData(tx, "commentId-delete", "deleteTaskID", 1)
+	err := insertCommentTestData(tx, insertComments)
+	if err != nil {
+		t.Fatalf("Failed to create Comment: %+v", err)
+	}
+	deleted := insertComments[0]
+	t.Run("Record will not be deleted if ID is empty", func(t *testing.T) {
+		deletedID := deleted.ID
+		deleted.ID = "" // Clear ID
+		if err := repo.DeleteComment(deleted); err != nil {
+			t.Errorf("Failed to delete: %+v", err)
+		}
+		// record must exist
+		result := model.Comment{}
+		if err := tx.Where(&model.Comment{ID: deletedID}).Find(&result).Error; err != nil {
+			t.Errorf("Failed to find: %+v", err)
+		}
+		result.ID = deletedID
+		deleted.ID = deletedID // Restore ID
+
+		// Verify not deleted
+		if !assert.Equal(t, *deleted, result) {
+			t.Error("Record is not same")
+		}
+	})
+
+	t.Run("Record will be deleted if ID is set", func(t *testing.T) {
+		if err := repo.DeleteComment(deleted); err != nil {
+			t.Errorf("Failed to delete: %+v", err)
+		}
+
+		// Check record will not be found
+		result := model.Comment{}
+		err := tx.Where(&model.Comment{ID: deleted.ID}).First(&result).Error
+		if !orm.IsRecordNotFoundError(err) {
+			t.Errorf("Record must be deleted, but got: %+v", err)
+		}
+	})
+}
+
+// Test for CreateComments, UpdateComments, DeleteComments are ommitted,
+// because that they are called internally in each single version
+
+////
+/// Optimistic lock test (if version lock supported)
+//
+func TestCommentRepository_UpdateCommentOptimisticCheck(t *testing.T) {
+	tx1, _ := newTxAndCommentRepository()
+	tx2, repo2 := newTxAndCommentRepository()
+	tx3, repo3 := newTxAndCommentRepository()
+	defer tx1.Rollback()
+	defer tx2.Rollback()
+	defer tx3.Rollback()
+
+	// Create and commit 1 record in tx1
+	insertComments := createCommentTestData(tx1, "commentID-optimistic", "", 1)
+	err := insertCommentTestData(tx1, insertComments)
+	if err != nil {
+		t.Fatalf("Failed to create comment: %+v", err)
+	}
+	if err := tx1.Commit().Error; err != nil {
+		t.Fatalf("Failed to commit data in tx1")
+	}
+	// Get commited data in tx2
+	data := insertComments[0]
+	find, err := repo2.FindFirstComment(model.Comment{ID: data.ID}, []string{})
+	if err != nil {
+		deleteCommitedCommentData(t, data)
+	}
+	find.TaskID = "UpdateInTx2"
+	data.TaskID = "NotUpdateInTx3"
+	// Find updated in tx2 (Version number incremented!!)
+	err = repo2.UpdateComment(&find)
+	if err != nil {
+		deleteCommitedCommentData(t, data)
+		t.Fatalf("Failed to update in tx2.")
+	}
+	err = tx2.Commit().Error
+	if err != nil {
+		deleteCommitedCommentData(t, data)
+		t.Fatalf("Failed to commit data in tx2")
+	}
+	// Check to not update due to optimistic error
+	if !assert.Error(t, repo3.UpdateComment(data)) {
+		deleteCommitedCommentData(t, data)
+		t.Fatalf("Not failed to update, No error occurred")
+	}
+	err = tx3.Commit().Error
+	if err != nil {
+		deleteCommitedCommentData(t, data)
+		t.Fatalf("Failed to Commit tx3, no affected row")
+	}
+
+	tx4, _ := newTxAndCommentRepository()
+	defer tx4.Rollback()
+	var result = model.Comment{}
+	// Can be found as same as find(tx2)
+	if err := tx4.Where(model.Comment{ID: find.ID}).Find(&result).Error; err != nil {
+		t.Fatalf("failed to retrieve Comment: %+v", err)
+	}
+	deleteCommitedCommentData(t, data)
+	if !assert.Equal(t, find, result) {
+		t.Errorf("expected %v, but got %v", find, result)
+	}
+}
+
+func deleteCommitedCommentData(t *testing.T, data *model.Comment) {
+	// Try to delete data in another transaction
+	tx, repo := newTxAndCommentRepository()
+	defer tx.Rollback()
+	err := repo.DeleteComment(data)
+	if err != nil {
+		t.Log("*** Failed to delete test data, please restart test. ***")
+	} else {
+		err := tx.Commit().Error
+		if err != nil {
+			t.Log("*** Failed to delete test data, please restart test. ***")
+		}
+	}
+}
+
+////
+/// Other fuctions' test should be written in below
+//
+func TestCommentRepository_DeleteTaskComments(t *testing.T) {
+	tx, repo := newTxAndCommentRepository()
+	defer tx.Rollback()
+
+	// Create 3 + 2 records
+	firstComments := createCommentTestData(tx, "commentID-delete", "deleteTaskID", 3)
+	secondComments := createCommentTestData(tx, "commentID-not-delete", "notDeleteTaskID", 2)
+	insertComments := append(firstComments, secondComments...)
+	err := insertCommentTestData(tx, insertComments)
+	if err != nil {
+		t.Fatalf("Failed to create comments: %+v", err)
+	}
+	err = repo.DeleteTaskComments("deleteTaskID")
+	if err != nil {
+		t.Fatalf("Failed to delete comments: %+v", err)
+	}
+	deleted, err := repo.CountComments(&model.Comment{TaskID: "deleteTaskID"})
+	if err != nil {
+		t.Fatalf("Failed to count comments: %+v", err)
+	}
+	assert.Equal(t, 0, deleted)
+	notDeleted, err := repo.CountComments(&model.Comment{TaskID: "notDeleteTaskID"})
+	if err != nil {
+		t.Fatalf("Failed to count comments: %+v", err)
+	}
+	assert.Equal(t, 2, notDeleted)
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"sync"
+	"taskboard/model"
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+var lockLabel = &sync.Mutex{}
+
+// LabelRepository is repository of label table
+type LabelRepository struct {
+	tx *gorm.DB
+}
+
+// NewLabelRepository returns new instance of LabelRepository
+func NewLabelRepository(tx *gorm.DB) *LabelRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &LabelRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstLabel returns first Label matching with specified condition
+func (repo *LabelRepository) FindFirstLabel(condition interface{}, sortOrders []string) (result model.Label, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindLabels returns Labels matching with specified condition
+func (repo *LabelRepository) FindLabels(condition interface{}, offset int, limit int, sortOrders []string) (result []model.Label, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, label := range sortOrders {
+		query = query.Order(label)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountLabels returns the number of Labels matching specfied condition
+func (repo *LabelRepository) CountLabels(condition interface{}) (count int, err error) {
+	var labels []model.Label
+	err = repo.tx.Where(condition).Find(&labels).Count(&count).Error
+	return
+}
+
+// CreateLabel inserts new Label record
+func (repo *LabelRepository) CreateLabel(label *model.Label) error {
+	return repo.CreateLabels([]*model.Label{label})
+}
+
+// UpdateLabel updates Label record
+func (repo *LabelRepository) UpdateLabel(label *model.Label) error {
+	return repo.UpdateLabels([]*model.Label{label})
+}
+
+// DeleteLabel deletes Label record
+func (repo *LabelRepository) DeleteLabel(label *model.Label) error {
+	return repo.DeleteLabels([]*model.Label{label})
+}
+
+// CreateLabels inserts new Label records.
+func (repo *LabelRepository) CreateLabels(labels []*model.Label) (err error) {
+	for _, label := range labels {
+		err = repo.tx.Create(label).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateLabels updates label records
+func (repo *LabelRepository) UpdateLabels(labels []*model.Label) (err error) {
+	lockLabel.Lock()
+	defer lockLabel.Unlock()
+
+	for _, label := range labels {
+		oldVersion := label.Version
+		label.Version++
+		db := repo.tx.Model(&model.Label{}).Where("version = ?", oldVersion).Updates(label)
+		count := db.RowsAffected
+		err = db.Error
+		// return ErrorRecordNotFoud as optimistic lock error
+		if err == nil && count == 0 {
+			return orm.ErrorRecordNotFound
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteLabels deletes Label records
+func (repo *LabelRepository) DeleteLabels(labels []*model.Label) (err error) {
+	for _, label := range labels {
+		if label.ID == "" {
+			continue // To avoid deleting all due to gorm warning, continue here.
+		}
+		err = repo.tx.Delete(label).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// FindTaskLabels returns links between labels and specified tasks
+func (repo *LabelRepository) FindTaskLabels(taskIDs []string) (result []model.TaskLabel, err error) {
+	err = repo.tx.Where("task_id in (?)", taskIDs).Order("task_id, label_id").Find(&result).Error
+	return
+}
+
+// CreateTaskLabels inserts links between a task and labels
+func (repo *LabelRepository) CreateTaskLabels(taskID string, labelIDs []string) (err error) {
+	for _, labelID := range labelIDs {
+		err = repo.tx.Create(&model.TaskLabel{TaskID: taskID, LabelID: labelID}).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteTaskLabels deletes links between labels and tasks matching specified condition
+func (repo *LabelRepository) DeleteTaskLabels(condition *model.TaskLabel) error {
+	if condition.TaskID == "" && condition.LabelID == "" {
+		return nil // To avoid deleting all due to gorm warning, return here.
+	}
+	return repo.tx.Where(condition).Delete(&model.TaskLabel{}).Error
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"sync"
+	"taskboard/model"
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+var lockProject = &sync.Mutex{}
+
+// ProjectRepository is repository of project table
+type ProjectRepository struct {
+	tx *gorm.DB
+}
+
+// NewProjectRepository returns new instance of ProjectRepository
+func NewProjectRepository(tx *gorm.DB) *ProjectRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &ProjectRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstProject returns first Project matching with specified condition
+func (repo *ProjectRepository) FindFirstProject(condition interface{}, sortOrders []string) (result model.Project, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindProjects returns Projects matching with specified condition
+func (repo *ProjectRepository) FindProjects(condition interface{}, offset int, limit int, sortOrders []string) (result []model.Project, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, project := range sortOrders {
+		query = query.Order(project)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountProjects returns the number of Projects matching specfied condition
+func (repo *ProjectRepository) CountProjects(condition interface{}) (count int, err error) {
+	var projects []model.Project
+	err = repo.tx.Where(condition).Find(&projects).Count(&count).Error
+	return
+}
+
+// CreateProject inserts new Project record
+func (repo *ProjectRepository) CreateProject(project *model.Project) error {
+	return repo.CreateProjects([]*model.Project{project})
+}
+
+// UpdateProject updates Project record
+func (repo *ProjectRepository) UpdateProject(project *model.Project) error {
+	return repo.UpdateProjects([]*model.Project{project})
+}
+
+// DeleteProject deletes Project record
+func (repo *ProjectRepository) DeleteProject(project *model.Project) error {
+	return repo.DeleteProjects([]*model.Project{project})
+}
+
+// CreateProjects inserts new Project records.
+func (repo *ProjectRepository) CreateProjects(projects []*model.Project) (err error) {
+	for _, project := range projects {
+		err = repo.tx.Create(project).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateProjects updates project records
+func (repo *ProjectRepository) UpdateProjects(projects []*model.Project) (err error) {
+	lockProject.Lock()
+	defer lockProject.Unlock()
+
+	for _, project := range projects {
+		oldVersion := project.Version
+		project.Version++
+		db := repo.tx.Model(&model.Project{}).Where("version = ?", oldVersion).Updates(project)
+		count := db.RowsAffected
+		err = db.Error
+		// return ErrorRecordNotFoud as optimistic lock error
+		if err == nil && count == 0 {
+			return orm.ErrorRecordNotFound
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteProjects deletes Project records
+func (repo *ProjectRepository) DeleteProjects(projects []*model.Project) (err error) {
+	for _, project := range projects {
+		if project.ID == "" {
+			continue // To avoid deleting all due to gorm warning, continue here.
+		}
+		err = repo.tx.Delete(project).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// FindMemberProjects returns Projects which specified user is a member of
+func (repo *ProjectRepository) FindMemberProjects(userID string, offset int, limit int, sortOrders []string) (result []model.Project, err error) {
+	query := repo.tx.Where("id in (select project_id from "+repo.tx.NewScope(&model.ProjectMember{}).TableName()+
+		" where user_id = ?)", userID)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.Find(&result).Error
+	return
+}
+
+// CountMemberProjects returns the number of Projects which specified user is a member of
+func (repo *ProjectRepository) CountMemberProjects(userID string) (count int, err error) {
+	err = repo.tx.Model(&model.Project{}).Where("id in (select project_id from "+
+		repo.tx.NewScope(&model.ProjectMember{}).TableName()+" where user_id = ?)", userID).Count(&count).Error
+	return
+}
+
+// FindProjectMembers returns members of projects matching specified condition
+func (repo *ProjectRepository) FindProjectMembers(condition *model.ProjectMember) (result []model.ProjectMember, err error) {
+	err = repo.tx.Where(condition).Order("project_id, user_id").Find(&result).Error
+	return
+}
+
+// FindMemberProjectIDs returns IDs of projects which user is a member of
+func (repo *ProjectRepository) FindMemberProjectIDs(userID string) (result []string, err error) {
+	err = repo.tx.Model(&model.ProjectMember{}).Where("user_id = ?", userID).Order("project_id").
+		Pluck("project_id", &result).Error
+	return
+}
+
+// CreateProjectMembers inserts members of a project
+func (repo *ProjectRepository) CreateProjectMembers(projectID string, userIDs []string) (err error) {
+	for _, userID := range userIDs {
+		err = repo.tx.Create(&model.ProjectMember{ProjectID: projectID, UserID: userID}).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteProjectMembers deletes members matching specified condition
+func (repo *ProjectRepository) DeleteProjectMembers(condition *model.ProjectMember) error {
+	if condition.ProjectID == "" && condition.UserID == "" {
+		return nil // To avoid deleting all due to gorm warning, return here.
+	}
+	return repo.tx.Where(condition).Delete(&model.ProjectMember{}).Error
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"fmt"
+	"taskboard/common"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndProjectRepository() (tx *gorm.DB, repo *ProjectRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewProjectRepository(tx)
+	return
+}
+
+func createProjectTestData(tx *gorm.DB, idFormat string, count int) []*model.Project {
+	result := make([]*model.Project, 0, count)
+	for i := 0; i < count; i++ {
+		project := model.NewProject(
+			"name"+common.GenerateID(),
+			time.Now().UTC(),
+		)
+		project.ID = fmt.Sprintf("%s-%03d", idFormat, i)
+		result = append(result, project)
+	}
+	return result
+}
+
+func insertProjectTestData(tx *gorm.DB, projects []*model.Project) (err error) {
+	for _, project := range projects {
+		err = tx.Create(project).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+////
+/// Common repository functions' test
+//
+func TestProjectRepository_FindProjects(t *testing.T) {
+	tx, repo := newTxAndProjectRepository()
+	defer tx.Rollback()
+
+	firstProjects := createProjectTestData(tx, "projectID-find", 5)
+	secondProjects := createProjectTestData(tx, "projectID-not-find", 4)
+	insertProjects := append(firstProjects, secondProjects...)
+	err := insertProjectTestData(tx, insertProjects)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	// Get 3 data from 2nd(index=1) position
+	const (
+		offset = 1
+		limit  = 3
+	)
+	projects, err := repo.FindProjects("id like 'projectID-find-%'", offset, limit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find: %+v", err)
+	}
+	if len(projects) != limit {
+		t.Errorf("Expected result size = %d, but got %d", limit, len(projects))
+		return
+	}
+	assert.Equal(t, "projectID-find-001", projects[0].ID)
+	assert.Equal(t, "projectID-find-003", projects[len(projects)-1].ID)
+
+	count, err := repo.CountProjects("id like 'projectID-find-%'")
+	if err != nil {
+		t.Fatalf("failed to count Project: %+v", err)
+	}
+	assert.Equal(t, 5, count)
+}
+
+func TestProjectRepository_UpdateProject(t *testing.T) {
+	tx, repo := newTxAndProjectRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertProjects := createProjectTestData(tx, "projectID-create", 1)
+	created := insertProjects[0]
+	if err := repo.CreateProject(created); err != nil {
+		t.Fatalf("Failed to create project: %+v", err)
+	}
+
+	// Update the record
+	updated := insertProjects[0]
+	updated.Name = "updatedName"
+	if err := repo.UpdateProject(updated); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+
+	// Find by ID
+	find, err := repo.FindFirstProject(&model.Project{ID: updated.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find project: %+v", err)
+	}
+	if !assert.Equal(t, find, *updated) {
+		t.Errorf("expected %v, but got %v", find, updated)
+	}
+}
+
+func TestProjectRepository_DeleteProject(t *testing.T) {
+	tx, repo := newTxAndProjectRepository()
+	defer tx.Rollback()
+
+	insertProjects := createProjectTestData(tx, "projectID-delete", 1)
+	err := insertProjectTestData(tx, insertProjects)
+	if err != nil {
+		t.Fatalf("Failed to create Project: %+v", err)
+	}
+	deleted := insertProjects[0]
+	if err := repo.DeleteProject(deleted); err != nil {
+		t.Errorf("Failed to delete: %+v", err)
+	}
+	_, err = repo.FindFirstProject(&model.Project{ID: deleted.ID}, []string{})
+	if !orm.IsRecordNotFoundError(err) {
+		t.Errorf("Record must be deleted, but got: %+v", err)
+	}
+}
+
+// Test for CreateProjects, UpdateProjects, DeleteProjects are ommitted,
+// because that they are called internally in each single version
+
+////
+/// Other fuctions' test should be written in below
+//
+func TestProjectRepository_ProjectMembers(t *testing.T) {
+	tx, repo := newTxAndProjectRepository()
+	defer tx.Rollback()
+
+	insertProjects := createProjectTestData(tx, "projectID-member", 3)
+	err := insertProjectTestData(tx, insertProjects)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+	err = repo.CreateProjectMembers("projectID-member-000", []string{"userID-member-001", "userID-member-002"})
+	if err != nil {
+		t.Fatalf("Failed to create project members: %+v", err)
+	}
+	err = repo.CreateProjectMembers("projectID-member-002", []string{"userID-member-001"})
+	if err != nil {
+		t.Fatalf("Failed to create project members: %+v", err)
+	}
+
+	// Projects of user
+	projects, err := repo.FindMemberProjects("userID-member-001", 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to find member projects: %+v", err)
+	}
+	assert.Equal(t, []model.Project{*insertProjects[0], *insertProjects[2]}, projects)
+	count, err := repo.CountMemberProjects("userID-member-001")
+	if err != nil {
+		t.Fatalf("Failed to count member projects: %+v", err)
+	}
+	assert.Equal(t, 2, count)
+	projectIDs, err := repo.FindMemberProjectIDs("userID-member-002")
+	if err != nil {
+		t.Fatalf("Failed to find member project IDs: %+v", err)
+	}
+	assert.Equal(t, []string{"projectID-member-000"}, projectIDs)
+
+	// Remove user from all projects
+	err = repo.DeleteProjectMembers(&model.ProjectMember{UserID: "userID-member-001"})
+	if err != nil {
+		t.Fatalf("Failed to delete project members: %+v", err)
+	}
+	members, err := repo.FindProjectMembers(&model.ProjectMember{})
+	if err != nil {
+		t.Fatalf("Failed to find project members: %+v", err)
+	}
+	assert.Contains(t, members, model.ProjectMember{ProjectID: "projectID-member-000", UserID: "userID-member-002"})
+	assert.NotContains(t, members, model.ProjectMember{ProjectID: "projectID-member-002", UserID: "userID-member-001"})
+
+	// Empty condition deletes nothing
+	err = repo.DeleteProjectMembers(&model.ProjectMember{})
+	if err != nil {
+		t.Fatalf("Failed to delete project members: %+v", err)
+	}
+	projectIDs, err = repo.FindMemberProjectIDs("userID-member-002")
+	if err != nil {
+		t.Fatalf("Failed to find member project IDs: %+v", err)
+	}
+	assert.Equal(t, []string{"projectID-member-000"}, projectIDs)
+}
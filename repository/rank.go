@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"taskboard/rank"
+
+	"github.com/jinzhu/gorm"
+)
+
+// rankedQuery is a query of records in a group ordered by rank, such as tasks in a board
+type rankedQuery func() *gorm.DB
+
+// previousRank returns the largest rank less than specified rank, empty rank means the end.
+// Record of excludeID is ignored, returns empty if no record exists.
+func previousRank(query rankedQuery, before, excludeID string) (string, error) {
+	q := query().Where("id <> ?", excludeID)
+	if before != "" {
+		q = q.Where("rank < ?", before)
+	}
+	return aggregateRank(q, "max")
+}
+
+// nextRank returns the smallest rank greater than specified rank, empty rank means the beginning.
+// Record of excludeID is ignored, returns empty if no record exists.
+func nextRank(query rankedQuery, after, excludeID string) (string, error) {
+	return aggregateRank(query().Where("id <> ? and rank > ?", excludeID, after), "min")
+}
+
+func aggregateRank(query *gorm.DB, aggregate string) (string, error) {
+	var out sql.NullString
+	err := query.Select(fmt.Sprintf("%s(rank)", aggregate)).Row().Scan(&out)
+	if err != nil {
+		return "", err
+	}
+	return out.String, nil
+}
+
+// lastRank returns a rank after all records
+func lastRank(query rankedQuery) (string, error) {
+	last, err := previousRank(query, "", "")
+	if err != nil {
+		return "", err
+	}
+	return rank.Between(last, "")
+}
+
+// rebalanceRanks replaces ranks of all records with short ones keeping the order
+func rebalanceRanks(query rankedQuery) error {
+	var ids []string
+	err := query().Order("rank").Order("id").Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	// Ranks are unique, so temporary ones are set not to conflict with current ones
+	for _, id := range ids {
+		err = query().Where("id = ?", id).UpdateColumn("rank", "~"+id).Error
+		if err != nil {
+			return err
+		}
+	}
+	for i, key := range rank.Sequence(len(ids)) {
+		err = query().Where("id = ?", ids[i]).UpdateColumn("rank", key).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
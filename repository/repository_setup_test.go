@@ -3,7 +3,7 @@ package repository_test
 import (
 	"fmt"
 	"os"
-	"taskboard/model"
+	"taskboard/migration"
 	"taskboard/orm"
 	"testing"
 )
@@ -34,11 +34,7 @@ func TestMain(m *testing.M) {
 	}
 
 	// Create tables
-	err = orm.Migrate(
-		&model.User{},
-		&model.Task{},
-		&model.Board{},
-	)
+	err = migration.Up(orm.GetDB())
 	if err != nil {
 		fmt.Printf("Failed to create tables: %+v\n", err)
 		err := orm.GetDB().Close()
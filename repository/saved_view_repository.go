@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"sync"
+	"taskboard/model"
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+var lockSavedView = &sync.Mutex{}
+
+// SavedViewRepository is repository of saved_view table
+type SavedViewRepository struct {
+	tx *gorm.DB
+}
+
+// NewSavedViewRepository returns new instance of SavedViewRepository
+func NewSavedViewRepository(tx *gorm.DB) *SavedViewRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &SavedViewRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstSavedView returns first SavedView matching with specified condition
+func (repo *SavedViewRepository) FindFirstSavedView(condition interface{}, sortOrders []string) (result model.SavedView, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindSavedViews returns SavedViews matching with specified condition
+func (repo *SavedViewRepository) FindSavedViews(condition interface{}, offset int, limit int, sortOrders []string) (result []model.SavedView, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, view := range sortOrders {
+		query = query.Order(view)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountSavedViews returns the number of SavedViews matching specfied condition
+func (repo *SavedViewRepository) CountSavedViews(condition interface{}) (count int, err error) {
+	var views []model.SavedView
+	err = repo.tx.Where(condition).Find(&views).Count(&count).Error
+	return
+}
+
+// CreateSavedView inserts new SavedView record
+func (repo *SavedViewRepository) CreateSavedView(view *model.SavedView) error {
+	return repo.CreateSavedViews([]*model.SavedView{view})
+}
+
+// UpdateSavedView updates SavedView record
+func (repo *SavedViewRepository) UpdateSavedView(view *model.SavedView) error {
+	return repo.UpdateSavedViews([]*model.SavedView{view})
+}
+
+// DeleteSavedView deletes SavedView record
+func (repo *SavedViewRepository) DeleteSavedView(view *model.SavedView) error {
+	return repo.DeleteSavedViews([]*model.SavedView{view})
+}
+
+// CreateSavedViews inserts new SavedView records.
+func (repo *SavedViewRepository) CreateSavedViews(views []*model.SavedView) (err error) {
+	for _, view := range views {
+		err = repo.tx.Create(view).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateSavedViews updates saved_view records
+func (repo *SavedViewRepository) UpdateSavedViews(views []*model.SavedView) (err error) {
+	lockSavedView.Lock()
+	defer lockSavedView.Unlock()
+
+	for _, view := range views {
+		oldVersion := view.Version
+		view.Version++
+		db := repo.tx.Model(&model.SavedView{}).Where("version = ?", oldVersion).Updates(view)
+		count := db.RowsAffected
+		err = db.Error
+		// return ErrorRecordNotFoud as optimistic lock error
+		if err == nil && count == 0 {
+			return orm.ErrorRecordNotFound
+		}
+		if err != nil {
+			return
+		}
+		// Updates() skips blank fields, so fields which can be blank are updated explicitly to be cleared
+		err = repo.tx.Model(&model.SavedView{}).Where("id = ?", view.ID).
+			Updates(map[string]interface{}{
+				"query":     view.Query,
+				"sort":      view.Sort,
+				"board_id":  view.BoardID,
+				"is_shared": view.IsShared,
+			}).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteSavedViews deletes SavedView records
+func (repo *SavedViewRepository) DeleteSavedViews(views []*model.SavedView) (err error) {
+	for _, view := range views {
+		if view.ID == "" {
+			continue // To avoid deleting all due to gorm warning, continue here.
+		}
+		err = repo.tx.Delete(view).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteUserSavedViews deletes all SavedView records owned by specified user
+func (repo *SavedViewRepository) DeleteUserSavedViews(userID string) error {
+	if userID == "" {
+		return nil // To avoid deleting all due to gorm warning, return here.
+	}
+	return repo.tx.Where("owner_user_id = ?", userID).Delete(&model.SavedView{}).Error
+}
+
+// FindVisibleSavedViews returns SavedViews which are owned by specified user or shared
+func (repo *SavedViewRepository) FindVisibleSavedViews(userID string, offset int, limit int, sortOrders []string) (result []model.SavedView, err error) {
+	query := repo.tx.Where("owner_user_id = ? or is_shared = ?", userID, true)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.Find(&result).Error
+	return
+}
+
+// CountVisibleSavedViews returns the number of SavedViews which are owned by specified user or shared
+func (repo *SavedViewRepository) CountVisibleSavedViews(userID string) (count int, err error) {
+	err = repo.tx.Model(&model.SavedView{}).Where("owner_user_id = ? or is_shared = ?", userID, true).Count(&count).Error
+	return
+}
@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"fmt"
+	"taskboard/common"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndSavedViewRepository() (tx *gorm.DB, repo *SavedViewRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewSavedViewRepository(tx)
+	return
+}
+
+func createSavedViewTestData(tx *gorm.DB, idFormat string, findIdentify string, count int) []*model.SavedView {
+	result := make([]*model.SavedView, 0, count)
+	for i := 0; i < count; i++ {
+		view := model.NewSavedView(
+			findIdentify,
+			"name"+common.GenerateID(),
+			"closed:false",
+			"name",
+			"",
+			false,
+			time.Now().UTC(),
+		)
+		view.ID = fmt.Sprintf("%s-%03d", idFormat, i)
+		result = append(result, view)
+	}
+	return result
+}
+
+func insertSavedViewTestData(tx *gorm.DB, views []*model.SavedView) (err error) {
+	for _, view := range views {
+		err = tx.Create(view).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+////
+/// Common repository functions' test
+//
+func TestSavedViewRepository_FindFirstSavedView(t *testing.T) {
+	tx, repo := newTxAndSavedViewRepository()
+	defer tx.Rollback()
+
+	firstViews := createSavedViewTestData(tx, "viewID-find", "findOwner", 5)
+	secondViews := createSavedViewTestData(tx, "viewID-not-find", "notFindOwner", 4)
+	insertViews := append(firstViews, secondViews...)
+	err := insertSavedViewTestData(tx, insertViews)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+	// Sort by id
+	view, err := repo.FindFirstSavedView(&model.SavedView{OwnerUserID: "findOwner"}, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find first: %+v", err)
+	}
+	expected := "viewID-find-000"
+	if view.ID != expected {
+		t.Errorf("expected view ID is %s, but got %s", expected, view.ID)
+	}
+}
+
+func TestSavedViewRepository_FindSavedViews(t *testing.T) {
+	tx, repo := newTxAndSavedViewRepository()
+	defer tx.Rollback()
+
+	firstViews := createSavedViewTestData(tx, "viewID-find", "findOwner", 5)
+	secondViews := createSavedViewTestData(tx, "viewID-not-find", "notFindOwner", 4)
+	insertViews := append(firstViews, secondViews...)
+	err := insertSavedViewTestData(tx, insertViews)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	// Get 3 data from 2nd(index=1) position
+	const (
+		offset = 1
+		limit  = 3
+	)
+	views, err := repo.FindSavedViews(&model.SavedView{OwnerUserID: "findOwner"}, offset, limit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find: %+v", err)
+	}
+
+	// Length of data must be limit
+	if len(views) != limit {
+		t.Errorf("Expected result size = %d, but got %d", limit, len(views))
+		return
+	}
+	// Head must be 001
+	head := views[0]
+	headExpected := "viewID-find-001"
+	if head.ID != headExpected {
+		t.Errorf("head ID must be %s, but got %s", headExpected, head.ID)
+	}
+	// Tail must be 003
+	tail := views[len(views)-1]
+	tailExpected := "viewID-find-003"
+	if tail.ID != tailExpected {
+		t.Errorf("tail ID must be %s, but got %s", tailExpected, tail.ID)
+	}
+}
+
+func TestAddressRepository_CountSavedViews(t *testing.T) {
+	tx, repo := newTxAndSavedViewRepository()
+	defer tx.Rollback()
+
+	expected := 5
+	firstViews := createSavedViewTestData(tx, "viewID-find", "findOwner", 5)
+	secondViews := createSavedViewTestData(tx, "viewID-not-find", "notFindOwner", 4)
+	insertViews := append(firstViews, secondViews...)
+	err := insertSavedViewTestData(tx, insertViews)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	count, err := repo.CountSavedViews(&model.SavedView{OwnerUserID: "findOwner"})
+	if err != nil {
+		t.Fatalf("failed to count SavedView: %+v", err)
+	}
+	if expected != count {
+		t.Errorf("expected %d records, but got %d record", expected, count)
+	}
+}
+
+func TestSavedViewRepository_CreateSavedView(t *testing.T) {
+	tx, repo := newTxAndSavedViewRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertViews := createSavedViewTestData(tx, "viewID-create", "createOwner", 1)
+	created := insertViews[0]
+	if err := repo.CreateSavedView(created); err != nil {
+		t.Fatalf("Failed to create view: %+v", err)
+	}
+
+	// Find by ID
+	var find = model.SavedView{}
+	if err := tx.Where(&model.SavedView{ID: created.ID}).First(&find).Error; err != nil {
+		t.Fatalf("Failed to find view: %+v", err)
+	}
+	// Verify created equals find
+	if !assert.Equal(t, find, *created) {
+		t.Errorf("expected: %+v, but got %+v", created.ID, find.ID)
+	}
+}
+
+func TestSavedViewRepository_UpdateSavedView(t *testing.T) {
+	tx, repo := newTxAndSavedViewRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertViews := createSavedViewTestData(tx, "viewID-create", "createOwner", 1)
+	created := insertViews[0]
+	if err := repo.CreateSavedView(created); err != nil {
+		t.Fatalf("Failed to create view: %+v", err)
+	}
+
+	// Update the record
+	updated := insertViews[0]
+	updated.Query = "updatedQuery"
+	if err := repo.UpdateSavedView(updated); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+
+	// Find by ID
+	var find = model.SavedView{}
+	if err := tx.Where(&model.SavedView{ID: updated.ID}).First(&find).Error; err != nil {
+		t.Fatalf("Failed to find view: %+v", err)
+	}
+
+	// Verify updated equals find
+	if !assert.Equal(t, find, *updated) {
+		t.Errorf("expected %v, but got %v", find, updated)
+	}
+}
+
+func TestSavedViewRepository_DeleteSavedView(t *testing.T) {
+	tx, repo := newTxAndSavedViewRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertViews := createSavedViewTestData(tx, "viewId-delete", "deleteOwner", 1)
+	err := insertSavedViewTestData(tx, insertViews)
+	if err != nil {
+		t.Fatalf("Failed to create SavedView: %+v", err)
+	}
+	deleted := insertViews[0]
+	t.Run("Record will not be deleted if ID is empty", func(t *testing.T) {
+		deletedID := deleted.ID
+		deleted.ID = "" // Clear ID
+		if err := repo.DeleteSavedView(deleted); err != nil {
+			t.Errorf("Failed to delete: %+v", err)
+		}
+		// record must exist
+		result := model.SavedView{}
+		if err := tx.Where(&model.SavedView{ID: deletedID}).Find(&result).Error; err != nil {
+			t.Errorf("Failed to find: %+v", err)
+		}
+		result.ID = deletedID
+		deleted.ID = deletedID // Restore ID
+
+		// Verify not deleted
+		if !assert.Equal(t, *deleted, result) {
+			t.Error("Record is not same")
+		}
+	})
+
+	t.Run("Record will be deleted if ID is set", func(t *testing.T) {
+		if err := repo.DeleteSavedView(deleted); err != nil {
+			t.Errorf("Failed to delete: %+v", err)
+		}
+
+		// Check record will not be found
+		result := model.SavedView{}
+		err := tx.Where(&model.SavedView{ID: deleted.ID}).First(&result).Error
+		if !orm.IsRecordNotFoundError(err) {
+			t.Errorf("Record must be deleted, but got: %+v", err)
+		}
+	})
+}
+
+// Test for CreateSavedViews, UpdateSavedViews, DeleteSavedViews are ommitted,
+// because that they are called internally in each single version
+
+////
+/// Optimistic lock test (if version lock supported)
+//
+func TestSavedViewRepository_UpdateSavedViewOptimisticCheck(t *testing.T) {
+	tx1, _ := newTxAndSavedViewRepository()
+	tx2, repo2 := newTxAndSavedViewRepository()
+	tx3, repo3 := newTxAndSavedViewRepository()
+	defer tx1.Rollback()
+	defer tx2.Rollback()
+	defer tx3.Rollback()
+
+	// Create and commit 1 record in tx1
+	insertViews := createSavedViewTestData(tx1, "viewID-optimistic", "", 1)
+	err := insertSavedViewTestData(tx1, insertViews)
+	if err != nil {
+		t.Fatalf("Failed to create view: %+v", err)
+	}
+	if err := tx1.Commit().Error; err != nil {
+		t.Fatalf("Failed to commit data in tx1")
+	}
+	// Get commited data in tx2
+	data := insertViews[0]
+	find, err := repo2.FindFirstSavedView(model.SavedView{ID: data.ID}, []string{})
+	if err != nil {
+		deleteCommitedSavedViewData(t, data)
+	}
+	find.Query = "UpdateInTx2"
+	data.Query = "NotUpdateInTx3"
+	// Find updated in tx2 (Version number incremented!!)
+	err = repo2.UpdateSavedView(&find)
+	if err != nil {
+		deleteCommitedSavedViewData(t, data)
+		t.Fatalf("Failed to update in tx2.")
+	}
+	err = tx2.Commit().Error
+	if err != nil {
+		deleteCommitedSavedViewData(t, data)
+		t.Fatalf("Failed to commit data in tx2")
+	}
+	// Check to not update due to optimistic error
+	if !assert.Error(t, repo3.UpdateSavedView(data)) {
+		deleteCommitedSavedViewData(t, data)
+		t.Fatalf("Not failed to update, No error occurred")
+	}
+	err = tx3.Commit().Error
+	if err != nil {
+		deleteCommitedSavedViewData(t, data)
+		t.Fatalf("Failed to Commit tx3, no affected row")
+	}
+
+	tx4, _ := newTxAndSavedViewRepository()
+	defer tx4.Rollback()
+	var result = model.SavedView{}
+	// Can be found as same as find(tx2)
+	if err := tx4.Where(model.SavedView{ID: find.ID}).Find(&result).Error; err != nil {
+		t.Fatalf("failed to retrieve SavedView: %+v", err)
+	}
+	deleteCommitedSavedViewData(t, data)
+	if !assert.Equal(t, find, result) {
+		t.Errorf("expected %v, but got %v", find, result)
+	}
+}
+
+func deleteCommitedSavedViewData(t *testing.T, data *model.SavedView) {
+	// Try to delete data in another transaction
+	tx, repo := newTxAndSavedViewRepository()
+	defer tx.Rollback()
+	err := repo.DeleteSavedView(data)
+	if err != nil {
+		t.Log("*** Failed to delete test data, please restart test. ***")
+	} else {
+		err := tx.Commit().Error
+		if err != nil {
+			t.Log("*** Failed to delete test data, please restart test. ***")
+		}
+	}
+}
+
+////
+/// Other fuctions' test should be written in below
+//
+func TestSavedViewRepository_UpdateSavedViewToBlank(t *testing.T) {
+	tx, repo := newTxAndSavedViewRepository()
+	defer tx.Rollback()
+
+	insertViews := createSavedViewTestData(tx, "viewID-blank", "blankOwner", 1)
+	view := insertViews[0]
+	view.BoardID = "boardID"
+	view.IsShared = true
+	if err := repo.CreateSavedView(view); err != nil {
+		t.Fatalf("Failed to create view: %+v", err)
+	}
+
+	// Blank fields must be cleared
+	view.Query = ""
+	view.Sort = ""
+	view.BoardID = ""
+	view.IsShared = false
+	if err := repo.UpdateSavedView(view); err != nil {
+		t.Fatalf("Failed to update view: %+v", err)
+	}
+	find, err := repo.FindFirstSavedView(&model.SavedView{ID: view.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find view: %+v", err)
+	}
+	assert.Equal(t, *view, find)
+}
+
+func TestSavedViewRepository_FindVisibleSavedViews(t *testing.T) {
+	tx, repo := newTxAndSavedViewRepository()
+	defer tx.Rollback()
+
+	// Views of owner, private view of other and shared view of other
+	ownViews := createSavedViewTestData(tx, "viewID-visible-own", "visibleOwner", 2)
+	otherViews := createSavedViewTestData(tx, "viewID-visible-other", "otherOwner", 2)
+	otherViews[1].IsShared = true
+	err := insertSavedViewTestData(tx, append(ownViews, otherViews...))
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	views, err := repo.FindVisibleSavedViews("visibleOwner", 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to find views: %+v", err)
+	}
+	ids := []string{}
+	for _, view := range views {
+		ids = append(ids, view.ID)
+	}
+	assert.Equal(t, []string{otherViews[1].ID, ownViews[0].ID, ownViews[1].ID}, ids)
+
+	count, err := repo.CountVisibleSavedViews("visibleOwner")
+	if err != nil {
+		t.Fatalf("Failed to count views: %+v", err)
+	}
+	assert.Equal(t, 3, count)
+
+	// Views of deleted user are deleted
+	if err := repo.DeleteUserSavedViews("visibleOwner"); err != nil {
+		t.Fatalf("Failed to delete views: %+v", err)
+	}
+	count, err = repo.CountSavedViews(&model.SavedView{OwnerUserID: "visibleOwner"})
+	if err != nil {
+		t.Fatalf("Failed to count views: %+v", err)
+	}
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+// searchIndexTable is FTS5 virtual table which indexes names and descriptions of tasks and bodies of comments.
+// It exists only when sqlite supports FTS5, see orm.IsFTS5Supported.
+const searchIndexTable = "search_index"
+
+// searchDocumentsTable maps tasks and comments to rowids of search index.
+// Documents are replaced and removed by rowid, because FTS5 can not look up other columns without scanning.
+const searchDocumentsTable = "search_documents"
+
+// Kinds of documents in search index
+const (
+	SearchKindTask    = "task"
+	SearchKindComment = "comment"
+)
+
+// Markers of matched words in snippet of search hit
+const (
+	SearchHighlightStart = "\x02"
+	SearchHighlightEnd   = "\x03"
+)
+
+// ErrorSearchNotSupported is an error when sqlite does not support FTS5
+var ErrorSearchNotSupported = errors.New("FTS5 is not supported by sqlite, build with tag sqlite_fts5")
+
+// SearchHit is a task or comment matching search words
+type SearchHit struct {
+	Kind     string
+	ID       string // ID of task or comment
+	TaskID   string // ID of task, or task of comment
+	TaskName string
+	Snippet  string  // Text around matched words which are marked by SearchHighlightStart and SearchHighlightEnd
+	Score    float64 // Relevance by bm25, larger is more relevant
+}
+
+// SearchRepository is repository of full-text search index of tasks and comments
+type SearchRepository struct {
+	tx *gorm.DB
+}
+
+// NewSearchRepository returns new instance of SearchRepository
+func NewSearchRepository(tx *gorm.DB) *SearchRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &SearchRepository{
+		tx: tx,
+	}
+}
+
+// IsSearchIndexEnabled checks whether search index exists and sqlite supports FTS5
+func (repo *SearchRepository) IsSearchIndexEnabled() (bool, error) {
+	if !orm.IsFTS5Supported(repo.tx) {
+		return false, nil
+	}
+	var row struct{ Count int }
+	err := repo.tx.Raw("select count(*) as count from sqlite_master where type = 'table' and name = ?", searchIndexTable).
+		Scan(&row).Error
+	return row.Count > 0, err
+}
+
+// SearchSortOrders is the order of search hits, they are ordered by relevance
+var SearchSortOrders = []string{"score desc", "id"}
+
+// SearchDocuments returns tasks and comments which contain all words in text in order of SearchSortOrders.
+// Words are matched as they are, FTS5 query syntax can not be used.
+// Only tasks on boards of projectIDs and their comments are returned, all of them if projectIDs is nil.
+// Hits after keyset of SearchSortOrders are returned if after is specified.
+func (repo *SearchRepository) SearchDocuments(text string, projectIDs []string, after *orm.Keyset, limit int) (result []SearchHit, err error) {
+	where, values := searchWhere(text, projectIDs)
+	hits := "select d.kind, d.id, d.task_id, tasks.name as task_name, " +
+		"snippet(" + searchIndexTable + ", -1, char(2), char(3), '...', 16) as snippet, -" + searchIndexTable + ".rank as score " +
+		"from " + searchJoin + " where " + where
+	afterWhere := "1 = 1"
+	if after != nil {
+		var afterValues []interface{}
+		afterWhere, afterValues = after.Where()
+		values = append(values, afterValues...)
+	}
+	err = repo.tx.Raw("select * from ("+hits+") where "+afterWhere+" order by "+strings.Join(SearchSortOrders, ", ")+" limit ?",
+		append(values, limit)...).Scan(&result).Error
+	return
+}
+
+// CountSearchDocuments returns the number of tasks and comments which contain all words in text on boards of projectIDs
+func (repo *SearchRepository) CountSearchDocuments(text string, projectIDs []string) (count int, err error) {
+	var row struct{ Count int }
+	where, values := searchWhere(text, projectIDs)
+	err = repo.tx.Raw("select count(*) as count from "+searchJoin+" where "+where, values...).Scan(&row).Error
+	return row.Count, err
+}
+
+// searchJoin joins search index with its documents and their tasks
+const searchJoin = searchIndexTable + " join " + searchDocumentsTable + " d on d.doc_id = " + searchIndexTable + ".rowid" +
+	" join tasks on tasks.id = d.task_id"
+
+// searchWhere returns condition of search words and projects
+func searchWhere(text string, projectIDs []string) (string, []interface{}) {
+	where := searchIndexTable + " match ?"
+	values := []interface{}{searchMatchQuery(text)}
+	if projectIDs != nil {
+		where += " and tasks.board_id in (select id from boards where project_id in (?))"
+		values = append(values, projectIDs)
+	}
+	return where, values
+}
+
+// RebuildSearchIndex recreates search index from all tasks and comments
+func (repo *SearchRepository) RebuildSearchIndex() error {
+	if !orm.IsFTS5Supported(repo.tx) {
+		return ErrorSearchNotSupported
+	}
+	statements := []string{
+		"drop table if exists " + searchIndexTable,
+		"drop table if exists " + searchDocumentsTable,
+		"create table " + searchDocumentsTable +
+			" (doc_id integer primary key, kind varchar(16) not null, id varchar(32) not null, task_id varchar(32) not null)",
+		"create unique index idx_search_documents_kind_id on " + searchDocumentsTable + " (kind, id)",
+		"create index idx_search_documents_task_id on " + searchDocumentsTable + " (task_id)",
+		"create virtual table " + searchIndexTable + " using fts5(name, body, tokenize = 'unicode61')",
+	}
+	for _, statement := range statements {
+		if err := repo.tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	for _, selectDocuments := range []string{selectTaskDocuments, selectCommentDocuments} {
+		if err := repo.insertDocuments(selectDocuments); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Queries of documents which have kind, id, task_id, name and body
+const (
+	selectTaskDocuments    = "select '" + SearchKindTask + "' as kind, id, id as task_id, name, description as body from tasks"
+	selectCommentDocuments = "select '" + SearchKindComment + "' as kind, id, task_id, '' as name, body from comments"
+)
+
+// indexTask adds or replaces task in search index by its current name and description
+func (repo *SearchRepository) indexTask(taskID string) error {
+	return repo.indexDocument(SearchKindTask, taskID, selectTaskDocuments+" where id = ?")
+}
+
+// indexComment adds or replaces comment in search index by its current body
+func (repo *SearchRepository) indexComment(commentID string) error {
+	return repo.indexDocument(SearchKindComment, commentID, selectCommentDocuments+" where id = ?")
+}
+
+func (repo *SearchRepository) indexDocument(kind, id, selectDocument string) error {
+	enabled, err := repo.IsSearchIndexEnabled()
+	if err != nil || !enabled {
+		return err
+	}
+	err = repo.tx.Exec("delete from "+searchIndexTable+" where rowid in "+
+		"(select doc_id from "+searchDocumentsTable+" where kind = ? and id = ?)", kind, id).Error
+	if err != nil {
+		return err
+	}
+	return repo.insertDocuments(selectDocument, id)
+}
+
+// insertDocuments adds documents to search index, rowid is assigned when document is indexed first
+func (repo *SearchRepository) insertDocuments(selectDocuments string, values ...interface{}) error {
+	err := repo.tx.Exec("insert or ignore into "+searchDocumentsTable+" (kind, id, task_id) "+
+		"select kind, id, task_id from ("+selectDocuments+")", values...).Error
+	if err != nil {
+		return err
+	}
+	return repo.tx.Exec("insert into "+searchIndexTable+" (rowid, name, body) "+
+		"select d.doc_id, s.name, s.body from ("+selectDocuments+") s "+
+		"join "+searchDocumentsTable+" d on d.kind = s.kind and d.id = s.id", values...).Error
+}
+
+// removeTask removes task and its comments from search index
+func (repo *SearchRepository) removeTask(taskID string) error {
+	return repo.removeDocuments("task_id = ?", taskID)
+}
+
+// removeComment removes comment from search index
+func (repo *SearchRepository) removeComment(commentID string) error {
+	return repo.removeDocuments("kind = ? and id = ?", SearchKindComment, commentID)
+}
+
+// removeTaskComments removes all comments of task from search index
+func (repo *SearchRepository) removeTaskComments(taskID string) error {
+	return repo.removeDocuments("kind = ? and task_id = ?", SearchKindComment, taskID)
+}
+
+func (repo *SearchRepository) removeDocuments(where string, values ...interface{}) error {
+	enabled, err := repo.IsSearchIndexEnabled()
+	if err != nil || !enabled {
+		return err
+	}
+	err = repo.tx.Exec("delete from "+searchIndexTable+" where rowid in "+
+		"(select doc_id from "+searchDocumentsTable+" where "+where+")", values...).Error
+	if err != nil {
+		return err
+	}
+	return repo.tx.Exec("delete from "+searchDocumentsTable+" where "+where, values...).Error
+}
+
+// searchMatchQuery converts words to FTS5 query which matches documents having all of them.
+// Each word is quoted not to be parsed as FTS5 syntax.
+func searchMatchQuery(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = `"` + strings.Replace(word, `"`, `""`, -1) + `"`
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndSearchRepository(t *testing.T) (tx *gorm.DB, repo *SearchRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewSearchRepository(tx)
+	enabled, err := repo.IsSearchIndexEnabled()
+	if err != nil {
+		t.Fatalf("Failed to check search index: %+v", err)
+	}
+	if !enabled {
+		tx.Rollback()
+		t.Skip("Search index is not enabled, execute test with build tag sqlite_fts5")
+	}
+	return
+}
+
+func searchHitIDs(hits []SearchHit) []string {
+	ids := []string{}
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+////
+/// Repository specific functions' test
+//
+func TestSearchRepository_SearchDocuments(t *testing.T) {
+	tx, repo := newTxAndSearchRepository(t)
+	defer tx.Rollback()
+
+	taskRepo := NewTaskRepository(tx)
+	commentRepo := NewCommentRepository(tx)
+	tasks := createTaskTestData(tx, "taskID-search", "searchDescription", 3)
+	tasks[0].Name = "Fix login bug"
+	tasks[1].Description = "The login page shows a bug when password is empty"
+	tasks[2].Description = "Nothing related"
+	if err := taskRepo.CreateTasks(tasks); err != nil {
+		t.Fatalf("Failed to create tasks: %+v", err)
+	}
+	comment := model.NewComment(tasks[2].ID, "authorUserID", "Login again after the bug is fixed", time.Now().UTC())
+	if err := commentRepo.CreateComment(comment); err != nil {
+		t.Fatalf("Failed to create comment: %+v", err)
+	}
+
+	t.Run("Tasks and comments are found", func(t *testing.T) {
+		hits, err := repo.SearchDocuments("login bug", nil, nil, orm.NoLimit)
+		if err != nil {
+			t.Fatalf("Failed to search: %+v", err)
+		}
+		assert.ElementsMatch(t, []string{tasks[0].ID, tasks[1].ID, comment.ID}, searchHitIDs(hits))
+		for _, hit := range hits {
+			if hit.ID == comment.ID {
+				assert.Equal(t, SearchKindComment, hit.Kind)
+				assert.Equal(t, tasks[2].ID, hit.TaskID)
+				assert.Equal(t, tasks[2].Name, hit.TaskName)
+				assert.Contains(t, hit.Snippet, SearchHighlightStart+"Login"+SearchHighlightEnd)
+			}
+		}
+		count, err := repo.CountSearchDocuments("login bug", nil)
+		if err != nil {
+			t.Fatalf("Failed to count: %+v", err)
+		}
+		assert.Equal(t, 3, count)
+
+		// Hits are paged after keyset of the last hit
+		pagedIDs := []string{}
+		var after *orm.Keyset
+		for {
+			page, err := repo.SearchDocuments("login bug", nil, after, 1)
+			if err != nil {
+				t.Fatalf("Failed to search: %+v", err)
+			}
+			if len(page) == 0 {
+				break
+			}
+			pagedIDs = append(pagedIDs, searchHitIDs(page)...)
+			if after, err = orm.NewKeyset(page[0], SearchSortOrders); err != nil {
+				t.Fatalf("Failed to create keyset: %+v", err)
+			}
+		}
+		assert.Equal(t, searchHitIDs(hits), pagedIDs)
+	})
+	t.Run("Index follows update and delete", func(t *testing.T) {
+		tasks[0].Name = "Fix logout"
+		if err := taskRepo.UpdateTask(tasks[0]); err != nil {
+			t.Fatalf("Failed to update task: %+v", err)
+		}
+		if err := taskRepo.DeleteTask(tasks[2]); err != nil {
+			t.Fatalf("Failed to delete task: %+v", err)
+		}
+		hits, err := repo.SearchDocuments(`login "bug`, nil, nil, orm.NoLimit)
+		if err != nil {
+			t.Fatalf("Failed to search: %+v", err)
+		}
+		assert.Equal(t, []string{tasks[1].ID}, searchHitIDs(hits))
+	})
+	t.Run("Index is rebuilt", func(t *testing.T) {
+		if err := repo.RebuildSearchIndex(); err != nil {
+			t.Fatalf("Failed to rebuild search index: %+v", err)
+		}
+		hits, err := repo.SearchDocuments("logout", nil, nil, orm.NoLimit)
+		if err != nil {
+			t.Fatalf("Failed to search: %+v", err)
+		}
+		assert.Equal(t, []string{tasks[0].ID}, searchHitIDs(hits))
+	})
+}
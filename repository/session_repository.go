@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"sync"
+	"taskboard/model"
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+var lockSession = &sync.Mutex{}
+
+// SessionRepository is repository of session table
+type SessionRepository struct {
+	tx *gorm.DB
+}
+
+// NewSessionRepository returns new instance of SessionRepository
+func NewSessionRepository(tx *gorm.DB) *SessionRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &SessionRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstSession returns first Session matching with specified condition
+func (repo *SessionRepository) FindFirstSession(condition interface{}, sortOrders []string) (result model.Session, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindSessions returns Sessions matching with specified condition
+func (repo *SessionRepository) FindSessions(condition interface{}, offset int, limit int, sortOrders []string) (result []model.Session, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, session := range sortOrders {
+		query = query.Order(session)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountSessions returns the number of Sessions matching specfied condition
+func (repo *SessionRepository) CountSessions(condition interface{}) (count int, err error) {
+	var sessions []model.Session
+	err = repo.tx.Where(condition).Find(&sessions).Count(&count).Error
+	return
+}
+
+// CreateSession inserts new Session record
+func (repo *SessionRepository) CreateSession(session *model.Session) error {
+	return repo.CreateSessions([]*model.Session{session})
+}
+
+// UpdateSession updates Session record
+func (repo *SessionRepository) UpdateSession(session *model.Session) error {
+	return repo.UpdateSessions([]*model.Session{session})
+}
+
+// DeleteSession deletes Session record
+func (repo *SessionRepository) DeleteSession(session *model.Session) error {
+	return repo.DeleteSessions([]*model.Session{session})
+}
+
+// CreateSessions inserts new Session records.
+func (repo *SessionRepository) CreateSessions(sessions []*model.Session) (err error) {
+	for _, session := range sessions {
+		err = repo.tx.Create(session).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateSessions updates session records
+func (repo *SessionRepository) UpdateSessions(sessions []*model.Session) (err error) {
+	lockSession.Lock()
+	defer lockSession.Unlock()
+
+	for _, session := range sessions {
+		oldVersion := session.Version
+		session.Version++
+		db := repo.tx.Model(&model.Session{}).Where("version = ?", oldVersion).Updates(session)
+		count := db.RowsAffected
+		err = db.Error
+		// return ErrorRecordNotFoud as optimistic lock error
+		if err == nil && count == 0 {
+			return orm.ErrorRecordNotFound
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteSessions deletes Session records
+func (repo *SessionRepository) DeleteSessions(sessions []*model.Session) (err error) {
+	for _, session := range sessions {
+		if session.ID == "" {
+			continue // To avoid deleting all due to gorm warning, continue here.
+		}
+		err = repo.tx.Delete(session).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// RevokeUserSessions revokes all active sessions of specified user
+func (repo *SessionRepository) RevokeUserSessions(userID string) (err error) {
+	lockSession.Lock()
+	defer lockSession.Unlock()
+
+	return repo.tx.Model(&model.Session{}).Where("user_id = ? and is_revoked = ?", userID, false).
+		Updates(map[string]interface{}{
+			"is_revoked": true,
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+}
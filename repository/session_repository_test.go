@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"fmt"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndSessionRepository() (tx *gorm.DB, repo *SessionRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewSessionRepository(tx)
+	return
+}
+
+func createSessionTestData(tx *gorm.DB, idFormat string, findIdentify string, count int) []*model.Session {
+	result := make([]*model.Session, 0, count)
+	for i := 0; i < count; i++ {
+		session := model.NewSession(
+			findIdentify,
+			time.Now().UTC(),
+		)
+		session.ID = fmt.Sprintf("%s-%03d", idFormat, i)
+		result = append(result, session)
+	}
+	return result
+}
+
+func insertSessionTestData(tx *gorm.DB, sessions []*model.Session) (err error) {
+	for _, session := range sessions {
+		err = tx.Create(session).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+////
+/// Common repository functions' test
+//
+func TestSessionRepository_FindFirstSession(t *testing.T) {
+	tx, repo := newTxAndSessionRepository()
+	defer tx.Rollback()
+
+	firstSessions := createSessionTestData(tx, "sessionID-find", "findUserID", 5)
+	secondSessions := createSessionTestData(tx, "sessionID-not-find", "notFindUserID", 4)
+	insertSessions := append(firstSessions, secondSessions...)
+	err := insertSessionTestData(tx, insertSessions)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+	// Sort by id
+	session, err := repo.FindFirstSession(&model.Session{UserID: "findUserID"}, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find first: %+v", err)
+	}
+	expected := "sessionID-find-000"
+	if session.ID != expected {
+		t.Errorf("expected session ID is %s, but got %s", expected, session.ID)
+	}
+}
+
+func TestSessionRepository_FindSessions(t *testing.T) {
+	tx, repo := newTxAndSessionRepository()
+	defer tx.Rollback()
+
+	firstSessions := createSessionTestData(tx, "sessionID-find", "findUserID", 5)
+	secondSessions := createSessionTestData(tx, "sessionID-not-find", "notFindUserID", 4)
+	insertSessions := append(firstSessions, secondSessions...)
+	err := insertSessionTestData(tx, insertSessions)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	// Get 3 data from 2nd(index=1) position
+	const (
+		offset = 1
+		limit  = 3
+	)
+	sessions, err := repo.FindSessions(&model.Session{UserID: "findUserID"}, offset, limit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find: %+v", err)
+	}
+
+	// Length of data must be limit
+	if len(sessions) != limit {
+		t.Errorf("Expected result size = %d, but got %d", limit, len(sessions))
+		return
+	}
+	// Head must be 001
+	head := sessions[0]
+	headExpected := "sessionID-find-001"
+	if head.ID != headExpected {
+		t.Errorf("head ID must be %s, but got %s", headExpected, head.ID)
+	}
+	// Tail must be 003
+	tail := sessions[len(sessions)-1]
+	tailExpected := "sessionID-find-003"
+	if tail.ID != tailExpected {
+		t.Errorf("tail ID must be %s, but got %s", tailExpected, tail.ID)
+	}
+}
+
+func TestAddressRepository_CountSessions(t *testing.T) {
+	tx, repo := newTxAndSessionRepository()
+	defer tx.Rollback()
+
+	expected := 5
+	firstSessions := createSessionTestData(tx, "sessionID-find", "findUserID", 5)
+	secondSessions := createSessionTestData(tx, "sessionID-not-find", "notFindUserID", 4)
+	insertSessions := append(firstSessions, secondSessions...)
+	err := insertSessionTestData(tx, insertSessions)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	count, err := repo.CountSessions(&model.Session{UserID: "findUserID"})
+	if err != nil {
+		t.Fatalf("failed to count Session: %+v", err)
+	}
+	if expected != count {
+		t.Errorf("expected %d records, but got %d record", expected, count)
+	}
+}
+
+func TestSessionRepository_CreateSession(t *testing.T) {
+	tx, repo := newTxAndSessionRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertSessions := createSessionTestData(tx, "sessionID-create", "createUserID", 1)
+	created := insertSessions[0]
+	if err := repo.CreateSession(created); err != nil {
+		t.Fatalf("Failed to create session: %+v", err)
+	}
+
+	// Find by ID
+	var find = model.Session{}
+	if err := tx.Where(&model.Session{ID: created.ID}).First(&find).Error; err != nil {
+		t.Fatalf("Failed to find session: %+v", err)
+	}
+	// Verify created equals find
+	if !assert.Equal(t, find, *created) {
+		t.Errorf("expected: %+v, but got %+v", created.ID, find.ID)
+	}
+}
+
+func TestSessionRepository_UpdateSession(t *testing.T) {
+	tx, repo := newTxAndSessionRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertSessions := createSessionTestData(tx, "sessionID-create", "createUserID", 1)
+	created := insertSessions[0]
+	if err := repo.CreateSession(created); err != nil {
+		t.Fatalf("Failed to create session: %+v", err)
+	}
+
+	// Update the record
+	updated := insertSessions[0]
+	updated.UserID = "updatedUserID"
+	if err := repo.UpdateSession(updated); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+
+	// Find by ID
+	var find = model.Session{}
+	if err := tx.Where(&model.Session{ID: updated.ID}).First(&find).Error; err != nil {
+		t.Fatalf("Failed to find session: %+v", err)
+	}
+
+	// Verify updated equals find
+	if !assert.Equal(t, find, *updated) {
+		t.Errorf("expected %v, but got %v", find, updated)
+	}
+}
+
+func TestSessionRepository_DeleteSession(t *testing.T) {
+	tx, repo := newTxAndSessionRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertSessions := createSessionTestData(tx, "sessionId-delete", "deleteUserID", 1)
+	err := insertSessionTestData(tx, insertSessions)
+	if err != nil {
+		t.Fatalf("Failed to create Session: %+v", err)
+	}
+	deleted := insertSessions[0]
+	t.Run("Record will not be deleted if ID is empty", func(t *testing.T) {
+		deletedID := deleted.ID
+		deleted.ID = "" // Clear ID
+		if err := repo.DeleteSession(deleted); err != nil {
+			t.Errorf("Failed to delete: %+v", err)
+		}
+		// record must exist
+		result := model.Session{}
+		if err := tx.Where(&model.Session{ID: deletedID}).Find(&result).Error; err != nil {
+			t.Errorf("Failed to find: %+v", err)
+		}
+		result.ID = deletedID
+		deleted.ID = deletedID // Restore ID
+
+		// Verify not deleted
+		if !assert.Equal(t, *deleted, result) {
+			t.Error("Record is not same")
+		}
+	})
+
+	t.Run("Record will be deleted if ID is set", func(t *testing.T) {
+		if err := repo.DeleteSession(deleted); err != nil {
+			t.Errorf("Failed to delete: %+v", err)
+		}
+
+		// Check record will not be found
+		result := model.Session{}
+		err := tx.Where(&model.Session{ID: deleted.ID}).First(&result).Error
+		if !orm.IsRecordNotFoundError(err) {
+			t.Errorf("Record must be deleted, but got: %+v", err)
+		}
+	})
+}
+
+// Test for CreateSessions, UpdateSessions, DeleteSessions are ommitted,
+// because that they are called internally in each single version
+
+////
+/// Optimistic lock test (if version lock supported)
+//
+func TestSessionRepository_UpdateSessionOptimisticCheck(t *testing.T) {
+	tx1, _ := newTxAndSessionRepository()
+	tx2, repo2 := newTxAndSessionRepository()
+	tx3, repo3 := newTxAndSessionRepository()
+	defer tx1.Rollback()
+	defer tx2.Rollback()
+	defer tx3.Rollback()
+
+	// Create and commit 1 record in tx1
+	insertSessions := createSessionTestData(tx1, "sessionID-optimistic", "", 1)
+	err := insertSessionTestData(tx1, insertSessions)
+	if err != nil {
+		t.Fatalf("Failed to create session: %+v", err)
+	}
+	if err := tx1.Commit().Error; err != nil {
+		t.Fatalf("Failed to commit data in tx1")
+	}
+	// Get commited data in tx2
+	data := insertSessions[0]
+	find, err := repo2.FindFirstSession(model.Session{ID: data.ID}, []string{})
+	if err != nil {
+		deleteCommitedSessionData(t, data)
+	}
+	find.UserID = "UpdateInTx2"
+	data.UserID = "NotUpdateInTx3"
+	// Find updated in tx2 (Version number incremented!!)
+	err = repo2.UpdateSession(&find)
+	if err != nil {
+		deleteCommitedSessionData(t, data)
+		t.Fatalf("Failed to update in tx2.")
+	}
+	err = tx2.Commit().Error
+	if err != nil {
+		deleteCommitedSessionData(t, data)
+		t.Fatalf("Failed to commit data in tx2")
+	}
+	// Check to not update due to optimistic error
+	if !assert.Error(t, repo3.UpdateSession(data)) {
+		deleteCommitedSessionData(t, data)
+		t.Fatalf("Not failed to update, No error occurred")
+	}
+	err = tx3.Commit().Error
+	if err != nil {
+		deleteCommitedSessionData(t, data)
+		t.Fatalf("Failed to Commit tx3, no affected row")
+	}
+
+	tx4, _ := newTxAndSessionRepository()
+	defer tx4.Rollback()
+	var result = model.Session{}
+	// Can be found as same as find(tx2)
+	if err := tx4.Where(model.Session{ID: find.ID}).Find(&result).Error; err != nil {
+		t.Fatalf("failed to retrieve Session: %+v", err)
+	}
+	deleteCommitedSessionData(t, data)
+	if !assert.Equal(t, find, result) {
+		t.Errorf("expected %v, but got %v", find, result)
+	}
+}
+
+func deleteCommitedSessionData(t *testing.T, data *model.Session) {
+	// Try to delete data in another transaction
+	tx, repo := newTxAndSessionRepository()
+	defer tx.Rollback()
+	err := repo.DeleteSession(data)
+	if err != nil {
+		t.Log("*** Failed to delete test data, please restart test. ***")
+	} else {
+		err := tx.Commit().Error
+		if err != nil {
+			t.Log("*** Failed to delete test data, please restart test. ***")
+		}
+	}
+}
+
+////
+/// Other fuctions' test should be written in below
+//
+func TestSessionRepository_RevokeUserSessions(t *testing.T) {
+	tx, repo := newTxAndSessionRepository()
+	defer tx.Rollback()
+
+	// Create 3 + 2 records
+	firstSessions := createSessionTestData(tx, "sessionID-revoke", "revokeUserID", 3)
+	secondSessions := createSessionTestData(tx, "sessionID-not-revoke", "notRevokeUserID", 2)
+	insertSessions := append(firstSessions, secondSessions...)
+	err := insertSessionTestData(tx, insertSessions)
+	if err != nil {
+		t.Fatalf("Failed to create sessions: %+v", err)
+	}
+	err = repo.RevokeUserSessions("revokeUserID")
+	if err != nil {
+		t.Fatalf("Failed to revoke sessions: %+v", err)
+	}
+	revoked, err := repo.CountSessions(map[string]interface{}{"user_id": "revokeUserID", "is_revoked": true})
+	if err != nil {
+		t.Fatalf("Failed to count sessions: %+v", err)
+	}
+	if !assert.Equal(t, 3, revoked) {
+		t.Errorf("expected: %d, but got %d", 3, revoked)
+	}
+	find, err := repo.FindFirstSession(&model.Session{ID: "sessionID-revoke-000"}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find session: %+v", err)
+	}
+	// Version is incremented by revoking
+	assert.Equal(t, 2, find.Version)
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"taskboard/model"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TaskFilter presents conditions of tasks which can not be expressed by model.Task
+type TaskFilter struct {
+	LabelIDs      []string   // Tasks which have the labels
+	LabelMatchAll bool       // true: tasks must have all of LabelIDs, false: tasks must have one of LabelIDs
+	DueFrom       *time.Time // Tasks whose due date is same or after this
+	DueBefore     *time.Time // Tasks whose due date is before this
+	IsOpenOnly    bool       // Tasks which are not closed
+	Query         *TaskQuery // Tasks matching query of task query language
+	ProjectIDs    []string   // Tasks on boards of the projects, tasks of all projects if nil
+}
+
+// apply adds conditions of filter to query
+func (filter *TaskFilter) apply(query *gorm.DB) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	if len(filter.LabelIDs) > 0 {
+		labelIDs := uniqueStrings(filter.LabelIDs)
+		subQuery := "select task_id from " + query.NewScope(&model.TaskLabel{}).TableName() + " where label_id in (?)"
+		if filter.LabelMatchAll {
+			subQuery += " group by task_id having count(distinct label_id) = ?"
+			query = query.Where("id in ("+subQuery+")", labelIDs, len(labelIDs))
+		} else {
+			query = query.Where("id in ("+subQuery+")", labelIDs)
+		}
+	}
+	if filter.DueFrom != nil {
+		query = query.Where("due_date >= ?", *filter.DueFrom)
+	}
+	if filter.DueBefore != nil {
+		query = query.Where("due_date < ?", *filter.DueBefore)
+	}
+	if filter.IsOpenOnly {
+		query = query.Where("is_closed = ?", false)
+	}
+	if filter.ProjectIDs != nil {
+		query = query.Where("board_id in (select id from "+query.NewScope(&model.Board{}).TableName()+
+			" where project_id in (?))", filter.ProjectIDs)
+	}
+	return filter.Query.apply(query)
+}
+
+func uniqueStrings(values []string) []string {
+	result := make([]string, 0, len(values))
+	exists := map[string]bool{}
+	for _, value := range values {
+		if !exists[value] {
+			exists[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
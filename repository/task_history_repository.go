@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"taskboard/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TaskHistoryRepository is repository of task history table.
+// Histories are immutable, so this has no apis to update and delete.
+type TaskHistoryRepository struct {
+	tx *gorm.DB
+}
+
+// NewTaskHistoryRepository returns new instance of TaskHistoryRepository
+func NewTaskHistoryRepository(tx *gorm.DB) *TaskHistoryRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &TaskHistoryRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstTaskHistory returns first TaskHistory matching with specified condition
+func (repo *TaskHistoryRepository) FindFirstTaskHistory(condition interface{}, sortOrders []string) (result model.TaskHistory, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindTaskHistories returns TaskHistories matching with specified condition
+func (repo *TaskHistoryRepository) FindTaskHistories(condition interface{}, offset int, limit int, sortOrders []string) (result []model.TaskHistory, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountTaskHistories returns the number of TaskHistories matching specfied condition
+func (repo *TaskHistoryRepository) CountTaskHistories(condition interface{}) (count int, err error) {
+	var taskHistories []model.TaskHistory
+	err = repo.tx.Where(condition).Find(&taskHistories).Count(&count).Error
+	return
+}
+
+// CreateTaskHistory inserts new TaskHistory record
+func (repo *TaskHistoryRepository) CreateTaskHistory(taskHistory *model.TaskHistory) error {
+	return repo.CreateTaskHistories([]*model.TaskHistory{taskHistory})
+}
+
+// CreateTaskHistories inserts new TaskHistory records.
+func (repo *TaskHistoryRepository) CreateTaskHistories(taskHistories []*model.TaskHistory) (err error) {
+	for _, taskHistory := range taskHistories {
+		err = repo.tx.Create(taskHistory).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// FindTaskHistoriesAfter returns histories of task changed after specified revision, newest first
+func (repo *TaskHistoryRepository) FindTaskHistoriesAfter(taskID string, revision int) (result []model.TaskHistory, err error) {
+	err = repo.tx.Where("task_id = ? and revision > ?", taskID, revision).
+		Order("revision desc, created_date desc, id desc").Find(&result).Error
+	return
+}
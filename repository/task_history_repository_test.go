@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"fmt"
+	"taskboard/common"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndTaskHistoryRepository() (tx *gorm.DB, repo *TaskHistoryRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewTaskHistoryRepository(tx)
+	return
+}
+
+func createTaskHistoryTestData(tx *gorm.DB, idFormat string, findIdentify string, count int) []*model.TaskHistory {
+	result := make([]*model.TaskHistory, 0, count)
+	for i := 0; i < count; i++ {
+		taskHistory := model.NewTaskHistory(
+			findIdentify,
+			i+1,
+			"actorUserID",
+			model.TaskFieldName,
+			"oldName",
+			"name"+common.GenerateID(),
+			time.Now().UTC(),
+		)
+		taskHistory.ID = fmt.Sprintf("%s-%03d", idFormat, i)
+		result = append(result, taskHistory)
+	}
+	return result
+}
+
+func insertTaskHistoryTestData(tx *gorm.DB, taskHistories []*model.TaskHistory) (err error) {
+	for _, taskHistory := range taskHistories {
+		err = tx.Create(taskHistory).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+////
+/// Common repository functions' test
+//
+func TestTaskHistoryRepository_FindFirstTaskHistory(t *testing.T) {
+	tx, repo := newTxAndTaskHistoryRepository()
+	defer tx.Rollback()
+
+	firstTaskHistories := createTaskHistoryTestData(tx, "taskHistoryID-find", "findTaskID", 5)
+	secondTaskHistories := createTaskHistoryTestData(tx, "taskHistoryID-not-find", "notFindTaskID", 4)
+	insertTaskHistories := append(firstTaskHistories, secondTaskHistories...)
+	err := insertTaskHistoryTestData(tx, insertTaskHistories)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+	// Sort by id
+	taskHistory, err := repo.FindFirstTaskHistory(&model.TaskHistory{TaskID: "findTaskID"}, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find first: %+v", err)
+	}
+	expected := "taskHistoryID-find-000"
+	if taskHistory.ID != expected {
+		t.Errorf("expected taskHistory ID is %s, but got %s", expected, taskHistory.ID)
+	}
+}
+
+func TestTaskHistoryRepository_FindTaskHistories(t *testing.T) {
+	tx, repo := newTxAndTaskHistoryRepository()
+	defer tx.Rollback()
+
+	firstTaskHistories := createTaskHistoryTestData(tx, "taskHistoryID-find", "findTaskID", 5)
+	secondTaskHistories := createTaskHistoryTestData(tx, "taskHistoryID-not-find", "notFindTaskID", 4)
+	insertTaskHistories := append(firstTaskHistories, secondTaskHistories...)
+	err := insertTaskHistoryTestData(tx, insertTaskHistories)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	// Get 3 data from 2nd(index=1) position
+	const (
+		offset = 1
+		limit  = 3
+	)
+	taskHistories, err := repo.FindTaskHistories(&model.TaskHistory{TaskID: "findTaskID"}, offset, limit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find: %+v", err)
+	}
+
+	// Length of data must be limit
+	if len(taskHistories) != limit {
+		t.Errorf("Expected result size = %d, but got %d", limit, len(taskHistories))
+		return
+	}
+	// Head must be 001
+	head := taskHistories[0]
+	headExpected := "taskHistoryID-find-001"
+	if head.ID != headExpected {
+		t.Errorf("head ID must be %s, but got %s", headExpected, head.ID)
+	}
+	// Tail must be 003
+	tail := taskHistories[len(taskHistories)-1]
+	tailExpected := "taskHistoryID-find-003"
+	if tail.ID != tailExpected {
+		t.Errorf("tail ID must be %s, but got %s", tailExpected, tail.ID)
+	}
+}
+
+func TestAddressRepository_CountTaskHistories(t *testing.T) {
+	tx, repo := newTxAndTaskHistoryRepository()
+	defer tx.Rollback()
+
+	expected := 5
+	firstTaskHistories := createTaskHistoryTestData(tx, "taskHistoryID-find", "findTaskID", 5)
+	secondTaskHistories := createTaskHistoryTestData(tx, "taskHistoryID-not-find", "notFindTaskID", 4)
+	insertTaskHistories := append(firstTaskHistories, secondTaskHistories...)
+	err := insertTaskHistoryTestData(tx, insertTaskHistories)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	count, err := repo.CountTaskHistories(&model.TaskHistory{TaskID: "findTaskID"})
+	if err != nil {
+		t.Fatalf("failed to count TaskHistory: %+v", err)
+	}
+	if expected != count {
+		t.Errorf("expected %d records, but got %d record", expected, count)
+	}
+}
+
+func TestTaskHistoryRepository_CreateTaskHistory(t *testing.T) {
+	tx, repo := newTxAndTaskHistoryRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertTaskHistories := createTaskHistoryTestData(tx, "taskHistoryID-create", "createTaskID", 1)
+	created := insertTaskHistories[0]
+	if err := repo.CreateTaskHistory(created); err != nil {
+		t.Fatalf("Failed to create taskHistory: %+v", err)
+	}
+
+	// Find by ID
+	var find = model.TaskHistory{}
+	if err := tx.Where(&model.TaskHistory{ID: created.ID}).First(&find).Error; err != nil {
+		t.Fatalf("Failed to find taskHistory: %+v", err)
+	}
+	// Verify created equals find
+	if !assert.Equal(t, find, *created) {
+		t.Errorf("expected: %+v, but got %+v", created.ID, find.ID)
+	}
+}
+
+////
+/// Other fuctions' test should be written in below
+//
+func TestTaskHistoryRepository_FindTaskHistoriesAfter(t *testing.T) {
+	tx, repo := newTxAndTaskHistoryRepository()
+	defer tx.Rollback()
+
+	// Create 4 records, whose revisions are 1 to 4
+	insertTaskHistories := createTaskHistoryTestData(tx, "taskHistoryID-after", "afterTaskID", 4)
+	err := insertTaskHistoryTestData(tx, insertTaskHistories)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	taskHistories, err := repo.FindTaskHistoriesAfter("afterTaskID", 2)
+	if err != nil {
+		t.Fatalf("Failed to find task histories: %+v", err)
+	}
+	// Newest first
+	if assert.Len(t, taskHistories, 2) {
+		assert.Equal(t, 4, taskHistories[0].Revision)
+		assert.Equal(t, 3, taskHistories[1].Revision)
+	}
+}
@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"taskboard/model"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Fields of task query language
+const (
+	TaskQueryFieldAssignee = "assignee" // Name of assignee, "me" or "none"
+	TaskQueryFieldBoard    = "board"    // Name of board
+	TaskQueryFieldLabel    = "label"    // Name of label
+	TaskQueryFieldClosed   = "closed"   // true or false
+	TaskQueryFieldEstimate = "estimate" // Number, can be compared
+	TaskQueryFieldDue      = "due"      // Date as YYYY-MM-DD or "none", can be compared
+)
+
+// Values of task query language which have special meanings
+const (
+	TaskQueryValueMe   = "me"
+	TaskQueryValueNone = "none"
+)
+
+// taskQueryDateFormat is format of date in task query, which is interpreted in UTC
+const taskQueryDateFormat = "2006-01-02"
+
+// taskQueryOperators maps operators of task query to sql, ":" is same as "="
+var taskQueryOperators = map[string]string{
+	":":  "=",
+	"=":  "=",
+	">":  ">",
+	">=": ">=",
+	"<":  "<",
+	"<=": "<=",
+}
+
+// taskQueryFieldOperators are operators which can be used with each field
+var taskQueryFieldOperators = map[string][]string{
+	TaskQueryFieldAssignee: {":", "="},
+	TaskQueryFieldBoard:    {":", "="},
+	TaskQueryFieldLabel:    {":", "="},
+	TaskQueryFieldClosed:   {":", "="},
+	TaskQueryFieldEstimate: {":", "=", ">", ">=", "<", "<="},
+	TaskQueryFieldDue:      {":", "=", ">", ">=", "<", "<="},
+}
+
+// taskQueryFieldPattern matches a token which starts with a field and an operator
+var taskQueryFieldPattern = regexp.MustCompile(`^([a-zA-Z]+)(>=|<=|:|=|>|<)(.*)$`)
+
+// TaskQuery is a parsed query of task query language such as [assignee:me board:Doing closed:false estimate>=3 "login bug"].
+// Terms are separated by spaces and all of them must be matched.
+// A term without field matches tasks whose name or description contains it.
+// Values including spaces must be quoted by double quotes.
+type TaskQuery struct {
+	terms []taskQueryTerm
+}
+
+// taskQueryTerm is a term of task query whose value is parsed
+type taskQueryTerm struct {
+	field    string // Empty for text term
+	operator string // Operator of sql
+	value    interface{}
+}
+
+// taskQueryUserID is ID of user, while string value of assignee is name of user
+type taskQueryUserID string
+
+// TaskQueryError is an error of parsing task query, which points at the invalid token
+type TaskQueryError struct {
+	Position int    // Position of token in query, starts from 1
+	Token    string // Invalid token
+	Message  string
+}
+
+// Error returns message with invalid token and its position
+func (e *TaskQueryError) Error() string {
+	return fmt.Sprintf("%s at position %d: [%s]", e.Message, e.Position, e.Token)
+}
+
+// taskQueryToken is a token separated by spaces
+type taskQueryToken struct {
+	text     string // Text whose quotes are removed
+	raw      string // Text as it is in query
+	quoted   bool   // true if the token starts with quote
+	position int
+}
+
+// ParseTaskQuery parses query of task query language.
+// loginUserID is used for "me", set empty if there is no login user.
+func ParseTaskQuery(text, loginUserID string) (*TaskQuery, error) {
+	tokens, err := tokenizeTaskQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	query := &TaskQuery{terms: make([]taskQueryTerm, 0, len(tokens))}
+	for _, token := range tokens {
+		term, err := parseTaskQueryToken(token, loginUserID)
+		if err != nil {
+			return nil, err
+		}
+		query.terms = append(query.terms, term)
+	}
+	return query, nil
+}
+
+// tokenizeTaskQuery splits query by spaces which are not quoted
+func tokenizeTaskQuery(text string) ([]taskQueryToken, error) {
+	tokens := []taskQueryToken{}
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if runes[i] == ' ' || runes[i] == '\t' {
+			i++
+			continue
+		}
+		start := i
+		builder := strings.Builder{}
+		quoteStart := -1
+		for ; i < len(runes); i++ {
+			r := runes[i]
+			if r == '"' {
+				if quoteStart < 0 {
+					quoteStart = i
+				} else {
+					quoteStart = -1
+				}
+				continue
+			}
+			if quoteStart < 0 && (r == ' ' || r == '\t') {
+				break
+			}
+			builder.WriteRune(r)
+		}
+		if quoteStart >= 0 {
+			return nil, &TaskQueryError{Position: quoteStart + 1, Token: string(runes[start:]), Message: "Quote is not closed"}
+		}
+		tokens = append(tokens, taskQueryToken{
+			text:     builder.String(),
+			raw:      string(runes[start:i]),
+			quoted:   runes[start] == '"',
+			position: start + 1,
+		})
+	}
+	return tokens, nil
+}
+
+// parseTaskQueryToken parses a token to a term, token is a text term if it does not start with field
+func parseTaskQueryToken(token taskQueryToken, loginUserID string) (taskQueryTerm, error) {
+	matches := taskQueryFieldPattern.FindStringSubmatch(token.text)
+	if token.quoted || matches == nil {
+		return taskQueryTerm{value: token.text}, nil
+	}
+	field, operator, value := strings.ToLower(matches[1]), matches[2], matches[3]
+	newError := func(format string, values ...interface{}) error {
+		return &TaskQueryError{Position: token.position, Token: token.raw, Message: fmt.Sprintf(format, values...)}
+	}
+	operators, ok := taskQueryFieldOperators[field]
+	if !ok {
+		return taskQueryTerm{}, newError("Field [%s] is unknown, must be one of [%s]", field, strings.Join([]string{
+			TaskQueryFieldAssignee, TaskQueryFieldBoard, TaskQueryFieldLabel,
+			TaskQueryFieldClosed, TaskQueryFieldEstimate, TaskQueryFieldDue}, ","))
+	}
+	if !containsString(operators, operator) {
+		return taskQueryTerm{}, newError("Operator [%s] can not be used for field [%s], must be one of [%s]",
+			operator, field, strings.Join(operators, " "))
+	}
+	if value == "" {
+		return taskQueryTerm{}, newError("Value of field [%s] is empty", field)
+	}
+	term := taskQueryTerm{field: field, operator: taskQueryOperators[operator], value: value}
+	switch field {
+	case TaskQueryFieldAssignee:
+		if value == TaskQueryValueMe {
+			if loginUserID == "" {
+				return taskQueryTerm{}, newError("Value [%s] requires login", TaskQueryValueMe)
+			}
+			term.value = taskQueryUserID(loginUserID)
+		} else if value == TaskQueryValueNone {
+			term.value = nil
+		}
+	case TaskQueryFieldClosed:
+		isClosed, err := strconv.ParseBool(value)
+		if err != nil {
+			return taskQueryTerm{}, newError("Value of field [%s] must be true or false", field)
+		}
+		term.value = isClosed
+	case TaskQueryFieldEstimate:
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return taskQueryTerm{}, newError("Value of field [%s] must be number", field)
+		}
+		term.value = size
+	case TaskQueryFieldDue:
+		if value == TaskQueryValueNone {
+			if term.operator != "=" {
+				return taskQueryTerm{}, newError("Value [%s] can not be compared", TaskQueryValueNone)
+			}
+			term.value = nil
+			break
+		}
+		date, err := time.Parse(taskQueryDateFormat, value)
+		if err != nil {
+			return taskQueryTerm{}, newError("Value of field [%s] must be date as YYYY-MM-DD or [%s]", field, TaskQueryValueNone)
+		}
+		term.value = date
+	}
+	return term, nil
+}
+
+// apply adds conditions of query to db
+func (query *TaskQuery) apply(db *gorm.DB) *gorm.DB {
+	if query == nil {
+		return db
+	}
+	userTable := db.NewScope(&model.User{}).TableName()
+	boardTable := db.NewScope(&model.Board{}).TableName()
+	labelTable := db.NewScope(&model.Label{}).TableName()
+	taskLabelTable := db.NewScope(&model.TaskLabel{}).TableName()
+	for _, term := range query.terms {
+		switch term.field {
+		case "":
+			pattern := "%" + escapeLike(term.value.(string)) + "%"
+			db = db.Where(`name like ? escape '\' or description like ? escape '\'`, pattern, pattern)
+		case TaskQueryFieldAssignee:
+			switch value := term.value.(type) {
+			case nil:
+				db = db.Where("assignee_user_id is null")
+			case taskQueryUserID:
+				db = db.Where("assignee_user_id = ?", string(value))
+			default:
+				db = db.Where("assignee_user_id in (select id from "+userTable+" where name = ?)", value)
+			}
+		case TaskQueryFieldBoard:
+			db = db.Where("board_id in (select id from "+boardTable+" where name = ?)", term.value)
+		case TaskQueryFieldLabel:
+			db = db.Where("id in (select task_id from "+taskLabelTable+
+				" where label_id in (select id from "+labelTable+" where name = ?))", term.value)
+		case TaskQueryFieldClosed:
+			db = db.Where("is_closed = ?", term.value)
+		case TaskQueryFieldEstimate:
+			db = db.Where("estimate_size "+term.operator+" ?", term.value)
+		case TaskQueryFieldDue:
+			db = applyDueTerm(db, term)
+		}
+	}
+	return db
+}
+
+// applyDueTerm adds condition of due date, a date means the whole day
+func applyDueTerm(db *gorm.DB, term taskQueryTerm) *gorm.DB {
+	if term.value == nil {
+		return db.Where("due_date is null")
+	}
+	from := term.value.(time.Time)
+	to := from.AddDate(0, 0, 1)
+	switch term.operator {
+	case "=":
+		return db.Where("due_date >= ? and due_date < ?", from, to)
+	case ">":
+		return db.Where("due_date >= ?", to)
+	case ">=":
+		return db.Where("due_date >= ?", from)
+	case "<":
+		return db.Where("due_date < ?", from)
+	default: // "<="
+		return db.Where("due_date < ?", to)
+	}
+}
+
+// escapeLike escapes wildcards of like by "\"
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
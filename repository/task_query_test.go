@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTaskQuery(t *testing.T) {
+	dueDate := time.Date(2019, 1, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query    string
+		expected []taskQueryTerm
+	}{
+		// Longer operators take precedence over their prefixes
+		{`estimate>=3`, []taskQueryTerm{{field: "estimate", operator: ">=", value: 3}}},
+		{`estimate>3`, []taskQueryTerm{{field: "estimate", operator: ">", value: 3}}},
+		{`due<=2019-01-10`, []taskQueryTerm{{field: "due", operator: "<=", value: dueDate}}},
+		{`estimate:3`, []taskQueryTerm{{field: "estimate", operator: "=", value: 3}}},
+		// Field is case insensitive, and the first operator separates field and value
+		{`Board:a:b`, []taskQueryTerm{{field: "board", operator: "=", value: "a:b"}}},
+		// Terms are separated by spaces and tabs, all of them are kept in order
+		{" closed:true\tbug  label:x ", []taskQueryTerm{
+			{field: "closed", operator: "=", value: true},
+			{value: "bug"},
+			{field: "label", operator: "=", value: "x"},
+		}},
+		// Quotes keep spaces, quoted token is a text term even if it looks like a field
+		{`"login bug"`, []taskQueryTerm{{value: "login bug"}}},
+		{`board:"In Progress"`, []taskQueryTerm{{field: "board", operator: "=", value: "In Progress"}}},
+		{`"assignee:me"`, []taskQueryTerm{{value: "assignee:me"}}},
+		{`log"in bu"g`, []taskQueryTerm{{value: "login bug"}}},
+		// Unknown word without operator, and wildcards of like are text
+		{`50%_done`, []taskQueryTerm{{value: "50%_done"}}},
+		// Special values
+		{`assignee:me`, []taskQueryTerm{{field: "assignee", operator: "=", value: taskQueryUserID("loginUserID")}}},
+		{`assignee:none`, []taskQueryTerm{{field: "assignee", operator: "=", value: nil}}},
+		{`assignee:Me`, []taskQueryTerm{{field: "assignee", operator: "=", value: "Me"}}},
+		{`due:none`, []taskQueryTerm{{field: "due", operator: "=", value: nil}}},
+		{``, []taskQueryTerm{}},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			query, err := ParseTaskQuery(test.query, "loginUserID")
+			if err != nil {
+				t.Fatalf("Failed to parse query: %+v", err)
+			}
+			assert.Equal(t, test.expected, query.terms)
+		})
+	}
+}
+
+func TestParseTaskQuery_Error(t *testing.T) {
+	tests := []struct {
+		query    string
+		position int
+		token    string
+	}{
+		{`closed:false estimate>=x`, 14, "estimate>=x"},
+		{`bug owner:me`, 5, "owner:me"},
+		{`board>Doing`, 1, "board>Doing"},
+		{`assignee:me`, 1, "assignee:me"}, // No login user
+		{`due>=none`, 1, "due>=none"},
+		{`due:2019-1-10`, 1, "due:2019-1-10"},
+		{`closed:yes`, 1, "closed:yes"},
+		{`label:`, 1, "label:"},
+		{`"login bug`, 1, `"login bug`},
+		{"bug\t  label:x  estimate<big", 16, "estimate<big"},
+		{`bug board:"In Progress`, 11, `board:"In Progress`}, // Points at the quote which is not closed
+		{`"In Progress" estimate>=`, 15, "estimate>="},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			_, err := ParseTaskQuery(test.query, "")
+			if qerr, ok := err.(*TaskQueryError); assert.True(t, ok, "TaskQueryError must be returned: %v", err) {
+				assert.Equal(t, test.position, qerr.Position)
+				assert.Equal(t, test.token, qerr.Token)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `50\% done`, escapeLike(`50% done`))
+	assert.Equal(t, `snake\_case`, escapeLike(`snake_case`))
+	assert.Equal(t, `back\\slash\\\%`, escapeLike(`back\slash\%`))
+}
+
+func TestTaskRepository_FindFilteredTasksByQueryAndFilter(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+
+	label := model.NewLabel("filterLabel", "red", time.Now().UTC())
+	if err := tx.Create(label).Error; err != nil {
+		t.Fatalf("Failed to create label: %+v", err)
+	}
+	// Create 4 records whose names have wildcards of like, 1st and 2nd are labeled and 2nd is closed
+	insertTasks := createTaskTestData(tx, "taskID-query-filter", "queryFilterDescription", 4)
+	insertTasks[0].Name = "snake_case 100%"
+	insertTasks[1].Name = "snake_case 100%"
+	insertTasks[1].IsClosed = true
+	insertTasks[2].Name = "snakeXcase 100%"
+	insertTasks[3].Name = "snake_case 1000"
+	err := insertTaskTestData(tx, insertTasks)
+	if err != nil {
+		t.Fatalf("Failed to create tasks: %+v", err)
+	}
+	for _, task := range insertTasks[:2] {
+		if err = NewLabelRepository(tx).CreateTaskLabels(task.ID, []string{label.ID}); err != nil {
+			t.Fatalf("Failed to create task labels: %+v", err)
+		}
+	}
+
+	condition := &model.Task{Description: "queryFilterDescription"}
+	tests := []struct {
+		query    string
+		filter   TaskFilter
+		expected []int // Indexes of insertTasks
+	}{
+		{`snake_case 100%`, TaskFilter{}, []int{0, 1}},
+		{`"e_c"`, TaskFilter{}, []int{0, 1, 3}},
+		{`100%`, TaskFilter{IsOpenOnly: true}, []int{0, 2}},
+		{`snake_case`, TaskFilter{LabelIDs: []string{label.ID}, IsOpenOnly: true}, []int{0}},
+		{`label:filterLabel closed:true`, TaskFilter{}, []int{1}},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			query, err := ParseTaskQuery(test.query, "")
+			if err != nil {
+				t.Fatalf("Failed to parse query: %+v", err)
+			}
+			filter := test.filter
+			filter.Query = query
+			findTasks, err := repo.FindFilteredTasks(condition, &filter, 0, orm.NoLimit, []string{"id"})
+			if err != nil {
+				t.Fatalf("Failed to find tasks: %+v", err)
+			}
+			findIDs := []string{}
+			for _, task := range findTasks {
+				findIDs = append(findIDs, task.ID)
+			}
+			expectedIDs := []string{}
+			for _, index := range test.expected {
+				expectedIDs = append(expectedIDs, insertTasks[index].ID)
+			}
+			assert.Equal(t, expectedIDs, findIDs)
+		})
+	}
+}
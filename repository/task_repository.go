@@ -1,16 +1,12 @@
 package repository
 
 import (
-	"database/sql"
-	"sync"
 	"taskboard/model"
 	"taskboard/orm"
 
 	"github.com/jinzhu/gorm"
 )
 
-var lockTask = &sync.Mutex{}
-
 // TaskRepository is repository of task table
 type TaskRepository struct {
 	tx *gorm.DB
@@ -43,7 +39,12 @@ func (repo *TaskRepository) FindFirstTask(condition interface{}, sortOrders []st
 
 // FindTasks returns Tasks matching with specified condition
 func (repo *TaskRepository) FindTasks(condition interface{}, offset int, limit int, sortOrders []string) (result []model.Task, err error) {
-	query := repo.tx.Where(condition)
+	return repo.FindFilteredTasks(condition, nil, offset, limit, sortOrders)
+}
+
+// FindFilteredTasks returns Tasks matching with specified condition and filter
+func (repo *TaskRepository) FindFilteredTasks(condition interface{}, filter *TaskFilter, offset int, limit int, sortOrders []string) (result []model.Task, err error) {
+	query := filter.apply(repo.tx.Where(condition))
 	if offset >= 0 {
 		query = query.Offset(offset)
 	}
@@ -69,6 +70,12 @@ func (repo *TaskRepository) CountTasks(condition interface{}) (count int, err er
 	return
 }
 
+// CountFilteredTasks returns the number of Tasks matching specfied condition and filter
+func (repo *TaskRepository) CountFilteredTasks(condition interface{}, filter *TaskFilter) (count int, err error) {
+	err = filter.apply(repo.tx.Model(&model.Task{}).Where(condition)).Count(&count).Error
+	return
+}
+
 // CreateTask inserts new Task record
 func (repo *TaskRepository) CreateTask(task *model.Task) error {
 	return repo.CreateTasks([]*model.Task{task})
@@ -85,32 +92,30 @@ func (repo *TaskRepository) DeleteTask(task *model.Task) error {
 }
 
 // CreateTasks inserts new Task records.
+// Tasks whose rank is empty are appended to the end of the board.
 func (repo *TaskRepository) CreateTasks(tasks []*model.Task) (err error) {
-	lockTask.Lock()
-	defer lockTask.Unlock()
-
 	for _, task := range tasks {
-		max := 0
-		if task.BoardID != "" {
-			max, err = repo.MaxTaskDispOrder(&model.Task{BoardID: task.BoardID})
+		if task.Rank == "" {
+			task.Rank, err = lastRank(repo.boardTasks(task.BoardID))
 			if err != nil {
 				return
 			}
 		}
-		task.DispOrder = max + 1
 		err = repo.tx.Create(task).Error
 		if err != nil {
 			return
 		}
+		err = NewSearchRepository(repo.tx).indexTask(task.ID)
+		if err != nil {
+			return
+		}
 	}
 	return
 }
 
-// UpdateTasks updates task records
+// UpdateTasks updates task records.
+// Concurrent updates are rejected by version check and unique rank in board, so they are not serialized here.
 func (repo *TaskRepository) UpdateTasks(tasks []*model.Task) (err error) {
-	lockTask.Lock()
-	defer lockTask.Unlock()
-
 	for _, task := range tasks {
 		oldVersion := task.Version
 		task.Version++
@@ -124,6 +129,23 @@ func (repo *TaskRepository) UpdateTasks(tasks []*model.Task) (err error) {
 		if err != nil {
 			return
 		}
+		// Updates() skips blank fields, so clearable fields are updated explicitly
+		err = repo.tx.Model(&model.Task{}).Where("id = ?", task.ID).
+			Updates(map[string]interface{}{
+				"description":      task.Description,
+				"assignee_user_id": task.AssigneeUserID,
+				"is_closed":        task.IsClosed,
+				"estimate_size":    task.EstimateSize,
+				"start_date":       task.StartDate,
+				"due_date":         task.DueDate,
+			}).Error
+		if err != nil {
+			return
+		}
+		err = NewSearchRepository(repo.tx).indexTask(task.ID)
+		if err != nil {
+			return
+		}
 	}
 	return
 }
@@ -138,73 +160,81 @@ func (repo *TaskRepository) DeleteTasks(tasks []*model.Task) (err error) {
 		if err != nil {
 			return
 		}
+		err = NewSearchRepository(repo.tx).removeTask(task.ID)
+		if err != nil {
+			return
+		}
 	}
 	return
 }
 
-// MaxTaskDispOrder return max of disp order matching specified condition
-func (repo *TaskRepository) MaxTaskDispOrder(condition interface{}) (max int, err error) {
-	var out sql.NullInt64
-	err = repo.tx.Model(&model.Task{}).Select("max(disp_order)").
-		Where(condition).Row().Scan(&out)
-	if err != nil {
-		return
-	}
-	if !out.Valid {
-		// no row selected -> returns 0
-		return
-	}
-	return int(out.Int64), nil
+// PreviousTaskRank returns the largest rank less than specified one in the board, empty rank means the end.
+// Task of excludeTaskID is ignored, returns empty if no task exists.
+func (repo *TaskRepository) PreviousTaskRank(boardID, before, excludeTaskID string) (string, error) {
+	return previousRank(repo.boardTasks(boardID), before, excludeTaskID)
 }
 
-// MoveToIceboxBoard move tasks to icebox board which matches specified board id
-func (repo *TaskRepository) MoveToIceboxBoard(boardID string) (err error) {
-	lockTask.Lock()
-	defer lockTask.Unlock()
-	max, err := repo.MaxTaskDispOrder(&model.Task{BoardID: model.SystemBoardIcebox.ID})
-	err = repo.tx.Model(&model.Task{}).Where("board_id = ?", boardID).
-		Update("disp_order", gorm.Expr("disp_order + ?", max)).Error
+// NextTaskRank returns the smallest rank greater than specified one in the board, empty rank means the beginning.
+// Task of excludeTaskID is ignored, returns empty if no task exists.
+func (repo *TaskRepository) NextTaskRank(boardID, after, excludeTaskID string) (string, error) {
+	return nextRank(repo.boardTasks(boardID), after, excludeTaskID)
+}
+
+// MoveBoardTasks move all tasks of a board to another board.
+// Tasks are appended to the end of the board keeping their order.
+func (repo *TaskRepository) MoveBoardTasks(boardID, toBoardID string) (err error) {
+	tasks, err := repo.FindTasks(&model.Task{BoardID: boardID}, 0, orm.NoLimit, []string{"rank", "id"})
 	if err != nil {
 		return
 	}
-	return repo.tx.Model(&model.Task{}).Where("board_id = ?", boardID).
-		Update(&model.Task{BoardID: model.SystemBoardIcebox.ID}).Error
-}
-
-// MoveTaskDispOrders changes task order position.
-func (repo *TaskRepository) MoveTaskDispOrders(
-	taskID, fromBoardID string, fromDispOrder int,
-	toBoardID string, toDispOrder int,
-) (err error) {
-	if fromBoardID == toBoardID {
-		low := fromDispOrder     // ex) 1
-		high := toDispOrder      // ex) 3
-		expr := "disp_order - 1" // a1 b2 c3 d4 => b1 c2 a3 d4
-		if fromDispOrder < toDispOrder {
-			high = fromDispOrder    // ex) 3
-			low = toDispOrder       // ex) 1
-			expr = "disp_order + 1" // a1 b2 c3 d4 => c1 a2 b3 d4
-		}
-		err = repo.tx.Model(&model.Task{}).
-			Where("board_id = ? and disp_order > ? and disp_order <= ?", fromBoardID, low, high).
-			Update("disp_order", gorm.Expr(expr)).Error
-	} else {
-		// ex) from=3 to=2
-		// a1 b2 c3 d4 e5  => a1 b2 d3 e4
-		// x1 y2 z3        => x1 c2 y3 z4
-		// shift - 1 (remove form source board order)
-		err = repo.tx.Model(&model.Task{}).Where("board_id = ? and disp_order > ?", fromBoardID, fromDispOrder).
-			Update("disp_order", gorm.Expr("disp_order - 1")).Error
+	for _, task := range tasks {
+		key, err := lastRank(repo.boardTasks(toBoardID))
 		if err != nil {
-			return
+			return err
 		}
-		// shift + 1 (insert to destination board order)
-		err = repo.tx.Model(&model.Task{}).Where("board_id = ? and disp_order >= ?", toBoardID, toDispOrder).
-			Update("disp_order", gorm.Expr("disp_order + 1")).Error
-		if err != nil {
-			return
+		if err = repo.MoveTask(task.ID, toBoardID, key); err != nil {
+			return err
 		}
 	}
-	// move
-	return repo.tx.Model(&model.Task{}).Where("task_id = ?", taskID).Update(&model.Task{DispOrder: toDispOrder}).Error
+	return
+}
+
+// MoveTask changes board and rank of a task, other tasks are not changed.
+func (repo *TaskRepository) MoveTask(taskID, boardID, rank string) error {
+	return repo.tx.Model(&model.Task{}).Where("id = ?", taskID).
+		Updates(map[string]interface{}{
+			"board_id": boardID,
+			"rank":     rank,
+			"version":  gorm.Expr("version + 1"),
+		}).Error
+}
+
+// UnassignTask clears assignee of a task, other fields are not changed.
+func (repo *TaskRepository) UnassignTask(taskID string) error {
+	return repo.tx.Model(&model.Task{}).Where("id = ?", taskID).
+		Updates(map[string]interface{}{
+			"assignee_user_id": gorm.Expr("null"),
+			"version":          gorm.Expr("version + 1"),
+		}).Error
+}
+
+// CloseTask closes or reopens a task, other fields are not changed.
+func (repo *TaskRepository) CloseTask(taskID string, isClosed bool) error {
+	return repo.tx.Model(&model.Task{}).Where("id = ?", taskID).
+		Updates(map[string]interface{}{
+			"is_closed": isClosed,
+			"version":   gorm.Expr("version + 1"),
+		}).Error
+}
+
+// RebalanceTaskRanks replaces ranks of all tasks in the board with short ones keeping the order
+func (repo *TaskRepository) RebalanceTaskRanks(boardID string) error {
+	return rebalanceRanks(repo.boardTasks(boardID))
+}
+
+// boardTasks returns query of tasks in the board
+func (repo *TaskRepository) boardTasks(boardID string) rankedQuery {
+	return func() *gorm.DB {
+		return repo.tx.Model(&model.Task{}).Where("board_id = ?", boardID)
+	}
 }
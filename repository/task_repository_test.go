@@ -5,6 +5,7 @@ import (
 	"taskboard/common"
 	"taskboard/model"
 	"taskboard/orm"
+	"taskboard/rank"
 	"testing"
 	"time"
 
@@ -31,8 +32,8 @@ func createTaskTestData(tx *gorm.DB, idFormat string, findIdentify string, count
 			time.Now().UTC(),
 		)
 		task.ID = fmt.Sprintf("%s-%03d", idFormat, i)
-		task.DispOrder = i + 1
 		task.BoardID = "fixBoardID"
+		task.Rank = task.ID // Must be unique in board
 		result = append(result, task)
 	}
 	return result
@@ -188,6 +189,90 @@ func TestTaskRepository_UpdateTask(t *testing.T) {
 	}
 }
 
+func TestTaskRepository_UpdateTaskClearFields(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+
+	// Create 1 record which has description and estimate
+	insertTasks := createTaskTestData(tx, "taskID-clear", "clearDescription", 1)
+	insertTasks[0].EstimateSize = 5
+	if err := insertTaskTestData(tx, insertTasks); err != nil {
+		t.Fatalf("Failed to create task: %+v", err)
+	}
+
+	// Clear description and estimate
+	updated := insertTasks[0]
+	updated.Description = ""
+	updated.EstimateSize = 0
+	if err := repo.UpdateTask(updated); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+
+	find, err := repo.FindFirstTask(&model.Task{ID: updated.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find task: %+v", err)
+	}
+	assert.Equal(t, "", find.Description)
+	assert.Equal(t, 0, find.EstimateSize)
+	assert.Equal(t, updated.Version, find.Version)
+}
+
+func TestTaskRepository_UpdateTaskRevertToEmptyFields(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+	historyRepo := NewTaskHistoryRepository(tx)
+
+	// Create 1 record without description and estimate at revision 1
+	insertTasks := createTaskTestData(tx, "taskID-revert", "", 1)
+	task := insertTasks[0]
+	if err := insertTaskTestData(tx, insertTasks); err != nil {
+		t.Fatalf("Failed to create task: %+v", err)
+	}
+
+	// Add description and estimate at revision 2
+	now := time.Now().UTC()
+	histories := []*model.TaskHistory{
+		model.NewTaskHistory(task.ID, 2, "", model.TaskFieldDescription, "", "added", now),
+		model.NewTaskHistory(task.ID, 2, "", model.TaskFieldEstimateSize, "0", "5", now),
+	}
+	for _, history := range histories {
+		if err := historyRepo.CreateTaskHistory(history); err != nil {
+			t.Fatalf("Failed to create task history: %+v", err)
+		}
+	}
+	task.Description = "added"
+	task.EstimateSize = 5
+	if err := repo.UpdateTask(task); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+
+	// Revert to revision 1 as TaskService does
+	revert, err := repo.FindFirstTask(&model.Task{ID: task.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find task: %+v", err)
+	}
+	afters, err := historyRepo.FindTaskHistoriesAfter(task.ID, 1)
+	if err != nil {
+		t.Fatalf("Failed to find task histories: %+v", err)
+	}
+	for _, history := range afters {
+		if err = revert.SetFieldValue(history.Field, history.OldValue); err != nil {
+			t.Fatalf("Failed to set field: %+v", err)
+		}
+	}
+	if err = repo.UpdateTask(&revert); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+
+	find, err := repo.FindFirstTask(&model.Task{ID: task.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find task: %+v", err)
+	}
+	assert.Equal(t, "", find.Description)
+	assert.Equal(t, 0, find.EstimateSize)
+	assert.Equal(t, 3, find.Version)
+}
+
 func TestTaskRepository_DeleteTask(t *testing.T) {
 	tx, repo := newTxAndTaskRepository()
 	defer tx.Rollback()
@@ -317,35 +402,60 @@ func deleteCommitedTaskData(t *testing.T, data *model.Task) {
 ////
 /// Other fuctions' test should be written in below
 //
-func TestTaskRepository_MaxTaskDispOrder(t *testing.T) {
+func TestTaskRepository_PreviousAndNextTaskRank(t *testing.T) {
 	tx, repo := newTxAndTaskRepository()
 	defer tx.Rollback()
 
-	// Create 10 + 20 records
-	firstTasks := createTaskTestData(tx, "taskID-order1st", "orderDescription", 10)
-	for _, task := range firstTasks {
-		task.BoardID = "firstBoardID"
-	}
-	secondTasks := createTaskTestData(tx, "taskID-order2nd", "orderDescription", 20)
-	for _, task := range secondTasks {
-		task.BoardID = "secondBoardID"
+	// Create 3 records, a0 a1 a2 on the board
+	insertTasks := createTaskTestData(tx, "taskID-rank", "rankDescription", 3)
+	for i, key := range rank.Sequence(3) {
+		insertTasks[i].BoardID = "rankBoardID"
+		insertTasks[i].Rank = key
 	}
-	insertTasks := append(firstTasks, secondTasks...)
 	err := insertTaskTestData(tx, insertTasks)
 	if err != nil {
 		t.Fatalf("Failed to create tasks: %+v", err)
 	}
-	max, err := repo.MaxTaskDispOrder(&model.Task{BoardID: "firstBoardID"})
-	if err != nil {
-		t.Fatalf("Failed to get max of dispOrder of tasks: %+v", err)
-	}
-	// Verify created equals find
-	if !assert.Equal(t, 10, max) {
-		t.Errorf("expected: %d, but got %d", 10, max)
-	}
+	tests := []struct {
+		name     string
+		find     func() (string, error)
+		expected string
+	}{
+		{"Previous of end", func() (string, error) { return repo.PreviousTaskRank("rankBoardID", "", "") }, "a2"},
+		{"Previous of end excluding last", func() (string, error) {
+			return repo.PreviousTaskRank("rankBoardID", "", insertTasks[2].ID)
+		}, "a1"},
+		{"Previous of middle", func() (string, error) { return repo.PreviousTaskRank("rankBoardID", "a1", "") }, "a0"},
+		{"Previous of first", func() (string, error) { return repo.PreviousTaskRank("rankBoardID", "a0", "") }, ""},
+		{"Next of beginning", func() (string, error) { return repo.NextTaskRank("rankBoardID", "", "") }, "a0"},
+		{"Next of middle excluding next", func() (string, error) {
+			return repo.NextTaskRank("rankBoardID", "a0", insertTasks[1].ID)
+		}, "a2"},
+		{"Next of last", func() (string, error) { return repo.NextTaskRank("rankBoardID", "a2", "") }, ""},
+		{"Empty board", func() (string, error) { return repo.PreviousTaskRank("emptyBoardID", "", "") }, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			find, err := test.find()
+			if err != nil {
+				t.Fatalf("Failed to find rank: %+v", err)
+			}
+			assert.Equal(t, test.expected, find)
+		})
+	}
+	t.Run("Created task is appended", func(t *testing.T) {
+		task := createTaskTestData(tx, "taskID-rank-append", "rankDescription", 1)[0]
+		task.BoardID = "rankBoardID"
+		task.Rank = ""
+		err := repo.CreateTask(task)
+		if err != nil {
+			t.Fatalf("Failed to create task: %+v", err)
+		}
+		assert.Equal(t, "a3", task.Rank)
+	})
 }
 
-func TestTaskRepository_MoveToIceboxBoard(t *testing.T) {
+func TestTaskRepository_MoveBoardTasks(t *testing.T) {
 	tx, repo := newTxAndTaskRepository()
 	defer tx.Rollback()
 
@@ -358,7 +468,7 @@ func TestTaskRepository_MoveToIceboxBoard(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create tasks: %+v", err)
 	}
-	err = repo.MoveToIceboxBoard("firstBoardID")
+	err = repo.MoveBoardTasks("firstBoardID", "board_icebox")
 	if err != nil {
 		t.Fatalf("Failed to move tasks to IcebboxBoard: %+v", err)
 	}
@@ -371,10 +481,397 @@ func TestTaskRepository_MoveToIceboxBoard(t *testing.T) {
 	if len(findTasks) != 3 {
 		t.Fatalf("")
 	}
-	// 0 and 2 will be changed.
-	insertTasks[0].BoardID = model.SystemBoardIcebox.ID
-	insertTasks[2].BoardID = model.SystemBoardIcebox.ID
+	// 0 and 2 will be appended to icebox.
+	insertTasks[0].BoardID = "board_icebox"
+	insertTasks[0].Rank = "a0"
+	insertTasks[0].Version++
+	insertTasks[2].BoardID = "board_icebox"
+	insertTasks[2].Rank = "a1"
+	insertTasks[2].Version++
 	assert.Equal(t, *insertTasks[0], findTasks[0])
 	assert.Equal(t, *insertTasks[1], findTasks[1])
 	assert.Equal(t, *insertTasks[2], findTasks[2])
 }
+
+func TestTaskRepository_FindFilteredTasks(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+
+	// Create 3 records, which have labels [1], [1, 2] and []
+	insertTasks := createTaskTestData(tx, "taskID-filter", "filterDescription", 3)
+	err := insertTaskTestData(tx, insertTasks)
+	if err != nil {
+		t.Fatalf("Failed to create tasks: %+v", err)
+	}
+	labelRepo := NewLabelRepository(tx)
+	err = labelRepo.CreateTaskLabels(insertTasks[0].ID, []string{"labelID-1"})
+	if err != nil {
+		t.Fatalf("Failed to create task labels: %+v", err)
+	}
+	err = labelRepo.CreateTaskLabels(insertTasks[1].ID, []string{"labelID-1", "labelID-2"})
+	if err != nil {
+		t.Fatalf("Failed to create task labels: %+v", err)
+	}
+
+	condition := &model.Task{Description: "filterDescription"}
+	t.Run("Tasks which have one of labels", func(t *testing.T) {
+		filter := &TaskFilter{LabelIDs: []string{"labelID-1", "labelID-2"}}
+		findTasks, err := repo.FindFilteredTasks(condition, filter, 0, orm.NoLimit, []string{"id"})
+		if err != nil {
+			t.Fatalf("Failed to find tasks: %+v", err)
+		}
+		if assert.Len(t, findTasks, 2) {
+			assert.Equal(t, insertTasks[0].ID, findTasks[0].ID)
+			assert.Equal(t, insertTasks[1].ID, findTasks[1].ID)
+		}
+	})
+	t.Run("Tasks which have all of labels", func(t *testing.T) {
+		filter := &TaskFilter{LabelIDs: []string{"labelID-1", "labelID-2", "labelID-2"}, LabelMatchAll: true}
+		findTasks, err := repo.FindFilteredTasks(condition, filter, 0, orm.NoLimit, []string{"id"})
+		if err != nil {
+			t.Fatalf("Failed to find tasks: %+v", err)
+		}
+		if assert.Len(t, findTasks, 1) {
+			assert.Equal(t, insertTasks[1].ID, findTasks[0].ID)
+		}
+	})
+}
+
+func TestTaskRepository_CountFilteredTasks(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+
+	// Create 3 records, the first 2 of which have a label and the last one is closed
+	insertTasks := createTaskTestData(tx, "taskID-countFilter", "countFilterDescription", 3)
+	insertTasks[2].IsClosed = true
+	err := insertTaskTestData(tx, insertTasks)
+	if err != nil {
+		t.Fatalf("Failed to create tasks: %+v", err)
+	}
+	labelRepo := NewLabelRepository(tx)
+	for _, task := range insertTasks[:2] {
+		err = labelRepo.CreateTaskLabels(task.ID, []string{"labelID-count"})
+		if err != nil {
+			t.Fatalf("Failed to create task labels: %+v", err)
+		}
+	}
+
+	condition := &model.Task{Description: "countFilterDescription"}
+	count, err := repo.CountFilteredTasks(condition, nil)
+	if err != nil {
+		t.Fatalf("Failed to count tasks: %+v", err)
+	}
+	assert.Equal(t, 3, count)
+
+	count, err = repo.CountFilteredTasks(condition, &TaskFilter{LabelIDs: []string{"labelID-count"}, IsOpenOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to count tasks: %+v", err)
+	}
+	assert.Equal(t, 2, count)
+}
+
+func TestTaskRepository_CountFilteredTasksByProject(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+
+	// Create 3 records, the first 2 of which are on the board of the project
+	insertTasks := createTaskTestData(tx, "taskID-projectFilter", "projectFilterDescription", 3)
+	insertTasks[0].BoardID = "boardID-projectFilter"
+	insertTasks[1].BoardID = "boardID-projectFilter"
+	err := insertTaskTestData(tx, insertTasks)
+	if err != nil {
+		t.Fatalf("Failed to create tasks: %+v", err)
+	}
+	board := model.NewBoard("name-projectFilter", false, false, time.Now().UTC())
+	board.ID = "boardID-projectFilter"
+	board.ProjectID = "projectID-projectFilter"
+	board.Rank = board.ID
+	if err = tx.Create(board).Error; err != nil {
+		t.Fatalf("Failed to create board: %+v", err)
+	}
+
+	condition := &model.Task{Description: "projectFilterDescription"}
+	count, err := repo.CountFilteredTasks(condition, &TaskFilter{ProjectIDs: []string{"projectID-projectFilter"}})
+	if err != nil {
+		t.Fatalf("Failed to count tasks: %+v", err)
+	}
+	assert.Equal(t, 2, count)
+
+	// No tasks are found without projects
+	count, err = repo.CountFilteredTasks(condition, &TaskFilter{ProjectIDs: []string{}})
+	if err != nil {
+		t.Fatalf("Failed to count tasks: %+v", err)
+	}
+	assert.Equal(t, 0, count)
+}
+
+func TestTaskRepository_FindFilteredTasksByDueDate(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+
+	// Create 4 records, which are due yesterday, due tomorrow, closed and due yesterday, and not planned
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	tomorrow := now.AddDate(0, 0, 1)
+	insertTasks := createTaskTestData(tx, "taskID-due", "dueDescription", 4)
+	insertTasks[0].DueDate = &yesterday
+	insertTasks[1].DueDate = &tomorrow
+	insertTasks[2].DueDate = &yesterday
+	insertTasks[2].IsClosed = true
+	err := insertTaskTestData(tx, insertTasks)
+	if err != nil {
+		t.Fatalf("Failed to create tasks: %+v", err)
+	}
+
+	condition := &model.Task{Description: "dueDescription"}
+	t.Run("Overdue tasks", func(t *testing.T) {
+		filter := &TaskFilter{DueBefore: &now, IsOpenOnly: true}
+		findTasks, err := repo.FindFilteredTasks(condition, filter, 0, orm.NoLimit, []string{"id"})
+		if err != nil {
+			t.Fatalf("Failed to find tasks: %+v", err)
+		}
+		if assert.Len(t, findTasks, 1) {
+			assert.Equal(t, insertTasks[0].ID, findTasks[0].ID)
+		}
+	})
+	t.Run("Tasks due within 2 days", func(t *testing.T) {
+		dueBefore := now.AddDate(0, 0, 2)
+		filter := &TaskFilter{DueFrom: &now, DueBefore: &dueBefore}
+		findTasks, err := repo.FindFilteredTasks(condition, filter, 0, orm.NoLimit, []string{"id"})
+		if err != nil {
+			t.Fatalf("Failed to find tasks: %+v", err)
+		}
+		if assert.Len(t, findTasks, 1) {
+			assert.Equal(t, insertTasks[1].ID, findTasks[0].ID)
+		}
+	})
+	t.Run("Clear due date", func(t *testing.T) {
+		task := insertTasks[0]
+		task.DueDate = nil
+		err := repo.UpdateTask(task)
+		if err != nil {
+			t.Fatalf("Failed to update task: %+v", err)
+		}
+		findTask, err := repo.FindFirstTask(&model.Task{ID: task.ID}, []string{})
+		if err != nil {
+			t.Fatalf("Failed to find task: %+v", err)
+		}
+		assert.Nil(t, findTask.DueDate)
+	})
+}
+
+func TestTaskRepository_FindFilteredTasksByQuery(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+
+	board := model.NewBoard("Query Board", false, false, time.Now().UTC())
+	user := model.NewUser("queryUser", "password", "")
+	label := model.NewLabel("queryLabel", "red", time.Now().UTC())
+	for _, record := range []interface{}{board, user, label} {
+		if err := tx.Create(record).Error; err != nil {
+			t.Fatalf("Failed to create record: %+v", err)
+		}
+	}
+	// Create 3 records: 1st is on the board, assigned and labeled, 2nd is closed with "50% done" and 3rd is due
+	dueDate := time.Date(2019, 1, 10, 12, 0, 0, 0, time.UTC)
+	insertTasks := createTaskTestData(tx, "taskID-query", "queryDescription", 3)
+	insertTasks[0].BoardID = board.ID
+	insertTasks[0].SetAssigneeUserID(user.ID)
+	insertTasks[0].EstimateSize = 5
+	insertTasks[1].IsClosed = true
+	insertTasks[1].Name = "Login bug 50% done"
+	insertTasks[2].DueDate = &dueDate
+	err := insertTaskTestData(tx, insertTasks)
+	if err != nil {
+		t.Fatalf("Failed to create tasks: %+v", err)
+	}
+	err = NewLabelRepository(tx).CreateTaskLabels(insertTasks[0].ID, []string{label.ID})
+	if err != nil {
+		t.Fatalf("Failed to create task labels: %+v", err)
+	}
+
+	condition := &model.Task{Description: "queryDescription"}
+	tests := []struct {
+		query    string
+		expected []int // Indexes of insertTasks
+	}{
+		{`board:"Query Board" assignee:me label:queryLabel estimate>=5`, []int{0}},
+		{`assignee:queryUser estimate>5`, []int{}},
+		{`assignee:none closed:false`, []int{2}},
+		{`"login BUG" 50%`, []int{1}},
+		{`5_%`, []int{}},
+		{`due:2019-01-10`, []int{2}},
+		{`due<2019-01-10`, []int{}},
+		{`due:none`, []int{0, 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			query, err := ParseTaskQuery(test.query, user.ID)
+			if err != nil {
+				t.Fatalf("Failed to parse query: %+v", err)
+			}
+			findTasks, err := repo.FindFilteredTasks(condition, &TaskFilter{Query: query}, 0, orm.NoLimit, []string{"id"})
+			if err != nil {
+				t.Fatalf("Failed to find tasks: %+v", err)
+			}
+			findIDs := []string{}
+			for _, task := range findTasks {
+				findIDs = append(findIDs, task.ID)
+			}
+			expectedIDs := []string{}
+			for _, index := range test.expected {
+				expectedIDs = append(expectedIDs, insertTasks[index].ID)
+			}
+			assert.Equal(t, expectedIDs, findIDs)
+		})
+	}
+}
+
+func TestTaskRepository_MoveTask(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+
+	// Create 3 records, a0 a1 a2 on the board
+	insertTasks := createTaskTestData(tx, "taskID-order", "moveOrderDescription", 3)
+	for i, key := range rank.Sequence(3) {
+		insertTasks[i].BoardID = "orderBoardID"
+		insertTasks[i].Rank = key
+	}
+	err := insertTaskTestData(tx, insertTasks)
+	if err != nil {
+		t.Fatalf("Failed to create tasks: %+v", err)
+	}
+	condition := &model.Task{Description: "moveOrderDescription"}
+
+	t.Run("Move in same board", func(t *testing.T) {
+		// a b c => b c a
+		err := repo.MoveTask(insertTasks[0].ID, "orderBoardID", "a3")
+		if err != nil {
+			t.Fatalf("Failed to move task: %+v", err)
+		}
+		findTasks, err := repo.FindTasks(condition, 0, orm.NoLimit, []string{"rank"})
+		if err != nil {
+			t.Fatalf("Failed to find tasks: %+v", err)
+		}
+		if assert.Len(t, findTasks, 3) {
+			assert.Equal(t, insertTasks[1].ID, findTasks[0].ID)
+			assert.Equal(t, insertTasks[2].ID, findTasks[1].ID)
+			assert.Equal(t, insertTasks[0].ID, findTasks[2].ID)
+			assert.Equal(t, insertTasks[0].Version+1, findTasks[2].Version)
+			// Other tasks are not changed
+			assert.Equal(t, *insertTasks[1], findTasks[0])
+			assert.Equal(t, *insertTasks[2], findTasks[1])
+		}
+	})
+	t.Run("Move to another board", func(t *testing.T) {
+		err := repo.MoveTask(insertTasks[1].ID, "anotherBoardID", "a0")
+		if err != nil {
+			t.Fatalf("Failed to move task: %+v", err)
+		}
+		findTask, err := repo.FindFirstTask(&model.Task{ID: insertTasks[1].ID}, []string{})
+		if err != nil {
+			t.Fatalf("Failed to find task: %+v", err)
+		}
+		assert.Equal(t, "anotherBoardID", findTask.BoardID)
+		assert.Equal(t, "a0", findTask.Rank)
+	})
+	t.Run("Same rank in board is rejected", func(t *testing.T) {
+		err := repo.MoveTask(insertTasks[2].ID, "orderBoardID", "a3")
+		assert.True(t, orm.IsUniqueConstraintError(err), "unexpected error: %+v", err)
+	})
+}
+
+func TestTaskRepository_UnassignTask(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+
+	// Create 2 records assigned to a user
+	insertTasks := createTaskTestData(tx, "taskID-unassign", "unassignDescription", 2)
+	for _, task := range insertTasks {
+		task.SetAssigneeUserID("unassignUserID")
+	}
+	err := insertTaskTestData(tx, insertTasks)
+	if err != nil {
+		t.Fatalf("Failed to create tasks: %+v", err)
+	}
+	err = repo.UnassignTask(insertTasks[0].ID)
+	if err != nil {
+		t.Fatalf("Failed to unassign task: %+v", err)
+	}
+	findTasks, err := repo.FindTasks(&model.Task{Description: "unassignDescription"}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to find tasks: %+v", err)
+	}
+	if assert.Len(t, findTasks, 2) {
+		assert.False(t, findTasks[0].AssigneeUserID.Valid)
+		assert.Equal(t, insertTasks[0].Version+1, findTasks[0].Version)
+		// Other task is not changed
+		assert.Equal(t, *insertTasks[1], findTasks[1])
+	}
+}
+
+func TestTaskRepository_CloseTask(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+
+	insertTasks := createTaskTestData(tx, "taskID-close", "closeDescription", 1)
+	err := insertTaskTestData(tx, insertTasks)
+	if err != nil {
+		t.Fatalf("Failed to create tasks: %+v", err)
+	}
+	task := insertTasks[0]
+	if err = repo.CloseTask(task.ID, true); err != nil {
+		t.Fatalf("Failed to close task: %+v", err)
+	}
+	find, err := repo.FindFirstTask(&model.Task{ID: task.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find task: %+v", err)
+	}
+	assert.True(t, find.IsClosed)
+	assert.Equal(t, task.Version+1, find.Version)
+
+	// Reopened by update
+	find.IsClosed = false
+	if err = repo.UpdateTask(&find); err != nil {
+		t.Fatalf("Failed to update task: %+v", err)
+	}
+	find, err = repo.FindFirstTask(&model.Task{ID: task.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find task: %+v", err)
+	}
+	assert.False(t, find.IsClosed)
+	assert.Equal(t, task.Version+2, find.Version)
+}
+
+func TestTaskRepository_RebalanceTaskRanks(t *testing.T) {
+	tx, repo := newTxAndTaskRepository()
+	defer tx.Rollback()
+
+	// Create 3 records, whose ranks are long
+	insertTasks := createTaskTestData(tx, "taskID-rebalance", "rebalanceDescription", 3)
+	for i, key := range []string{"a0zzzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "a1", "a0V"} {
+		insertTasks[i].BoardID = "rebalanceBoardID"
+		insertTasks[i].Rank = key
+	}
+	err := insertTaskTestData(tx, insertTasks)
+	if err != nil {
+		t.Fatalf("Failed to create tasks: %+v", err)
+	}
+	err = repo.RebalanceTaskRanks("rebalanceBoardID")
+	if err != nil {
+		t.Fatalf("Failed to rebalance ranks: %+v", err)
+	}
+	findTasks, err := repo.FindTasks(&model.Task{BoardID: "rebalanceBoardID"}, 0, orm.NoLimit, []string{"rank"})
+	if err != nil {
+		t.Fatalf("Failed to find tasks: %+v", err)
+	}
+	// Order is kept
+	if assert.Len(t, findTasks, 3) {
+		assert.Equal(t, insertTasks[2].ID, findTasks[0].ID)
+		assert.Equal(t, "a0", findTasks[0].Rank)
+		assert.Equal(t, insertTasks[0].ID, findTasks[1].ID)
+		assert.Equal(t, "a1", findTasks[1].Rank)
+		assert.Equal(t, insertTasks[1].ID, findTasks[2].ID)
+		assert.Equal(t, "a2", findTasks[2].Rank)
+	}
+}
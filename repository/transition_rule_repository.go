@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"sync"
+	"taskboard/model"
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+var lockTransitionRule = &sync.Mutex{}
+
+// TransitionRuleRepository is repository of transition_rule table
+type TransitionRuleRepository struct {
+	tx *gorm.DB
+}
+
+// NewTransitionRuleRepository returns new instance of TransitionRuleRepository
+func NewTransitionRuleRepository(tx *gorm.DB) *TransitionRuleRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &TransitionRuleRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstTransitionRule returns first TransitionRule matching with specified condition
+func (repo *TransitionRuleRepository) FindFirstTransitionRule(condition interface{}, sortOrders []string) (result model.TransitionRule, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindTransitionRules returns TransitionRules matching with specified condition
+func (repo *TransitionRuleRepository) FindTransitionRules(condition interface{}, offset int, limit int, sortOrders []string) (result []model.TransitionRule, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, transitionRule := range sortOrders {
+		query = query.Order(transitionRule)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountTransitionRules returns the number of TransitionRules matching specfied condition
+func (repo *TransitionRuleRepository) CountTransitionRules(condition interface{}) (count int, err error) {
+	var transitionRules []model.TransitionRule
+	err = repo.tx.Where(condition).Find(&transitionRules).Count(&count).Error
+	return
+}
+
+// CreateTransitionRule inserts new TransitionRule record
+func (repo *TransitionRuleRepository) CreateTransitionRule(transitionRule *model.TransitionRule) error {
+	return repo.CreateTransitionRules([]*model.TransitionRule{transitionRule})
+}
+
+// UpdateTransitionRule updates TransitionRule record
+func (repo *TransitionRuleRepository) UpdateTransitionRule(transitionRule *model.TransitionRule) error {
+	return repo.UpdateTransitionRules([]*model.TransitionRule{transitionRule})
+}
+
+// DeleteTransitionRule deletes TransitionRule record
+func (repo *TransitionRuleRepository) DeleteTransitionRule(transitionRule *model.TransitionRule) error {
+	return repo.DeleteTransitionRules([]*model.TransitionRule{transitionRule})
+}
+
+// CreateTransitionRules inserts new TransitionRule records.
+func (repo *TransitionRuleRepository) CreateTransitionRules(transitionRules []*model.TransitionRule) (err error) {
+	for _, transitionRule := range transitionRules {
+		err = repo.tx.Create(transitionRule).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateTransitionRules updates transitionRule records
+func (repo *TransitionRuleRepository) UpdateTransitionRules(transitionRules []*model.TransitionRule) (err error) {
+	lockTransitionRule.Lock()
+	defer lockTransitionRule.Unlock()
+
+	for _, transitionRule := range transitionRules {
+		oldVersion := transitionRule.Version
+		transitionRule.Version++
+		db := repo.tx.Model(&model.TransitionRule{}).Where("version = ?", oldVersion).Updates(transitionRule)
+		count := db.RowsAffected
+		err = db.Error
+		// return ErrorRecordNotFoud as optimistic lock error
+		if err == nil && count == 0 {
+			return orm.ErrorRecordNotFound
+		}
+		if err != nil {
+			return
+		}
+		// Updates() skips blank fields, so fields which can be blank are updated explicitly to be cleared
+		err = repo.tx.Model(&model.TransitionRule{}).Where("id = ?", transitionRule.ID).
+			Updates(map[string]interface{}{
+				"required_role":   transitionRule.RequiredRole,
+				"required_fields": transitionRule.RequiredFields,
+			}).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteTransitionRules deletes TransitionRule records
+func (repo *TransitionRuleRepository) DeleteTransitionRules(transitionRules []*model.TransitionRule) (err error) {
+	for _, transitionRule := range transitionRules {
+		if transitionRule.ID == "" {
+			continue // To avoid deleting all due to gorm warning, continue here.
+		}
+		err = repo.tx.Delete(transitionRule).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteBoardTransitionRules deletes TransitionRule records from or to specified board
+func (repo *TransitionRuleRepository) DeleteBoardTransitionRules(boardID string) error {
+	if boardID == "" {
+		return nil // To avoid deleting all due to gorm warning, return here.
+	}
+	return repo.tx.Where("from_board_id = ? or to_board_id = ?", boardID, boardID).Delete(&model.TransitionRule{}).Error
+}
+
+// FindOnlyTransitionRulesTo returns rules to specified board which are the only rules of their from-boards.
+// Once they are deleted, tasks on their from-boards can be moved to any board.
+func (repo *TransitionRuleRepository) FindOnlyTransitionRulesTo(boardID string) (result []model.TransitionRule, err error) {
+	err = repo.tx.Where("to_board_id = ?", boardID).
+		Where("not exists (select 1 from transition_rules o where o.from_board_id = transition_rules.from_board_id and o.to_board_id <> ?)", boardID).
+		Order("from_board_id").Find(&result).Error
+	return
+}
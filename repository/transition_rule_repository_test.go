@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"fmt"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndTransitionRuleRepository() (tx *gorm.DB, repo *TransitionRuleRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewTransitionRuleRepository(tx)
+	return
+}
+
+func createTransitionRuleTestData(tx *gorm.DB, idFormat string, count int) []*model.TransitionRule {
+	result := make([]*model.TransitionRule, 0, count)
+	for i := 0; i < count; i++ {
+		rule := model.NewTransitionRule(
+			fmt.Sprintf("%s-from", idFormat),
+			fmt.Sprintf("%s-to-%03d", idFormat, i),
+			model.RoleMember,
+			[]string{model.RequiredFieldAssignee, model.RequiredFieldEstimate},
+			time.Now().UTC(),
+		)
+		rule.ID = fmt.Sprintf("%s-%03d", idFormat, i)
+		result = append(result, rule)
+	}
+	return result
+}
+
+func insertTransitionRuleTestData(tx *gorm.DB, rules []*model.TransitionRule) (err error) {
+	for _, rule := range rules {
+		err = tx.Create(rule).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+////
+/// Common repository functions' test
+//
+func TestTransitionRuleRepository_FindTransitionRules(t *testing.T) {
+	tx, repo := newTxAndTransitionRuleRepository()
+	defer tx.Rollback()
+
+	firstRules := createTransitionRuleTestData(tx, "transitionID-find", 5)
+	secondRules := createTransitionRuleTestData(tx, "transitionID-not-find", 4)
+	err := insertTransitionRuleTestData(tx, append(firstRules, secondRules...))
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	rules, err := repo.FindTransitionRules(&model.TransitionRule{FromBoardID: "transitionID-find-from"}, 1, 3, []string{"to_board_id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find: %+v", err)
+	}
+	if len(rules) != 3 {
+		t.Errorf("Expected result size = %d, but got %d", 3, len(rules))
+		return
+	}
+	assert.Equal(t, "transitionID-find-001", rules[0].ID)
+	assert.Equal(t, []string{model.RequiredFieldAssignee, model.RequiredFieldEstimate}, rules[0].GetRequiredFields())
+
+	count, err := repo.CountTransitionRules(&model.TransitionRule{FromBoardID: "transitionID-find-from"})
+	if err != nil {
+		t.Fatalf("failed to count TransitionRule: %+v", err)
+	}
+	assert.Equal(t, 5, count)
+}
+
+func TestTransitionRuleRepository_UpdateTransitionRule(t *testing.T) {
+	tx, repo := newTxAndTransitionRuleRepository()
+	defer tx.Rollback()
+
+	rules := createTransitionRuleTestData(tx, "transitionID-update", 1)
+	if err := repo.CreateTransitionRule(rules[0]); err != nil {
+		t.Fatalf("Failed to create transition rule: %+v", err)
+	}
+
+	// Blank role and fields are cleared
+	updated := rules[0]
+	updated.RequiredRole = ""
+	updated.SetRequiredFields([]string{})
+	if err := repo.UpdateTransitionRule(updated); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+	find, err := repo.FindFirstTransitionRule(&model.TransitionRule{ID: updated.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find transition rule: %+v", err)
+	}
+	assert.Equal(t, "", find.RequiredRole)
+	assert.Equal(t, []string{}, find.GetRequiredFields())
+	assert.Equal(t, 2, find.Version)
+}
+
+////
+/// Other fuctions' test should be written in below
+//
+func TestTransitionRuleRepository_DeleteBoardTransitionRules(t *testing.T) {
+	tx, repo := newTxAndTransitionRuleRepository()
+	defer tx.Rollback()
+
+	rules := createTransitionRuleTestData(tx, "transitionID-board", 3)
+	rules[2].FromBoardID = "transitionID-board-to-000"
+	rules[2].ToBoardID = "transitionID-board-other"
+	err := insertTransitionRuleTestData(tx, rules)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	// Rules from and to the board are deleted
+	err = repo.DeleteBoardTransitionRules("transitionID-board-to-000")
+	if err != nil {
+		t.Fatalf("Failed to delete transition rules: %+v", err)
+	}
+	remains, err := repo.FindTransitionRules("id like 'transitionID-board-%'", 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to find transition rules: %+v", err)
+	}
+	assert.Equal(t, 1, len(remains))
+	assert.Equal(t, "transitionID-board-001", remains[0].ID)
+
+	// Empty board ID deletes nothing
+	err = repo.DeleteBoardTransitionRules("")
+	if err != nil {
+		t.Fatalf("Failed to delete transition rules: %+v", err)
+	}
+	count, err := repo.CountTransitionRules("id like 'transitionID-board-%'")
+	if err != nil {
+		t.Fatalf("Failed to count transition rules: %+v", err)
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestTransitionRuleRepository_FindOnlyTransitionRulesTo(t *testing.T) {
+	tx, repo := newTxAndTransitionRuleRepository()
+	defer tx.Rollback()
+
+	// Board "a" has the only rule to "target", board "b" has rules to "target" and "other"
+	rules := createTransitionRuleTestData(tx, "transitionID-only", 3)
+	rules[0].FromBoardID, rules[0].ToBoardID = "transitionID-only-a", "transitionID-only-target"
+	rules[1].FromBoardID, rules[1].ToBoardID = "transitionID-only-b", "transitionID-only-target"
+	rules[2].FromBoardID, rules[2].ToBoardID = "transitionID-only-b", "transitionID-only-other"
+	err := insertTransitionRuleTestData(tx, rules)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	onlyRules, err := repo.FindOnlyTransitionRulesTo("transitionID-only-target")
+	if err != nil {
+		t.Fatalf("Failed to find transition rules: %+v", err)
+	}
+	if assert.Len(t, onlyRules, 1) {
+		assert.Equal(t, "transitionID-only-000", onlyRules[0].ID)
+	}
+
+	// Board which is not the only target of any rules
+	onlyRules, err = repo.FindOnlyTransitionRulesTo("transitionID-only-other")
+	if err != nil {
+		t.Fatalf("Failed to find transition rules: %+v", err)
+	}
+	assert.Len(t, onlyRules, 0)
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"sync"
+	"taskboard/model"
+	"taskboard/orm"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+var lockWebhookDelivery = &sync.Mutex{}
+
+// WebhookDeliveryRepository is repository of webhookDelivery table
+type WebhookDeliveryRepository struct {
+	tx *gorm.DB
+}
+
+// NewWebhookDeliveryRepository returns new instance of WebhookDeliveryRepository
+func NewWebhookDeliveryRepository(tx *gorm.DB) *WebhookDeliveryRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &WebhookDeliveryRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstWebhookDelivery returns first WebhookDelivery matching with specified condition
+func (repo *WebhookDeliveryRepository) FindFirstWebhookDelivery(condition interface{}, sortOrders []string) (result model.WebhookDelivery, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindWebhookDeliveries returns WebhookDeliveries matching with specified condition
+func (repo *WebhookDeliveryRepository) FindWebhookDeliveries(condition interface{}, offset int, limit int, sortOrders []string) (result []model.WebhookDelivery, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, webhookDelivery := range sortOrders {
+		query = query.Order(webhookDelivery)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountWebhookDeliveries returns the number of WebhookDeliveries matching specfied condition
+func (repo *WebhookDeliveryRepository) CountWebhookDeliveries(condition interface{}) (count int, err error) {
+	var webhookDeliveries []model.WebhookDelivery
+	err = repo.tx.Where(condition).Find(&webhookDeliveries).Count(&count).Error
+	return
+}
+
+// CreateWebhookDelivery inserts new WebhookDelivery record
+func (repo *WebhookDeliveryRepository) CreateWebhookDelivery(webhookDelivery *model.WebhookDelivery) error {
+	return repo.CreateWebhookDeliveries([]*model.WebhookDelivery{webhookDelivery})
+}
+
+// UpdateWebhookDelivery updates WebhookDelivery record
+func (repo *WebhookDeliveryRepository) UpdateWebhookDelivery(webhookDelivery *model.WebhookDelivery) error {
+	return repo.UpdateWebhookDeliveries([]*model.WebhookDelivery{webhookDelivery})
+}
+
+// DeleteWebhookDelivery deletes WebhookDelivery record
+func (repo *WebhookDeliveryRepository) DeleteWebhookDelivery(webhookDelivery *model.WebhookDelivery) error {
+	return repo.DeleteWebhookDeliveries([]*model.WebhookDelivery{webhookDelivery})
+}
+
+// CreateWebhookDeliveries inserts new WebhookDelivery records.
+func (repo *WebhookDeliveryRepository) CreateWebhookDeliveries(webhookDeliveries []*model.WebhookDelivery) (err error) {
+	for _, webhookDelivery := range webhookDeliveries {
+		err = repo.tx.Create(webhookDelivery).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateWebhookDeliveries updates webhookDelivery records
+func (repo *WebhookDeliveryRepository) UpdateWebhookDeliveries(webhookDeliveries []*model.WebhookDelivery) (err error) {
+	lockWebhookDelivery.Lock()
+	defer lockWebhookDelivery.Unlock()
+
+	for _, webhookDelivery := range webhookDeliveries {
+		oldVersion := webhookDelivery.Version
+		webhookDelivery.Version++
+		db := repo.tx.Model(&model.WebhookDelivery{}).Where("version = ?", oldVersion).Updates(webhookDelivery)
+		count := db.RowsAffected
+		err = db.Error
+		// return ErrorRecordNotFoud as optimistic lock error
+		if err == nil && count == 0 {
+			return orm.ErrorRecordNotFound
+		}
+		if err != nil {
+			return
+		}
+		// Updates() skips blank fields, so fields which can be blank are updated explicitly to be cleared
+		err = repo.tx.Model(&model.WebhookDelivery{}).Where("id = ?", webhookDelivery.ID).
+			Updates(map[string]interface{}{
+				"last_status_code": webhookDelivery.LastStatusCode,
+				"last_error":       webhookDelivery.LastError,
+			}).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteWebhookDeliveries deletes WebhookDelivery records
+func (repo *WebhookDeliveryRepository) DeleteWebhookDeliveries(webhookDeliveries []*model.WebhookDelivery) (err error) {
+	for _, webhookDelivery := range webhookDeliveries {
+		if webhookDelivery.ID == "" {
+			continue // To avoid deleting all due to gorm warning, continue here.
+		}
+		err = repo.tx.Delete(webhookDelivery).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// FindDueWebhookDeliveries returns pending deliveries whose next attempt date has come, oldest first
+func (repo *WebhookDeliveryRepository) FindDueWebhookDeliveries(now time.Time, limit int) (result []model.WebhookDelivery, err error) {
+	err = repo.tx.Where("status = ? and next_attempt_date <= ?", model.DeliveryStatusPending, now).
+		Order("next_attempt_date, id").Limit(limit).Find(&result).Error
+	return
+}
+
+// DeleteDeliveriesOfWebhook deletes all WebhookDelivery records of specified webhook
+func (repo *WebhookDeliveryRepository) DeleteDeliveriesOfWebhook(webhookID string) error {
+	if webhookID == "" {
+		return nil // To avoid deleting all due to gorm warning, return here.
+	}
+	return repo.tx.Where("webhook_id = ?", webhookID).Delete(&model.WebhookDelivery{}).Error
+}
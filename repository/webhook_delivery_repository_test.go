@@ -0,0 +1,397 @@
+package repository
+
+import (
+	"fmt"
+	"taskboard/common"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndWebhookDeliveryRepository() (tx *gorm.DB, repo *WebhookDeliveryRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewWebhookDeliveryRepository(tx)
+	return
+}
+
+func createWebhookDeliveryTestData(tx *gorm.DB, idFormat string, findIdentify string, count int) []*model.WebhookDelivery {
+	result := make([]*model.WebhookDelivery, 0, count)
+	for i := 0; i < count; i++ {
+		webhookDelivery := model.NewWebhookDelivery(
+			findIdentify,
+			"event_"+common.GenerateID(),
+			"task.created",
+			"{}",
+			time.Now().UTC(),
+		)
+		webhookDelivery.ID = fmt.Sprintf("%s-%03d", idFormat, i)
+		result = append(result, webhookDelivery)
+	}
+	return result
+}
+
+func insertWebhookDeliveryTestData(tx *gorm.DB, webhookDeliveries []*model.WebhookDelivery) (err error) {
+	for _, webhookDelivery := range webhookDeliveries {
+		err = tx.Create(webhookDelivery).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+////
+/// Common repository functions' test
+//
+func TestWebhookDeliveryRepository_FindFirstWebhookDelivery(t *testing.T) {
+	tx, repo := newTxAndWebhookDeliveryRepository()
+	defer tx.Rollback()
+
+	firstWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-find", "findWebhookID", 5)
+	secondWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-not-find", "notFindWebhookID", 4)
+	insertWebhookDeliveries := append(firstWebhookDeliveries, secondWebhookDeliveries...)
+	err := insertWebhookDeliveryTestData(tx, insertWebhookDeliveries)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+	// Sort by id
+	webhookDelivery, err := repo.FindFirstWebhookDelivery(&model.WebhookDelivery{WebhookID: "findWebhookID"}, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find first: %+v", err)
+	}
+	expected := "webhookDeliveryID-find-000"
+	if webhookDelivery.ID != expected {
+		t.Errorf("expected webhookDelivery ID is %s, but got %s", expected, webhookDelivery.ID)
+	}
+}
+
+func TestWebhookDeliveryRepository_FindWebhookDeliveries(t *testing.T) {
+	tx, repo := newTxAndWebhookDeliveryRepository()
+	defer tx.Rollback()
+
+	firstWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-find", "findWebhookID", 5)
+	secondWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-not-find", "notFindWebhookID", 4)
+	insertWebhookDeliveries := append(firstWebhookDeliveries, secondWebhookDeliveries...)
+	err := insertWebhookDeliveryTestData(tx, insertWebhookDeliveries)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	// Get 3 data from 2nd(index=1) position
+	const (
+		offset = 1
+		limit  = 3
+	)
+	webhookDeliveries, err := repo.FindWebhookDeliveries(&model.WebhookDelivery{WebhookID: "findWebhookID"}, offset, limit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find: %+v", err)
+	}
+
+	// Length of data must be limit
+	if len(webhookDeliveries) != limit {
+		t.Errorf("Expected result size = %d, but got %d", limit, len(webhookDeliveries))
+		return
+	}
+	// Head must be 001
+	head := webhookDeliveries[0]
+	headExpected := "webhookDeliveryID-find-001"
+	if head.ID != headExpected {
+		t.Errorf("head ID must be %s, but got %s", headExpected, head.ID)
+	}
+	// Tail must be 003
+	tail := webhookDeliveries[len(webhookDeliveries)-1]
+	tailExpected := "webhookDeliveryID-find-003"
+	if tail.ID != tailExpected {
+		t.Errorf("tail ID must be %s, but got %s", tailExpected, tail.ID)
+	}
+}
+
+func TestAddressRepository_CountWebhookDeliveries(t *testing.T) {
+	tx, repo := newTxAndWebhookDeliveryRepository()
+	defer tx.Rollback()
+
+	expected := 5
+	firstWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-find", "findWebhookID", 5)
+	secondWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-not-find", "notFindWebhookID", 4)
+	insertWebhookDeliveries := append(firstWebhookDeliveries, secondWebhookDeliveries...)
+	err := insertWebhookDeliveryTestData(tx, insertWebhookDeliveries)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	count, err := repo.CountWebhookDeliveries(&model.WebhookDelivery{WebhookID: "findWebhookID"})
+	if err != nil {
+		t.Fatalf("failed to count WebhookDelivery: %+v", err)
+	}
+	if expected != count {
+		t.Errorf("expected %d records, but got %d record", expected, count)
+	}
+}
+
+func TestWebhookDeliveryRepository_CreateWebhookDelivery(t *testing.T) {
+	tx, repo := newTxAndWebhookDeliveryRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-create", "createWebhookID", 1)
+	created := insertWebhookDeliveries[0]
+	if err := repo.CreateWebhookDelivery(created); err != nil {
+		t.Fatalf("Failed to create webhookDelivery: %+v", err)
+	}
+
+	// Find by ID
+	var find = model.WebhookDelivery{}
+	if err := tx.Where(&model.WebhookDelivery{ID: created.ID}).First(&find).Error; err != nil {
+		t.Fatalf("Failed to find webhookDelivery: %+v", err)
+	}
+	// Verify created equals find
+	if !assert.Equal(t, find, *created) {
+		t.Errorf("expected: %+v, but got %+v", created.ID, find.ID)
+	}
+}
+
+func TestWebhookDeliveryRepository_UpdateWebhookDelivery(t *testing.T) {
+	tx, repo := newTxAndWebhookDeliveryRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-create", "createWebhookID", 1)
+	created := insertWebhookDeliveries[0]
+	if err := repo.CreateWebhookDelivery(created); err != nil {
+		t.Fatalf("Failed to create webhookDelivery: %+v", err)
+	}
+
+	// Update the record
+	updated := insertWebhookDeliveries[0]
+	updated.WebhookID = "updatedWebhookID"
+	if err := repo.UpdateWebhookDelivery(updated); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+
+	// Find by ID
+	var find = model.WebhookDelivery{}
+	if err := tx.Where(&model.WebhookDelivery{ID: updated.ID}).First(&find).Error; err != nil {
+		t.Fatalf("Failed to find webhookDelivery: %+v", err)
+	}
+
+	// Verify updated equals find
+	if !assert.Equal(t, find, *updated) {
+		t.Errorf("expected %v, but got %v", find, updated)
+	}
+}
+
+func TestWebhookDeliveryRepository_DeleteWebhookDelivery(t *testing.T) {
+	tx, repo := newTxAndWebhookDeliveryRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryId-delete", "deleteWebhookID", 1)
+	err := insertWebhookDeliveryTestData(tx, insertWebhookDeliveries)
+	if err != nil {
+		t.Fatalf("Failed to create WebhookDelivery: %+v", err)
+	}
+	deleted := insertWebhookDeliveries[0]
+	t.Run("Record will not be deleted if ID is empty", func(t *testing.T) {
+		deletedID := deleted.ID
+		deleted.ID = "" // Clear ID
+		if err := repo.DeleteWebhookDelivery(deleted); err != nil {
+			t.Errorf("Failed to delete: %+v", err)
+		}
+		// record must exist
+		result := model.WebhookDelivery{}
+		if err := tx.Where(&model.WebhookDelivery{ID: deletedID}).Find(&result).Error; err != nil {
+			t.Errorf("Failed to find: %+v", err)
+		}
+		result.ID = deletedID
+		deleted.ID = deletedID // Restore ID
+
+		// Verify not deleted
+		if !assert.Equal(t, *deleted, result) {
+			t.Error("Record is not same")
+		}
+	})
+
+	t.Run("Record will be deleted if ID is set", func(t *testing.T) {
+		if err := repo.DeleteWebhookDelivery(deleted); err != nil {
+			t.Errorf("Failed to delete: %+v", err)
+		}
+
+		// Check record will not be found
+		result := model.WebhookDelivery{}
+		err := tx.Where(&model.WebhookDelivery{ID: deleted.ID}).First(&result).Error
+		if !orm.IsRecordNotFoundError(err) {
+			t.Errorf("Record must be deleted, but got: %+v", err)
+		}
+	})
+}
+
+// Test for CreateWebhookDeliveries, UpdateWebhookDeliveries, DeleteWebhookDeliveries are ommitted,
+// because that they are called internally in each single version
+
+////
+/// Optimistic lock test (if version lock supported)
+//
+func TestWebhookDeliveryRepository_UpdateWebhookDeliveryOptimisticCheck(t *testing.T) {
+	tx1, _ := newTxAndWebhookDeliveryRepository()
+	tx2, repo2 := newTxAndWebhookDeliveryRepository()
+	tx3, repo3 := newTxAndWebhookDeliveryRepository()
+	defer tx1.Rollback()
+	defer tx2.Rollback()
+	defer tx3.Rollback()
+
+	// Create and commit 1 record in tx1
+	insertWebhookDeliveries := createWebhookDeliveryTestData(tx1, "webhookDeliveryID-optimistic", "", 1)
+	err := insertWebhookDeliveryTestData(tx1, insertWebhookDeliveries)
+	if err != nil {
+		t.Fatalf("Failed to create webhookDelivery: %+v", err)
+	}
+	if err := tx1.Commit().Error; err != nil {
+		t.Fatalf("Failed to commit data in tx1")
+	}
+	// Get commited data in tx2
+	data := insertWebhookDeliveries[0]
+	find, err := repo2.FindFirstWebhookDelivery(model.WebhookDelivery{ID: data.ID}, []string{})
+	if err != nil {
+		deleteCommitedWebhookDeliveryData(t, data)
+	}
+	find.WebhookID = "UpdateInTx2"
+	data.WebhookID = "NotUpdateInTx3"
+	// Find updated in tx2 (Version number incremented!!)
+	err = repo2.UpdateWebhookDelivery(&find)
+	if err != nil {
+		deleteCommitedWebhookDeliveryData(t, data)
+		t.Fatalf("Failed to update in tx2.")
+	}
+	err = tx2.Commit().Error
+	if err != nil {
+		deleteCommitedWebhookDeliveryData(t, data)
+		t.Fatalf("Failed to commit data in tx2")
+	}
+	// Check to not update due to optimistic error
+	if !assert.Error(t, repo3.UpdateWebhookDelivery(data)) {
+		deleteCommitedWebhookDeliveryData(t, data)
+		t.Fatalf("Not failed to update, No error occurred")
+	}
+	err = tx3.Commit().Error
+	if err != nil {
+		deleteCommitedWebhookDeliveryData(t, data)
+		t.Fatalf("Failed to Commit tx3, no affected row")
+	}
+
+	tx4, _ := newTxAndWebhookDeliveryRepository()
+	defer tx4.Rollback()
+	var result = model.WebhookDelivery{}
+	// Can be found as same as find(tx2)
+	if err := tx4.Where(model.WebhookDelivery{ID: find.ID}).Find(&result).Error; err != nil {
+		t.Fatalf("failed to retrieve WebhookDelivery: %+v", err)
+	}
+	deleteCommitedWebhookDeliveryData(t, data)
+	if !assert.Equal(t, find, result) {
+		t.Errorf("expected %v, but got %v", find, result)
+	}
+}
+
+func deleteCommitedWebhookDeliveryData(t *testing.T, data *model.WebhookDelivery) {
+	// Try to delete data in another transaction
+	tx, repo := newTxAndWebhookDeliveryRepository()
+	defer tx.Rollback()
+	err := repo.DeleteWebhookDelivery(data)
+	if err != nil {
+		t.Log("*** Failed to delete test data, please restart test. ***")
+	} else {
+		err := tx.Commit().Error
+		if err != nil {
+			t.Log("*** Failed to delete test data, please restart test. ***")
+		}
+	}
+}
+
+////
+/// Other fuctions' test should be written in below
+//
+func TestWebhookDeliveryRepository_FindDueWebhookDeliveries(t *testing.T) {
+	tx, repo := newTxAndWebhookDeliveryRepository()
+	defer tx.Rollback()
+
+	// Create 4 records, which are due, not due yet, succeeded and failed
+	now := time.Now().UTC()
+	insertWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-due", "dueWebhookID", 4)
+	insertWebhookDeliveries[0].NextAttemptDate = now.Add(-1 * time.Second)
+	insertWebhookDeliveries[1].NextAttemptDate = now.Add(1 * time.Hour)
+	insertWebhookDeliveries[2].Status = model.DeliveryStatusSucceeded
+	insertWebhookDeliveries[3].Status = model.DeliveryStatusFailed
+	err := insertWebhookDeliveryTestData(tx, insertWebhookDeliveries)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	webhookDeliveries, err := repo.FindDueWebhookDeliveries(now, orm.NoLimit)
+	if err != nil {
+		t.Fatalf("Failed to find webhook deliveries: %+v", err)
+	}
+	dueIDs := []string{}
+	for _, webhookDelivery := range webhookDeliveries {
+		if webhookDelivery.WebhookID == "dueWebhookID" {
+			dueIDs = append(dueIDs, webhookDelivery.ID)
+		}
+	}
+	assert.Equal(t, []string{insertWebhookDeliveries[0].ID}, dueIDs)
+}
+
+func TestWebhookDeliveryRepository_UpdateWebhookDeliveryToBlank(t *testing.T) {
+	tx, repo := newTxAndWebhookDeliveryRepository()
+	defer tx.Rollback()
+
+	insertWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-blank", "blankWebhookID", 1)
+	delivery := insertWebhookDeliveries[0]
+	delivery.LastStatusCode = 500
+	delivery.LastError = "500 Internal Server Error"
+	if err := repo.CreateWebhookDelivery(delivery); err != nil {
+		t.Fatalf("Failed to create webhookDelivery: %+v", err)
+	}
+
+	// Blank fields must be cleared
+	delivery.LastStatusCode = 0
+	delivery.LastError = ""
+	if err := repo.UpdateWebhookDelivery(delivery); err != nil {
+		t.Fatalf("Failed to update webhookDelivery: %+v", err)
+	}
+	find, err := repo.FindFirstWebhookDelivery(&model.WebhookDelivery{ID: delivery.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find webhookDelivery: %+v", err)
+	}
+	assert.Equal(t, *delivery, find)
+}
+
+func TestWebhookDeliveryRepository_DeleteDeliveriesOfWebhook(t *testing.T) {
+	tx, repo := newTxAndWebhookDeliveryRepository()
+	defer tx.Rollback()
+
+	firstWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-delete", "deleteWebhookID", 3)
+	secondWebhookDeliveries := createWebhookDeliveryTestData(tx, "webhookDeliveryID-not-delete", "notDeleteWebhookID", 2)
+	err := insertWebhookDeliveryTestData(tx, append(firstWebhookDeliveries, secondWebhookDeliveries...))
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+	err = repo.DeleteDeliveriesOfWebhook("deleteWebhookID")
+	if err != nil {
+		t.Fatalf("Failed to delete webhook deliveries: %+v", err)
+	}
+	count, err := repo.CountWebhookDeliveries(&model.WebhookDelivery{WebhookID: "deleteWebhookID"})
+	if err != nil {
+		t.Fatalf("Failed to count webhook deliveries: %+v", err)
+	}
+	assert.Equal(t, 0, count)
+	count, err = repo.CountWebhookDeliveries(&model.WebhookDelivery{WebhookID: "notDeleteWebhookID"})
+	if err != nil {
+		t.Fatalf("Failed to count webhook deliveries: %+v", err)
+	}
+	assert.Equal(t, 2, count)
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"sync"
+	"taskboard/model"
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+var lockWebhook = &sync.Mutex{}
+
+// WebhookRepository is repository of webhook table
+type WebhookRepository struct {
+	tx *gorm.DB
+}
+
+// NewWebhookRepository returns new instance of WebhookRepository
+func NewWebhookRepository(tx *gorm.DB) *WebhookRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &WebhookRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstWebhook returns first Webhook matching with specified condition
+func (repo *WebhookRepository) FindFirstWebhook(condition interface{}, sortOrders []string) (result model.Webhook, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindWebhooks returns Webhooks matching with specified condition
+func (repo *WebhookRepository) FindWebhooks(condition interface{}, offset int, limit int, sortOrders []string) (result []model.Webhook, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, webhook := range sortOrders {
+		query = query.Order(webhook)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountWebhooks returns the number of Webhooks matching specfied condition
+func (repo *WebhookRepository) CountWebhooks(condition interface{}) (count int, err error) {
+	var webhooks []model.Webhook
+	err = repo.tx.Where(condition).Find(&webhooks).Count(&count).Error
+	return
+}
+
+// CreateWebhook inserts new Webhook record
+func (repo *WebhookRepository) CreateWebhook(webhook *model.Webhook) error {
+	return repo.CreateWebhooks([]*model.Webhook{webhook})
+}
+
+// UpdateWebhook updates Webhook record
+func (repo *WebhookRepository) UpdateWebhook(webhook *model.Webhook) error {
+	return repo.UpdateWebhooks([]*model.Webhook{webhook})
+}
+
+// DeleteWebhook deletes Webhook record
+func (repo *WebhookRepository) DeleteWebhook(webhook *model.Webhook) error {
+	return repo.DeleteWebhooks([]*model.Webhook{webhook})
+}
+
+// CreateWebhooks inserts new Webhook records.
+func (repo *WebhookRepository) CreateWebhooks(webhooks []*model.Webhook) (err error) {
+	for _, webhook := range webhooks {
+		err = repo.tx.Create(webhook).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateWebhooks updates webhook records
+func (repo *WebhookRepository) UpdateWebhooks(webhooks []*model.Webhook) (err error) {
+	lockWebhook.Lock()
+	defer lockWebhook.Unlock()
+
+	for _, webhook := range webhooks {
+		oldVersion := webhook.Version
+		webhook.Version++
+		db := repo.tx.Model(&model.Webhook{}).Where("version = ?", oldVersion).Updates(webhook)
+		count := db.RowsAffected
+		err = db.Error
+		// return ErrorRecordNotFoud as optimistic lock error
+		if err == nil && count == 0 {
+			return orm.ErrorRecordNotFound
+		}
+		if err != nil {
+			return
+		}
+		// Updates() skips blank fields, so fields which can be blank are updated explicitly to be cleared
+		err = repo.tx.Model(&model.Webhook{}).Where("id = ?", webhook.ID).
+			Updates(map[string]interface{}{
+				"is_active": webhook.IsActive,
+			}).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteWebhooks deletes Webhook records
+func (repo *WebhookRepository) DeleteWebhooks(webhooks []*model.Webhook) (err error) {
+	for _, webhook := range webhooks {
+		if webhook.ID == "" {
+			continue // To avoid deleting all due to gorm warning, continue here.
+		}
+		err = repo.tx.Delete(webhook).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
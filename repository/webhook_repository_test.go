@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"fmt"
+	"taskboard/common"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndWebhookRepository() (tx *gorm.DB, repo *WebhookRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewWebhookRepository(tx)
+	return
+}
+
+func createWebhookTestData(tx *gorm.DB, idFormat string, findIdentify string, count int) []*model.Webhook {
+	result := make([]*model.Webhook, 0, count)
+	for i := 0; i < count; i++ {
+		webhook := model.NewWebhook(
+			findIdentify,
+			"secret"+common.GenerateID(),
+			[]string{"task.created", "task.moved"},
+			true,
+			time.Now().UTC(),
+		)
+		webhook.ID = fmt.Sprintf("%s-%03d", idFormat, i)
+		result = append(result, webhook)
+	}
+	return result
+}
+
+func insertWebhookTestData(tx *gorm.DB, webhooks []*model.Webhook) (err error) {
+	for _, webhook := range webhooks {
+		err = tx.Create(webhook).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+////
+/// Common repository functions' test
+//
+func TestWebhookRepository_FindFirstWebhook(t *testing.T) {
+	tx, repo := newTxAndWebhookRepository()
+	defer tx.Rollback()
+
+	firstWebhooks := createWebhookTestData(tx, "webhookID-find", "findURL", 5)
+	secondWebhooks := createWebhookTestData(tx, "webhookID-not-find", "notFindURL", 4)
+	insertWebhooks := append(firstWebhooks, secondWebhooks...)
+	err := insertWebhookTestData(tx, insertWebhooks)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+	// Sort by id
+	webhook, err := repo.FindFirstWebhook(&model.Webhook{URL: "findURL"}, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find first: %+v", err)
+	}
+	expected := "webhookID-find-000"
+	if webhook.ID != expected {
+		t.Errorf("expected webhook ID is %s, but got %s", expected, webhook.ID)
+	}
+}
+
+func TestWebhookRepository_FindWebhooks(t *testing.T) {
+	tx, repo := newTxAndWebhookRepository()
+	defer tx.Rollback()
+
+	firstWebhooks := createWebhookTestData(tx, "webhookID-find", "findURL", 5)
+	secondWebhooks := createWebhookTestData(tx, "webhookID-not-find", "notFindURL", 4)
+	insertWebhooks := append(firstWebhooks, secondWebhooks...)
+	err := insertWebhookTestData(tx, insertWebhooks)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	// Get 3 data from 2nd(index=1) position
+	const (
+		offset = 1
+		limit  = 3
+	)
+	webhooks, err := repo.FindWebhooks(&model.Webhook{URL: "findURL"}, offset, limit, []string{"id"})
+	if err != nil {
+		t.Fatalf("Failed to execute find: %+v", err)
+	}
+
+	// Length of data must be limit
+	if len(webhooks) != limit {
+		t.Errorf("Expected result size = %d, but got %d", limit, len(webhooks))
+		return
+	}
+	// Head must be 001
+	head := webhooks[0]
+	headExpected := "webhookID-find-001"
+	if head.ID != headExpected {
+		t.Errorf("head ID must be %s, but got %s", headExpected, head.ID)
+	}
+	// Tail must be 003
+	tail := webhooks[len(webhooks)-1]
+	tailExpected := "webhookID-find-003"
+	if tail.ID != tailExpected {
+		t.Errorf("tail ID must be %s, but got %s", tailExpected, tail.ID)
+	}
+}
+
+func TestAddressRepository_CountWebhooks(t *testing.T) {
+	tx, repo := newTxAndWebhookRepository()
+	defer tx.Rollback()
+
+	expected := 5
+	firstWebhooks := createWebhookTestData(tx, "webhookID-find", "findURL", 5)
+	secondWebhooks := createWebhookTestData(tx, "webhookID-not-find", "notFindURL", 4)
+	insertWebhooks := append(firstWebhooks, secondWebhooks...)
+	err := insertWebhookTestData(tx, insertWebhooks)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %+v", err)
+	}
+
+	count, err := repo.CountWebhooks(&model.Webhook{URL: "findURL"})
+	if err != nil {
+		t.Fatalf("failed to count Webhook: %+v", err)
+	}
+	if expected != count {
+		t.Errorf("expected %d records, but got %d record", expected, count)
+	}
+}
+
+func TestWebhookRepository_CreateWebhook(t *testing.T) {
+	tx, repo := newTxAndWebhookRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertWebhooks := createWebhookTestData(tx, "webhookID-create", "createURL", 1)
+	created := insertWebhooks[0]
+	if err := repo.CreateWebhook(created); err != nil {
+		t.Fatalf("Failed to create webhook: %+v", err)
+	}
+
+	// Find by ID
+	var find = model.Webhook{}
+	if err := tx.Where(&model.Webhook{ID: created.ID}).First(&find).Error; err != nil {
+		t.Fatalf("Failed to find webhook: %+v", err)
+	}
+	// Verify created equals find
+	if !assert.Equal(t, find, *created) {
+		t.Errorf("expected: %+v, but got %+v", created.ID, find.ID)
+	}
+}
+
+func TestWebhookRepository_UpdateWebhook(t *testing.T) {
+	tx, repo := newTxAndWebhookRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertWebhooks := createWebhookTestData(tx, "webhookID-create", "createURL", 1)
+	created := insertWebhooks[0]
+	if err := repo.CreateWebhook(created); err != nil {
+		t.Fatalf("Failed to create webhook: %+v", err)
+	}
+
+	// Update the record
+	updated := insertWebhooks[0]
+	updated.URL = "updatedURL"
+	if err := repo.UpdateWebhook(updated); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+
+	// Find by ID
+	var find = model.Webhook{}
+	if err := tx.Where(&model.Webhook{ID: updated.ID}).First(&find).Error; err != nil {
+		t.Fatalf("Failed to find webhook: %+v", err)
+	}
+
+	// Verify updated equals find
+	if !assert.Equal(t, find, *updated) {
+		t.Errorf("expected %v, but got %v", find, updated)
+	}
+}
+
+func TestWebhookRepository_DeleteWebhook(t *testing.T) {
+	tx, repo := newTxAndWebhookRepository()
+	defer tx.Rollback()
+
+	// Create 1 record
+	insertWebhooks := createWebhookTestData(tx, "webhookId-delete", "deleteURL", 1)
+	err := insertWebhookTestData(tx, insertWebhooks)
+	if err != nil {
+		t.Fatalf("Failed to create Webhook: %+v", err)
+	}
+	deleted := insertWebhooks[0]
+	t.Run("Record will not be deleted if ID is empty", func(t *testing.T) {
+		deletedID := deleted.ID
+		deleted.ID = "" // Clear ID
+		if err := repo.DeleteWebhook(deleted); err != nil {
+			t.Errorf("Failed to delete: %+v", err)
+		}
+		// record must exist
+		result := model.Webhook{}
+		if err := tx.Where(&model.Webhook{ID: deletedID}).Find(&result).Error; err != nil {
+			t.Errorf("Failed to find: %+v", err)
+		}
+		result.ID = deletedID
+		deleted.ID = deletedID // Restore ID
+
+		// Verify not deleted
+		if !assert.Equal(t, *deleted, result) {
+			t.Error("Record is not same")
+		}
+	})
+
+	t.Run("Record will be deleted if ID is set", func(t *testing.T) {
+		if err := repo.DeleteWebhook(deleted); err != nil {
+			t.Errorf("Failed to delete: %+v", err)
+		}
+
+		// Check record will not be found
+		result := model.Webhook{}
+		err := tx.Where(&model.Webhook{ID: deleted.ID}).First(&result).Error
+		if !orm.IsRecordNotFoundError(err) {
+			t.Errorf("Record must be deleted, but got: %+v", err)
+		}
+	})
+}
+
+// Test for CreateWebhooks, UpdateWebhooks, DeleteWebhooks are ommitted,
+// because that they are called internally in each single version
+
+////
+/// Optimistic lock test (if version lock supported)
+//
+func TestWebhookRepository_UpdateWebhookOptimisticCheck(t *testing.T) {
+	tx1, _ := newTxAndWebhookRepository()
+	tx2, repo2 := newTxAndWebhookRepository()
+	tx3, repo3 := newTxAndWebhookRepository()
+	defer tx1.Rollback()
+	defer tx2.Rollback()
+	defer tx3.Rollback()
+
+	// Create and commit 1 record in tx1
+	insertWebhooks := createWebhookTestData(tx1, "webhookID-optimistic", "", 1)
+	err := insertWebhookTestData(tx1, insertWebhooks)
+	if err != nil {
+		t.Fatalf("Failed to create webhook: %+v", err)
+	}
+	if err := tx1.Commit().Error; err != nil {
+		t.Fatalf("Failed to commit data in tx1")
+	}
+	// Get commited data in tx2
+	data := insertWebhooks[0]
+	find, err := repo2.FindFirstWebhook(model.Webhook{ID: data.ID}, []string{})
+	if err != nil {
+		deleteCommitedWebhookData(t, data)
+	}
+	find.URL = "UpdateInTx2"
+	data.URL = "NotUpdateInTx3"
+	// Find updated in tx2 (Version number incremented!!)
+	err = repo2.UpdateWebhook(&find)
+	if err != nil {
+		deleteCommitedWebhookData(t, data)
+		t.Fatalf("Failed to update in tx2.")
+	}
+	err = tx2.Commit().Error
+	if err != nil {
+		deleteCommitedWebhookData(t, data)
+		t.Fatalf("Failed to commit data in tx2")
+	}
+	// Check to not update due to optimistic error
+	if !assert.Error(t, repo3.UpdateWebhook(data)) {
+		deleteCommitedWebhookData(t, data)
+		t.Fatalf("Not failed to update, No error occurred")
+	}
+	err = tx3.Commit().Error
+	if err != nil {
+		deleteCommitedWebhookData(t, data)
+		t.Fatalf("Failed to Commit tx3, no affected row")
+	}
+
+	tx4, _ := newTxAndWebhookRepository()
+	defer tx4.Rollback()
+	var result = model.Webhook{}
+	// Can be found as same as find(tx2)
+	if err := tx4.Where(model.Webhook{ID: find.ID}).Find(&result).Error; err != nil {
+		t.Fatalf("failed to retrieve Webhook: %+v", err)
+	}
+	deleteCommitedWebhookData(t, data)
+	if !assert.Equal(t, find, result) {
+		t.Errorf("expected %v, but got %v", find, result)
+	}
+}
+
+func deleteCommitedWebhookData(t *testing.T, data *model.Webhook) {
+	// Try to delete data in another transaction
+	tx, repo := newTxAndWebhookRepository()
+	defer tx.Rollback()
+	err := repo.DeleteWebhook(data)
+	if err != nil {
+		t.Log("*** Failed to delete test data, please restart test. ***")
+	} else {
+		err := tx.Commit().Error
+		if err != nil {
+			t.Log("*** Failed to delete test data, please restart test. ***")
+		}
+	}
+}
+
+////
+/// Other fuctions' test should be written in below
+//
+
+func TestWebhookRepository_UpdateWebhookToBlank(t *testing.T) {
+	tx, repo := newTxAndWebhookRepository()
+	defer tx.Rollback()
+
+	insertWebhooks := createWebhookTestData(tx, "webhookID-blank", "blankURL", 1)
+	webhook := insertWebhooks[0]
+	if err := repo.CreateWebhook(webhook); err != nil {
+		t.Fatalf("Failed to create webhook: %+v", err)
+	}
+
+	// Blank fields must be cleared
+	webhook.IsActive = false
+	if err := repo.UpdateWebhook(webhook); err != nil {
+		t.Fatalf("Failed to update webhook: %+v", err)
+	}
+	find, err := repo.FindFirstWebhook(&model.Webhook{ID: webhook.ID}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find webhook: %+v", err)
+	}
+	assert.Equal(t, *webhook, find)
+}
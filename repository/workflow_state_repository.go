@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"sync"
+	"taskboard/model"
+	"taskboard/orm"
+
+	"github.com/jinzhu/gorm"
+)
+
+var lockWorkflowState = &sync.Mutex{}
+
+// WorkflowStateRepository is repository of workflow_state table
+type WorkflowStateRepository struct {
+	tx *gorm.DB
+}
+
+// NewWorkflowStateRepository returns new instance of WorkflowStateRepository
+func NewWorkflowStateRepository(tx *gorm.DB) *WorkflowStateRepository {
+	if tx == nil {
+		// Programing error!!
+		panic("tx must be set")
+	}
+	return &WorkflowStateRepository{
+		tx: tx,
+	}
+}
+
+// FindFirstWorkflowState returns first WorkflowState matching with specified condition
+func (repo *WorkflowStateRepository) FindFirstWorkflowState(condition interface{}, sortOrders []string) (result model.WorkflowState, err error) {
+	query := repo.tx.Where(condition)
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+
+	for _, sortOrder := range sortOrders {
+		query = query.Order(sortOrder)
+	}
+	err = query.First(&result).Error
+	return
+}
+
+// FindWorkflowStates returns WorkflowStates matching with specified condition
+func (repo *WorkflowStateRepository) FindWorkflowStates(condition interface{}, offset int, limit int, sortOrders []string) (result []model.WorkflowState, err error) {
+	query := repo.tx.Where(condition)
+	if offset >= 0 {
+		query = query.Offset(offset)
+	}
+	if limit >= 0 {
+		query = query.Limit(limit)
+	}
+
+	if sortOrders == nil {
+		sortOrders = []string{}
+	}
+	for _, workflowState := range sortOrders {
+		query = query.Order(workflowState)
+	}
+
+	err = query.Find(&result).Error
+	return
+}
+
+// CountWorkflowStates returns the number of WorkflowStates matching specfied condition
+func (repo *WorkflowStateRepository) CountWorkflowStates(condition interface{}) (count int, err error) {
+	var workflowStates []model.WorkflowState
+	err = repo.tx.Where(condition).Find(&workflowStates).Count(&count).Error
+	return
+}
+
+// CreateWorkflowState inserts new WorkflowState record
+func (repo *WorkflowStateRepository) CreateWorkflowState(workflowState *model.WorkflowState) error {
+	return repo.CreateWorkflowStates([]*model.WorkflowState{workflowState})
+}
+
+// UpdateWorkflowState updates WorkflowState record
+func (repo *WorkflowStateRepository) UpdateWorkflowState(workflowState *model.WorkflowState) error {
+	return repo.UpdateWorkflowStates([]*model.WorkflowState{workflowState})
+}
+
+// DeleteWorkflowState deletes WorkflowState record
+func (repo *WorkflowStateRepository) DeleteWorkflowState(workflowState *model.WorkflowState) error {
+	return repo.DeleteWorkflowStates([]*model.WorkflowState{workflowState})
+}
+
+// CreateWorkflowStates inserts new WorkflowState records.
+func (repo *WorkflowStateRepository) CreateWorkflowStates(workflowStates []*model.WorkflowState) (err error) {
+	for _, workflowState := range workflowStates {
+		err = repo.tx.Create(workflowState).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateWorkflowStates updates workflowState records
+func (repo *WorkflowStateRepository) UpdateWorkflowStates(workflowStates []*model.WorkflowState) (err error) {
+	lockWorkflowState.Lock()
+	defer lockWorkflowState.Unlock()
+
+	for _, workflowState := range workflowStates {
+		oldVersion := workflowState.Version
+		workflowState.Version++
+		db := repo.tx.Model(&model.WorkflowState{}).Where("version = ?", oldVersion).Updates(workflowState)
+		count := db.RowsAffected
+		err = db.Error
+		// return ErrorRecordNotFoud as optimistic lock error
+		if err == nil && count == 0 {
+			return orm.ErrorRecordNotFound
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteWorkflowStates deletes WorkflowState records
+func (repo *WorkflowStateRepository) DeleteWorkflowStates(workflowStates []*model.WorkflowState) (err error) {
+	for _, workflowState := range workflowStates {
+		if workflowState.ID == "" {
+			continue // To avoid deleting all due to gorm warning, continue here.
+		}
+		err = repo.tx.Delete(workflowState).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
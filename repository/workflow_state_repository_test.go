@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+////
+/// Model specific functions (Only replace model name, take care names are casesencitive!!)
+//
+func newTxAndWorkflowStateRepository() (tx *gorm.DB, repo *WorkflowStateRepository) {
+	tx = orm.GetDB().Begin()
+	repo = NewWorkflowStateRepository(tx)
+	return
+}
+
+////
+/// Common repository functions' test
+//
+func TestWorkflowStateRepository_FindWorkflowStates(t *testing.T) {
+	tx, repo := newTxAndWorkflowStateRepository()
+	defer tx.Rollback()
+
+	// Default workflow is created by migration
+	states, err := repo.FindWorkflowStates(&model.WorkflowState{}, 0, orm.NoLimit, []string{"position"})
+	if err != nil {
+		t.Fatalf("Failed to execute find: %+v", err)
+	}
+	assert.Equal(t, []model.WorkflowState{
+		{ID: "icebox", Name: "Icebox", Position: 0, IsInbox: true, IsDone: false, Version: 1},
+		{ID: "todo", Name: "Todo", Position: 1, IsInbox: false, IsDone: false, Version: 1},
+		{ID: "doing", Name: "Doing", Position: 2, IsInbox: false, IsDone: false, Version: 1},
+		{ID: "done", Name: "Done", Position: 3, IsInbox: false, IsDone: true, Version: 1},
+	}, states)
+
+	inbox, err := repo.FindFirstWorkflowState(&model.WorkflowState{IsInbox: true}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to execute find first: %+v", err)
+	}
+	assert.Equal(t, "icebox", inbox.ID)
+}
+
+func TestWorkflowStateRepository_CreateAndDeleteWorkflowStates(t *testing.T) {
+	tx, repo := newTxAndWorkflowStateRepository()
+	defer tx.Rollback()
+
+	states := []*model.WorkflowState{
+		model.NewWorkflowState("state-review", "Review", false, false),
+		model.NewWorkflowState("state-released", "Released", false, true),
+	}
+	states[0].Position = 10
+	states[1].Position = 11
+	if err := repo.CreateWorkflowStates(states); err != nil {
+		t.Fatalf("Failed to create workflow states: %+v", err)
+	}
+	count, err := repo.CountWorkflowStates("id like 'state-%'")
+	if err != nil {
+		t.Fatalf("Failed to count workflow states: %+v", err)
+	}
+	assert.Equal(t, 2, count)
+
+	// Update the record
+	states[0].Name = "Code review"
+	if err := repo.UpdateWorkflowState(states[0]); err != nil {
+		t.Fatalf("Failed to update workflow state: %+v", err)
+	}
+	find, err := repo.FindFirstWorkflowState(&model.WorkflowState{ID: "state-review"}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find workflow state: %+v", err)
+	}
+	assert.Equal(t, *states[0], find)
+
+	if err := repo.DeleteWorkflowStates(states); err != nil {
+		t.Fatalf("Failed to delete workflow states: %+v", err)
+	}
+	count, err = repo.CountWorkflowStates("id like 'state-%'")
+	if err != nil {
+		t.Fatalf("Failed to count workflow states: %+v", err)
+	}
+	assert.Equal(t, 0, count)
+}
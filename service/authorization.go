@@ -0,0 +1,61 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/repository"
+)
+
+// authorize checks whether login user has one of specified roles.
+// Login user is nil when service is called internally (e.g. creating system boards at startup),
+// it is allowed to do anything.
+func authorize(loginUser *model.User, roles ...string) error {
+	if loginUser == nil || loginUser.HasRole(roles...) {
+		return nil
+	}
+	return NewSvcErrorf(ErrorCodeForbidden, nil, "User [%s] whose role is [%s] is not allowed to do this operation",
+		loginUser.Name, loginUser.Role)
+}
+
+// authorizeEditor checks whether login user can change boards and tasks
+func authorizeEditor(loginUser *model.User) error {
+	return authorize(loginUser, model.RoleAdmin, model.RoleMember)
+}
+
+// authorizeAdmin checks whether login user can manage users and system boards
+func authorizeAdmin(loginUser *model.User) error {
+	return authorize(loginUser, model.RoleAdmin)
+}
+
+// visibleProjectIDs returns IDs of projects which login user is a member of.
+// Returns nil if login user can see all projects, that is admin or internal call.
+func visibleProjectIDs(projectRepo *repository.ProjectRepository, loginUser *model.User) ([]string, error) {
+	if loginUser == nil || loginUser.HasRole(model.RoleAdmin) {
+		return nil, nil
+	}
+	projectIDs, err := projectRepo.FindMemberProjectIDs(loginUser.ID)
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find projects of user")
+	}
+	if projectIDs == nil {
+		projectIDs = []string{}
+	}
+	return projectIDs, nil
+}
+
+// isProjectVisible checks whether login user can see the project and its boards and tasks
+func isProjectVisible(projectRepo *repository.ProjectRepository, loginUser *model.User, projectID string) (bool, error) {
+	projectIDs, serr := visibleProjectIDs(projectRepo, loginUser)
+	if serr != nil {
+		return false, serr
+	}
+	return projectIDs == nil || containsProjectID(projectIDs, projectID), nil
+}
+
+func containsProjectID(projectIDs []string, projectID string) bool {
+	for _, id := range projectIDs {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,448 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"taskboard/event"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// maxAutomationDepth is the max depth of rules triggered by actions of other rules
+const maxAutomationDepth = 5
+
+// automationChain is the state shared by rules which are triggered by a call of TaskService and by their actions.
+// A rule runs only once for a task in a chain, so that rules triggering each other do not loop forever.
+type automationChain struct {
+	depth int             // 0 for rules triggered by user or worker
+	ran   map[string]bool // Keys of rule and task which have run in the chain
+}
+
+func newAutomationChain() *automationChain {
+	return &automationChain{ran: map[string]bool{}}
+}
+
+// next returns chain for rules triggered by actions of rules in this chain
+func (c *automationChain) next() *automationChain {
+	return &automationChain{depth: c.depth + 1, ran: c.ran}
+}
+
+// automationFiredData is data of event sent by fireWebhook action
+type automationFiredData struct {
+	RuleID   string `json:"ruleID"`
+	RuleName string `json:"ruleName"`
+	Trigger  string `json:"trigger"`
+	TaskID   string `json:"taskID"`
+	TaskName string `json:"taskName"`
+	BoardID  string `json:"boardID"`
+}
+
+// AutomationService provides apis for automation rules and runs them for tasks.
+// Rules run in the transaction of the call of TaskService which triggered them, and their actions run as internal call.
+type AutomationService struct {
+	tx            *gorm.DB
+	loginUser     *model.User
+	ruleRepo      *repository.AutomationRuleRepository
+	executionRepo *repository.AutomationExecutionRepository
+	taskRepo      *repository.TaskRepository
+	userRepo      *repository.UserRepository
+}
+
+// NewAutomationService return new instance of AutomationService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewAutomationService(tx *gorm.DB, loginUser *model.User) *AutomationService {
+	return &AutomationService{
+		tx:            tx,
+		loginUser:     loginUser,
+		ruleRepo:      repository.NewAutomationRuleRepository(tx),
+		executionRepo: repository.NewAutomationExecutionRepository(tx),
+		taskRepo:      repository.NewTaskRepository(tx),
+		userRepo:      repository.NewUserRepository(tx),
+	}
+}
+
+// FindAutomationRule returns automation rule matching specified condition
+func (s *AutomationService) FindAutomationRule(condition interface{}) (*model.AutomationRule, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	find, err := s.ruleRepo.FindFirstAutomationRule(condition, []string{"id"})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, err, "Automation rule not found")
+		}
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find automation rule")
+	}
+	return &find, nil
+}
+
+// FindAutomationRulesPage finds a page of automation rules and returns it with the number of all rules matching condition
+func (s *AutomationService) FindAutomationRulesPage(condition interface{}, after *orm.Keyset, limit int, sortOrders []string,
+) ([]model.AutomationRule, int, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, 0, serr
+	}
+	rules, err := repository.NewAutomationRuleRepository(orm.WhereAfter(s.tx, after)).
+		FindAutomationRules(condition, 0, limit, sortOrders)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to find automation rules")
+	}
+	count, err := s.ruleRepo.CountAutomationRules(condition)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to count automation rules")
+	}
+	return rules, count, nil
+}
+
+// CreateAutomationRule creates new automation rule
+func (s *AutomationService) CreateAutomationRule(rule *model.AutomationRule) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := s.validateAutomationRule(rule); serr != nil {
+		return serr
+	}
+	err := s.ruleRepo.CreateAutomationRule(rule)
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to create automation rule")
+	}
+	return nil
+}
+
+// UpdateAutomationRule updates specifed automation rule
+func (s *AutomationService) UpdateAutomationRule(rule *model.AutomationRule) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := s.validateAutomationRule(rule); serr != nil {
+		return serr
+	}
+	err := s.ruleRepo.UpdateAutomationRule(rule)
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return NewSvcErrorf(ErrorCodeOptimisticLockFailure, err, "Automation rule has been updated by other request. ID:%s", rule.ID)
+		}
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update automation rule. ID:%s", rule.ID)
+	}
+	return nil
+}
+
+// DeleteAutomationRule deletes specifed automation rule and its executions
+func (s *AutomationService) DeleteAutomationRule(rule *model.AutomationRule) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	err := s.ruleRepo.DeleteAutomationRule(rule)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete automation rule. ID:%s", rule.ID)
+	}
+	err = s.executionRepo.DeleteRuleAutomationExecutions(rule.ID)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete executions of automation rule. ID:%s", rule.ID)
+	}
+	return nil
+}
+
+// FindAutomationExecutionsPage finds a page of executions of automation rule and returns it with the number of all executions
+func (s *AutomationService) FindAutomationExecutionsPage(ruleID string, after *orm.Keyset, limit int, sortOrders []string,
+) ([]model.AutomationExecution, int, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, 0, serr
+	}
+	condition := &model.AutomationExecution{RuleID: ruleID}
+	executions, err := repository.NewAutomationExecutionRepository(orm.WhereAfter(s.tx, after)).
+		FindAutomationExecutions(condition, 0, limit, sortOrders)
+	if err != nil {
+		return nil, 0, NewSvcErrorf(ErrorCodeDB, err, "Failed to find executions of automation rule. ID:%s", ruleID)
+	}
+	count, err := s.executionRepo.CountAutomationExecutions(condition)
+	if err != nil {
+		return nil, 0, NewSvcErrorf(ErrorCodeDB, err, "Failed to count executions of automation rule. ID:%s", ruleID)
+	}
+	return executions, count, nil
+}
+
+// RunDuePassedRules runs rules of duePassed trigger for open tasks whose due date has passed.
+// A rule runs once for a task after its due date, it runs again if the due date is changed and passed again.
+// Returns tasks which rules ran for, as they are after actions.
+func (s *AutomationService) RunDuePassedRules(now time.Time) ([]model.Task, error) {
+	rules, err := s.ruleRepo.FindAutomationRules(&model.AutomationRule{Trigger: model.AutomationTriggerDuePassed, IsActive: true},
+		0, orm.NoLimit, []string{"created_date", "id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find automation rules")
+	}
+	if len(rules) == 0 {
+		return []model.Task{}, nil
+	}
+	tasks, err := s.taskRepo.FindFilteredTasks(&model.Task{}, &repository.TaskFilter{DueBefore: &now, IsOpenOnly: true},
+		0, orm.NoLimit, []string{"due_date", "id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find overdue tasks")
+	}
+	result := []model.Task{}
+	for _, task := range tasks {
+		pending := []model.AutomationRule{}
+		for _, rule := range rules {
+			count, err := s.executionRepo.CountAutomationExecutionsSince(rule.ID, task.ID, *task.DueDate)
+			if err != nil {
+				return nil, NewSvcErrorf(ErrorCodeDB, err, "Failed to count executions of automation rule. ID:%s", rule.ID)
+			}
+			if count == 0 {
+				pending = append(pending, rule)
+			}
+		}
+		ran, serr := s.runRules(newAutomationChain(), pending, model.AutomationTriggerDuePassed, task.ID)
+		if serr != nil {
+			return nil, serr
+		}
+		if ran == 0 {
+			continue
+		}
+		find, err := s.taskRepo.FindFirstTask(&model.Task{ID: task.ID}, []string{})
+		if err != nil {
+			return nil, NewSvcErrorf(ErrorCodeDB, err, "Failed to find task. ID:%s", task.ID)
+		}
+		result = append(result, find)
+	}
+	return result, nil
+}
+
+// runTrigger runs active rules of the trigger for the task, and returns the number of rules which ran
+func (s *AutomationService) runTrigger(chain *automationChain, trigger, taskID string) (int, error) {
+	rules, err := s.ruleRepo.FindAutomationRules(&model.AutomationRule{Trigger: trigger, IsActive: true},
+		0, orm.NoLimit, []string{"created_date", "id"})
+	if err != nil {
+		return 0, NewSvcError(ErrorCodeDB, err, "Failed to find automation rules")
+	}
+	return s.runRules(chain, rules, trigger, taskID)
+}
+
+// runRules runs rules whose condition the task matches, and records the executions.
+// Conditions are matched before any action runs, so that actions of a rule do not make following rules match.
+// Rule which has already run for the task in the chain is skipped to stop loop.
+// Rejected action fails the rule and changes of its actions are rolled back, but error of database fails the caller.
+func (s *AutomationService) runRules(chain *automationChain, rules []model.AutomationRule, trigger, taskID string) (int, error) {
+	matched := make([]bool, len(rules))
+	conditionErrors := make([]error, len(rules))
+	for i := range rules {
+		ok, serr := s.matchCondition(&rules[i], taskID)
+		if serr != nil && !isAutomationActionError(serr) {
+			return 0, serr
+		}
+		matched[i], conditionErrors[i] = ok, serr
+	}
+	ran := 0
+	for i := range rules {
+		rule := &rules[i]
+		serr := conditionErrors[i]
+		if serr == nil && !matched[i] {
+			continue
+		}
+		status := model.ExecutionStatusSucceeded
+		message := ""
+		key := rule.ID + "/" + taskID
+		switch {
+		case serr != nil:
+			status = model.ExecutionStatusFailed
+			message = fmt.Sprintf("Invalid condition: %s", serr.Error())
+		case chain.ran[key]:
+			status = model.ExecutionStatusSkipped
+			message = "Rule has already run for the task in this chain of rules"
+		case chain.depth >= maxAutomationDepth:
+			status = model.ExecutionStatusSkipped
+			message = fmt.Sprintf("Rules are triggered by other rules deeper than %d", maxAutomationDepth)
+		default:
+			chain.ran[key] = true
+			ran++
+			status, message, serr = s.runActions(chain, rule, trigger, taskID)
+			if serr != nil {
+				return 0, serr
+			}
+		}
+		execution := model.NewAutomationExecution(rule.ID, taskID, trigger, status, message, chain.depth, time.Now().UTC())
+		if err := s.executionRepo.CreateAutomationExecution(execution); err != nil {
+			return 0, NewSvcErrorf(ErrorCodeDB, err, "Failed to record execution of automation rule. ID:%s", rule.ID)
+		}
+	}
+	return ran, nil
+}
+
+// runActions runs actions of rule as a unit, and returns status and message of the execution.
+// Actions run in a savepoint, which is rolled back if one of them is rejected so that the task is not changed halfway.
+// Nested rules triggered by the actions use savepoints of their own depth.
+func (s *AutomationService) runActions(chain *automationChain, rule *model.AutomationRule, trigger, taskID string,
+) (string, string, error) {
+	savepoint := fmt.Sprintf("automation_%d", chain.depth)
+	if err := s.tx.Exec("SAVEPOINT " + savepoint).Error; err != nil {
+		return "", "", NewSvcErrorf(ErrorCodeDB, err, "Failed to run actions of automation rule. ID:%s", rule.ID)
+	}
+	for i, action := range rule.GetActions() {
+		serr := s.runAction(chain, rule, trigger, taskID, &action)
+		if serr == nil {
+			continue
+		}
+		if !isAutomationActionError(serr) {
+			return "", "", serr
+		}
+		if err := s.tx.Exec("ROLLBACK TO SAVEPOINT " + savepoint).Error; err != nil {
+			return "", "", NewSvcErrorf(ErrorCodeDB, err, "Failed to roll back actions of automation rule. ID:%s", rule.ID)
+		}
+		if err := s.tx.Exec("RELEASE SAVEPOINT " + savepoint).Error; err != nil {
+			return "", "", NewSvcErrorf(ErrorCodeDB, err, "Failed to roll back actions of automation rule. ID:%s", rule.ID)
+		}
+		message := fmt.Sprintf("Action %d [%s] failed and all actions are rolled back: %s", i+1, action.Type, serr.Error())
+		return model.ExecutionStatusFailed, truncate(message, 1000), nil
+	}
+	if err := s.tx.Exec("RELEASE SAVEPOINT " + savepoint).Error; err != nil {
+		return "", "", NewSvcErrorf(ErrorCodeDB, err, "Failed to run actions of automation rule. ID:%s", rule.ID)
+	}
+	return model.ExecutionStatusSucceeded, "", nil
+}
+
+// matchCondition checks whether the task matches condition of rule
+func (s *AutomationService) matchCondition(rule *model.AutomationRule, taskID string) (bool, error) {
+	if strings.TrimSpace(rule.Condition) == "" {
+		return true, nil
+	}
+	query, serr := ParseTaskQuery(rule.Condition, nil)
+	if serr != nil {
+		return false, serr
+	}
+	count, err := s.taskRepo.CountFilteredTasks(&model.Task{ID: taskID}, &repository.TaskFilter{Query: query})
+	if err != nil {
+		return false, NewSvcErrorf(ErrorCodeDB, err, "Failed to match condition of automation rule. ID:%s", rule.ID)
+	}
+	return count > 0, nil
+}
+
+// runAction runs an action of rule for the task, rules triggered by the action run in the next depth of chain
+func (s *AutomationService) runAction(chain *automationChain, rule *model.AutomationRule, trigger, taskID string,
+	action *model.AutomationAction,
+) error {
+	taskSrvc := NewTaskService(s.tx, nil)
+	taskSrvc.chain = chain.next()
+	task, serr := taskSrvc.FindTask(&model.Task{ID: taskID})
+	if serr != nil {
+		return serr
+	}
+	switch action.Type {
+	case model.AutomationActionMoveBoard:
+		if task.BoardID == action.Value {
+			return nil
+		}
+		_, serr = taskSrvc.MoveTask(taskID, action.Value, "", "")
+		return serr
+	case model.AutomationActionSetAssignee:
+		assignee := sql.NullString{String: action.Value, Valid: action.Value != ""}
+		if task.AssigneeUserID == assignee {
+			return nil
+		}
+		task.AssigneeUserID = assignee
+		return taskSrvc.UpdateTask(task)
+	case model.AutomationActionSetClosed:
+		isClosed, _ := strconv.ParseBool(action.Value)
+		if task.IsClosed == isClosed {
+			return nil
+		}
+		task.IsClosed = isClosed
+		return taskSrvc.UpdateTask(task)
+	case model.AutomationActionAddComment:
+		// Comments of automation have no author
+		comment := model.NewComment(taskID, "", action.Value, time.Now().UTC())
+		return NewCommentService(s.tx, nil).CreateComment(comment)
+	case model.AutomationActionFireWebhook:
+		e := event.NewEvent(event.TypeAutomationFired, []string{task.BoardID}, &automationFiredData{
+			RuleID:   rule.ID,
+			RuleName: rule.Name,
+			Trigger:  trigger,
+			TaskID:   task.ID,
+			TaskName: task.Name,
+			BoardID:  task.BoardID,
+		})
+		return NewWebhookService(s.tx, nil).EnqueueDelivery(action.Value, e)
+	}
+	return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Unknown type of automation action [%s]", action.Type)
+}
+
+// isAutomationActionError checks whether the error is a rejection of action, not an error of the system.
+// Rejections fail the rule without failing the caller.
+func isAutomationActionError(err error) bool {
+	serr, ok := err.(*SvcError)
+	return ok && serr.Code != ErrorCodeDB && serr.Code != ErrorCodeUnexpected
+}
+
+// validateAutomationRule checks name, trigger, condition and actions of rule
+func (s *AutomationService) validateAutomationRule(rule *model.AutomationRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return NewSvcError(ErrorCodeInvalidArguments, nil, "Name of automation rule must not be empty")
+	}
+	rules, err := s.ruleRepo.FindAutomationRules(&model.AutomationRule{Name: rule.Name}, 0, orm.NoLimit, []string{})
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to find automation rules")
+	}
+	for _, other := range rules {
+		if other.ID != rule.ID {
+			return NewSvcErrorf(ErrorCodeAlreadyExist, nil, "Automation rule [%s] already exists", rule.Name)
+		}
+	}
+	if !model.IsValidAutomationTrigger(rule.Trigger) {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Trigger [%s] must be one of [%s]",
+			rule.Trigger, strings.Join(model.AutomationTriggers, ","))
+	}
+	if _, serr := ParseTaskQuery(rule.Condition, nil); serr != nil {
+		return serr
+	}
+	actions := rule.GetActions()
+	if len(actions) == 0 {
+		return NewSvcError(ErrorCodeInvalidArguments, nil, "Automation rule must have one or more actions")
+	}
+	for i := range actions {
+		if serr := s.validateAutomationAction(&actions[i]); serr != nil {
+			return serr
+		}
+	}
+	return nil
+}
+
+// validateAutomationAction checks type of action and its value
+func (s *AutomationService) validateAutomationAction(action *model.AutomationAction) error {
+	if action.Value == "" && (action.Type == model.AutomationActionMoveBoard || action.Type == model.AutomationActionFireWebhook) {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Value of %s action must not be empty", action.Type)
+	}
+	switch action.Type {
+	case model.AutomationActionMoveBoard:
+		_, serr := NewBoardService(s.tx, s.loginUser).FindBoard(&model.Board{ID: action.Value})
+		return serr
+	case model.AutomationActionSetAssignee:
+		if action.Value == "" {
+			return nil
+		}
+		_, err := s.userRepo.FindFirstUser(&model.User{ID: action.Value}, []string{})
+		if err != nil {
+			if err == orm.ErrorRecordNotFound {
+				return NewSvcErrorf(ErrorCodeNotFound, err, "Assignee user not found. ID:%s", action.Value)
+			}
+			return NewSvcError(ErrorCodeDB, err, "Failed to find user")
+		}
+		return nil
+	case model.AutomationActionSetClosed:
+		if _, err := strconv.ParseBool(action.Value); err != nil {
+			return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Value of %s action must be true or false. Value:%s",
+				action.Type, action.Value)
+		}
+		return nil
+	case model.AutomationActionAddComment:
+		return validateCommentBody(action.Value)
+	case model.AutomationActionFireWebhook:
+		_, serr := NewWebhookService(s.tx, s.loginUser).FindWebhook(&model.Webhook{ID: action.Value})
+		return serr
+	}
+	return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Type of action [%s] must be one of [%s]",
+		action.Type, strings.Join(model.AutomationActionTypes, ","))
+}
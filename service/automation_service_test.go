@@ -0,0 +1,242 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func createAutomationTestBoard(t *testing.T, tx *gorm.DB, name string) *model.Board {
+	board := model.NewBoard(name, false, false, time.Now().UTC())
+	if serr := NewBoardService(tx, nil).CreateBoard(board); serr != nil {
+		t.Fatalf("Failed to create board: %+v", serr)
+	}
+	return board
+}
+
+func createAutomationTestTask(t *testing.T, tx *gorm.DB, name, boardID string) *model.Task {
+	task := model.NewTask(name, "", false, time.Now().UTC())
+	task.BoardID = boardID
+	if serr := NewTaskService(tx, nil).CreateTask(task); serr != nil {
+		t.Fatalf("Failed to create task: %+v", serr)
+	}
+	return task
+}
+
+func createAutomationTestRule(t *testing.T, tx *gorm.DB, name, trigger, condition string, actions ...model.AutomationAction,
+) *model.AutomationRule {
+	rule := model.NewAutomationRule(name, trigger, condition, actions, true, time.Now().UTC())
+	if serr := NewAutomationService(tx, nil).CreateAutomationRule(rule); serr != nil {
+		t.Fatalf("Failed to create automation rule: %+v", serr)
+	}
+	return rule
+}
+
+func findAutomationTestExecutions(t *testing.T, tx *gorm.DB, ruleID string) []model.AutomationExecution {
+	executions, err := repository.NewAutomationExecutionRepository(tx).FindAutomationExecutions(
+		&model.AutomationExecution{RuleID: ruleID}, 0, orm.NoLimit, []string{"depth", "created_date", "id"})
+	if err != nil {
+		t.Fatalf("Failed to find automation executions: %+v", err)
+	}
+	return executions
+}
+
+func TestAutomationService_RulesTriggeringEachOther(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	ping := createAutomationTestBoard(t, tx, "automation-ping")
+	pong := createAutomationTestBoard(t, tx, "automation-pong")
+	task := createAutomationTestTask(t, tx, "ping-pong", ping.ID)
+	toPing := createAutomationTestRule(t, tx, "to-ping", model.AutomationTriggerMoved, "board:automation-pong",
+		model.AutomationAction{Type: model.AutomationActionMoveBoard, Value: ping.ID})
+	toPong := createAutomationTestRule(t, tx, "to-pong", model.AutomationTriggerMoved, "board:automation-ping",
+		model.AutomationAction{Type: model.AutomationActionMoveBoard, Value: pong.ID})
+
+	// to-ping moves task back, to-pong moves it again, and to-ping is not run twice
+	moved, serr := NewTaskService(tx, nil).MoveTask(task.ID, pong.ID, "", "")
+	if serr != nil {
+		t.Fatalf("Failed to move task: %+v", serr)
+	}
+	assert.Equal(t, pong.ID, moved.BoardID)
+
+	executions := findAutomationTestExecutions(t, tx, toPing.ID)
+	if len(executions) != 2 {
+		t.Fatalf("Expected executions of to-ping = %d, but got %d", 2, len(executions))
+	}
+	assert.Equal(t, model.ExecutionStatusSucceeded, executions[0].Status)
+	assert.Equal(t, 0, executions[0].Depth)
+	assert.Equal(t, model.ExecutionStatusSkipped, executions[1].Status)
+	assert.Equal(t, 2, executions[1].Depth)
+
+	executions = findAutomationTestExecutions(t, tx, toPong.ID)
+	if len(executions) != 1 {
+		t.Fatalf("Expected executions of to-pong = %d, but got %d", 1, len(executions))
+	}
+	assert.Equal(t, model.ExecutionStatusSucceeded, executions[0].Status)
+	assert.Equal(t, 1, executions[0].Depth)
+}
+
+func TestAutomationService_MaxAutomationDepth(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	// Each rule moves task to the next board, so rules are chained without running twice
+	boards := make([]*model.Board, 0, maxAutomationDepth+2)
+	for i := 0; i < maxAutomationDepth+2; i++ {
+		boards = append(boards, createAutomationTestBoard(t, tx, fmt.Sprintf("automation-depth-%d", i)))
+	}
+	rules := make([]*model.AutomationRule, 0, maxAutomationDepth+1)
+	for i := 0; i <= maxAutomationDepth; i++ {
+		rules = append(rules, createAutomationTestRule(t, tx, fmt.Sprintf("depth-%d", i), model.AutomationTriggerMoved,
+			"board:"+boards[i].Name,
+			model.AutomationAction{Type: model.AutomationActionMoveBoard, Value: boards[i+1].ID}))
+	}
+	start := createAutomationTestBoard(t, tx, "automation-depth-start")
+	task := createAutomationTestTask(t, tx, "deep", start.ID)
+
+	moved, serr := NewTaskService(tx, nil).MoveTask(task.ID, boards[0].ID, "", "")
+	if serr != nil {
+		t.Fatalf("Failed to move task: %+v", serr)
+	}
+	assert.Equal(t, boards[maxAutomationDepth].ID, moved.BoardID)
+
+	for i, rule := range rules {
+		executions := findAutomationTestExecutions(t, tx, rule.ID)
+		if len(executions) != 1 {
+			t.Fatalf("Expected executions of %s = %d, but got %d", rule.Name, 1, len(executions))
+		}
+		assert.Equal(t, i, executions[0].Depth)
+		if i < maxAutomationDepth {
+			assert.Equal(t, model.ExecutionStatusSucceeded, executions[0].Status)
+		} else {
+			assert.Equal(t, model.ExecutionStatusSkipped, executions[0].Status)
+		}
+	}
+}
+
+func TestAutomationService_RejectedAction(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	start := createAutomationTestBoard(t, tx, "automation-reject-start")
+	full := model.NewBoard("automation-reject-full", false, false, time.Now().UTC())
+	full.WIPLimit = 1
+	full.WIPMode = model.WIPModeBlock
+	if serr := NewBoardService(tx, nil).CreateBoard(full); serr != nil {
+		t.Fatalf("Failed to create board: %+v", serr)
+	}
+	user := model.NewUser("automation-reject-user", "password", "")
+	if err := tx.Create(user).Error; err != nil {
+		t.Fatalf("Failed to create user: %+v", err)
+	}
+	createAutomationTestTask(t, tx, "occupying", full.ID)
+	rule := createAutomationTestRule(t, tx, "reject", model.AutomationTriggerCreated, "board:automation-reject-start",
+		model.AutomationAction{Type: model.AutomationActionSetAssignee, Value: user.ID},
+		model.AutomationAction{Type: model.AutomationActionAddComment, Value: "first"},
+		model.AutomationAction{Type: model.AutomationActionMoveBoard, Value: full.ID},
+		model.AutomationAction{Type: model.AutomationActionAddComment, Value: "never"})
+
+	// Task is created though the action is rejected by WIP limit
+	task := createAutomationTestTask(t, tx, "rejected", start.ID)
+	executions := findAutomationTestExecutions(t, tx, rule.ID)
+	if len(executions) != 1 {
+		t.Fatalf("Expected executions = %d, but got %d", 1, len(executions))
+	}
+	assert.Equal(t, model.ExecutionStatusFailed, executions[0].Status)
+	assert.True(t, strings.HasPrefix(executions[0].Message, "Action 3 [moveBoard] failed"), executions[0].Message)
+
+	// Actions before the rejected one are rolled back, and actions after it are not run
+	find, serr := NewTaskService(tx, nil).FindTask(&model.Task{ID: task.ID})
+	if serr != nil {
+		t.Fatalf("Failed to find task: %+v", serr)
+	}
+	assert.Equal(t, start.ID, find.BoardID)
+	assert.False(t, find.AssigneeUserID.Valid)
+	assert.Equal(t, task.Version, find.Version)
+	comments, err := repository.NewCommentRepository(tx).FindComments(&model.Comment{TaskID: task.ID}, 0, orm.NoLimit, []string{})
+	if err != nil {
+		t.Fatalf("Failed to find comments: %+v", err)
+	}
+	assert.Equal(t, 0, len(comments))
+
+	// Later rules still run after the rollback
+	other := createAutomationTestRule(t, tx, "after-reject", model.AutomationTriggerCreated, "board:automation-reject-start",
+		model.AutomationAction{Type: model.AutomationActionSetAssignee, Value: user.ID})
+	task = createAutomationTestTask(t, tx, "rejected-and-assigned", start.ID)
+	find, serr = NewTaskService(tx, nil).FindTask(&model.Task{ID: task.ID})
+	if serr != nil {
+		t.Fatalf("Failed to find task: %+v", serr)
+	}
+	assert.Equal(t, user.ID, find.AssigneeUserID.String)
+	executions = findAutomationTestExecutions(t, tx, other.ID)
+	if len(executions) != 1 {
+		t.Fatalf("Expected executions = %d, but got %d", 1, len(executions))
+	}
+	assert.Equal(t, model.ExecutionStatusSucceeded, executions[0].Status)
+}
+
+func TestAutomationService_RunDuePassedRules(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	board := createAutomationTestBoard(t, tx, "automation-due")
+	now := time.Now().UTC()
+	due := now.Add(-time.Hour)
+	task := model.NewTask("overdue", "", false, now)
+	task.BoardID = board.ID
+	task.DueDate = &due
+	if serr := NewTaskService(tx, nil).CreateTask(task); serr != nil {
+		t.Fatalf("Failed to create task: %+v", serr)
+	}
+	rule := createAutomationTestRule(t, tx, "overdue", model.AutomationTriggerDuePassed, "",
+		model.AutomationAction{Type: model.AutomationActionAddComment, Value: "Overdue"})
+
+	srvc := NewAutomationService(tx, nil)
+	tasks, serr := srvc.RunDuePassedRules(now)
+	if serr != nil {
+		t.Fatalf("Failed to run rules: %+v", serr)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("Expected tasks = %d, but got %d", 1, len(tasks))
+	}
+	assert.Equal(t, task.ID, tasks[0].ID)
+
+	// Rule does not run again for the same due date
+	tasks, serr = srvc.RunDuePassedRules(now)
+	if serr != nil {
+		t.Fatalf("Failed to run rules: %+v", serr)
+	}
+	assert.Equal(t, 0, len(tasks))
+
+	// Rule runs again after the changed due date has passed
+	find, serr := NewTaskService(tx, nil).FindTask(&model.Task{ID: task.ID})
+	if serr != nil {
+		t.Fatalf("Failed to find task: %+v", serr)
+	}
+	due = now.Add(time.Hour)
+	find.DueDate = &due
+	if serr = NewTaskService(tx, nil).UpdateTask(find); serr != nil {
+		t.Fatalf("Failed to update task: %+v", serr)
+	}
+	tasks, serr = srvc.RunDuePassedRules(now)
+	if serr != nil {
+		t.Fatalf("Failed to run rules: %+v", serr)
+	}
+	assert.Equal(t, 0, len(tasks))
+	tasks, serr = srvc.RunDuePassedRules(now.Add(2 * time.Hour))
+	if serr != nil {
+		t.Fatalf("Failed to run rules: %+v", serr)
+	}
+	assert.Equal(t, 1, len(tasks))
+
+	executions := findAutomationTestExecutions(t, tx, rule.ID)
+	assert.Equal(t, 2, len(executions))
+}
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"taskboard/common"
+	"taskboard/model"
+)
+
+// importPlan has records to be created and maps from IDs in backup to IDs in database
+type importPlan struct {
+	options ImportOptions
+	result  *ImportResult
+	details []string // Invalid records and references
+
+	users          []*model.User
+	labels         []*model.Label
+	projects       []*model.Project
+	projectMembers []*model.ProjectMember // Members added to created or existing projects
+	hasProjects    bool                   // False if backup was exported before projects
+	boards         []*model.Board
+	tasks          []*model.Task
+	taskLabels     map[string][]string // Label IDs of created tasks
+	comments       []*model.Comment
+
+	userIDs    map[string]string
+	labelIDs   map[string]string
+	projectIDs map[string]string
+	boardIDs   map[string]string
+	taskIDs    map[string]string
+}
+
+// existingRecords has IDs and names of records in database
+type existingRecords struct {
+	users        map[string]bool
+	userNames    map[string]string // Name to ID
+	labels       map[string]bool
+	labelNames   map[string]string // Name to ID
+	projects     map[string]bool
+	projectNames map[string]string // Name to ID
+	states       map[string]bool   // IDs of workflow states
+	boards       map[string]bool
+	boardNames   map[string]string // Project ID and name to ID, see boardKey
+	systemBoards map[string]string // Project ID and workflow state to ID, see boardKey
+	tasks        map[string]bool
+	comments     map[string]bool
+}
+
+// planUsers plans users to be created, users having the same name as existing ones are mapped to them
+func (plan *importPlan) planUsers(users []BackupUser, existing *existingRecords) {
+	names := map[string]bool{}
+	for _, u := range users {
+		if !plan.checkRecord(ImportTypeUser, u.ID, plan.userIDs) {
+			continue
+		}
+		if names[u.Name] {
+			plan.addDetail("User name [%s] is duplicated", u.Name)
+			continue
+		}
+		names[u.Name] = true
+		if !model.IsValidRole(u.Role) {
+			plan.addDetail("Role [%s] of user [%s] is invalid", u.Role, u.Name)
+			continue
+		}
+		if !plan.options.RemapIDs && existing.users[u.ID] {
+			plan.skip(ImportTypeUser, u.ID, u.ID, u.Name, "ID already exists", plan.userIDs)
+			continue
+		}
+		if id, ok := existing.userNames[u.Name]; ok {
+			plan.skip(ImportTypeUser, u.ID, id, u.Name, "name already exists", plan.userIDs)
+			continue
+		}
+		user := &model.User{
+			ID:           plan.newID(u.ID, "user_"),
+			Name:         u.Name,
+			PasswordHash: u.PasswordHash,
+			Avator:       u.Avator,
+			Role:         u.Role,
+			Version:      u.Version,
+		}
+		plan.users = append(plan.users, user)
+		plan.create(ImportTypeUser, u.ID, user.ID, u.Name, plan.userIDs)
+	}
+}
+
+// planLabels plans labels to be created, labels having the same name as existing ones are mapped to them
+func (plan *importPlan) planLabels(labels []BackupLabel, existing *existingRecords) {
+	names := map[string]bool{}
+	for _, l := range labels {
+		if !plan.checkRecord(ImportTypeLabel, l.ID, plan.labelIDs) {
+			continue
+		}
+		if names[l.Name] {
+			plan.addDetail("Label name [%s] is duplicated", l.Name)
+			continue
+		}
+		names[l.Name] = true
+		label := &model.Label{Name: l.Name, Color: l.Color, CreatedDate: l.CreatedDate, Version: l.Version}
+		if serr := validateLabel(label); serr != nil {
+			plan.addDetail("Label [%s] is invalid: %v", l.ID, serr)
+			continue
+		}
+		if !plan.options.RemapIDs && existing.labels[l.ID] {
+			plan.skip(ImportTypeLabel, l.ID, l.ID, l.Name, "ID already exists", plan.labelIDs)
+			continue
+		}
+		if id, ok := existing.labelNames[l.Name]; ok {
+			plan.skip(ImportTypeLabel, l.ID, id, l.Name, "name already exists", plan.labelIDs)
+			continue
+		}
+		label.ID = plan.newID(l.ID, "label_")
+		plan.labels = append(plan.labels, label)
+		plan.create(ImportTypeLabel, l.ID, label.ID, l.Name, plan.labelIDs)
+	}
+}
+
+// planProjects plans projects to be created, projects having the same name as existing ones are mapped to them.
+// Members are added to created projects, and imported users are added to existing projects as well.
+func (plan *importPlan) planProjects(projects []BackupProject, existing *existingRecords) {
+	plan.hasProjects = len(projects) > 0
+	createdUsers := map[string]bool{}
+	for _, user := range plan.users {
+		createdUsers[user.ID] = true
+	}
+	names := map[string]bool{}
+	for _, p := range projects {
+		if !plan.checkRecord(ImportTypeProject, p.ID, plan.projectIDs) {
+			continue
+		}
+		if p.Name == "" {
+			plan.addDetail("Name of project [%s] is empty", p.ID)
+			continue
+		}
+		if names[p.Name] {
+			plan.addDetail("Project name [%s] is duplicated", p.Name)
+			continue
+		}
+		names[p.Name] = true
+		userIDs := make([]string, 0, len(p.MemberUserIDs))
+		for _, memberUserID := range p.MemberUserIDs {
+			id, ok := plan.reference(memberUserID, plan.userIDs, existing.users)
+			if !ok {
+				plan.addDetail("Member user [%s] of project [%s] does not exist", memberUserID, p.ID)
+			}
+			userIDs = append(userIDs, id)
+		}
+		// ID of default project is fixed
+		if (!plan.options.RemapIDs || p.ID == model.DefaultProjectID) && existing.projects[p.ID] {
+			plan.skip(ImportTypeProject, p.ID, p.ID, p.Name, "ID already exists", plan.projectIDs)
+			plan.addProjectMembers(p.ID, userIDs, createdUsers)
+			continue
+		}
+		if id, ok := existing.projectNames[p.Name]; ok {
+			plan.skip(ImportTypeProject, p.ID, id, p.Name, "name already exists", plan.projectIDs)
+			plan.addProjectMembers(id, userIDs, createdUsers)
+			continue
+		}
+		id := p.ID
+		if id != model.DefaultProjectID {
+			id = plan.newID(p.ID, "project_")
+		}
+		plan.projects = append(plan.projects, &model.Project{
+			ID:          id,
+			Name:        p.Name,
+			CreatedDate: p.CreatedDate,
+			Version:     p.Version,
+		})
+		plan.create(ImportTypeProject, p.ID, id, p.Name, plan.projectIDs)
+		plan.addProjectMembers(id, userIDs, nil)
+	}
+}
+
+// addProjectMembers adds users to project, only created users are added if createdUsers is not nil
+func (plan *importPlan) addProjectMembers(projectID string, userIDs []string, createdUsers map[string]bool) {
+	added := map[string]bool{}
+	for _, userID := range userIDs {
+		if added[userID] || (createdUsers != nil && !createdUsers[userID]) {
+			continue
+		}
+		added[userID] = true
+		plan.projectMembers = append(plan.projectMembers, &model.ProjectMember{ProjectID: projectID, UserID: userID})
+	}
+}
+
+// planBoards plans boards to be created, system boards of default project keep their IDs.
+// Boards having the same name as existing ones in the project, and system boards of states which the project already has,
+// are mapped to existing ones.
+func (plan *importPlan) planBoards(boards []BackupBoard, existing *existingRecords) {
+	// Boards are appended to the end in exported order, not to conflict with ranks of existing ones
+	boards = append([]BackupBoard{}, boards...)
+	sort.SliceStable(boards, func(i, j int) bool { return boards[i].Rank < boards[j].Rank })
+	names := map[string]bool{}
+	systemKinds := map[string]bool{}
+	for _, b := range boards {
+		if !plan.checkRecord(ImportTypeBoard, b.ID, plan.boardIDs) {
+			continue
+		}
+		// ID of system board is fixed
+		if (!plan.options.RemapIDs || b.IsSystem) && existing.boards[b.ID] {
+			plan.skip(ImportTypeBoard, b.ID, b.ID, b.Name, "ID already exists", plan.boardIDs)
+			continue
+		}
+		// Boards of projects which are neither in backup nor in database are imported into default project as normal boards,
+		// because default project already has its own system boards. Backups exported before projects have no project.
+		// System boards of states which are not in the workflow are imported as normal boards as well.
+		projectID, ok := plan.reference(b.ProjectID, plan.projectIDs, existing.projects)
+		isSystem, systemKind := b.IsSystem, b.SystemKind
+		if !ok {
+			if b.ProjectID != "" {
+				isSystem, systemKind = false, ""
+			}
+			projectID = model.DefaultProjectID
+		}
+		if isSystem && !existing.states[systemKind] {
+			isSystem, systemKind = false, ""
+		}
+		if isSystem {
+			if id, ok := existing.systemBoards[boardKey(projectID, systemKind)]; ok {
+				plan.skip(ImportTypeBoard, b.ID, id, b.Name, "system board of the state already exists", plan.boardIDs)
+				continue
+			}
+		}
+		if id, ok := existing.boardNames[boardKey(projectID, b.Name)]; ok {
+			plan.skip(ImportTypeBoard, b.ID, id, b.Name, "name already exists", plan.boardIDs)
+			continue
+		}
+		if names[boardKey(projectID, b.Name)] {
+			plan.addDetail("Name [%s] of board [%s] is duplicated in its project", b.Name, b.ID)
+			continue
+		}
+		names[boardKey(projectID, b.Name)] = true
+		if isSystem {
+			if systemKinds[boardKey(projectID, systemKind)] {
+				plan.addDetail("System board [%s] of state [%s] is duplicated in its project", b.ID, systemKind)
+				continue
+			}
+			systemKinds[boardKey(projectID, systemKind)] = true
+		}
+		id := b.ID
+		if !isSystem || projectID != model.DefaultProjectID {
+			id = plan.newID(b.ID, "board_")
+		}
+		plan.boards = append(plan.boards, &model.Board{
+			ID:          id,
+			ProjectID:   projectID,
+			Name:        b.Name,
+			IsSystem:    isSystem,
+			SystemKind:  systemKind,
+			IsClosed:    b.IsClosed,
+			WIPLimit:    b.WIPLimit,
+			WIPMode:     b.WIPMode,
+			CreatedDate: b.CreatedDate,
+			Version:     b.Version,
+		})
+		plan.create(ImportTypeBoard, b.ID, id, b.Name, plan.boardIDs)
+	}
+}
+
+// boardKey returns key of board which is unique in the project, such as name or workflow state
+func boardKey(projectID, key string) string {
+	return projectID + "/" + key
+}
+
+// planTasks plans tasks to be created and validates their board, assignee and labels
+func (plan *importPlan) planTasks(tasks []BackupTask, existing *existingRecords) {
+	// Tasks are appended to the end of boards in exported order
+	tasks = append([]BackupTask{}, tasks...)
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Rank < tasks[j].Rank })
+	for _, t := range tasks {
+		if !plan.checkRecord(ImportTypeTask, t.ID, plan.taskIDs) {
+			continue
+		}
+		if !plan.options.RemapIDs && existing.tasks[t.ID] {
+			plan.skip(ImportTypeTask, t.ID, t.ID, t.Name, "ID already exists", plan.taskIDs)
+			continue
+		}
+		boardID, ok := plan.reference(t.BoardID, plan.boardIDs, existing.boards)
+		if !ok {
+			plan.addDetail("Board [%s] of task [%s] does not exist", t.BoardID, t.ID)
+		}
+		assigneeUserID := ""
+		if t.AssigneeUserID != "" {
+			if assigneeUserID, ok = plan.reference(t.AssigneeUserID, plan.userIDs, existing.users); !ok {
+				plan.addDetail("Assignee user [%s] of task [%s] does not exist", t.AssigneeUserID, t.ID)
+			}
+		}
+		labelIDs := make([]string, 0, len(t.LabelIDs))
+		for _, labelID := range t.LabelIDs {
+			id, ok := plan.reference(labelID, plan.labelIDs, existing.labels)
+			if !ok {
+				plan.addDetail("Label [%s] of task [%s] does not exist", labelID, t.ID)
+			}
+			labelIDs = append(labelIDs, id)
+		}
+		task := &model.Task{
+			ID:             plan.newID(t.ID, "task_"),
+			Name:           t.Name,
+			Description:    t.Description,
+			AssigneeUserID: sql.NullString{String: assigneeUserID, Valid: assigneeUserID != ""},
+			BoardID:        boardID,
+			CreatedDate:    t.CreatedDate,
+			IsClosed:       t.IsClosed,
+			Version:        t.Version,
+			EstimateSize:   t.EstimateSize,
+			StartDate:      t.StartDate,
+			DueDate:        t.DueDate,
+		}
+		plan.tasks = append(plan.tasks, task)
+		plan.taskLabels[task.ID] = labelIDs
+		plan.create(ImportTypeTask, t.ID, task.ID, t.Name, plan.taskIDs)
+	}
+}
+
+// planComments plans comments to be created and validates their task and author
+func (plan *importPlan) planComments(comments []BackupComment, existing *existingRecords) {
+	commentIDs := map[string]string{}
+	for _, c := range comments {
+		if !plan.checkRecord(ImportTypeComment, c.ID, commentIDs) {
+			continue
+		}
+		if !plan.options.RemapIDs && existing.comments[c.ID] {
+			plan.skip(ImportTypeComment, c.ID, c.ID, c.TaskID, "ID already exists", commentIDs)
+			continue
+		}
+		taskID, ok := plan.reference(c.TaskID, plan.taskIDs, existing.tasks)
+		if !ok {
+			plan.addDetail("Task [%s] of comment [%s] does not exist", c.TaskID, c.ID)
+		}
+		authorUserID, ok := plan.reference(c.AuthorUserID, plan.userIDs, existing.users)
+		if !ok {
+			plan.addDetail("Author user [%s] of comment [%s] does not exist", c.AuthorUserID, c.ID)
+		}
+		comment := &model.Comment{
+			ID:           plan.newID(c.ID, "comment_"),
+			TaskID:       taskID,
+			AuthorUserID: authorUserID,
+			Body:         c.Body,
+			CreatedDate:  c.CreatedDate,
+			EditedDate:   c.EditedDate,
+			Version:      c.Version,
+		}
+		plan.comments = append(plan.comments, comment)
+		plan.create(ImportTypeComment, c.ID, comment.ID, c.TaskID, commentIDs)
+	}
+}
+
+// checkRecord checks that record has ID which is not duplicated in backup
+func (plan *importPlan) checkRecord(recordType, id string, ids map[string]string) bool {
+	if id == "" {
+		plan.addDetail("ID of %s is empty", recordType)
+		return false
+	}
+	if _, ok := ids[id]; ok {
+		plan.addDetail("ID [%s] of %s is duplicated", id, recordType)
+		return false
+	}
+	return true
+}
+
+// reference returns ID in database referred by ID in backup, referred record is in backup or database
+func (plan *importPlan) reference(id string, ids map[string]string, existing map[string]bool) (string, bool) {
+	if newID, ok := ids[id]; ok {
+		return newID, true
+	}
+	return id, existing[id]
+}
+
+// newID returns ID of created record, it is generated in the same way of model if IDs are remapped
+func (plan *importPlan) newID(id, prefix string) string {
+	if plan.options.RemapIDs {
+		return prefix + common.GenerateID()
+	}
+	return id
+}
+
+// create adds the record to the result as created and maps its ID
+func (plan *importPlan) create(recordType, id, newID, name string, ids map[string]string) {
+	ids[id] = newID
+	plan.result.Records = append(plan.result.Records, ImportRecord{
+		Type:   recordType,
+		ID:     id,
+		NewID:  newID,
+		Name:   name,
+		Action: ImportActionCreate,
+	})
+}
+
+// skip adds the record to the result as skipped and maps its ID to the existing one
+func (plan *importPlan) skip(recordType, id, newID, name, reason string, ids map[string]string) {
+	ids[id] = newID
+	plan.result.Records = append(plan.result.Records, ImportRecord{
+		Type:   recordType,
+		ID:     id,
+		NewID:  newID,
+		Name:   name,
+		Action: ImportActionSkip,
+		Reason: reason,
+	})
+}
+
+func (plan *importPlan) addDetail(format string, values ...interface{}) {
+	plan.details = append(plan.details, fmt.Sprintf(format, values...))
+}
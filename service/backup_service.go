@@ -0,0 +1,505 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// BackupFormatVersion is the version of backup document, it is increased when the format is changed
+const BackupFormatVersion = 2
+
+// Backup is a document of exported users, labels, projects, boards, tasks and comments
+type Backup struct {
+	FormatVersion int             `json:"formatVersion"` // 0 if exported before versioning, 0 and 1 have no projects
+	ExportedDate  time.Time       `json:"exportedDate"`
+	Users         []BackupUser    `json:"users"`
+	Labels        []BackupLabel   `json:"labels"`
+	Projects      []BackupProject `json:"projects"`
+	Boards        []BackupBoard   `json:"boards"`
+	Tasks         []BackupTask    `json:"tasks"`
+	Comments      []BackupComment `json:"comments"`
+}
+
+// BackupUser is an exported user
+type BackupUser struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	PasswordHash string `json:"passwordHash,omitempty"` // Exported only if requested
+	Avator       string `json:"avator"`
+	Role         string `json:"role"`
+	Version      int    `json:"version"`
+}
+
+// BackupLabel is an exported label
+type BackupLabel struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Color       string    `json:"color"`
+	CreatedDate time.Time `json:"createdDate"`
+	Version     int       `json:"version"`
+}
+
+// BackupProject is an exported project
+type BackupProject struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	MemberUserIDs []string  `json:"memberUserIDs"`
+	CreatedDate   time.Time `json:"createdDate"`
+	Version       int       `json:"version"`
+}
+
+// BackupBoard is an exported board
+type BackupBoard struct {
+	ID          string    `json:"id"`
+	ProjectID   string    `json:"projectID,omitempty"` // Default project if empty or not exist
+	Name        string    `json:"name"`
+	Rank        string    `json:"rank"`
+	IsSystem    bool      `json:"isSystem"`
+	SystemKind  string    `json:"systemKind,omitempty"`
+	IsClosed    bool      `json:"isClosed"`
+	WIPLimit    int       `json:"wipLimit,omitempty"`
+	WIPMode     string    `json:"wipMode,omitempty"`
+	CreatedDate time.Time `json:"createdDate"`
+	Version     int       `json:"version"`
+}
+
+// BackupTask is an exported task
+type BackupTask struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	AssigneeUserID string     `json:"assigneeUserID"`
+	BoardID        string     `json:"boardID"`
+	Rank           string     `json:"rank"`
+	CreatedDate    time.Time  `json:"createdDate"`
+	IsClosed       bool       `json:"isClosed"`
+	Version        int        `json:"version"`
+	EstimateSize   int        `json:"estimateSize"`
+	StartDate      *time.Time `json:"startDate"`
+	DueDate        *time.Time `json:"dueDate"`
+	LabelIDs       []string   `json:"labelIDs"`
+}
+
+// BackupComment is an exported comment
+type BackupComment struct {
+	ID           string     `json:"id"`
+	TaskID       string     `json:"taskID"`
+	AuthorUserID string     `json:"authorUserID"`
+	Body         string     `json:"body"`
+	CreatedDate  time.Time  `json:"createdDate"`
+	EditedDate   *time.Time `json:"editedDate"`
+	Version      int        `json:"version"`
+}
+
+// ExportOptions changes what is exported
+type ExportOptions struct {
+	IncludePasswordHashes bool // Password hashes of users are exported, keep the document secret
+}
+
+// ImportOptions changes how records are imported
+type ImportOptions struct {
+	RemapIDs bool // New IDs are generated for imported records, otherwise IDs in backup are kept
+	DryRun   bool // Nothing is changed, only what would change is reported
+}
+
+// Types of imported records
+const (
+	ImportTypeUser    = "user"
+	ImportTypeLabel   = "label"
+	ImportTypeProject = "project"
+	ImportTypeBoard   = "board"
+	ImportTypeTask    = "task"
+	ImportTypeComment = "comment"
+)
+
+// ImportTypes is the types of imported records in the order of import
+var ImportTypes = []string{ImportTypeUser, ImportTypeLabel, ImportTypeProject, ImportTypeBoard, ImportTypeTask, ImportTypeComment}
+
+// Actions for imported records
+const (
+	ImportActionCreate = "create"
+	ImportActionSkip   = "skip"
+)
+
+// ImportRecord presents what is done for a record of backup
+type ImportRecord struct {
+	Type   string
+	ID     string // ID in backup
+	NewID  string // ID in database, differs from ID if remapped or mapped to existing record
+	Name   string // Name of record, or ID of task for comment
+	Action string
+	Reason string // Reason why record is skipped
+}
+
+// ImportResult presents what is done, or would be done in dry run, for each record
+type ImportResult struct {
+	Records []ImportRecord
+	DryRun  bool
+}
+
+// Count returns the number of records of the type and the action
+func (r *ImportResult) Count(recordType, action string) (count int) {
+	for _, record := range r.Records {
+		if record.Type == recordType && record.Action == action {
+			count++
+		}
+	}
+	return
+}
+
+// BackupService provides apis for exporting and importing whole taskboard.
+type BackupService struct {
+	tx          *gorm.DB
+	loginUser   *model.User
+	userRepo    *repository.UserRepository
+	labelRepo   *repository.LabelRepository
+	boardRepo   *repository.BoardRepository
+	taskRepo    *repository.TaskRepository
+	commentRepo *repository.CommentRepository
+	projectRepo *repository.ProjectRepository
+}
+
+// NewBackupService return new instance of BackupService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewBackupService(tx *gorm.DB, loginUser *model.User) *BackupService {
+	return &BackupService{
+		tx:          tx,
+		loginUser:   loginUser,
+		userRepo:    repository.NewUserRepository(tx),
+		labelRepo:   repository.NewLabelRepository(tx),
+		boardRepo:   repository.NewBoardRepository(tx),
+		taskRepo:    repository.NewTaskRepository(tx),
+		commentRepo: repository.NewCommentRepository(tx),
+		projectRepo: repository.NewProjectRepository(tx),
+	}
+}
+
+// Export returns all users, labels, projects with their members, boards, tasks and comments
+func (s *BackupService) Export(options ExportOptions) (*Backup, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	users, err := s.userRepo.FindUsers(&model.User{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find users")
+	}
+	labels, err := s.labelRepo.FindLabels(&model.Label{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find labels")
+	}
+	projects, err := s.projectRepo.FindProjects(&model.Project{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find projects")
+	}
+	members, err := s.projectRepo.FindProjectMembers(&model.ProjectMember{})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find members of projects")
+	}
+	boards, err := s.boardRepo.FindBoards(&model.Board{}, 0, orm.NoLimit, []string{"rank"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find boards")
+	}
+	tasks, err := s.taskRepo.FindTasks(&model.Task{}, 0, orm.NoLimit, []string{"board_id", "rank"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find tasks")
+	}
+	taskIDs := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+	taskLabels, err := s.labelRepo.FindTaskLabels(taskIDs)
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find labels of tasks")
+	}
+	comments, err := s.commentRepo.FindComments(&model.Comment{}, 0, orm.NoLimit, []string{"created_date", "id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find comments")
+	}
+
+	backup := &Backup{
+		FormatVersion: BackupFormatVersion,
+		ExportedDate:  time.Now().UTC(),
+		Users:         make([]BackupUser, 0, len(users)),
+		Labels:        make([]BackupLabel, 0, len(labels)),
+		Projects:      make([]BackupProject, 0, len(projects)),
+		Boards:        make([]BackupBoard, 0, len(boards)),
+		Tasks:         make([]BackupTask, 0, len(tasks)),
+		Comments:      make([]BackupComment, 0, len(comments)),
+	}
+	for _, user := range users {
+		u := BackupUser{
+			ID:      user.ID,
+			Name:    user.Name,
+			Avator:  user.Avator,
+			Role:    user.Role,
+			Version: user.Version,
+		}
+		if options.IncludePasswordHashes {
+			u.PasswordHash = user.PasswordHash
+		}
+		backup.Users = append(backup.Users, u)
+	}
+	for _, label := range labels {
+		backup.Labels = append(backup.Labels, BackupLabel{
+			ID:          label.ID,
+			Name:        label.Name,
+			Color:       label.Color,
+			CreatedDate: label.CreatedDate,
+			Version:     label.Version,
+		})
+	}
+	memberUserIDs := map[string][]string{}
+	for _, member := range members {
+		memberUserIDs[member.ProjectID] = append(memberUserIDs[member.ProjectID], member.UserID)
+	}
+	for _, project := range projects {
+		ids := memberUserIDs[project.ID]
+		if ids == nil {
+			ids = []string{}
+		}
+		backup.Projects = append(backup.Projects, BackupProject{
+			ID:            project.ID,
+			Name:          project.Name,
+			MemberUserIDs: ids,
+			CreatedDate:   project.CreatedDate,
+			Version:       project.Version,
+		})
+	}
+	for _, board := range boards {
+		backup.Boards = append(backup.Boards, BackupBoard{
+			ID:          board.ID,
+			ProjectID:   board.ProjectID,
+			Name:        board.Name,
+			Rank:        board.Rank,
+			IsSystem:    board.IsSystem,
+			SystemKind:  board.SystemKind,
+			IsClosed:    board.IsClosed,
+			WIPLimit:    board.WIPLimit,
+			WIPMode:     board.WIPMode,
+			CreatedDate: board.CreatedDate,
+			Version:     board.Version,
+		})
+	}
+	labelIDs := map[string][]string{}
+	for _, taskLabel := range taskLabels {
+		labelIDs[taskLabel.TaskID] = append(labelIDs[taskLabel.TaskID], taskLabel.LabelID)
+	}
+	for _, task := range tasks {
+		ids := labelIDs[task.ID]
+		if ids == nil {
+			ids = []string{}
+		}
+		backup.Tasks = append(backup.Tasks, BackupTask{
+			ID:             task.ID,
+			Name:           task.Name,
+			Description:    task.Description,
+			AssigneeUserID: task.AssigneeUserID.String,
+			BoardID:        task.BoardID,
+			Rank:           task.Rank,
+			CreatedDate:    task.CreatedDate,
+			IsClosed:       task.IsClosed,
+			Version:        task.Version,
+			EstimateSize:   task.EstimateSize,
+			StartDate:      task.StartDate,
+			DueDate:        task.DueDate,
+			LabelIDs:       ids,
+		})
+	}
+	for _, comment := range comments {
+		backup.Comments = append(backup.Comments, BackupComment{
+			ID:           comment.ID,
+			TaskID:       comment.TaskID,
+			AuthorUserID: comment.AuthorUserID,
+			Body:         comment.Body,
+			CreatedDate:  comment.CreatedDate,
+			EditedDate:   comment.EditedDate,
+			Version:      comment.Version,
+		})
+	}
+	return backup, nil
+}
+
+// Import creates records of backup which do not exist yet, in the transaction of the service.
+// Users, labels and projects having the same name as existing ones are not created and their references are mapped to existing ones,
+// and so are boards having the same name in the project and system boards of states which the project already has.
+// Records whose ID already exists are skipped. When RemapIDs is set, new IDs are generated except for default project and its system boards.
+// Imported users become members of projects as in backup, or of default project if backup has no projects.
+// All references are validated before anything is created, and nothing is created in dry run.
+// Imported users without password hash can not login until their password is reset.
+func (s *BackupService) Import(backup *Backup, options ImportOptions) (*ImportResult, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	if backup.FormatVersion < 0 || backup.FormatVersion > BackupFormatVersion {
+		return nil, NewSvcErrorf(ErrorCodeInvalidArguments, nil,
+			"Format version [%d] of backup is not supported, version %d or older is required", backup.FormatVersion, BackupFormatVersion)
+	}
+	existing, serr := s.findExistingRecords()
+	if serr != nil {
+		return nil, serr
+	}
+
+	plan := &importPlan{
+		options:    options,
+		result:     &ImportResult{Records: []ImportRecord{}, DryRun: options.DryRun},
+		details:    []string{},
+		taskLabels: map[string][]string{},
+		userIDs:    map[string]string{},
+		labelIDs:   map[string]string{},
+		projectIDs: map[string]string{},
+		boardIDs:   map[string]string{},
+		taskIDs:    map[string]string{},
+	}
+	plan.planUsers(backup.Users, existing)
+	plan.planLabels(backup.Labels, existing)
+	plan.planProjects(backup.Projects, existing)
+	plan.planBoards(backup.Boards, existing)
+	plan.planTasks(backup.Tasks, existing)
+	plan.planComments(backup.Comments, existing)
+	if len(plan.details) > 0 {
+		return nil, NewSvcErrorWithDetailsf(ErrorCodeInvalidArguments, nil,
+			"Backup has %d invalid record(s) or reference(s)", plan.details, len(plan.details))
+	}
+	if options.DryRun {
+		return plan.result, nil
+	}
+	if serr = s.createPlannedRecords(plan); serr != nil {
+		return nil, serr
+	}
+	return plan.result, nil
+}
+
+// findExistingRecords returns IDs and names of all records in database
+func (s *BackupService) findExistingRecords() (*existingRecords, error) {
+	existing := &existingRecords{
+		users:        map[string]bool{},
+		userNames:    map[string]string{},
+		labels:       map[string]bool{},
+		labelNames:   map[string]string{},
+		projects:     map[string]bool{},
+		projectNames: map[string]string{},
+		states:       map[string]bool{},
+		boards:       map[string]bool{},
+		boardNames:   map[string]string{},
+		systemBoards: map[string]string{},
+		tasks:        map[string]bool{},
+		comments:     map[string]bool{},
+	}
+	users, err := s.userRepo.FindUsers(&model.User{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find users")
+	}
+	for _, user := range users {
+		existing.users[user.ID] = true
+		existing.userNames[user.Name] = user.ID
+	}
+	labels, err := s.labelRepo.FindLabels(&model.Label{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find labels")
+	}
+	for _, label := range labels {
+		existing.labels[label.ID] = true
+		existing.labelNames[label.Name] = label.ID
+	}
+	projects, err := s.projectRepo.FindProjects(&model.Project{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find projects")
+	}
+	for _, project := range projects {
+		existing.projects[project.ID] = true
+		existing.projectNames[project.Name] = project.ID
+	}
+	states, err := repository.NewWorkflowStateRepository(s.tx).FindWorkflowStates(&model.WorkflowState{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find workflow states")
+	}
+	for _, state := range states {
+		existing.states[state.ID] = true
+	}
+	boards, err := s.boardRepo.FindBoards(&model.Board{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find boards")
+	}
+	for _, board := range boards {
+		existing.boards[board.ID] = true
+		existing.boardNames[boardKey(board.ProjectID, board.Name)] = board.ID
+		if board.IsSystem {
+			existing.systemBoards[boardKey(board.ProjectID, board.SystemKind)] = board.ID
+		}
+	}
+	tasks, err := s.taskRepo.FindTasks(&model.Task{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find tasks")
+	}
+	for _, task := range tasks {
+		existing.tasks[task.ID] = true
+	}
+	comments, err := s.commentRepo.FindComments(&model.Comment{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find comments")
+	}
+	for _, comment := range comments {
+		existing.comments[comment.ID] = true
+	}
+	return existing, nil
+}
+
+// createPlannedRecords creates records in the order of references
+func (s *BackupService) createPlannedRecords(plan *importPlan) error {
+	for _, user := range plan.users {
+		if err := s.userRepo.CreateUser(user); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to create user. ID:%s", user.ID)
+		}
+		if plan.hasProjects {
+			continue
+		}
+		if err := s.projectRepo.CreateProjectMembers(model.DefaultProjectID, []string{user.ID}); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to add user to default project. ID:%s", user.ID)
+		}
+	}
+	for _, label := range plan.labels {
+		if err := s.labelRepo.CreateLabel(label); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to create label. ID:%s", label.ID)
+		}
+	}
+	for _, project := range plan.projects {
+		if err := s.projectRepo.CreateProject(project); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to create project. ID:%s", project.ID)
+		}
+	}
+	for _, member := range plan.projectMembers {
+		if err := s.projectRepo.CreateProjectMembers(member.ProjectID, []string{member.UserID}); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to add user to project. ID:%s", member.ProjectID)
+		}
+	}
+	for _, board := range plan.boards {
+		if err := s.boardRepo.CreateBoard(board); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to create board. ID:%s", board.ID)
+		}
+	}
+	// Created projects get boards of states which are not in backup, such as states added after export
+	boardSrvc := NewBoardService(s.tx, nil)
+	for _, project := range plan.projects {
+		if serr := boardSrvc.SyncSystemBoards(project.ID); serr != nil {
+			return serr
+		}
+	}
+	for _, task := range plan.tasks {
+		if err := s.taskRepo.CreateTask(task); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to create task. ID:%s", task.ID)
+		}
+		if err := s.labelRepo.CreateTaskLabels(task.ID, plan.taskLabels[task.ID]); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to create labels of task. ID:%s", task.ID)
+		}
+	}
+	for _, comment := range plan.comments {
+		if err := s.commentRepo.CreateComment(comment); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to create comment. ID:%s", comment.ID)
+		}
+	}
+	return nil
+}
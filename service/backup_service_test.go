@@ -0,0 +1,342 @@
+package service
+
+import (
+	"fmt"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func createBackupTestProject(t *testing.T, tx *gorm.DB, name string) *model.Project {
+	project := model.NewProject(name, time.Now().UTC())
+	if serr := NewProjectService(tx, nil).CreateProject(project); serr != nil {
+		t.Fatalf("Failed to create project: %+v", serr)
+	}
+	return project
+}
+
+func createBackupTestBoard(t *testing.T, tx *gorm.DB, projectID, name string) *model.Board {
+	board := model.NewBoard(name, false, false, time.Now().UTC())
+	board.ProjectID = projectID
+	if serr := NewBoardService(tx, nil).CreateBoard(board); serr != nil {
+		t.Fatalf("Failed to create board: %+v", serr)
+	}
+	return board
+}
+
+func findImportRecord(t *testing.T, result *ImportResult, recordType, id string) ImportRecord {
+	for _, record := range result.Records {
+		if record.Type == recordType && record.ID == id {
+			return record
+		}
+	}
+	t.Fatalf("Record of %s [%s] is not in import result", recordType, id)
+	return ImportRecord{}
+}
+
+func countBackupTestRecords(t *testing.T, tx *gorm.DB, value, condition interface{}) int {
+	count := 0
+	if err := tx.Model(value).Where(condition).Count(&count).Error; err != nil {
+		t.Fatalf("Failed to count records: %+v", err)
+	}
+	return count
+}
+
+func TestBackupService_ImportRemapDryRunBoardNames(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	project := createBackupTestProject(t, tx, "backup-names")
+	board := createBackupTestBoard(t, tx, project.ID, "Backlog")
+	task := model.NewTask("exported", "", false, time.Now().UTC())
+	task.BoardID = board.ID
+	if serr := NewTaskService(tx, nil).CreateTask(task); serr != nil {
+		t.Fatalf("Failed to create task: %+v", serr)
+	}
+	boardCount := countBackupTestRecords(t, tx, &model.Board{}, &model.Board{})
+
+	srvc := NewBackupService(tx, nil)
+	backup, serr := srvc.Export(ExportOptions{})
+	if serr != nil {
+		t.Fatalf("Failed to export: %+v", serr)
+	}
+
+	// Dry run reports what the real run does, boards are mapped to existing ones by name
+	result, serr := srvc.Import(backup, ImportOptions{RemapIDs: true, DryRun: true})
+	if serr != nil {
+		t.Fatalf("Failed to import in dry run: %+v", serr)
+	}
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 0, result.Count(ImportTypeBoard, ImportActionCreate))
+	record := findImportRecord(t, result, ImportTypeBoard, board.ID)
+	assert.Equal(t, ImportActionSkip, record.Action)
+	assert.Equal(t, board.ID, record.NewID)
+	assert.Equal(t, len(backup.Tasks), result.Count(ImportTypeTask, ImportActionCreate))
+	assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.Task{}, &model.Task{BoardID: board.ID}))
+
+	result, serr = srvc.Import(backup, ImportOptions{RemapIDs: true})
+	if serr != nil {
+		t.Fatalf("Failed to import: %+v", serr)
+	}
+	assert.Equal(t, 0, result.Count(ImportTypeBoard, ImportActionCreate))
+	assert.Equal(t, boardCount, countBackupTestRecords(t, tx, &model.Board{}, &model.Board{}))
+	assert.Equal(t, 2, countBackupTestRecords(t, tx, &model.Task{}, &model.Task{BoardID: board.ID}))
+}
+
+func TestBackupService_ImportBoardsOfExistingProjectName(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	project := createBackupTestProject(t, tx, "backup-existing")
+	board := createBackupTestBoard(t, tx, project.ID, "Backlog")
+	icebox, err := NewBoardService(tx, nil).FindInboxBoard(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to find inbox board: %+v", err)
+	}
+	now := time.Now().UTC()
+	backup := &Backup{
+		FormatVersion: BackupFormatVersion,
+		Projects:      []BackupProject{{ID: "project_other", Name: project.Name, MemberUserIDs: []string{}, CreatedDate: now, Version: 1}},
+		Boards: []BackupBoard{
+			{ID: "board_other_icebox", ProjectID: "project_other", Name: "Old Icebox", Rank: "a0", IsSystem: true,
+				SystemKind: icebox.SystemKind, CreatedDate: now, Version: 1},
+			{ID: "board_other_backlog", ProjectID: "project_other", Name: "Backlog", Rank: "a1", CreatedDate: now, Version: 1},
+			{ID: "board_other_new", ProjectID: "project_other", Name: "New", Rank: "a2", CreatedDate: now, Version: 1},
+		},
+		Tasks: []BackupTask{
+			{ID: "task_other_1", Name: "to backlog", BoardID: "board_other_backlog", Rank: "a0", CreatedDate: now, Version: 1},
+			{ID: "task_other_2", Name: "to icebox", BoardID: "board_other_icebox", Rank: "a1", CreatedDate: now, Version: 1},
+		},
+	}
+
+	result, serr := NewBackupService(tx, nil).Import(backup, ImportOptions{})
+	if serr != nil {
+		t.Fatalf("Failed to import: %+v", serr)
+	}
+	assert.Equal(t, project.ID, findImportRecord(t, result, ImportTypeProject, "project_other").NewID)
+	record := findImportRecord(t, result, ImportTypeBoard, "board_other_icebox")
+	assert.Equal(t, ImportActionSkip, record.Action)
+	assert.Equal(t, icebox.ID, record.NewID)
+	record = findImportRecord(t, result, ImportTypeBoard, "board_other_backlog")
+	assert.Equal(t, ImportActionSkip, record.Action)
+	assert.Equal(t, board.ID, record.NewID)
+	assert.Equal(t, ImportActionCreate, findImportRecord(t, result, ImportTypeBoard, "board_other_new").Action)
+
+	// Project still has one system board of the state
+	assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.Board{},
+		map[string]interface{}{"project_id": project.ID, "system_kind": icebox.SystemKind}))
+	assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.Task{}, &model.Task{ID: "task_other_1", BoardID: board.ID}))
+	assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.Task{}, &model.Task{ID: "task_other_2", BoardID: icebox.ID}))
+}
+
+func TestBackupService_ImportDuplicatedBoardNames(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	backup := &Backup{
+		FormatVersion: BackupFormatVersion,
+		Projects:      []BackupProject{{ID: "project_duplicated", Name: "backup-duplicated", MemberUserIDs: []string{}, CreatedDate: now, Version: 1}},
+		Boards: []BackupBoard{
+			{ID: "board_duplicated_1", ProjectID: "project_duplicated", Name: "Same", Rank: "a0", CreatedDate: now, Version: 1},
+			{ID: "board_duplicated_2", ProjectID: "project_duplicated", Name: "Same", Rank: "a1", CreatedDate: now, Version: 1},
+		},
+	}
+
+	_, serr := NewBackupService(tx, nil).Import(backup, ImportOptions{RemapIDs: true, DryRun: true})
+	if assert.Error(t, serr) {
+		assert.Equal(t, ErrorCodeInvalidArguments, serr.(*SvcError).Code)
+		assert.Equal(t, []string{"Name [Same] of board [board_duplicated_2] is duplicated in its project"}, serr.(*SvcError).Details)
+	}
+}
+
+func newBackupTestDocument(now time.Time) *Backup {
+	return &Backup{
+		FormatVersion: BackupFormatVersion,
+		Users:         []BackupUser{{ID: "user_backup", Name: "backup-user", Role: model.RoleMember, Version: 1}},
+		Labels:        []BackupLabel{{ID: "label_backup", Name: "backup-label", Color: "#ff0000", CreatedDate: now, Version: 1}},
+		Projects: []BackupProject{
+			{ID: "project_backup", Name: "backup-project", MemberUserIDs: []string{"user_backup"}, CreatedDate: now, Version: 1},
+		},
+		Boards: []BackupBoard{
+			{ID: "board_backup", ProjectID: "project_backup", Name: "Backlog", Rank: "a0", CreatedDate: now, Version: 1},
+		},
+		Tasks: []BackupTask{
+			{ID: "task_backup", Name: "imported", AssigneeUserID: "user_backup", BoardID: "board_backup", Rank: "a0",
+				CreatedDate: now, Version: 1, LabelIDs: []string{"label_backup"}},
+		},
+		Comments: []BackupComment{
+			{ID: "comment_backup", TaskID: "task_backup", AuthorUserID: "user_backup", Body: "imported", CreatedDate: now, Version: 1},
+		},
+	}
+}
+
+func TestBackupService_ImportRemapIDs(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	result, serr := NewBackupService(tx, nil).Import(newBackupTestDocument(time.Now().UTC()), ImportOptions{RemapIDs: true})
+	if serr != nil {
+		t.Fatalf("Failed to import: %+v", serr)
+	}
+	ids := map[string]string{}
+	for _, record := range result.Records {
+		assert.Equal(t, ImportActionCreate, record.Action, record.ID)
+		assert.NotEqual(t, record.ID, record.NewID)
+		ids[record.ID] = record.NewID
+	}
+	assert.Equal(t, 6, len(ids))
+
+	// References are remapped to new IDs
+	var task model.Task
+	if err := tx.Where(&model.Task{ID: ids["task_backup"]}).First(&task).Error; err != nil {
+		t.Fatalf("Failed to find task: %+v", err)
+	}
+	assert.Equal(t, ids["board_backup"], task.BoardID)
+	assert.Equal(t, ids["user_backup"], task.AssigneeUserID.String)
+	assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.TaskLabel{}, &model.TaskLabel{TaskID: task.ID, LabelID: ids["label_backup"]}))
+	assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.Comment{},
+		&model.Comment{ID: ids["comment_backup"], TaskID: task.ID, AuthorUserID: ids["user_backup"]}))
+	assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.Board{}, &model.Board{ID: ids["board_backup"], ProjectID: ids["project_backup"]}))
+	assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.ProjectMember{},
+		&model.ProjectMember{ProjectID: ids["project_backup"], UserID: ids["user_backup"]}))
+	// Imported user is a member of imported project only
+	assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.ProjectMember{}, &model.ProjectMember{UserID: ids["user_backup"]}))
+	// Created project gets system boards of the workflow
+	assert.Equal(t, 4, countBackupTestRecords(t, tx, &model.Board{}, &model.Board{ProjectID: ids["project_backup"], IsSystem: true}))
+}
+
+func TestBackupService_ImportMapByName(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	user := model.NewUser("backup-user", "password", "")
+	if err := tx.Create(user).Error; err != nil {
+		t.Fatalf("Failed to create user: %+v", err)
+	}
+	label := model.NewLabel("backup-label", "#00ff00", time.Now().UTC())
+	if err := tx.Create(label).Error; err != nil {
+		t.Fatalf("Failed to create label: %+v", err)
+	}
+
+	result, serr := NewBackupService(tx, nil).Import(newBackupTestDocument(time.Now().UTC()), ImportOptions{})
+	if serr != nil {
+		t.Fatalf("Failed to import: %+v", serr)
+	}
+	record := findImportRecord(t, result, ImportTypeUser, "user_backup")
+	assert.Equal(t, ImportActionSkip, record.Action)
+	assert.Equal(t, "name already exists", record.Reason)
+	assert.Equal(t, user.ID, record.NewID)
+	record = findImportRecord(t, result, ImportTypeLabel, "label_backup")
+	assert.Equal(t, ImportActionSkip, record.Action)
+	assert.Equal(t, label.ID, record.NewID)
+
+	// IDs are kept, and references to mapped records are replaced
+	var task model.Task
+	if err := tx.Where(&model.Task{ID: "task_backup"}).First(&task).Error; err != nil {
+		t.Fatalf("Failed to find task: %+v", err)
+	}
+	assert.Equal(t, "board_backup", task.BoardID)
+	assert.Equal(t, user.ID, task.AssigneeUserID.String)
+	assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.TaskLabel{}, &model.TaskLabel{TaskID: task.ID, LabelID: label.ID}))
+	// Existing user is added to created project
+	assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.ProjectMember{},
+		&model.ProjectMember{ProjectID: "project_backup", UserID: user.ID}))
+
+	// Records whose ID already exists are skipped on the second import
+	result, serr = NewBackupService(tx, nil).Import(newBackupTestDocument(time.Now().UTC()), ImportOptions{})
+	if serr != nil {
+		t.Fatalf("Failed to import again: %+v", serr)
+	}
+	for _, recordType := range ImportTypes {
+		assert.Equal(t, 0, result.Count(recordType, ImportActionCreate), recordType)
+	}
+	assert.Equal(t, "ID already exists", findImportRecord(t, result, ImportTypeTask, "task_backup").Reason)
+}
+
+func TestBackupService_ImportInvalidReferences(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	backup := newBackupTestDocument(time.Now().UTC())
+	backup.Projects[0].MemberUserIDs = []string{"user_missing"}
+	backup.Tasks[0].BoardID = "board_missing"
+	backup.Tasks[0].LabelIDs = []string{"label_missing"}
+	backup.Comments[0].TaskID = "task_missing"
+	backup.Users = append(backup.Users, BackupUser{ID: "user_backup", Name: "backup-user-2", Role: model.RoleMember})
+	userCount := countBackupTestRecords(t, tx, &model.User{}, &model.User{})
+
+	_, serr := NewBackupService(tx, nil).Import(backup, ImportOptions{})
+	if assert.Error(t, serr) {
+		assert.Equal(t, ErrorCodeInvalidArguments, serr.(*SvcError).Code)
+		assert.Equal(t, []string{
+			"ID [user_backup] of user is duplicated",
+			"Member user [user_missing] of project [project_backup] does not exist",
+			"Board [board_missing] of task [task_backup] does not exist",
+			"Label [label_missing] of task [task_backup] does not exist",
+			"Task [task_missing] of comment [comment_backup] does not exist",
+		}, serr.(*SvcError).Details)
+	}
+	// Nothing is created when backup is invalid
+	assert.Equal(t, userCount, countBackupTestRecords(t, tx, &model.User{}, &model.User{}))
+}
+
+func TestBackupService_ImportDryRun(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	result, serr := NewBackupService(tx, nil).Import(newBackupTestDocument(time.Now().UTC()), ImportOptions{DryRun: true})
+	if serr != nil {
+		t.Fatalf("Failed to import in dry run: %+v", serr)
+	}
+	assert.True(t, result.DryRun)
+	for _, recordType := range ImportTypes {
+		assert.Equal(t, 1, result.Count(recordType, ImportActionCreate), recordType)
+	}
+	assert.Equal(t, 0, countBackupTestRecords(t, tx, &model.User{}, &model.User{ID: "user_backup"}))
+	assert.Equal(t, 0, countBackupTestRecords(t, tx, &model.Project{}, &model.Project{ID: "project_backup"}))
+	assert.Equal(t, 0, countBackupTestRecords(t, tx, &model.Task{}, &model.Task{ID: "task_backup"}))
+}
+
+func TestBackupService_ImportFormatVersion(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	for _, version := range []int{-1, BackupFormatVersion + 1} {
+		backup := newBackupTestDocument(time.Now().UTC())
+		backup.FormatVersion = version
+		_, serr := NewBackupService(tx, nil).Import(backup, ImportOptions{DryRun: true})
+		if assert.Error(t, serr, version) {
+			assert.Equal(t, ErrorCodeInvalidArguments, serr.(*SvcError).Code)
+		}
+	}
+}
+
+func TestBackupService_ImportWithoutProjects(t *testing.T) {
+	for _, version := range []int{0, 1} {
+		t.Run(fmt.Sprintf("version %d", version), func(t *testing.T) {
+			tx := orm.GetDB().Begin()
+			defer tx.Rollback()
+
+			// Backups exported before projects have neither projects nor project of boards
+			backup := newBackupTestDocument(time.Now().UTC())
+			backup.FormatVersion = version
+			backup.Projects = nil
+			backup.Boards[0].ProjectID = ""
+			result, serr := NewBackupService(tx, nil).Import(backup, ImportOptions{RemapIDs: true})
+			if serr != nil {
+				t.Fatalf("Failed to import: %+v", serr)
+			}
+			userID := findImportRecord(t, result, ImportTypeUser, "user_backup").NewID
+			boardID := findImportRecord(t, result, ImportTypeBoard, "board_backup").NewID
+			assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.Board{},
+				&model.Board{ID: boardID, ProjectID: model.DefaultProjectID}))
+			assert.Equal(t, 1, countBackupTestRecords(t, tx, &model.ProjectMember{},
+				&model.ProjectMember{ProjectID: model.DefaultProjectID, UserID: userID}))
+		})
+	}
+}
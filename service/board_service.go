@@ -1,30 +1,44 @@
 package service
 
 import (
+	"fmt"
+	"taskboard/common"
 	"taskboard/model"
 	"taskboard/orm"
 	"taskboard/repository"
+	"time"
 
 	"github.com/jinzhu/gorm"
 )
 
 // BoardService provides apis for board management.
 type BoardService struct {
-	tx        *gorm.DB
-	boardRepo *repository.BoardRepository
-	taskRepo  *repository.TaskRepository
+	tx          *gorm.DB
+	loginUser   *model.User
+	boardRepo   *repository.BoardRepository
+	taskRepo    *repository.TaskRepository
+	historyRepo *repository.TaskHistoryRepository
+	projectRepo *repository.ProjectRepository
+	stateRepo   *repository.WorkflowStateRepository
+	ruleRepo    *repository.TransitionRuleRepository
 }
 
 // NewBoardService return new instance of BoardService.
-func NewBoardService(tx *gorm.DB) *BoardService {
+// loginUser is used for authorization, set nil when service is called internally.
+func NewBoardService(tx *gorm.DB, loginUser *model.User) *BoardService {
 	return &BoardService{
-		tx:        tx,
-		boardRepo: repository.NewBoardRepository(tx),
-		taskRepo:  repository.NewTaskRepository(tx),
+		tx:          tx,
+		loginUser:   loginUser,
+		boardRepo:   repository.NewBoardRepository(tx),
+		taskRepo:    repository.NewTaskRepository(tx),
+		historyRepo: repository.NewTaskHistoryRepository(tx),
+		projectRepo: repository.NewProjectRepository(tx),
+		stateRepo:   repository.NewWorkflowStateRepository(tx),
+		ruleRepo:    repository.NewTransitionRuleRepository(tx),
 	}
 }
 
-// FindBoard returns board matching specified condition
+// FindBoard returns board matching specified condition, boards of projects which login user is not a member of are not found
 func (s *BoardService) FindBoard(condition interface{}) (*model.Board, error) {
 	find, err := s.boardRepo.FindFirstBoard(condition, []string{"id"})
 	if err != nil {
@@ -33,34 +47,137 @@ func (s *BoardService) FindBoard(condition interface{}) (*model.Board, error) {
 		}
 		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find board")
 	}
+	visible, serr := isProjectVisible(s.projectRepo, s.loginUser, find.ProjectID)
+	if serr != nil {
+		return nil, serr
+	}
+	if !visible {
+		return nil, NewSvcErrorf(ErrorCodeNotFound, nil, "Board not found")
+	}
 	return &find, nil
 }
 
-// FindBoards finds all boards
+// FindBoards finds all boards of projects which login user is a member of
 func (s *BoardService) FindBoards(condition interface{}, sortOrders []string) ([]model.Board, error) {
-	boards, err := s.boardRepo.FindBoards(condition, 0, orm.NoLimit, sortOrders)
+	boards, _, serr := s.findBoards(condition, nil, orm.NoLimit, sortOrders, false)
+	return boards, serr
+}
+
+// FindBoardsPage finds a page of boards of projects which login user is a member of,
+// and returns it with the number of all boards matching condition
+func (s *BoardService) FindBoardsPage(condition interface{}, after *orm.Keyset, limit int, sortOrders []string) ([]model.Board, int, error) {
+	return s.findBoards(condition, after, limit, sortOrders, true)
+}
+
+func (s *BoardService) findBoards(condition interface{}, after *orm.Keyset, limit int, sortOrders []string, withCount bool,
+) ([]model.Board, int, error) {
+	projectIDs, serr := visibleProjectIDs(s.projectRepo, s.loginUser)
+	if serr != nil {
+		return nil, 0, serr
+	}
+	boardRepo := repository.NewBoardRepository(orm.WhereAfter(s.tx, after))
+	var boards []model.Board
+	var err error
+	if projectIDs == nil {
+		boards, err = boardRepo.FindBoards(condition, 0, limit, sortOrders)
+	} else {
+		boards, err = boardRepo.FindProjectBoards(condition, projectIDs, 0, limit, sortOrders)
+	}
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to find boards")
+	}
+	if !withCount {
+		return boards, 0, nil
+	}
+	var count int
+	if projectIDs == nil {
+		count, err = s.boardRepo.CountBoards(condition)
+	} else {
+		count, err = s.boardRepo.CountProjectBoards(condition, projectIDs)
+	}
 	if err != nil {
-		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find boards")
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to count boards")
+	}
+	return boards, count, nil
+}
+
+// IsAnyBoardVisible checks whether login user can see any of specified boards, deleted boards are not visible.
+// Empty boardIDs means boards of all projects, which only admin can see.
+func (s *BoardService) IsAnyBoardVisible(boardIDs []string) (bool, error) {
+	projectIDs, serr := visibleProjectIDs(s.projectRepo, s.loginUser)
+	if serr != nil {
+		return false, serr
+	}
+	if projectIDs == nil {
+		return true, nil
+	}
+	for _, boardID := range boardIDs {
+		if boardID == "" {
+			continue // Empty condition matches any board
+		}
+		find, err := s.boardRepo.FindFirstBoard(&model.Board{ID: boardID}, []string{})
+		if err != nil {
+			if err == orm.ErrorRecordNotFound {
+				continue
+			}
+			return false, NewSvcError(ErrorCodeDB, err, "Failed to find board")
+		}
+		if containsProjectID(projectIDs, find.ProjectID) {
+			return true, nil
+		}
 	}
-	return boards, nil
+	return false, nil
 }
 
-// CreateBoard creates new board
+// CreateBoard creates new board, board is created in default project if project is not specified
 func (s *BoardService) CreateBoard(board *model.Board) error {
-	count, err := s.boardRepo.CountBoards(&model.Board{})
-	if err != nil {
-		return NewSvcError(ErrorCodeDB, err, "Failed to count boards")
+	if serr := s.authorizeBoard(board); serr != nil {
+		return serr
+	}
+	if board.ProjectID == "" {
+		board.ProjectID = model.DefaultProjectID
+	}
+	if serr := s.findVisibleProject(board.ProjectID); serr != nil {
+		return serr
+	}
+	if serr := validateBoardWIPLimit(board); serr != nil {
+		return serr
+	}
+	// Board is appended to the end
+	board.Rank = ""
+	if serr := s.checkBoardNameUnique(board); serr != nil {
+		return serr
 	}
-	board.DispOrder = count
-	err = s.boardRepo.CreateBoard(board)
+	err := s.boardRepo.CreateBoard(board)
 	if err != nil {
 		return NewSvcError(ErrorCodeDB, err, "Failed to create board")
 	}
 	return nil
 }
 
-// UpdateBoard updates specifed board
+// UpdateBoard updates specifed board, project and kind of board can not be changed.
+// Name and closing of system board follow the workflow, they are changed by updating workflow states.
 func (s *BoardService) UpdateBoard(board *model.Board) error {
+	find, serr := s.FindBoard(&model.Board{ID: board.ID})
+	if serr != nil {
+		return serr
+	}
+	if serr = s.authorizeBoard(find); serr != nil {
+		return serr
+	}
+	if serr = validateBoardWIPLimit(board); serr != nil {
+		return serr
+	}
+	board.ProjectID = find.ProjectID
+	board.IsSystem = find.IsSystem
+	board.SystemKind = find.SystemKind
+	if find.IsSystem {
+		board.Name = find.Name
+		board.IsClosed = find.IsClosed
+	}
+	if serr = s.checkBoardNameUnique(board); serr != nil {
+		return serr
+	}
 	err := s.boardRepo.UpdateBoard(board)
 	if err != nil {
 		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update board. ID:%s", board.ID)
@@ -68,73 +185,287 @@ func (s *BoardService) UpdateBoard(board *model.Board) error {
 	return nil
 }
 
-// DeleteBoard deletes specifed board
+// DeleteBoard deletes specifed board and moves its tasks to inbox board of the project.
+// Inbox board can not be deleted.
 func (s *BoardService) DeleteBoard(board *model.Board) error {
-	err := s.boardRepo.DeleteBoard(board)
+	if serr := s.authorizeBoard(board); serr != nil {
+		return serr
+	}
+	inbox, serr := s.FindInboxBoard(board.ProjectID)
+	if serr != nil {
+		return serr
+	}
+	if board.ID == inbox.ID {
+		return NewSvcErrorf(ErrorCodePreconditionInvalid, nil, "Inbox board [%s] can not be deleted. ID:%s", board.Name, board.ID)
+	}
+	if serr = s.checkOnlyTransitionTarget(board); serr != nil {
+		return serr
+	}
+	tasks, err := s.taskRepo.FindTasks(&model.Task{BoardID: board.ID}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to find tasks. BoardID:%s", board.ID)
+	}
+	// Closing is decided while the board exists, tasks leaving closed board for open inbox are reopened
+	taskSrvc := NewTaskService(s.tx, s.loginUser)
+	closings := make([]bool, len(tasks))
+	for i := range tasks {
+		if closings[i], serr = taskSrvc.closingOnBoard(tasks[i].IsClosed, board.ID, inbox.ID); serr != nil {
+			return serr
+		}
+	}
+	err = s.boardRepo.DeleteBoard(board)
 	if err != nil {
 		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete board. ID:%s", board.ID)
 	}
-	err = s.taskRepo.MoveToIceboxBoard(board.ID)
+	err = s.ruleRepo.DeleteBoardTransitionRules(board.ID)
 	if err != nil {
-		return NewSvcErrorf(ErrorCodeDB, err, "Failed to move tasks to iceboax. BoardID:%s", board.ID)
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete transition rules of board. ID:%s", board.ID)
+	}
+	err = s.taskRepo.MoveBoardTasks(board.ID, inbox.ID)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to move tasks to inbox. BoardID:%s", board.ID)
+	}
+	for i, task := range tasks {
+		after := task
+		after.BoardID = inbox.ID
+		after.Version++
+		if closings[i] != task.IsClosed {
+			if err = s.taskRepo.CloseTask(task.ID, closings[i]); err != nil {
+				return NewSvcErrorf(ErrorCodeDB, err, "Failed to close task. ID:%s", task.ID)
+			}
+			after.IsClosed = closings[i]
+			after.Version++
+		}
+		serr := recordTaskHistories(s.historyRepo, s.loginUser, &task, &after, after.Version)
+		if serr != nil {
+			return serr
+		}
 	}
 	return nil
 }
 
-// CreateSystemBoards creates all system boards if not exist
-func (s *BoardService) CreateSystemBoards() error {
-	boards, serr := s.FindBoards(&model.Board{IsSystem: true}, []string{"disp_order"})
+// checkOnlyTransitionTarget checks that board is not the only board which transition rules of other boards allow.
+// Deleting the rule would lift all restrictions of its from-board, so rules must be changed explicitly first.
+func (s *BoardService) checkOnlyTransitionTarget(board *model.Board) error {
+	rules, err := s.ruleRepo.FindOnlyTransitionRulesTo(board.ID)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to find transition rules. BoardID:%s", board.ID)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	details := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		details = append(details, fmt.Sprintf("fromBoardID:%s", rule.FromBoardID))
+	}
+	return NewSvcErrorWithDetailsf(ErrorCodePreconditionInvalid, nil,
+		"Board [%s] can not be deleted, because it is the only board which tasks of other boards can be moved to. ID:%s",
+		details, board.Name, board.ID)
+}
+
+// FindInboxBoard returns inbox board of project, where tasks are placed if their board is not specified or deleted
+func (s *BoardService) FindInboxBoard(projectID string) (*model.Board, error) {
+	state, serr := NewWorkflowService(s.tx, s.loginUser).FindInboxState()
 	if serr != nil {
-		return serr
+		return nil, serr
 	}
-	isFindIcebox := false
-	isFindTodo := false
-	isFindDoing := false
-	isFindDone := false
-	for _, board := range boards {
-		switch board.ID {
-		case model.SystemBoardIcebox.ID:
-			isFindIcebox = true
-		case model.SystemBoardTodo.ID:
-			isFindTodo = true
-		case model.SystemBoardDoing.ID:
-			isFindDoing = true
-		case model.SystemBoardDone.ID:
-			isFindDone = true
-		}
-	}
-	if isFindIcebox != true {
-		serr = s.CreateBoard(model.SystemBoardIcebox)
-		if serr != nil {
-			return serr
+	find, err := s.boardRepo.FindFirstBoard(&model.Board{ProjectID: projectID, SystemKind: state.ID}, []string{})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcErrorf(ErrorCodePreconditionInvalid, err, "Inbox board of project is not found. ID:%s", projectID)
 		}
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find inbox board")
 	}
-	if isFindTodo != true {
-		serr = s.CreateBoard(model.SystemBoardTodo)
-		if serr != nil {
-			return serr
-		}
+	return &find, nil
+}
+
+// SyncSystemBoards makes system boards of project follow the workflow.
+// Boards of new states are created, boards of existing states are renamed and closed as their states,
+// boards of removed states become normal boards keeping their tasks,
+// and normal boards having the same name as new states become their system boards.
+func (s *BoardService) SyncSystemBoards(projectID string) error {
+	states, err := s.stateRepo.FindWorkflowStates(&model.WorkflowState{}, 0, orm.NoLimit, []string{"position"})
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to find workflow states")
 	}
-	if isFindDoing != true {
-		serr = s.CreateBoard(model.SystemBoardDoing)
-		if serr != nil {
+	boards, err := s.boardRepo.FindBoards(&model.Board{ProjectID: projectID, IsSystem: true}, 0, orm.NoLimit, []string{"rank"})
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to find system boards")
+	}
+	stateMap := map[string]*model.WorkflowState{}
+	for i := range states {
+		stateMap[states[i].ID] = &states[i]
+	}
+	// Boards are updated before creating new ones, to release names used by removed states
+	exists := map[string]bool{}
+	for i := range boards {
+		board := &boards[i]
+		state, ok := stateMap[board.SystemKind]
+		if ok && !exists[state.ID] {
+			exists[state.ID] = true
+			if board.Name == state.Name && board.IsClosed == state.IsDone {
+				continue
+			}
+			board.Name = state.Name
+			board.IsClosed = state.IsDone
+		} else {
+			board.IsSystem = false
+			board.SystemKind = ""
+		}
+		if serr := s.checkBoardNameUnique(board); serr != nil {
 			return serr
 		}
+		if err = s.boardRepo.UpdateBoard(board); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to update system board. ID:%s", board.ID)
+		}
 	}
-	if isFindDone != true {
-		serr = s.CreateBoard(model.SystemBoardDone)
-		if serr != nil {
+	now := time.Now().UTC()
+	for _, state := range states {
+		if exists[state.ID] {
+			continue
+		}
+		// Normal board having the same name becomes system board of the state, such as board of state which was removed once
+		same, err := s.boardRepo.FindFirstBoard(&model.Board{ProjectID: projectID, Name: state.Name}, []string{})
+		if err == nil {
+			same.IsSystem = true
+			same.SystemKind = state.ID
+			same.IsClosed = state.IsDone
+			if err = s.boardRepo.UpdateBoard(&same); err != nil {
+				return NewSvcErrorf(ErrorCodeDB, err, "Failed to update board. ID:%s", same.ID)
+			}
+			continue
+		}
+		if err != orm.ErrorRecordNotFound {
+			return NewSvcError(ErrorCodeDB, err, "Failed to find board")
+		}
+		board := model.NewSystemBoard(projectID, &state, now)
+		// Fixed ID of default project may be used by a board which was a system board of removed state
+		count, err := s.boardRepo.CountBoards(&model.Board{ID: board.ID})
+		if err != nil {
+			return NewSvcError(ErrorCodeDB, err, "Failed to count boards")
+		}
+		if count > 0 {
+			board.ID = "board_" + common.GenerateID()
+		}
+		if serr := s.CreateBoard(board); serr != nil {
 			return serr
 		}
 	}
 	return nil
 }
 
-// UpdateBoardOrders updates order of boards
-func (s *BoardService) UpdateBoardOrders(boardIDs []string) error {
-	err := s.boardRepo.UpdateBoardOrders(boardIDs)
+// MoveBoard moves board to specified position, only the moved board is changed.
+// Board is placed right after afterBoardID if specified, or right before beforeBoardID if specified,
+// otherwise it is appended to the end.
+func (s *BoardService) MoveBoard(boardID, afterBoardID, beforeBoardID string) (*model.Board, error) {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	board, serr := s.FindBoard(&model.Board{ID: boardID})
+	if serr != nil {
+		return nil, serr
+	}
+	findNeighbors := func() (prev, next string, err error) {
+		switch {
+		case afterBoardID != "":
+			neighbor, serr := s.findNeighborBoard(afterBoardID, board)
+			if serr != nil {
+				return "", "", serr
+			}
+			prev = neighbor.Rank
+			next, err = s.boardRepo.NextBoardRank(prev, boardID)
+		case beforeBoardID != "":
+			neighbor, serr := s.findNeighborBoard(beforeBoardID, board)
+			if serr != nil {
+				return "", "", serr
+			}
+			next = neighbor.Rank
+			prev, err = s.boardRepo.PreviousBoardRank(next, boardID)
+		default:
+			prev, err = s.boardRepo.PreviousBoardRank("", boardID)
+		}
+		if err != nil {
+			return "", "", NewSvcError(ErrorCodeDB, err, "Failed to find ranks of boards")
+		}
+		return
+	}
+	key, serr := newRank(findNeighbors, s.boardRepo.RebalanceBoardRanks)
+	if serr != nil {
+		return nil, serr
+	}
+	err := s.boardRepo.MoveBoard(boardID, key)
+	if err != nil {
+		if orm.IsUniqueConstraintError(err) {
+			return nil, newRankConflictError(err, boardID)
+		}
+		return nil, NewSvcErrorf(ErrorCodeDB, err, "Failed to move board. ID:%s", boardID)
+	}
+	board.Rank = key
+	board.Version++
+	return board, nil
+}
+
+// findNeighborBoard returns board of the same project next to which the moved board is placed
+func (s *BoardService) findNeighborBoard(neighborBoardID string, board *model.Board) (*model.Board, error) {
+	if neighborBoardID == board.ID {
+		return nil, NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Board can not be placed next to itself. ID:%s", board.ID)
+	}
+	neighbor, serr := s.FindBoard(&model.Board{ID: neighborBoardID})
+	if serr != nil {
+		return nil, serr
+	}
+	if neighbor.ProjectID != board.ProjectID {
+		return nil, NewSvcErrorf(ErrorCodeInvalidArguments, nil,
+			"Board can not be placed next to board of another project. ID:%s", neighborBoardID)
+	}
+	return neighbor, nil
+}
+
+// checkBoardNameUnique checks whether other board of the project has the same name
+func (s *BoardService) checkBoardNameUnique(board *model.Board) error {
+	if board.Name == "" {
+		return nil
+	}
+	boards, err := s.boardRepo.FindBoards(&model.Board{ProjectID: board.ProjectID, Name: board.Name}, 0, orm.NoLimit, []string{})
 	if err != nil {
-		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update board's order")
+		return NewSvcError(ErrorCodeDB, err, "Failed to find boards")
+	}
+	for _, other := range boards {
+		if other.ID != board.ID {
+			return NewSvcErrorf(ErrorCodeAlreadyExist, nil, "Board [%s] already exists in project", board.Name)
+		}
+	}
+	return nil
+}
+
+// findVisibleProject checks whether project exists and login user is a member of it
+func (s *BoardService) findVisibleProject(projectID string) error {
+	_, serr := NewProjectService(s.tx, s.loginUser).FindProject(&model.Project{ID: projectID})
+	return serr
+}
+
+// authorizeBoard checks whether login user can change specified board
+func (s *BoardService) authorizeBoard(board *model.Board) error {
+	if board.IsSystem {
+		return authorizeAdmin(s.loginUser)
+	}
+	return authorizeEditor(s.loginUser)
+}
+
+// validateBoardWIPLimit checks WIP limit and mode of board, mode is warn if not specified
+func validateBoardWIPLimit(board *model.Board) error {
+	if board.WIPLimit < 0 {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "WIP limit [%d] must not be negative", board.WIPLimit)
+	}
+	switch board.WIPMode {
+	case "":
+		if board.WIPLimit > 0 {
+			board.WIPMode = model.WIPModeWarn
+		}
+	case model.WIPModeWarn, model.WIPModeBlock:
+	default:
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "WIP mode [%s] must be one of [%s,%s]",
+			board.WIPMode, model.WIPModeWarn, model.WIPModeBlock)
 	}
 	return nil
 }
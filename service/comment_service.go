@@ -0,0 +1,145 @@
+package service
+
+import (
+	"strings"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jinzhu/gorm"
+)
+
+// MaxCommentBodyLength is max length of comment body
+const MaxCommentBodyLength = 8000
+
+// CommentService provides apis for comment management.
+type CommentService struct {
+	tx          *gorm.DB
+	loginUser   *model.User
+	commentRepo *repository.CommentRepository
+}
+
+// NewCommentService return new instance of CommentService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewCommentService(tx *gorm.DB, loginUser *model.User) *CommentService {
+	return &CommentService{
+		tx:          tx,
+		loginUser:   loginUser,
+		commentRepo: repository.NewCommentRepository(tx),
+	}
+}
+
+// FindComment returns comment matching specified condition.
+// Comments of tasks in projects which login user is not a member of are not found.
+func (s *CommentService) FindComment(condition interface{}) (*model.Comment, error) {
+	find, err := s.commentRepo.FindFirstComment(condition, []string{"id"})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, err, "Comment not found")
+		}
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find comment")
+	}
+	if _, err := NewTaskService(s.tx, s.loginUser).FindTask(&model.Task{ID: find.TaskID}); err != nil {
+		if serr, ok := err.(*SvcError); ok && serr.Code == ErrorCodeNotFound {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, nil, "Comment not found")
+		}
+		return nil, err
+	}
+	return &find, nil
+}
+
+// FindComments finds all comments
+func (s *CommentService) FindComments(condition interface{}, sortOrders []string) ([]model.Comment, error) {
+	comments, err := s.commentRepo.FindComments(condition, 0, orm.NoLimit, sortOrders)
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find comments")
+	}
+	return comments, nil
+}
+
+// FindCommentsPage finds a page of comments and returns it with the number of all comments matching condition
+func (s *CommentService) FindCommentsPage(condition interface{}, after *orm.Keyset, limit int, sortOrders []string) ([]model.Comment, int, error) {
+	comments, err := repository.NewCommentRepository(orm.WhereAfter(s.tx, after)).FindComments(condition, 0, limit, sortOrders)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to find comments")
+	}
+	count, err := s.commentRepo.CountComments(condition)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to count comments")
+	}
+	return comments, count, nil
+}
+
+// CreateComment creates new comment
+func (s *CommentService) CreateComment(comment *model.Comment) error {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := validateCommentBody(comment.Body); serr != nil {
+		return serr
+	}
+	// Tasks of projects which login user is not a member of are not found
+	if _, serr := NewTaskService(s.tx, s.loginUser).FindTask(&model.Task{ID: comment.TaskID}); serr != nil {
+		return serr
+	}
+	err := s.commentRepo.CreateComment(comment)
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to create comment")
+	}
+	return nil
+}
+
+// UpdateComment updates specifed comment
+func (s *CommentService) UpdateComment(comment *model.Comment) error {
+	if serr := s.authorizeAuthor(comment); serr != nil {
+		return serr
+	}
+	if serr := validateCommentBody(comment.Body); serr != nil {
+		return serr
+	}
+	now := time.Now().UTC()
+	comment.EditedDate = &now
+	err := s.commentRepo.UpdateComment(comment)
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return NewSvcErrorf(ErrorCodeOptimisticLockFailure, err, "Comment has been updated by other request. ID:%s", comment.ID)
+		}
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update comment. ID:%s", comment.ID)
+	}
+	return nil
+}
+
+// DeleteComment deletes specifed comment
+func (s *CommentService) DeleteComment(comment *model.Comment) error {
+	if serr := s.authorizeAuthor(comment); serr != nil {
+		return serr
+	}
+	err := s.commentRepo.DeleteComment(comment)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete comment. ID:%s", comment.ID)
+	}
+	return nil
+}
+
+// authorizeAuthor checks whether login user can change specified comment, only author or admin can do it
+func (s *CommentService) authorizeAuthor(comment *model.Comment) error {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return serr
+	}
+	if s.loginUser != nil && s.loginUser.ID != comment.AuthorUserID {
+		return authorizeAdmin(s.loginUser)
+	}
+	return nil
+}
+
+func validateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return NewSvcError(ErrorCodeInvalidArguments, nil, "Comment body must not be empty")
+	}
+	if utf8.RuneCountInString(body) > MaxCommentBodyLength {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Comment body must be less than %d characters", MaxCommentBodyLength)
+	}
+	return nil
+}
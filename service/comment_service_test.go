@@ -0,0 +1,74 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+// createCommentTestComment creates comment of new task on new board of new project
+func createCommentTestComment(t *testing.T, tx *gorm.DB, name string) (*model.Project, *model.Comment) {
+	project := model.NewProject(name, time.Now().UTC())
+	if serr := NewProjectService(tx, nil).CreateProject(project); serr != nil {
+		t.Fatalf("Failed to create project: %+v", serr)
+	}
+	board := model.NewBoard(name, false, false, time.Now().UTC())
+	board.ProjectID = project.ID
+	if serr := NewBoardService(tx, nil).CreateBoard(board); serr != nil {
+		t.Fatalf("Failed to create board: %+v", serr)
+	}
+	task := createTransitionTestTask(t, tx, name, board)
+	comment := model.NewComment(task.ID, "", "comment of "+name, time.Now().UTC())
+	if serr := NewCommentService(tx, nil).CreateComment(comment); serr != nil {
+		t.Fatalf("Failed to create comment: %+v", serr)
+	}
+	return project, comment
+}
+
+func TestCommentService_FindComment_NotMember(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	project, comment := createCommentTestComment(t, tx, "comment-member")
+	user := model.NewUser("comment-user", "password", "")
+	if err := tx.Create(user).Error; err != nil {
+		t.Fatalf("Failed to create user: %+v", err)
+	}
+	condition := &model.Comment{ID: comment.ID, TaskID: comment.TaskID}
+
+	// Comments of tasks in projects which user is not a member of are not found
+	_, err := NewCommentService(tx, user).FindComment(condition)
+	if serr, ok := err.(*SvcError); assert.True(t, ok, "SvcError must be returned: %v", err) {
+		assert.Equal(t, ErrorCodeNotFound, serr.Code)
+	}
+
+	if err := tx.Create(&model.ProjectMember{ProjectID: project.ID, UserID: user.ID}).Error; err != nil {
+		t.Fatalf("Failed to add project member: %+v", err)
+	}
+	find, serr := NewCommentService(tx, user).FindComment(condition)
+	if serr != nil {
+		t.Fatalf("Failed to find comment: %+v", serr)
+	}
+	assert.Equal(t, comment.Body, find.Body)
+}
+
+func TestCommentService_UpdateComment_StaleVersion(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	_, comment := createCommentTestComment(t, tx, "comment-stale")
+	stale := *comment
+	comment.Body = "updated"
+	if serr := NewCommentService(tx, nil).UpdateComment(comment); serr != nil {
+		t.Fatalf("Failed to update comment: %+v", serr)
+	}
+	stale.Body = "stale"
+	err := NewCommentService(tx, nil).UpdateComment(&stale)
+	if serr, ok := err.(*SvcError); assert.True(t, ok, "SvcError must be returned: %v", err) {
+		assert.Equal(t, ErrorCodeOptimisticLockFailure, serr.Code)
+	}
+}
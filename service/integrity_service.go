@@ -0,0 +1,284 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/rank"
+	"taskboard/repository"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Types of integrity issue
+const (
+	IssueTypeInvalidBoardRank   = "invalidBoardRank"
+	IssueTypeDuplicateBoardRank = "duplicateBoardRank"
+	IssueTypeInvalidTaskRank    = "invalidTaskRank"
+	IssueTypeDuplicateTaskRank  = "duplicateTaskRank"
+	IssueTypeOrphanTask         = "orphanTask"
+	IssueTypeDeletedAssignee    = "deletedAssignee"
+)
+
+// IntegrityIssue presents an anomaly of a board or a task
+type IntegrityIssue struct {
+	Type    string
+	ID      string // ID of board or task
+	Message string
+}
+
+// IntegrityReport presents the result of checking boards and tasks
+type IntegrityReport struct {
+	CheckedDate time.Time
+	BoardCount  int
+	TaskCount   int
+	Issues      []IntegrityIssue
+	Repaired    bool // True if issues have been repaired
+}
+
+// integrityScan has issues found and what to do to repair them
+type integrityScan struct {
+	report          *IntegrityReport
+	rebalanceBoards bool            // Ranks of boards are rebalanced
+	rebalanceTasks  map[string]bool // Ranks of tasks in the boards are rebalanced
+	brokenTasks     []model.Task    // Tasks which are orphaned or assigned to deleted users
+	orphanTasks     map[string]string // Tasks are moved to inbox board of their project, keyed by task ID to project ID
+	unassignedTasks map[string]bool   // Assignees of the tasks are cleared
+	inboxBoardIDs   map[string]string // Inbox boards keyed by project ID
+}
+
+// IntegrityService provides apis for checking and repairing order of boards and tasks and their references.
+type IntegrityService struct {
+	tx          *gorm.DB
+	loginUser   *model.User
+	userRepo    *repository.UserRepository
+	boardRepo   *repository.BoardRepository
+	taskRepo    *repository.TaskRepository
+	historyRepo *repository.TaskHistoryRepository
+}
+
+// NewIntegrityService return new instance of IntegrityService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewIntegrityService(tx *gorm.DB, loginUser *model.User) *IntegrityService {
+	return &IntegrityService{
+		tx:          tx,
+		loginUser:   loginUser,
+		userRepo:    repository.NewUserRepository(tx),
+		boardRepo:   repository.NewBoardRepository(tx),
+		taskRepo:    repository.NewTaskRepository(tx),
+		historyRepo: repository.NewTaskHistoryRepository(tx),
+	}
+}
+
+// Check reports invalid or duplicate ranks, tasks on deleted boards and tasks assigned to deleted users.
+// Nothing is changed.
+func (s *IntegrityService) Check() (*IntegrityReport, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	scan, serr := s.scan()
+	if serr != nil {
+		return nil, serr
+	}
+	return scan.report, nil
+}
+
+// Repair reports issues same as Check and repairs them.
+// Tasks on deleted boards are appended to inbox of their project, assignees which are deleted are cleared
+// and ranks of boards and tasks having issues are rebalanced keeping their order as much as possible.
+// Tasks on deleted boards whose project can not be determined are only reported, they are left in place.
+func (s *IntegrityService) Repair() (*IntegrityReport, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	scan, serr := s.scan()
+	if serr != nil {
+		return nil, serr
+	}
+	for _, projectID := range scan.orphanTasks {
+		if scan.inboxBoardIDs[projectID] == "" {
+			return nil, NewSvcErrorf(ErrorCodePreconditionInvalid, nil,
+				"Inbox board of project is required to repair tasks on deleted boards. ProjectID:%s", projectID)
+		}
+	}
+
+	for i := range scan.brokenTasks {
+		task := &scan.brokenTasks[i]
+		moveToBoardID := ""
+		if projectID, ok := scan.orphanTasks[task.ID]; ok {
+			moveToBoardID = scan.inboxBoardIDs[projectID]
+		}
+		serr = s.repairTask(task, moveToBoardID, scan.unassignedTasks[task.ID])
+		if serr != nil {
+			return nil, serr
+		}
+	}
+	if scan.rebalanceBoards {
+		if err := s.boardRepo.RebalanceBoardRanks(); err != nil {
+			return nil, NewSvcError(ErrorCodeDB, err, "Failed to rebalance ranks of boards")
+		}
+	}
+	boardIDs := make([]string, 0, len(scan.rebalanceTasks))
+	for boardID := range scan.rebalanceTasks {
+		boardIDs = append(boardIDs, boardID)
+	}
+	sort.Strings(boardIDs)
+	for _, boardID := range boardIDs {
+		if err := s.taskRepo.RebalanceTaskRanks(boardID); err != nil {
+			return nil, NewSvcErrorf(ErrorCodeDB, err, "Failed to rebalance ranks of tasks. BoardID:%s", boardID)
+		}
+	}
+	scan.report.Repaired = true
+	return scan.report, nil
+}
+
+// repairTask moves orphan task to inbox board if moveToBoardID is specified and clears assignee which is deleted,
+// changes are recorded in history
+func (s *IntegrityService) repairTask(before *model.Task, moveToBoardID string, unassign bool) error {
+	after := *before
+	if moveToBoardID != "" {
+		// Temporary rank sorts after all valid ones, inbox is rebalanced later to make it valid
+		after.BoardID = moveToBoardID
+		after.Rank = "~" + before.ID
+		if err := s.taskRepo.MoveTask(after.ID, after.BoardID, after.Rank); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to move task to inbox board. ID:%s", after.ID)
+		}
+		after.Version++
+	}
+	if unassign {
+		after.AssigneeUserID.String = ""
+		after.AssigneeUserID.Valid = false
+		if err := s.taskRepo.UnassignTask(after.ID); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to clear assignee of task. ID:%s", after.ID)
+		}
+		after.Version++
+	}
+	return recordTaskHistories(s.historyRepo, s.loginUser, before, &after, after.Version)
+}
+
+// scan finds issues of all boards and tasks
+func (s *IntegrityService) scan() (*integrityScan, error) {
+	users, err := s.userRepo.FindUsers(&model.User{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find users")
+	}
+	boards, err := s.boardRepo.FindBoards(&model.Board{}, 0, orm.NoLimit, []string{"rank", "id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find boards")
+	}
+	tasks, err := s.taskRepo.FindTasks(&model.Task{}, 0, orm.NoLimit, []string{"board_id", "rank", "id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find tasks")
+	}
+	// Orphan tasks can not be repaired without inbox state
+	var inbox *model.WorkflowState
+	state, err := repository.NewWorkflowStateRepository(s.tx).FindFirstWorkflowState(&model.WorkflowState{IsInbox: true}, []string{})
+	if err == nil {
+		inbox = &state
+	} else if err != orm.ErrorRecordNotFound {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find inbox state")
+	}
+
+	scan := &integrityScan{
+		report: &IntegrityReport{
+			CheckedDate: time.Now().UTC(),
+			BoardCount:  len(boards),
+			TaskCount:   len(tasks),
+			Issues:      []IntegrityIssue{},
+		},
+		rebalanceTasks:  map[string]bool{},
+		orphanTasks:     map[string]string{},
+		unassignedTasks: map[string]bool{},
+		inboxBoardIDs:   map[string]string{},
+	}
+	userIDs := map[string]bool{}
+	for _, user := range users {
+		userIDs[user.ID] = true
+	}
+	boardProjectIDs := map[string]string{}
+	boardRanks := map[string]int{}
+	for _, board := range boards {
+		boardProjectIDs[board.ID] = board.ProjectID
+		boardRanks[board.Rank]++
+		if inbox != nil && board.SystemKind == inbox.ID {
+			scan.inboxBoardIDs[board.ProjectID] = board.ID
+		}
+	}
+	for _, board := range boards {
+		if !rank.IsValid(board.Rank) {
+			scan.addIssue(IssueTypeInvalidBoardRank, board.ID, "Board [%s] has invalid rank [%s]", board.Name, board.Rank)
+			scan.rebalanceBoards = true
+		} else if boardRanks[board.Rank] > 1 {
+			scan.addIssue(IssueTypeDuplicateBoardRank, board.ID, "Board [%s] has the same rank [%s] as other boards", board.Name, board.Rank)
+			scan.rebalanceBoards = true
+		}
+	}
+
+	taskRanks := map[[2]string]int{} // Keyed by board ID and rank
+	for _, task := range tasks {
+		taskRanks[[2]string{task.BoardID, task.Rank}]++
+	}
+	for _, task := range tasks {
+		if _, ok := boardProjectIDs[task.BoardID]; !ok {
+			projectID, serr := s.findOrphanProjectID(task.ID, boardProjectIDs)
+			if serr != nil {
+				return nil, serr
+			}
+			if projectID == "" {
+				scan.addIssue(IssueTypeOrphanTask, task.ID,
+					"Task [%s] is on deleted board [%s] whose project can not be determined, it is left in place",
+					task.Name, task.BoardID)
+			} else {
+				scan.addIssue(IssueTypeOrphanTask, task.ID, "Task [%s] is on deleted board [%s] of project [%s]",
+					task.Name, task.BoardID, projectID)
+				scan.orphanTasks[task.ID] = projectID
+				scan.rebalanceTasks[scan.inboxBoardIDs[projectID]] = true
+			}
+		} else if !rank.IsValid(task.Rank) {
+			scan.addIssue(IssueTypeInvalidTaskRank, task.ID, "Task [%s] has invalid rank [%s]", task.Name, task.Rank)
+			scan.rebalanceTasks[task.BoardID] = true
+		} else if taskRanks[[2]string{task.BoardID, task.Rank}] > 1 {
+			scan.addIssue(IssueTypeDuplicateTaskRank, task.ID, "Task [%s] has the same rank [%s] as other tasks in board [%s]",
+				task.Name, task.Rank, task.BoardID)
+			scan.rebalanceTasks[task.BoardID] = true
+		}
+		if task.AssigneeUserID.Valid && !userIDs[task.AssigneeUserID.String] {
+			scan.addIssue(IssueTypeDeletedAssignee, task.ID, "Task [%s] is assigned to deleted user [%s]",
+				task.Name, task.AssigneeUserID.String)
+			scan.unassignedTasks[task.ID] = true
+		}
+		if _, ok := scan.orphanTasks[task.ID]; ok || scan.unassignedTasks[task.ID] {
+			scan.brokenTasks = append(scan.brokenTasks, task)
+		}
+	}
+	return scan, nil
+}
+
+// findOrphanProjectID returns project of the task on deleted board, which is the project of the latest board
+// in history of the task which still exists. Tasks never move across projects, so all of its boards are in the same project.
+// Empty is returned if none of the boards exists.
+func (s *IntegrityService) findOrphanProjectID(taskID string, boardProjectIDs map[string]string) (string, error) {
+	histories, err := s.historyRepo.FindTaskHistories(&model.TaskHistory{TaskID: taskID, Field: model.TaskFieldBoardID},
+		0, orm.NoLimit, []string{"revision desc", "created_date desc", "id desc"})
+	if err != nil {
+		return "", NewSvcErrorf(ErrorCodeDB, err, "Failed to find histories of task. ID:%s", taskID)
+	}
+	for _, history := range histories {
+		for _, boardID := range []string{history.NewValue, history.OldValue} {
+			if projectID, ok := boardProjectIDs[boardID]; ok {
+				return projectID, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (scan *integrityScan) addIssue(issueType, id, format string, values ...interface{}) {
+	scan.report.Issues = append(scan.report.Issues, IntegrityIssue{
+		Type:    issueType,
+		ID:      id,
+		Message: fmt.Sprintf(format, values...),
+	})
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrityService_RepairOrphanTasks(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	project := model.NewProject("integrity-project", time.Now().UTC())
+	if serr := NewProjectService(tx, nil).CreateProject(project); serr != nil {
+		t.Fatalf("Failed to create project: %+v", serr)
+	}
+	boards := []*model.Board{}
+	for _, name := range []string{"integrity-kept", "integrity-deleted", "integrity-lost"} {
+		board := model.NewBoard(name, false, false, time.Now().UTC())
+		board.ProjectID = project.ID
+		if serr := NewBoardService(tx, nil).CreateBoard(board); serr != nil {
+			t.Fatalf("Failed to create board: %+v", serr)
+		}
+		boards = append(boards, board)
+	}
+	// Project of moved task is found by the board where it was before, lost task has been only on deleted board
+	srvc := NewTaskService(tx, nil)
+	moved := createTransitionTestTask(t, tx, "integrity-moved", boards[0])
+	if _, serr := srvc.MoveTask(moved.ID, boards[1].ID, "", ""); serr != nil {
+		t.Fatalf("Failed to move task: %+v", serr)
+	}
+	lost := createTransitionTestTask(t, tx, "integrity-lost", boards[2])
+	if err := repository.NewBoardRepository(tx).DeleteBoards(boards[1:]); err != nil {
+		t.Fatalf("Failed to delete boards: %+v", err)
+	}
+
+	report, serr := NewIntegrityService(tx, nil).Repair()
+	if serr != nil {
+		t.Fatalf("Failed to repair: %+v", serr)
+	}
+	assert.True(t, report.Repaired)
+	// Issues are ordered by board ID which is random
+	assert.ElementsMatch(t, []IntegrityIssue{
+		{Type: IssueTypeOrphanTask, ID: moved.ID,
+			Message: "Task [integrity-moved] is on deleted board [" + boards[1].ID + "] of project [" + project.ID + "]"},
+		{Type: IssueTypeOrphanTask, ID: lost.ID,
+			Message: "Task [integrity-lost] is on deleted board [" + boards[2].ID + "] whose project can not be determined, it is left in place"},
+	}, report.Issues)
+
+	// Moved task is repaired into inbox of its project, not of default project
+	inbox, serr := NewBoardService(tx, nil).FindInboxBoard(project.ID)
+	if serr != nil {
+		t.Fatalf("Failed to find inbox: %+v", serr)
+	}
+	find, serr := srvc.FindTask(&model.Task{ID: moved.ID})
+	if serr != nil {
+		t.Fatalf("Failed to find task: %+v", serr)
+	}
+	assert.Equal(t, inbox.ID, find.BoardID)
+	find, serr = srvc.FindTask(&model.Task{ID: lost.ID})
+	if serr != nil {
+		t.Fatalf("Failed to find task: %+v", serr)
+	}
+	assert.Equal(t, boards[2].ID, find.BoardID)
+	assert.Equal(t, lost.Version, find.Version)
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"regexp"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+
+	"github.com/jinzhu/gorm"
+)
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// LabelService provides apis for label management.
+type LabelService struct {
+	tx        *gorm.DB
+	loginUser *model.User
+	labelRepo *repository.LabelRepository
+	taskRepo  *repository.TaskRepository
+}
+
+// NewLabelService return new instance of LabelService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewLabelService(tx *gorm.DB, loginUser *model.User) *LabelService {
+	return &LabelService{
+		tx:        tx,
+		loginUser: loginUser,
+		labelRepo: repository.NewLabelRepository(tx),
+		taskRepo:  repository.NewTaskRepository(tx),
+	}
+}
+
+// FindLabel returns label matching specified condition
+func (s *LabelService) FindLabel(condition interface{}) (*model.Label, error) {
+	find, err := s.labelRepo.FindFirstLabel(condition, []string{"id"})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, err, "Label not found")
+		}
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find label")
+	}
+	return &find, nil
+}
+
+// FindLabels finds all labels
+func (s *LabelService) FindLabels(condition interface{}, sortOrders []string) ([]model.Label, error) {
+	labels, err := s.labelRepo.FindLabels(condition, 0, orm.NoLimit, sortOrders)
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find labels")
+	}
+	return labels, nil
+}
+
+// FindLabelsPage finds a page of labels and returns it with the number of all labels matching condition
+func (s *LabelService) FindLabelsPage(condition interface{}, after *orm.Keyset, limit int, sortOrders []string) ([]model.Label, int, error) {
+	labels, err := repository.NewLabelRepository(orm.WhereAfter(s.tx, after)).FindLabels(condition, 0, limit, sortOrders)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to find labels")
+	}
+	count, err := s.labelRepo.CountLabels(condition)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to count labels")
+	}
+	return labels, count, nil
+}
+
+// CreateLabel creates new label
+func (s *LabelService) CreateLabel(label *model.Label) error {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := validateLabel(label); serr != nil {
+		return serr
+	}
+	err := s.labelRepo.CreateLabel(label)
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to create label")
+	}
+	return nil
+}
+
+// UpdateLabel updates specifed label
+func (s *LabelService) UpdateLabel(label *model.Label) error {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := validateLabel(label); serr != nil {
+		return serr
+	}
+	err := s.labelRepo.UpdateLabel(label)
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return NewSvcErrorf(ErrorCodeOptimisticLockFailure, err, "Label has been updated by other request. ID:%s", label.ID)
+		}
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update label. ID:%s", label.ID)
+	}
+	return nil
+}
+
+// DeleteLabel deletes specifed label and removes it from tasks
+func (s *LabelService) DeleteLabel(label *model.Label) error {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return serr
+	}
+	err := s.labelRepo.DeleteLabel(label)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete label. ID:%s", label.ID)
+	}
+	err = s.labelRepo.DeleteTaskLabels(&model.TaskLabel{LabelID: label.ID})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to remove label from tasks. ID:%s", label.ID)
+	}
+	return nil
+}
+
+// FindTaskLabelIDs returns label ids of each task, the key of result is task id
+func (s *LabelService) FindTaskLabelIDs(taskIDs []string) (map[string][]string, error) {
+	result := map[string][]string{}
+	if len(taskIDs) == 0 {
+		return result, nil
+	}
+	taskLabels, err := s.labelRepo.FindTaskLabels(taskIDs)
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find labels of tasks")
+	}
+	for _, taskLabel := range taskLabels {
+		result[taskLabel.TaskID] = append(result[taskLabel.TaskID], taskLabel.LabelID)
+	}
+	return result, nil
+}
+
+// SetTaskLabels replaces labels of specified task
+func (s *LabelService) SetTaskLabels(taskID string, labelIDs []string) error {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return serr
+	}
+	_, err := s.taskRepo.FindFirstTask(&model.Task{ID: taskID}, []string{})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return NewSvcErrorf(ErrorCodeNotFound, err, "Task not found. ID:%s", taskID)
+		}
+		return NewSvcError(ErrorCodeDB, err, "Failed to find task")
+	}
+	uniqueIDs := make([]string, 0, len(labelIDs))
+	exists := map[string]bool{}
+	for _, labelID := range labelIDs {
+		if exists[labelID] {
+			continue
+		}
+		exists[labelID] = true
+		if _, serr := s.FindLabel(&model.Label{ID: labelID}); serr != nil {
+			return serr
+		}
+		uniqueIDs = append(uniqueIDs, labelID)
+	}
+	err = s.labelRepo.DeleteTaskLabels(&model.TaskLabel{TaskID: taskID})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to remove labels from task. ID:%s", taskID)
+	}
+	err = s.labelRepo.CreateTaskLabels(taskID, uniqueIDs)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to add labels to task. ID:%s", taskID)
+	}
+	return nil
+}
+
+func validateLabel(label *model.Label) error {
+	if label.Name == "" {
+		return NewSvcError(ErrorCodeInvalidArguments, nil, "Label name must not be empty")
+	}
+	if !labelColorPattern.MatchString(label.Color) {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Label color [%s] must be #rrggbb format", label.Color)
+	}
+	return nil
+}
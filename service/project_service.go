@@ -0,0 +1,218 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ProjectService provides apis for projects and their members.
+type ProjectService struct {
+	tx          *gorm.DB
+	loginUser   *model.User
+	projectRepo *repository.ProjectRepository
+	boardRepo   *repository.BoardRepository
+	taskRepo    *repository.TaskRepository
+	userRepo    *repository.UserRepository
+	ruleRepo    *repository.TransitionRuleRepository
+}
+
+// NewProjectService return new instance of ProjectService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewProjectService(tx *gorm.DB, loginUser *model.User) *ProjectService {
+	return &ProjectService{
+		tx:          tx,
+		loginUser:   loginUser,
+		projectRepo: repository.NewProjectRepository(tx),
+		boardRepo:   repository.NewBoardRepository(tx),
+		taskRepo:    repository.NewTaskRepository(tx),
+		userRepo:    repository.NewUserRepository(tx),
+		ruleRepo:    repository.NewTransitionRuleRepository(tx),
+	}
+}
+
+// FindProject returns project matching specified condition, projects which login user is not a member of are not found
+func (s *ProjectService) FindProject(condition interface{}) (*model.Project, error) {
+	find, err := s.projectRepo.FindFirstProject(condition, []string{"id"})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, err, "Project not found")
+		}
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find project")
+	}
+	visible, serr := isProjectVisible(s.projectRepo, s.loginUser, find.ID)
+	if serr != nil {
+		return nil, serr
+	}
+	if !visible {
+		return nil, NewSvcErrorf(ErrorCodeNotFound, nil, "Project not found")
+	}
+	return &find, nil
+}
+
+// FindProjectsPage finds a page of projects which login user is a member of, and returns it with the number of them
+func (s *ProjectService) FindProjectsPage(after *orm.Keyset, limit int, sortOrders []string) ([]model.Project, int, error) {
+	projectRepo := repository.NewProjectRepository(orm.WhereAfter(s.tx, after))
+	var projects []model.Project
+	var count int
+	var err error
+	if s.loginUser == nil || s.loginUser.HasRole(model.RoleAdmin) {
+		projects, err = projectRepo.FindProjects(&model.Project{}, 0, limit, sortOrders)
+	} else {
+		projects, err = projectRepo.FindMemberProjects(s.loginUser.ID, 0, limit, sortOrders)
+	}
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to find projects")
+	}
+	if s.loginUser == nil || s.loginUser.HasRole(model.RoleAdmin) {
+		count, err = s.projectRepo.CountProjects(&model.Project{})
+	} else {
+		count, err = s.projectRepo.CountMemberProjects(s.loginUser.ID)
+	}
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to count projects")
+	}
+	return projects, count, nil
+}
+
+// CreateProject creates new project with system boards of the workflow
+func (s *ProjectService) CreateProject(project *model.Project) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := s.validateProject(project); serr != nil {
+		return serr
+	}
+	err := s.projectRepo.CreateProject(project)
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to create project")
+	}
+	return NewBoardService(s.tx, s.loginUser).SyncSystemBoards(project.ID)
+}
+
+// UpdateProject updates specifed project
+func (s *ProjectService) UpdateProject(project *model.Project) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := s.validateProject(project); serr != nil {
+		return serr
+	}
+	err := s.projectRepo.UpdateProject(project)
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return NewSvcErrorf(ErrorCodeOptimisticLockFailure, err, "Project has been updated by other request. ID:%s", project.ID)
+		}
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update project. ID:%s", project.ID)
+	}
+	return nil
+}
+
+// DeleteProject deletes specifed project with its boards and members.
+// Default project and projects which have tasks can not be deleted.
+func (s *ProjectService) DeleteProject(project *model.Project) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if project.ID == model.DefaultProjectID {
+		return NewSvcError(ErrorCodePreconditionInvalid, nil, "Default project can not be deleted")
+	}
+	boards, err := s.boardRepo.FindBoards(&model.Board{ProjectID: project.ID}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to find boards. ProjectID:%s", project.ID)
+	}
+	count, err := s.taskRepo.CountFilteredTasks(&model.Task{}, &repository.TaskFilter{ProjectIDs: []string{project.ID}})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to count tasks. ProjectID:%s", project.ID)
+	}
+	if count > 0 {
+		return NewSvcErrorf(ErrorCodePreconditionInvalid, nil,
+			"Project has %d task(s), move or delete them before deleting project", count)
+	}
+	for i := range boards {
+		if err = s.boardRepo.DeleteBoard(&boards[i]); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete board. ID:%s", boards[i].ID)
+		}
+		if err = s.ruleRepo.DeleteBoardTransitionRules(boards[i].ID); err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete transition rules of board. ID:%s", boards[i].ID)
+		}
+	}
+	err = s.projectRepo.DeleteProjectMembers(&model.ProjectMember{ProjectID: project.ID})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete members of project. ID:%s", project.ID)
+	}
+	err = s.projectRepo.DeleteProject(project)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete project. ID:%s", project.ID)
+	}
+	return nil
+}
+
+// FindProjectMemberIDs returns IDs of users who are members of specified project
+func (s *ProjectService) FindProjectMemberIDs(projectID string) ([]string, error) {
+	if _, serr := s.FindProject(&model.Project{ID: projectID}); serr != nil {
+		return nil, serr
+	}
+	members, err := s.projectRepo.FindProjectMembers(&model.ProjectMember{ProjectID: projectID})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find members of project")
+	}
+	userIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+	return userIDs, nil
+}
+
+// SetProjectMembers replaces members of specified project
+func (s *ProjectService) SetProjectMembers(projectID string, userIDs []string) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if _, serr := s.FindProject(&model.Project{ID: projectID}); serr != nil {
+		return serr
+	}
+	uniqueIDs := make([]string, 0, len(userIDs))
+	exists := map[string]bool{}
+	for _, userID := range userIDs {
+		if exists[userID] {
+			continue
+		}
+		exists[userID] = true
+		_, err := s.userRepo.FindFirstUser(&model.User{ID: userID}, []string{})
+		if err != nil {
+			if err == orm.ErrorRecordNotFound {
+				return NewSvcErrorf(ErrorCodeNotFound, err, "User not found. ID:%s", userID)
+			}
+			return NewSvcError(ErrorCodeDB, err, "Failed to find user")
+		}
+		uniqueIDs = append(uniqueIDs, userID)
+	}
+	err := s.projectRepo.DeleteProjectMembers(&model.ProjectMember{ProjectID: projectID})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to remove members from project. ID:%s", projectID)
+	}
+	err = s.projectRepo.CreateProjectMembers(projectID, uniqueIDs)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to add members to project. ID:%s", projectID)
+	}
+	return nil
+}
+
+func (s *ProjectService) validateProject(project *model.Project) error {
+	if project.Name == "" {
+		return NewSvcError(ErrorCodeInvalidArguments, nil, "Project name must not be empty")
+	}
+	projects, err := s.projectRepo.FindProjects(&model.Project{Name: project.Name}, 0, orm.NoLimit, []string{})
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to find projects")
+	}
+	for _, other := range projects {
+		if other.ID != project.ID {
+			return NewSvcErrorf(ErrorCodeAlreadyExist, nil, "Project [%s] already exists", project.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"taskboard/rank"
+)
+
+// newRank returns a rank between neighbors found by findNeighbors.
+// When the rank gets too long, ranks of siblings are rebalanced and neighbors are found again.
+func newRank(findNeighbors func() (prev, next string, err error), rebalance func() error) (string, error) {
+	prev, next, err := findNeighbors()
+	if err != nil {
+		return "", err
+	}
+	key, err := rank.Between(prev, next)
+	if err != nil {
+		return "", NewSvcErrorf(ErrorCodeUnexpected, err, "Failed to generate rank between [%s] and [%s]", prev, next)
+	}
+	if !rank.NeedsRebalance(key) {
+		return key, nil
+	}
+	if err = rebalance(); err != nil {
+		return "", NewSvcError(ErrorCodeDB, err, "Failed to rebalance ranks")
+	}
+	prev, next, err = findNeighbors()
+	if err != nil {
+		return "", err
+	}
+	key, err = rank.Between(prev, next)
+	if err != nil {
+		return "", NewSvcErrorf(ErrorCodeUnexpected, err, "Failed to generate rank between [%s] and [%s]", prev, next)
+	}
+	return key, nil
+}
+
+// newRankConflictError returns error when other request places an item at the same position concurrently
+func newRankConflictError(err error, groupID string) error {
+	return NewSvcErrorf(ErrorCodeOptimisticLockFailure, err,
+		"Order has been changed by other request, please retry. ID:%s", groupID)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+
+	"github.com/jinzhu/gorm"
+)
+
+// SavedViewService provides apis for saved views of tasks.
+type SavedViewService struct {
+	tx        *gorm.DB
+	loginUser *model.User
+	viewRepo  *repository.SavedViewRepository
+}
+
+// NewSavedViewService return new instance of SavedViewService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewSavedViewService(tx *gorm.DB, loginUser *model.User) *SavedViewService {
+	return &SavedViewService{
+		tx:        tx,
+		loginUser: loginUser,
+		viewRepo:  repository.NewSavedViewRepository(tx),
+	}
+}
+
+// FindSavedView returns saved view matching specified condition.
+// Private views of other users are not found.
+func (s *SavedViewService) FindSavedView(condition interface{}) (*model.SavedView, error) {
+	find, err := s.viewRepo.FindFirstSavedView(condition, []string{"id"})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, err, "Saved view not found")
+		}
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find saved view")
+	}
+	if s.loginUser != nil && !find.IsShared && find.OwnerUserID != s.loginUser.ID {
+		return nil, NewSvcErrorf(ErrorCodeNotFound, nil, "Saved view not found")
+	}
+	return &find, nil
+}
+
+// FindSavedViewsPage finds a page of views which are owned by login user or shared,
+// and returns it with the number of all those views
+func (s *SavedViewService) FindSavedViewsPage(after *orm.Keyset, limit int, sortOrders []string) ([]model.SavedView, int, error) {
+	userID := ""
+	if s.loginUser != nil {
+		userID = s.loginUser.ID
+	}
+	views, err := repository.NewSavedViewRepository(orm.WhereAfter(s.tx, after)).
+		FindVisibleSavedViews(userID, 0, limit, sortOrders)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to find saved views")
+	}
+	count, err := s.viewRepo.CountVisibleSavedViews(userID)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to count saved views")
+	}
+	return views, count, nil
+}
+
+// CreateSavedView creates new saved view
+func (s *SavedViewService) CreateSavedView(view *model.SavedView) error {
+	if serr := s.validateSavedView(view); serr != nil {
+		return serr
+	}
+	err := s.viewRepo.CreateSavedView(view)
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to create saved view")
+	}
+	return nil
+}
+
+// UpdateSavedView updates specifed saved view, only owner or admin can update it
+func (s *SavedViewService) UpdateSavedView(view *model.SavedView) error {
+	if serr := s.authorizeOwner(view); serr != nil {
+		return serr
+	}
+	if serr := s.validateSavedView(view); serr != nil {
+		return serr
+	}
+	err := s.viewRepo.UpdateSavedView(view)
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return NewSvcErrorf(ErrorCodeOptimisticLockFailure, err, "Saved view has been updated by other request. ID:%s", view.ID)
+		}
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update saved view. ID:%s", view.ID)
+	}
+	return nil
+}
+
+// DeleteSavedView deletes specifed saved view, only owner or admin can delete it
+func (s *SavedViewService) DeleteSavedView(view *model.SavedView) error {
+	if serr := s.authorizeOwner(view); serr != nil {
+		return serr
+	}
+	err := s.viewRepo.DeleteSavedView(view)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete saved view. ID:%s", view.ID)
+	}
+	return nil
+}
+
+// ExecuteSavedViewPage finds a page of tasks matching board and query of saved view,
+// and returns it with the number of all matching tasks. Use SavedViewSortOrders for the sort of the view.
+// "me" in query means login user, not owner of the view.
+func (s *SavedViewService) ExecuteSavedViewPage(view *model.SavedView, after *orm.Keyset, limit int, sortOrders []string,
+) ([]model.Task, int, error) {
+	query, serr := ParseTaskQuery(view.Query, s.loginUser)
+	if serr != nil {
+		return nil, 0, serr
+	}
+	return NewTaskService(s.tx, s.loginUser).FindTasksPage(
+		&model.Task{BoardID: view.BoardID}, &repository.TaskFilter{Query: query}, after, limit, sortOrders)
+}
+
+// authorizeOwner checks whether login user owns the view.
+// Views do not change tasks, so viewers can manage their own views.
+func (s *SavedViewService) authorizeOwner(view *model.SavedView) error {
+	if s.loginUser != nil && s.loginUser.ID != view.OwnerUserID {
+		return authorizeAdmin(s.loginUser)
+	}
+	return nil
+}
+
+func (s *SavedViewService) validateSavedView(view *model.SavedView) error {
+	if view.Name == "" {
+		return NewSvcError(ErrorCodeInvalidArguments, nil, "Saved view name must not be empty")
+	}
+	if _, serr := ParseTaskQuery(view.Query, s.loginUser); serr != nil {
+		return serr
+	}
+	if _, serr := SavedViewSortOrders(view); serr != nil {
+		return serr
+	}
+	if view.BoardID != "" {
+		// Boards of projects which owner is not a member of are not found
+		if _, serr := NewBoardService(s.tx, s.loginUser).FindBoard(&model.Board{ID: view.BoardID}); serr != nil {
+			return serr
+		}
+	}
+	views, err := s.viewRepo.FindSavedViews(&model.SavedView{OwnerUserID: view.OwnerUserID, Name: view.Name},
+		0, orm.NoLimit, []string{})
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to find saved views")
+	}
+	for _, other := range views {
+		if other.ID != view.ID {
+			return NewSvcErrorf(ErrorCodeAlreadyExist, nil, "Saved view [%s] already exists", view.Name)
+		}
+	}
+	return nil
+}
+
+// SavedViewSortOrders returns sort orders of tasks in saved view, or default sort orders of tasks if view has no sort
+func SavedViewSortOrders(view *model.SavedView) ([]string, error) {
+	if view.Sort == "" {
+		return TaskDefaultSortOrders, nil
+	}
+	return ParseSortOrders(view.Sort, TaskSortKeys)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"strings"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+
+	"github.com/jinzhu/gorm"
+)
+
+// SearchService provides apis for full-text search of tasks and comments.
+type SearchService struct {
+	tx          *gorm.DB
+	loginUser   *model.User
+	searchRepo  *repository.SearchRepository
+	projectRepo *repository.ProjectRepository
+}
+
+// NewSearchService return new instance of SearchService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewSearchService(tx *gorm.DB, loginUser *model.User) *SearchService {
+	return &SearchService{
+		tx:          tx,
+		loginUser:   loginUser,
+		searchRepo:  repository.NewSearchRepository(tx),
+		projectRepo: repository.NewProjectRepository(tx),
+	}
+}
+
+// IsIndexEnabled checks whether search index is available
+func (s *SearchService) IsIndexEnabled() (bool, error) {
+	enabled, err := s.searchRepo.IsSearchIndexEnabled()
+	if err != nil {
+		return false, NewSvcError(ErrorCodeDB, err, "Failed to check search index")
+	}
+	return enabled, nil
+}
+
+// Search finds a page of tasks and comments which contain all words in text in order of relevance,
+// and returns it with the number of all hits. Only tasks of projects which login user is a member of are found.
+func (s *SearchService) Search(text string, after *orm.Keyset, limit int) ([]repository.SearchHit, int, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, 0, NewSvcError(ErrorCodeInvalidArguments, nil, "Search words must not be empty")
+	}
+	enabled, serr := s.IsIndexEnabled()
+	if serr != nil {
+		return nil, 0, serr
+	}
+	if !enabled {
+		return nil, 0, NewSvcError(ErrorCodePreconditionInvalid, nil,
+			"Search index is not available, build server with tag sqlite_fts5 and execute rebuild-index command")
+	}
+	projectIDs, serr := visibleProjectIDs(s.projectRepo, s.loginUser)
+	if serr != nil {
+		return nil, 0, serr
+	}
+	hits, err := s.searchRepo.SearchDocuments(text, projectIDs, after, limit)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to search tasks and comments")
+	}
+	count, err := s.searchRepo.CountSearchDocuments(text, projectIDs)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to count hits of search")
+	}
+	return hits, count, nil
+}
+
+// RebuildIndex recreates search index from all tasks and comments
+func (s *SearchService) RebuildIndex() error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	err := s.searchRepo.RebuildSearchIndex()
+	if err == repository.ErrorSearchNotSupported {
+		return NewSvcError(ErrorCodePreconditionInvalid, err, err.Error())
+	}
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to rebuild search index")
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ const (
 	ErrorCodeOptimisticLockFailure ErrorCode = "OptimisticLockFailure"
 	ErrorCodePreconditionInvalid   ErrorCode = "PreconditionInvalid"
 	ErrorCodeUnauthenticated       ErrorCode = "Unauthenticated"
+	ErrorCodeForbidden             ErrorCode = "Forbidden"
 )
 
 // SvcError presents error of logic service, This has error code, message and cause error.
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"taskboard/common"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Kinds of session token
+const (
+	tokenKindAccess  = "access"
+	tokenKindRefresh = "refresh"
+)
+
+var tokenSecret []byte
+
+// SetTokenSecret sets secret key to sign session tokens. Must be called before issuing tokens.
+func SetTokenSecret(secret []byte) {
+	tokenSecret = secret
+}
+
+// SessionService provides apis for login session management.
+type SessionService struct {
+	tx          *gorm.DB
+	sessionRepo *repository.SessionRepository
+	userRepo    *repository.UserRepository
+}
+
+// NewSessionService return new instance of SessionService.
+func NewSessionService(tx *gorm.DB) *SessionService {
+	return &SessionService{
+		tx:          tx,
+		sessionRepo: repository.NewSessionRepository(tx),
+		userRepo:    repository.NewUserRepository(tx),
+	}
+}
+
+// FindSession returns session matching specified condition
+func (s *SessionService) FindSession(condition interface{}) (*model.Session, error) {
+	find, err := s.sessionRepo.FindFirstSession(condition, []string{"id"})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, err, "Session not found")
+		}
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find session")
+	}
+	return &find, nil
+}
+
+// FindSessions finds all sessions
+func (s *SessionService) FindSessions(condition interface{}, sortOrders []string) ([]model.Session, error) {
+	sessions, err := s.sessionRepo.FindSessions(condition, 0, orm.NoLimit, sortOrders)
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find sessions")
+	}
+	return sessions, nil
+}
+
+// FindSessionsPage finds a page of sessions and returns it with the number of all sessions matching condition
+func (s *SessionService) FindSessionsPage(condition interface{}, after *orm.Keyset, limit int, sortOrders []string) ([]model.Session, int, error) {
+	sessions, err := repository.NewSessionRepository(orm.WhereAfter(s.tx, after)).FindSessions(condition, 0, limit, sortOrders)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to find sessions")
+	}
+	count, err := s.sessionRepo.CountSessions(condition)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to count sessions")
+	}
+	return sessions, count, nil
+}
+
+// Login verifies password of user and starts new session
+func (s *SessionService) Login(name, password string) (*model.User, *model.Session, error) {
+	user, serr := NewUserService(s.tx, nil).Login(name, password)
+	if serr != nil {
+		return nil, nil, serr
+	}
+	session := model.NewSession(user.ID, time.Now().UTC())
+	err := s.sessionRepo.CreateSession(session)
+	if err != nil {
+		return nil, nil, NewSvcError(ErrorCodeDB, err, "Failed to create session")
+	}
+	return user, session, nil
+}
+
+// Authenticate verifies access token and returns user and session of the token
+func (s *SessionService) Authenticate(accessToken string) (*model.User, *model.Session, error) {
+	session, serr := s.verifyToken(tokenKindAccess, accessToken)
+	if serr != nil {
+		return nil, nil, serr
+	}
+	user, err := s.userRepo.FindFirstUser(&model.User{ID: session.UserID}, []string{})
+	if err != nil {
+		// Does not describe details
+		return nil, nil, NewSvcError(ErrorCodeUnauthenticated, err, "Invalid session token")
+	}
+	return &user, session, nil
+}
+
+// Refresh verifies refresh token and extends expiration of the session.
+// Refresh token is rotated, the token of returned session must be used for the next refresh.
+func (s *SessionService) Refresh(refreshToken string) (*model.Session, error) {
+	session, serr := s.verifyToken(tokenKindRefresh, refreshToken)
+	if serr != nil {
+		return nil, serr
+	}
+	session.Extend(time.Now().UTC())
+	err := s.sessionRepo.UpdateSession(session)
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcErrorf(ErrorCodeOptimisticLockFailure, err, "Session has been updated by other request. ID:%s", session.ID)
+		}
+		return nil, NewSvcErrorf(ErrorCodeDB, err, "Failed to refresh session. ID:%s", session.ID)
+	}
+	return session, nil
+}
+
+// RevokeSession revokes specified session, the tokens of it can not be used any more
+func (s *SessionService) RevokeSession(session *model.Session) error {
+	session.IsRevoked = true
+	err := s.sessionRepo.UpdateSession(session)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to revoke session. ID:%s", session.ID)
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes all sessions of specified user
+func (s *SessionService) RevokeUserSessions(userID string) error {
+	err := s.sessionRepo.RevokeUserSessions(userID)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to revoke sessions. UserID:%s", userID)
+	}
+	return nil
+}
+
+// AccessToken returns signed access token of session
+func (s *SessionService) AccessToken(session *model.Session) string {
+	return signSessionToken(tokenKindAccess, session, session.ExpiredDate)
+}
+
+// RefreshToken returns signed refresh token of session
+func (s *SessionService) RefreshToken(session *model.Session) string {
+	return signSessionToken(tokenKindRefresh, session, session.RefreshExpiredDate)
+}
+
+func (s *SessionService) verifyToken(kind, token string) (*model.Session, error) {
+	sessionID, version, expiredDate, err := parseSessionToken(kind, token)
+	if err != nil {
+		// Does not describe details
+		return nil, NewSvcError(ErrorCodeUnauthenticated, err, "Invalid session token")
+	}
+	now := time.Now().UTC()
+	if !now.Before(expiredDate) {
+		return nil, NewSvcError(ErrorCodeUnauthenticated, nil, "Session token is expired")
+	}
+	session, err := s.sessionRepo.FindFirstSession(&model.Session{ID: sessionID}, []string{})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeUnauthenticated, err, "Invalid session token")
+	}
+	if !session.IsActive(now) {
+		return nil, NewSvcError(ErrorCodeUnauthenticated, nil, "Session is revoked or expired")
+	}
+	// Refresh token can be used only once, while access tokens issued before stay valid until they expire
+	if kind == tokenKindRefresh && session.Version != version {
+		return nil, NewSvcError(ErrorCodeUnauthenticated, nil, "Refresh token has already been used")
+	}
+	return &session, nil
+}
+
+// signSessionToken creates token whose payload is "kind:sessionID:version:expiration(unix time)",
+// version of session changes when it is refreshed
+func signSessionToken(kind string, session *model.Session, expiredDate time.Time) string {
+	payload := fmt.Sprintf("%s:%s:%d:%d", kind, session.ID, session.Version, expiredDate.Unix())
+	return common.SignToken(tokenSecret, payload)
+}
+
+func parseSessionToken(kind, token string) (sessionID string, version int, expiredDate time.Time, err error) {
+	payload, err := common.VerifyToken(tokenSecret, token)
+	if err != nil {
+		return
+	}
+	values := strings.Split(payload, ":")
+	if len(values) != 4 || values[0] != kind {
+		err = common.ErrorInvalidToken
+		return
+	}
+	version, err = strconv.Atoi(values[2])
+	if err != nil {
+		err = common.ErrorInvalidToken
+		return
+	}
+	expiration, err := strconv.ParseInt(values[3], 10, 64)
+	if err != nil {
+		err = common.ErrorInvalidToken
+		return
+	}
+	return values[1], version, time.Unix(expiration, 0).UTC(), nil
+}
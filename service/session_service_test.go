@@ -0,0 +1,155 @@
+package service
+
+import (
+	"strings"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func loginSessionTestUser(t *testing.T, tx *gorm.DB, name string) (*SessionService, *model.User, *model.Session) {
+	SetTokenSecret([]byte("session-test-secret"))
+	if err := tx.Create(model.NewUser(name, "password", "")).Error; err != nil {
+		t.Fatalf("Failed to create user: %+v", err)
+	}
+	srvc := NewSessionService(tx)
+	user, session, serr := srvc.Login(name, "password")
+	if serr != nil {
+		t.Fatalf("Failed to login: %+v", serr)
+	}
+	return srvc, user, session
+}
+
+func assertUnauthenticated(t *testing.T, err error, message string) {
+	if serr, ok := err.(*SvcError); assert.True(t, ok, "SvcError must be returned: %v", err) {
+		assert.Equal(t, ErrorCodeUnauthenticated, serr.Code)
+		assert.Equal(t, message, serr.Message)
+	}
+}
+
+func TestSessionService_Authenticate(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	srvc, user, session := loginSessionTestUser(t, tx, "session-user")
+	find, findSession, serr := srvc.Authenticate(srvc.AccessToken(session))
+	if serr != nil {
+		t.Fatalf("Failed to authenticate: %+v", serr)
+	}
+	assert.Equal(t, user.ID, find.ID)
+	assert.Equal(t, session.ID, findSession.ID)
+
+	// Refresh token is not an access token
+	_, _, serr = srvc.Authenticate(srvc.RefreshToken(session))
+	assertUnauthenticated(t, serr, "Invalid session token")
+}
+
+func TestSessionService_TamperedToken(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	srvc, _, session := loginSessionTestUser(t, tx, "session-tampered")
+	token := srvc.AccessToken(session)
+	signature := token[strings.Index(token, ".")+1:]
+	tampered := token[:strings.Index(token, ".")+1] + strings.Repeat("A", len(signature))
+	_, _, serr := srvc.Authenticate(tampered)
+	assertUnauthenticated(t, serr, "Invalid session token")
+
+	// Token signed by other secret is invalid
+	SetTokenSecret([]byte("other-secret"))
+	_, _, serr = srvc.Authenticate(token)
+	assertUnauthenticated(t, serr, "Invalid session token")
+}
+
+func TestSessionService_ExpiredToken(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	srvc, _, session := loginSessionTestUser(t, tx, "session-expired")
+	session.ExpiredDate = time.Now().UTC().Add(-time.Second)
+	_, _, serr := srvc.Authenticate(srvc.AccessToken(session))
+	assertUnauthenticated(t, serr, "Session token is expired")
+
+	session.RefreshExpiredDate = time.Now().UTC().Add(-time.Second)
+	_, serr = srvc.Refresh(srvc.RefreshToken(session))
+	assertUnauthenticated(t, serr, "Session token is expired")
+}
+
+func TestSessionService_RevokedSession(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	srvc, _, session := loginSessionTestUser(t, tx, "session-revoked")
+	accessToken := srvc.AccessToken(session)
+	refreshToken := srvc.RefreshToken(session)
+	if serr := srvc.RevokeSession(session); serr != nil {
+		t.Fatalf("Failed to revoke session: %+v", serr)
+	}
+	_, _, serr := srvc.Authenticate(accessToken)
+	assertUnauthenticated(t, serr, "Session is revoked or expired")
+	_, serr = srvc.Refresh(refreshToken)
+	assertUnauthenticated(t, serr, "Session is revoked or expired")
+}
+
+func TestSessionService_Refresh(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	srvc, _, session := loginSessionTestUser(t, tx, "session-refresh")
+	oldAccessToken := srvc.AccessToken(session)
+	oldRefreshToken := srvc.RefreshToken(session)
+	refreshed, serr := srvc.Refresh(oldRefreshToken)
+	if serr != nil {
+		t.Fatalf("Failed to refresh: %+v", serr)
+	}
+	assert.Equal(t, session.ID, refreshed.ID)
+
+	// Tokens are rotated, the old refresh token can not be used again
+	newRefreshToken := srvc.RefreshToken(refreshed)
+	assert.NotEqual(t, oldRefreshToken, newRefreshToken)
+	_, serr = srvc.Refresh(oldRefreshToken)
+	assertUnauthenticated(t, serr, "Refresh token has already been used")
+	_, _, serr = srvc.Authenticate(srvc.AccessToken(refreshed))
+	assert.Nil(t, serr)
+	_, _, serr = srvc.Authenticate(oldAccessToken)
+	assert.Nil(t, serr)
+	_, serr = srvc.Refresh(newRefreshToken)
+	assert.Nil(t, serr)
+}
+
+func TestSessionService_RevokedByUserChange(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	srvc, user, session := loginSessionTestUser(t, tx, "session-user-change")
+	userSrvc := NewUserService(tx, nil)
+
+	// Changing profile keeps sessions, changing password revokes them
+	user.Avator = "avator.png"
+	if serr := userSrvc.UpdateUser(user); serr != nil {
+		t.Fatalf("Failed to update user: %+v", serr)
+	}
+	_, _, serr := srvc.Authenticate(srvc.AccessToken(session))
+	assert.Nil(t, serr)
+	user.SetPassword("new-password")
+	if serr := userSrvc.UpdateUser(user); serr != nil {
+		t.Fatalf("Failed to update user: %+v", serr)
+	}
+	_, _, serr = srvc.Authenticate(srvc.AccessToken(session))
+	assertUnauthenticated(t, serr, "Session is revoked or expired")
+
+	// Deleting user revokes sessions
+	_, session, serr = srvc.Login(user.Name, "new-password")
+	if serr != nil {
+		t.Fatalf("Failed to login: %+v", serr)
+	}
+	if serr := userSrvc.DeleteUser(user); serr != nil {
+		t.Fatalf("Failed to delete user: %+v", serr)
+	}
+	_, _, serr = srvc.Authenticate(srvc.AccessToken(session))
+	assertUnauthenticated(t, serr, "Session is revoked or expired")
+}
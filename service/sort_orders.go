@@ -0,0 +1,45 @@
+package service
+
+import (
+	"sort"
+	"strings"
+)
+
+// TaskSortKeys maps sort keys of tasks to columns
+var TaskSortKeys = map[string]string{
+	"name":         "name",
+	"boardID":      "board_id",
+	"rank":         "rank",
+	"createDate":   "created_date",
+	"isClosed":     "is_closed",
+	"estimateSize": "estimate_size",
+	"startDate":    "start_date",
+	"dueDate":      "due_date",
+}
+
+// TaskDefaultSortOrders orders tasks as they are shown on boards
+var TaskDefaultSortOrders = []string{"board_id, rank"}
+
+// ParseSortOrders converts comma separated sort keys to sort orders of columns, key prefixed by "-" is descending
+func ParseSortOrders(sortParam string, sortKeys map[string]string) ([]string, error) {
+	sortOrders := []string{}
+	for _, key := range strings.Split(sortParam, ",") {
+		order := ""
+		if strings.HasPrefix(key, "-") {
+			key = key[1:]
+			order = " desc"
+		}
+		column, ok := sortKeys[key]
+		if !ok {
+			keys := make([]string, 0, len(sortKeys))
+			for k := range sortKeys {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			return nil, NewSvcErrorf(ErrorCodeInvalidArguments, nil,
+				"Sort key [%s] is invalid, must be one of [%s]", key, strings.Join(keys, ","))
+		}
+		sortOrders = append(sortOrders, column+order)
+	}
+	return sortOrders, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/repository"
+	"time"
+)
+
+// recordTaskHistories records changed fields between before and after as histories of revision.
+// before is empty task when task is created.
+func recordTaskHistories(repo *repository.TaskHistoryRepository, loginUser *model.User,
+	before, after *model.Task, revision int,
+) error {
+	actorUserID := ""
+	if loginUser != nil {
+		actorUserID = loginUser.ID
+	}
+	now := time.Now().UTC()
+	histories := make([]*model.TaskHistory, 0, len(model.TaskHistoryFields))
+	for _, field := range model.TaskHistoryFields {
+		oldValue := before.FieldValue(field)
+		newValue := after.FieldValue(field)
+		if oldValue == newValue {
+			continue
+		}
+		histories = append(histories,
+			model.NewTaskHistory(after.ID, revision, actorUserID, field, oldValue, newValue, now))
+	}
+	err := repo.CreateTaskHistories(histories)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to record history of task. ID:%s", after.ID)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"taskboard/model"
+	"time"
+)
+
+// TaskImportRow is a task to be imported, board and assignee are specified by their names
+type TaskImportRow struct {
+	Row          int // Row number in imported data, used for reporting
+	Name         string
+	Description  string
+	BoardName    string // Name of board in the project, inbox board if empty
+	AssigneeName string // Not assigned if empty
+	EstimateSize int
+	IsClosed     bool
+	CreatedDate  *time.Time // Now if nil
+}
+
+// TaskImportResult presents the result of importing a row, either Task or Error is set
+type TaskImportResult struct {
+	Row   int
+	Task  *model.Task // Created task
+	Error error       // Error of the row, other rows are imported even if this is set
+}
+
+// ImportTasks creates tasks of rows in the project and returns the result of each row.
+// Rows whose board or assignee is not found, which are invalid or which are rejected such as by WIP limit
+// are reported as errors and not created, while other errors such as DB errors fail the whole import.
+// Warnings of created tasks are returned by Warnings.
+func (s *TaskService) ImportTasks(projectID string, rows []*TaskImportRow) ([]*TaskImportResult, error) {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	boardSrvc := NewBoardService(s.tx, s.loginUser)
+	if serr := boardSrvc.findVisibleProject(projectID); serr != nil {
+		return nil, serr
+	}
+	inbox, serr := boardSrvc.FindInboxBoard(projectID)
+	if serr != nil {
+		return nil, serr
+	}
+	userSrvc := NewUserService(s.tx, s.loginUser)
+	boardIDs := map[string]string{"": inbox.ID} // Board name to ID
+	userIDs := map[string]string{}              // User name to ID
+	results := make([]*TaskImportResult, 0, len(rows))
+	for _, row := range rows {
+		task, serr := s.importTask(projectID, row, boardSrvc, userSrvc, boardIDs, userIDs)
+		if serr != nil {
+			if !isTaskImportRowError(serr) {
+				return nil, serr
+			}
+			results = append(results, &TaskImportResult{Row: row.Row, Error: serr})
+			continue
+		}
+		results = append(results, &TaskImportResult{Row: row.Row, Task: task})
+	}
+	return results, nil
+}
+
+// importTask creates a task of the row, names of boards and users found are cached in maps
+func (s *TaskService) importTask(projectID string, row *TaskImportRow, boardSrvc *BoardService, userSrvc *UserService,
+	boardIDs, userIDs map[string]string,
+) (*model.Task, error) {
+	if row.Name == "" {
+		return nil, NewSvcError(ErrorCodeInvalidArguments, nil, "Task name must not be empty")
+	}
+	if row.EstimateSize < 0 {
+		return nil, NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Estimate size [%d] must not be negative", row.EstimateSize)
+	}
+	createdDate := time.Now().UTC()
+	if row.CreatedDate != nil {
+		createdDate = row.CreatedDate.UTC()
+	}
+	task := model.NewTask(row.Name, row.Description, row.IsClosed, createdDate)
+	task.EstimateSize = row.EstimateSize
+	boardID, ok := boardIDs[row.BoardName]
+	if !ok {
+		board, serr := boardSrvc.FindBoard(&model.Board{ProjectID: projectID, Name: row.BoardName})
+		if serr != nil {
+			return nil, withTaskImportMessage(serr, "Board [%s] not found", row.BoardName)
+		}
+		boardID = board.ID
+		boardIDs[row.BoardName] = boardID
+	}
+	task.BoardID = boardID
+	if row.AssigneeName != "" {
+		userID, ok := userIDs[row.AssigneeName]
+		if !ok {
+			user, serr := userSrvc.FindUser(&model.User{Name: row.AssigneeName})
+			if serr != nil {
+				return nil, withTaskImportMessage(serr, "User [%s] not found", row.AssigneeName)
+			}
+			userID = user.ID
+			userIDs[row.AssigneeName] = userID
+		}
+		task.SetAssigneeUserID(userID)
+	}
+	if serr := s.CreateTask(task); serr != nil {
+		return nil, serr
+	}
+	return task, nil
+}
+
+// withTaskImportMessage replaces message of not found error with the one describing the name
+func withTaskImportMessage(err error, format string, values ...interface{}) error {
+	if serr, ok := err.(*SvcError); ok && serr.Code == ErrorCodeNotFound {
+		return NewSvcErrorf(ErrorCodeNotFound, nil, format, values...)
+	}
+	return err
+}
+
+// isTaskImportRowError checks whether the error is caused by the row, not by the system.
+// Rows are checked before changing database, so the rejected row leaves nothing behind.
+func isTaskImportRowError(err error) bool {
+	serr, ok := err.(*SvcError)
+	if !ok {
+		return false
+	}
+	switch serr.Code {
+	case ErrorCodeInvalidArguments, ErrorCodeNotFound, ErrorCodePreconditionInvalid, ErrorCodeForbidden:
+		return true
+	}
+	return false
+}
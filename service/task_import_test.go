@@ -0,0 +1,59 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskService_ImportTasksOverWIPLimit(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	project := createBackupTestProject(t, tx, "import-wip")
+	boardSrvc := NewBoardService(tx, nil)
+	for _, mode := range []string{model.WIPModeBlock, model.WIPModeWarn} {
+		board := model.NewBoard("Limited-"+mode, false, false, time.Now().UTC())
+		board.ProjectID = project.ID
+		board.WIPLimit = 1
+		board.WIPMode = mode
+		if serr := boardSrvc.CreateBoard(board); serr != nil {
+			t.Fatalf("Failed to create board: %+v", serr)
+		}
+	}
+
+	srvc := NewTaskService(tx, nil)
+	results, serr := srvc.ImportTasks(project.ID, []*TaskImportRow{
+		{Row: 2, Name: "first", BoardName: "Limited-block"},
+		{Row: 3, Name: "blocked", BoardName: "Limited-block"},
+		{Row: 4, Name: "inbox"},
+		{Row: 5, Name: "first", BoardName: "Limited-warn"},
+		{Row: 6, Name: "warned", BoardName: "Limited-warn"},
+		{Row: 7, Name: "unknown", BoardName: "Unknown"},
+	})
+	if serr != nil {
+		t.Fatalf("Failed to import tasks: %+v", serr)
+	}
+	if len(results) != 6 {
+		t.Fatalf("Expected results = %d, but got %d", 6, len(results))
+	}
+	for _, result := range results {
+		switch result.Row {
+		case 3:
+			if assert.Error(t, result.Error) {
+				assert.Equal(t, ErrorCodePreconditionInvalid, result.Error.(*SvcError).Code)
+			}
+		case 7:
+			if assert.Error(t, result.Error) {
+				assert.Equal(t, ErrorCodeNotFound, result.Error.(*SvcError).Code)
+			}
+		default:
+			assert.NoError(t, result.Error, result.Row)
+			assert.NotNil(t, result.Task, result.Row)
+		}
+	}
+	assert.Equal(t, []string{"Board [Limited-warn] exceeds WIP limit 1 with 2 open tasks"}, srvc.Warnings())
+}
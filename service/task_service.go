@@ -1,24 +1,42 @@
 package service
 
 import (
+	"fmt"
+	"strings"
 	"taskboard/model"
 	"taskboard/orm"
 	"taskboard/repository"
+	"time"
 
 	"github.com/jinzhu/gorm"
 )
 
 // TaskService provides apis for task management.
 type TaskService struct {
-	tx       *gorm.DB
-	taskRepo *repository.TaskRepository
+	tx          *gorm.DB
+	loginUser   *model.User
+	taskRepo    *repository.TaskRepository
+	boardRepo   *repository.BoardRepository
+	commentRepo *repository.CommentRepository
+	labelRepo   *repository.LabelRepository
+	historyRepo *repository.TaskHistoryRepository
+	projectRepo *repository.ProjectRepository
+	warnings    []string         // Warnings of succeeded operations such as exceeded WIP limit
+	chain       *automationChain // Chain of automation rules whose action calls this service, nil if not called by rules
 }
 
 // NewTaskService return new instance of TaskService.
-func NewTaskService(tx *gorm.DB) *TaskService {
+// loginUser is used for authorization, set nil when service is called internally.
+func NewTaskService(tx *gorm.DB, loginUser *model.User) *TaskService {
 	return &TaskService{
-		tx:       tx,
-		taskRepo: repository.NewTaskRepository(tx),
+		tx:          tx,
+		loginUser:   loginUser,
+		taskRepo:    repository.NewTaskRepository(tx),
+		boardRepo:   repository.NewBoardRepository(tx),
+		commentRepo: repository.NewCommentRepository(tx),
+		labelRepo:   repository.NewLabelRepository(tx),
+		historyRepo: repository.NewTaskHistoryRepository(tx),
+		projectRepo: repository.NewProjectRepository(tx),
 	}
 }
 
@@ -31,57 +49,541 @@ func (s *TaskService) FindTask(condition interface{}) (*model.Task, error) {
 		}
 		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find task")
 	}
+	projectIDs, serr := visibleProjectIDs(s.projectRepo, s.loginUser)
+	if serr != nil {
+		return nil, serr
+	}
+	if projectIDs != nil {
+		// Task is not found if its board is not in projects of login user
+		board, err := s.boardRepo.FindFirstBoard(&model.Board{ID: find.BoardID}, []string{})
+		if err != nil && err != orm.ErrorRecordNotFound {
+			return nil, NewSvcError(ErrorCodeDB, err, "Failed to find board of task")
+		}
+		if err == orm.ErrorRecordNotFound || !containsProjectID(projectIDs, board.ProjectID) {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, nil, "Task not found")
+		}
+	}
 	return &find, nil
 }
 
-// FindTasks finds all tasks
-func (s *TaskService) FindTasks(condition interface{}, sortOrders []string) ([]model.Task, error) {
-	tasks, err := s.taskRepo.FindTasks(condition, 0, orm.NoLimit, sortOrders)
+// FindTasks finds all tasks matching condition and filter, filter can be nil.
+// Only tasks of projects which login user is a member of are found.
+func (s *TaskService) FindTasks(condition interface{}, filter *repository.TaskFilter, sortOrders []string) ([]model.Task, error) {
+	filter, serr := s.projectFilter(filter)
+	if serr != nil {
+		return nil, serr
+	}
+	tasks, err := s.taskRepo.FindFilteredTasks(condition, filter, 0, orm.NoLimit, sortOrders)
 	if err != nil {
 		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find tasks")
 	}
 	return tasks, nil
 }
 
-// CreateTask creates new task
-func (s *TaskService) CreateTask(task *model.Task) error {
-	max, err := s.taskRepo.MaxTaskDispOrder(&model.Task{BoardID: task.BoardID})
+// FindTasksPage finds a page of tasks matching condition and filter, and returns it with the number of all matching tasks.
+// Only tasks of projects which login user is a member of are found.
+func (s *TaskService) FindTasksPage(condition interface{}, filter *repository.TaskFilter, after *orm.Keyset, limit int, sortOrders []string,
+) ([]model.Task, int, error) {
+	filter, serr := s.projectFilter(filter)
+	if serr != nil {
+		return nil, 0, serr
+	}
+	tasks, err := repository.NewTaskRepository(orm.WhereAfter(s.tx, after)).FindFilteredTasks(condition, filter, 0, limit, sortOrders)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to find tasks")
+	}
+	count, err := s.taskRepo.CountFilteredTasks(condition, filter)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to count tasks")
+	}
+	return tasks, count, nil
+}
+
+// ParseTaskQuery parses query of task query language, "me" in query means loginUser.
+// Invalid query is reported with details which point at the invalid token.
+func ParseTaskQuery(text string, loginUser *model.User) (*repository.TaskQuery, error) {
+	loginUserID := ""
+	if loginUser != nil {
+		loginUserID = loginUser.ID
+	}
+	query, err := repository.ParseTaskQuery(text, loginUserID)
 	if err != nil {
-		return NewSvcError(ErrorCodeDB, err, "Failed to get max disp order")
+		qerr, ok := err.(*repository.TaskQueryError)
+		if !ok {
+			return nil, NewSvcError(ErrorCodeInvalidArguments, err, err.Error())
+		}
+		caret := strings.Repeat(" ", qerr.Position-1) + "^"
+		return nil, NewSvcErrorWithDetails(ErrorCodeInvalidArguments, nil, qerr.Error(), []string{text, caret})
+	}
+	return query, nil
+}
+
+// CreateTask creates new task, task whose board is not specified is placed in the inbox board of default project
+func (s *TaskService) CreateTask(task *model.Task) error {
+	return s.CreateTaskWithLabels("", task, nil)
+}
+
+// CreateTaskWithLabels creates new task which has specified labels.
+// Task whose board is not specified is placed in the inbox board of the project, or of default project if projectID is empty.
+// Board of task must be in the project if both are specified.
+// Labels are set before automation rules run, so that conditions of rules can match them.
+func (s *TaskService) CreateTaskWithLabels(projectID string, task *model.Task, labelIDs []string) error {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := validateTaskDates(task); serr != nil {
+		return serr
+	}
+	boardSrvc := NewBoardService(s.tx, s.loginUser)
+	if task.BoardID == "" {
+		if projectID == "" {
+			projectID = model.DefaultProjectID
+		}
+		// Members of other projects must specify their board or project
+		if serr := boardSrvc.findVisibleProject(projectID); serr != nil {
+			return serr
+		}
+		inbox, serr := boardSrvc.FindInboxBoard(projectID)
+		if serr != nil {
+			return serr
+		}
+		task.BoardID = inbox.ID
+	}
+	board, serr := boardSrvc.FindBoard(&model.Board{ID: task.BoardID})
+	if serr != nil {
+		return serr
+	}
+	if projectID != "" && board.ProjectID != projectID {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Board [%s] is not in the project. ProjectID:%s", board.Name, projectID)
+	}
+	isClosed, serr := s.closingOnBoard(task.IsClosed, "", task.BoardID)
+	if serr != nil {
+		return serr
+	}
+	task.IsClosed = isClosed
+	if serr = s.checkWIPLimit(task, task.BoardID); serr != nil {
+		return serr
 	}
-	task.DispOrder = max + 1
-	err = s.taskRepo.CreateTask(task)
+	// Task is appended to the end of the board
+	task.Rank = ""
+	err := s.taskRepo.CreateTask(task)
 	if err != nil {
+		if orm.IsUniqueConstraintError(err) {
+			return newRankConflictError(err, task.BoardID)
+		}
 		return NewSvcError(ErrorCodeDB, err, "Failed to create task")
 	}
-	return nil
+	serr = recordTaskHistories(s.historyRepo, s.loginUser, &model.Task{}, task, task.Version)
+	if serr != nil {
+		return serr
+	}
+	if len(labelIDs) > 0 {
+		if serr = NewLabelService(s.tx, s.loginUser).SetTaskLabels(task.ID, labelIDs); serr != nil {
+			return serr
+		}
+	}
+	return s.runAutomation(task, model.AutomationTriggerCreated)
 }
 
 // UpdateTask updates specifed task
 func (s *TaskService) UpdateTask(task *model.Task) error {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := validateTaskDates(task); serr != nil {
+		return serr
+	}
+	before, serr := s.FindTask(&model.Task{ID: task.ID})
+	if serr != nil {
+		return serr
+	}
+	if task.BoardID != before.BoardID {
+		if serr = s.checkSameProject(before.BoardID, task.BoardID); serr != nil {
+			return serr
+		}
+		if serr = s.checkTransition(task, before.BoardID, task.BoardID); serr != nil {
+			return serr
+		}
+		if task.IsClosed, serr = s.closingOnBoard(task.IsClosed, before.BoardID, task.BoardID); serr != nil {
+			return serr
+		}
+		if serr = s.checkWIPLimit(task, task.BoardID); serr != nil {
+			return serr
+		}
+		// Task moved to another board is appended to the end of it
+		task.Rank, serr = s.newTaskRank(task.ID, task.BoardID, "", "")
+		if serr != nil {
+			return serr
+		}
+	} else if before.IsClosed {
+		// Reopened task enters its board again as open task
+		if serr = s.checkWIPLimit(task, task.BoardID); serr != nil {
+			return serr
+		}
+	}
 	err := s.taskRepo.UpdateTask(task)
 	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return NewSvcErrorf(ErrorCodeOptimisticLockFailure, err, "Task has been updated by other request. ID:%s", task.ID)
+		}
 		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update task. ID:%s", task.ID)
 	}
-	return nil
+	serr = recordTaskHistories(s.historyRepo, s.loginUser, before, task, task.Version)
+	if serr != nil {
+		return serr
+	}
+	return s.runAutomation(task, taskTriggers(before, task)...)
 }
 
-// DeleteTask deletes specifed task
+// DeleteTask deletes specifed task, its comments and links to labels
 func (s *TaskService) DeleteTask(task *model.Task) error {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return serr
+	}
 	err := s.taskRepo.DeleteTask(task)
 	if err != nil {
 		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete task. ID:%s", task.ID)
 	}
+	err = s.commentRepo.DeleteTaskComments(task.ID)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete comments of task. ID:%s", task.ID)
+	}
+	err = s.labelRepo.DeleteTaskLabels(&model.TaskLabel{TaskID: task.ID})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to remove labels from task. ID:%s", task.ID)
+	}
 	return nil
 }
 
-// UpdateTaskOrders changes display order of tasks.
-func (s *TaskService) UpdateTaskOrders(taskID, fromBoardID string, fromDispOrder int,
-	toBoardID string, toDispOrder int,
-) (err error) {
-	err = s.taskRepo.MoveTaskDispOrders(taskID, fromBoardID, fromDispOrder, toBoardID, toDispOrder)
+// MoveTask moves task to specified position of board, only the moved task is changed.
+// Task is placed right after afterTaskID if specified, or right before beforeTaskID if specified,
+// otherwise it is appended to the end of the board.
+func (s *TaskService) MoveTask(taskID, toBoardID, afterTaskID, beforeTaskID string) (*model.Task, error) {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	before, serr := s.FindTask(&model.Task{ID: taskID})
+	if serr != nil {
+		return nil, serr
+	}
+	if toBoardID == "" {
+		toBoardID = before.BoardID
+	}
+	isClosed := before.IsClosed
+	if toBoardID != before.BoardID {
+		if serr = s.checkSameProject(before.BoardID, toBoardID); serr != nil {
+			return nil, serr
+		}
+		if serr = s.checkTransition(before, before.BoardID, toBoardID); serr != nil {
+			return nil, serr
+		}
+		if isClosed, serr = s.closingOnBoard(before.IsClosed, before.BoardID, toBoardID); serr != nil {
+			return nil, serr
+		}
+		moving := *before
+		moving.IsClosed = isClosed
+		if serr = s.checkWIPLimit(&moving, toBoardID); serr != nil {
+			return nil, serr
+		}
+	}
+	key, serr := s.newTaskRank(taskID, toBoardID, afterTaskID, beforeTaskID)
+	if serr != nil {
+		return nil, serr
+	}
+	err := s.taskRepo.MoveTask(taskID, toBoardID, key)
 	if err != nil {
+		if orm.IsUniqueConstraintError(err) {
+			return nil, newRankConflictError(err, toBoardID)
+		}
+		return nil, NewSvcErrorf(ErrorCodeDB, err, "Failed to move task. ID:%s", taskID)
+	}
+	after := *before
+	after.BoardID = toBoardID
+	after.Rank = key
+	after.Version++
+	if isClosed != before.IsClosed {
+		if err = s.taskRepo.CloseTask(taskID, isClosed); err != nil {
+			return nil, NewSvcErrorf(ErrorCodeDB, err, "Failed to close task. ID:%s", taskID)
+		}
+		after.IsClosed = isClosed
+		after.Version++
+	}
+	serr = recordTaskHistories(s.historyRepo, s.loginUser, before, &after, after.Version)
+	if serr != nil {
+		return nil, serr
+	}
+	if serr = s.runAutomation(&after, taskTriggers(before, &after)...); serr != nil {
+		return nil, serr
+	}
+	return &after, nil
+}
+
+// runAutomation runs automation rules of the triggers for the task in the transaction of this service.
+// Task is reloaded if rules ran, because their actions may have changed it.
+func (s *TaskService) runAutomation(task *model.Task, triggers ...string) error {
+	chain := s.chain
+	if chain == nil {
+		chain = newAutomationChain()
+	}
+	srvc := NewAutomationService(s.tx, s.loginUser)
+	ran := 0
+	for _, trigger := range triggers {
+		count, serr := srvc.runTrigger(chain, trigger, task.ID)
+		if serr != nil {
+			return serr
+		}
+		ran += count
+	}
+	if ran == 0 {
+		return nil
+	}
+	find, err := s.taskRepo.FindFirstTask(&model.Task{ID: task.ID}, []string{})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to find task. ID:%s", task.ID)
+	}
+	*task = find
+	return nil
+}
+
+// taskTriggers returns triggers of automation rules caused by the change of task
+func taskTriggers(before, after *model.Task) []string {
+	triggers := []string{}
+	if before.BoardID != after.BoardID {
+		triggers = append(triggers, model.AutomationTriggerMoved)
+	}
+	if after.AssigneeUserID.Valid && after.AssigneeUserID.String != "" && after.AssigneeUserID != before.AssigneeUserID {
+		triggers = append(triggers, model.AutomationTriggerAssigned)
+	}
+	if after.IsClosed && !before.IsClosed {
+		triggers = append(triggers, model.AutomationTriggerClosed)
+	}
+	return triggers
+}
+
+// projectFilter returns copy of filter which is restricted to projects of login user
+func (s *TaskService) projectFilter(filter *repository.TaskFilter) (*repository.TaskFilter, error) {
+	projectIDs, serr := visibleProjectIDs(s.projectRepo, s.loginUser)
+	if serr != nil {
+		return nil, serr
+	}
+	if projectIDs == nil {
+		return filter, nil
+	}
+	restricted := repository.TaskFilter{}
+	if filter != nil {
+		restricted = *filter
+	}
+	if restricted.ProjectIDs == nil {
+		restricted.ProjectIDs = projectIDs
+	} else {
+		visibles := []string{}
+		for _, projectID := range restricted.ProjectIDs {
+			if containsProjectID(projectIDs, projectID) {
+				visibles = append(visibles, projectID)
+			}
+		}
+		restricted.ProjectIDs = visibles
+	}
+	return &restricted, nil
+}
+
+// checkSameProject checks whether task can be moved to the board, which must be in the same project as current board
+func (s *TaskService) checkSameProject(fromBoardID, toBoardID string) error {
+	boardSrvc := NewBoardService(s.tx, s.loginUser)
+	to, serr := boardSrvc.FindBoard(&model.Board{ID: toBoardID})
+	if serr != nil {
+		return serr
+	}
+	from, err := s.boardRepo.FindFirstBoard(&model.Board{ID: fromBoardID}, []string{})
+	if err == orm.ErrorRecordNotFound {
+		return nil // Task on deleted board can be moved anywhere
+	}
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to find board")
+	}
+	if from.ProjectID != to.ProjectID {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil,
+			"Task can not be moved to board of another project. BoardID:%s", toBoardID)
+	}
+	return nil
+}
+
+// checkTransition checks whether transition rules allow the task to be moved to the board
+func (s *TaskService) checkTransition(task *model.Task, fromBoardID, toBoardID string) error {
+	return NewTransitionRuleService(s.tx, s.loginUser).CheckTransition(task, fromBoardID, toBoardID)
+}
+
+// closingOnBoard returns whether task is closed after entering the board.
+// Tasks entering closed board such as board of done state are closed,
+// and tasks leaving closed board for open one are reopened.
+func (s *TaskService) closingOnBoard(isClosed bool, fromBoardID, toBoardID string) (bool, error) {
+	to, err := s.boardRepo.FindFirstBoard(&model.Board{ID: toBoardID}, []string{})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return isClosed, nil // Board is not checked here
+		}
+		return false, NewSvcError(ErrorCodeDB, err, "Failed to find board")
+	}
+	if to.IsClosed {
+		return true, nil
+	}
+	if fromBoardID == "" {
+		return isClosed, nil
+	}
+	from, err := s.boardRepo.FindFirstBoard(&model.Board{ID: fromBoardID}, []string{})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return isClosed, nil
+		}
+		return false, NewSvcError(ErrorCodeDB, err, "Failed to find board")
+	}
+	if from.IsClosed {
+		return false, nil
+	}
+	return isClosed, nil
+}
+
+// Warnings returns warnings of operations which succeeded, such as tasks entered boards over WIP limit
+func (s *TaskService) Warnings() []string {
+	return s.warnings
+}
+
+// checkWIPLimit checks whether open task can enter the board without exceeding its WIP limit.
+// Exceeding limit is an error in block mode, and is added to warnings in warn mode.
+func (s *TaskService) checkWIPLimit(task *model.Task, boardID string) error {
+	if task.IsClosed {
+		return nil
+	}
+	board, err := s.boardRepo.FindFirstBoard(&model.Board{ID: boardID}, []string{})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil // Board is not checked here
+		}
+		return NewSvcError(ErrorCodeDB, err, "Failed to find board")
+	}
+	if board.WIPLimit <= 0 {
+		return nil
+	}
+	count, err := s.taskRepo.CountTasks(map[string]interface{}{"board_id": boardID, "is_closed": false})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to count tasks. BoardID:%s", boardID)
+	}
+	if count < board.WIPLimit {
+		return nil
+	}
+	if board.WIPMode == model.WIPModeBlock {
+		return NewSvcErrorWithDetailsf(ErrorCodePreconditionInvalid, nil,
+			"Board [%s] has reached WIP limit %d", []string{
+				fmt.Sprintf("boardID:%s", board.ID),
+				fmt.Sprintf("wipLimit:%d", board.WIPLimit),
+				fmt.Sprintf("openTaskCount:%d", count),
+			}, board.Name, board.WIPLimit)
+	}
+	s.warnings = append(s.warnings, fmt.Sprintf("Board [%s] exceeds WIP limit %d with %d open tasks",
+		board.Name, board.WIPLimit, count+1))
+	return nil
+}
+
+// newTaskRank returns rank of task placed next to specified task in the board
+func (s *TaskService) newTaskRank(taskID, boardID, afterTaskID, beforeTaskID string) (string, error) {
+	findNeighbors := func() (prev, next string, err error) {
+		switch {
+		case afterTaskID != "":
+			neighbor, serr := s.findNeighborTask(afterTaskID, taskID, boardID)
+			if serr != nil {
+				return "", "", serr
+			}
+			prev = neighbor.Rank
+			next, err = s.taskRepo.NextTaskRank(boardID, prev, taskID)
+		case beforeTaskID != "":
+			neighbor, serr := s.findNeighborTask(beforeTaskID, taskID, boardID)
+			if serr != nil {
+				return "", "", serr
+			}
+			next = neighbor.Rank
+			prev, err = s.taskRepo.PreviousTaskRank(boardID, next, taskID)
+		default:
+			prev, err = s.taskRepo.PreviousTaskRank(boardID, "", taskID)
+		}
+		if err != nil {
+			return "", "", NewSvcErrorf(ErrorCodeDB, err, "Failed to find ranks of tasks. BoardID:%s", boardID)
+		}
 		return
 	}
-	return
+	return newRank(findNeighbors, func() error {
+		return s.taskRepo.RebalanceTaskRanks(boardID)
+	})
+}
+
+// findNeighborTask returns task next to which the moved task is placed
+func (s *TaskService) findNeighborTask(neighborTaskID, taskID, boardID string) (*model.Task, error) {
+	if neighborTaskID == taskID {
+		return nil, NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Task can not be placed next to itself. ID:%s", taskID)
+	}
+	neighbor, serr := s.FindTask(&model.Task{ID: neighborTaskID})
+	if serr != nil {
+		return nil, serr
+	}
+	if neighbor.BoardID != boardID {
+		return nil, NewSvcErrorf(ErrorCodeInvalidArguments, nil,
+			"Task [%s] is not in board [%s]", neighborTaskID, boardID)
+	}
+	return neighbor, nil
+}
+
+// FindTaskHistoriesPage returns a page of histories of specified task and total count of them
+func (s *TaskService) FindTaskHistoriesPage(taskID string, after *orm.Keyset, limit int, sortOrders []string) ([]model.TaskHistory, int, error) {
+	condition := &model.TaskHistory{TaskID: taskID}
+	histories, err := repository.NewTaskHistoryRepository(orm.WhereAfter(s.tx, after)).
+		FindTaskHistories(condition, 0, limit, sortOrders)
+	if err != nil {
+		return nil, 0, NewSvcErrorf(ErrorCodeDB, err, "Failed to find histories of task. ID:%s", taskID)
+	}
+	count, err := s.historyRepo.CountTaskHistories(condition)
+	if err != nil {
+		return nil, 0, NewSvcErrorf(ErrorCodeDB, err, "Failed to count histories of task. ID:%s", taskID)
+	}
+	return histories, count, nil
+}
+
+// RevertTask restores fields of task to specified revision by undoing later changes.
+// version is the version of task which client knows, it is checked as optimistic lock.
+// Reverting is recorded as a new revision, histories are never removed.
+func (s *TaskService) RevertTask(taskID string, revision, version int) (*model.Task, error) {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	task, serr := s.FindTask(&model.Task{ID: taskID})
+	if serr != nil {
+		return nil, serr
+	}
+	if revision < 1 || revision >= task.Version {
+		return nil, NewSvcErrorf(ErrorCodeInvalidArguments, nil,
+			"Revision must be between 1 and %d. Revision:%d", task.Version-1, revision)
+	}
+	histories, err := s.historyRepo.FindTaskHistoriesAfter(taskID, revision)
+	if err != nil {
+		return nil, NewSvcErrorf(ErrorCodeDB, err, "Failed to find histories of task. ID:%s", taskID)
+	}
+	for _, history := range histories {
+		err = task.SetFieldValue(history.Field, history.OldValue)
+		if err != nil {
+			return nil, NewSvcErrorf(ErrorCodeUnexpected, err, "Invalid history of task. ID:%s", history.ID)
+		}
+	}
+	task.Version = version
+	serr = s.UpdateTask(task)
+	if serr != nil {
+		return nil, serr
+	}
+	return task, nil
+}
+
+func validateTaskDates(task *model.Task) error {
+	if task.StartDate != nil && task.DueDate != nil && task.DueDate.Before(*task.StartDate) {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil,
+			"Due date must be same or after start date. StartDate:%s DueDate:%s",
+			task.StartDate.Format(time.RFC3339), task.DueDate.Format(time.RFC3339))
+	}
+	return nil
 }
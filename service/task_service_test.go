@@ -0,0 +1,168 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTaskQuery(t *testing.T) {
+	loginUser := model.NewUser("query-user", "password", "")
+	_, serr := ParseTaskQuery("assignee:me closed:false", loginUser)
+	assert.Nil(t, serr)
+
+	// Details point at the invalid token by caret under the query
+	tests := []struct {
+		query     string
+		loginUser *model.User
+		caret     string
+	}{
+		{"closed:false estimate>=x", loginUser, "             ^"},
+		{"bug owner:me", loginUser, "    ^"},
+		{"assignee:me", nil, "^"},
+		{`bug "login`, loginUser, "    ^"},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			_, err := ParseTaskQuery(test.query, test.loginUser)
+			if serr, ok := err.(*SvcError); assert.True(t, ok, "SvcError must be returned: %v", err) {
+				assert.Equal(t, ErrorCodeInvalidArguments, serr.Code)
+				assert.Equal(t, []string{test.query, test.caret}, serr.Details)
+			}
+		})
+	}
+}
+
+// wipTestEnters are the ways for a task to enter a board, WIP limit must be checked in all of them
+var wipTestEnters = []struct {
+	name  string
+	enter func(srvc *TaskService, task *model.Task, boardID string) error
+}{
+	{"CreateTask", func(srvc *TaskService, task *model.Task, boardID string) error {
+		entering := model.NewTask(task.Name+"-created", "", false, time.Now().UTC())
+		entering.BoardID = boardID
+		return srvc.CreateTask(entering)
+	}},
+	{"UpdateTask", func(srvc *TaskService, task *model.Task, boardID string) error {
+		task.BoardID = boardID
+		return srvc.UpdateTask(task)
+	}},
+	{"MoveTask", func(srvc *TaskService, task *model.Task, boardID string) error {
+		_, serr := srvc.MoveTask(task.ID, boardID, "", "")
+		return serr
+	}},
+}
+
+// createWIPTestBoards creates a board limited to 1 open task which is already there, and another board of a task entering it
+func createWIPTestBoards(t *testing.T, tx *gorm.DB, mode string) (limited *model.Board, occupying, entering *model.Task) {
+	limited = model.NewBoard("wip-limited", false, false, time.Now().UTC())
+	limited.WIPLimit = 1
+	limited.WIPMode = mode
+	other := model.NewBoard("wip-other", false, false, time.Now().UTC())
+	for _, board := range []*model.Board{limited, other} {
+		if serr := NewBoardService(tx, nil).CreateBoard(board); serr != nil {
+			t.Fatalf("Failed to create board: %+v", serr)
+		}
+	}
+	occupying = model.NewTask("wip-occupying", "", false, time.Now().UTC())
+	occupying.BoardID = limited.ID
+	entering = model.NewTask("wip-entering", "", false, time.Now().UTC())
+	entering.BoardID = other.ID
+	for _, task := range []*model.Task{occupying, entering} {
+		if serr := NewTaskService(tx, nil).CreateTask(task); serr != nil {
+			t.Fatalf("Failed to create task: %+v", serr)
+		}
+	}
+	return
+}
+
+func TestTaskService_WIPLimitWarn(t *testing.T) {
+	for _, e := range wipTestEnters {
+		t.Run(e.name, func(t *testing.T) {
+			tx := orm.GetDB().Begin()
+			defer tx.Rollback()
+
+			limited, _, entering := createWIPTestBoards(t, tx, model.WIPModeWarn)
+			srvc := NewTaskService(tx, nil)
+			assert.Nil(t, e.enter(srvc, entering, limited.ID))
+			assert.Equal(t, []string{"Board [wip-limited] exceeds WIP limit 1 with 2 open tasks"}, srvc.Warnings())
+		})
+	}
+}
+
+func TestTaskService_WIPLimitBlock(t *testing.T) {
+	for _, e := range wipTestEnters {
+		t.Run(e.name, func(t *testing.T) {
+			tx := orm.GetDB().Begin()
+			defer tx.Rollback()
+
+			limited, occupying, entering := createWIPTestBoards(t, tx, model.WIPModeBlock)
+			srvc := NewTaskService(tx, nil)
+			err := e.enter(srvc, entering, limited.ID)
+			if serr, ok := err.(*SvcError); assert.True(t, ok, "SvcError must be returned: %v", err) {
+				assert.Equal(t, ErrorCodePreconditionInvalid, serr.Code)
+				assert.Equal(t, []string{"boardID:" + limited.ID, "wipLimit:1", "openTaskCount:1"}, serr.Details)
+			}
+			assert.Equal(t, 0, len(srvc.Warnings()))
+
+			// Task already on the board is not counted as entering it
+			srvc = NewTaskService(tx, nil)
+			find, serr := srvc.FindTask(&model.Task{ID: occupying.ID})
+			if serr != nil {
+				t.Fatalf("Failed to find task: %+v", serr)
+			}
+			if e.name != "CreateTask" {
+				assert.Nil(t, e.enter(srvc, find, limited.ID))
+				if find, serr = srvc.FindTask(&model.Task{ID: occupying.ID}); serr != nil {
+					t.Fatalf("Failed to find task: %+v", serr)
+				}
+			}
+			find.Name = "wip-occupying-renamed"
+			assert.Nil(t, srvc.UpdateTask(find))
+			assert.Equal(t, 0, len(srvc.Warnings()))
+		})
+	}
+}
+
+func TestTaskService_WIPLimitClosedTask(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	// Closed tasks neither count nor are blocked
+	limited, _, entering := createWIPTestBoards(t, tx, model.WIPModeBlock)
+	srvc := NewTaskService(tx, nil)
+	entering.IsClosed = true
+	if serr := srvc.UpdateTask(entering); serr != nil {
+		t.Fatalf("Failed to close task: %+v", serr)
+	}
+	_, serr := srvc.MoveTask(entering.ID, limited.ID, "", "")
+	assert.Nil(t, serr)
+	assert.Equal(t, 0, len(srvc.Warnings()))
+}
+
+func TestTaskService_WIPLimitReopenedTask(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	// Closed task on the board is blocked when it is reopened there
+	limited, _, entering := createWIPTestBoards(t, tx, model.WIPModeBlock)
+	srvc := NewTaskService(tx, nil)
+	entering.IsClosed = true
+	if serr := srvc.UpdateTask(entering); serr != nil {
+		t.Fatalf("Failed to close task: %+v", serr)
+	}
+	closed, serr := srvc.MoveTask(entering.ID, limited.ID, "", "")
+	if serr != nil {
+		t.Fatalf("Failed to move task: %+v", serr)
+	}
+	closed.IsClosed = false
+	err := srvc.UpdateTask(closed)
+	if serr, ok := err.(*SvcError); assert.True(t, ok, "SvcError must be returned: %v", err) {
+		assert.Equal(t, ErrorCodePreconditionInvalid, serr.Code)
+		assert.Equal(t, []string{"boardID:" + limited.ID, "wipLimit:1", "openTaskCount:1"}, serr.Details)
+	}
+}
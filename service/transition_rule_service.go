@@ -0,0 +1,225 @@
+package service
+
+import (
+	"fmt"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TransitionRuleService provides apis for rules of moving tasks between boards.
+type TransitionRuleService struct {
+	tx        *gorm.DB
+	loginUser *model.User
+	ruleRepo  *repository.TransitionRuleRepository
+}
+
+// NewTransitionRuleService return new instance of TransitionRuleService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewTransitionRuleService(tx *gorm.DB, loginUser *model.User) *TransitionRuleService {
+	return &TransitionRuleService{
+		tx:        tx,
+		loginUser: loginUser,
+		ruleRepo:  repository.NewTransitionRuleRepository(tx),
+	}
+}
+
+// FindTransitionRule returns transition rule matching specified condition,
+// rules of boards which login user can not see are not found
+func (s *TransitionRuleService) FindTransitionRule(condition interface{}) (*model.TransitionRule, error) {
+	find, err := s.ruleRepo.FindFirstTransitionRule(condition, []string{"id"})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, err, "Transition rule not found")
+		}
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find transition rule")
+	}
+	if _, serr := NewBoardService(s.tx, s.loginUser).FindBoard(&model.Board{ID: find.FromBoardID}); serr != nil {
+		if svcErr, ok := serr.(*SvcError); ok && svcErr.Code == ErrorCodeNotFound {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, nil, "Transition rule not found")
+		}
+		return nil, serr
+	}
+	return &find, nil
+}
+
+// FindTransitionRules returns transition rules from boards which login user can see.
+// Only rules from specified board are returned if fromBoardID is not empty.
+func (s *TransitionRuleService) FindTransitionRules(fromBoardID string) ([]model.TransitionRule, error) {
+	boardSrvc := NewBoardService(s.tx, s.loginUser)
+	if fromBoardID != "" {
+		if _, serr := boardSrvc.FindBoard(&model.Board{ID: fromBoardID}); serr != nil {
+			return nil, serr
+		}
+		rules, err := s.ruleRepo.FindTransitionRules(&model.TransitionRule{FromBoardID: fromBoardID}, 0, orm.NoLimit,
+			[]string{"to_board_id"})
+		if err != nil {
+			return nil, NewSvcError(ErrorCodeDB, err, "Failed to find transition rules")
+		}
+		return rules, nil
+	}
+	boards, serr := boardSrvc.FindBoards(&model.Board{}, []string{})
+	if serr != nil {
+		return nil, serr
+	}
+	visibles := map[string]bool{}
+	for _, board := range boards {
+		visibles[board.ID] = true
+	}
+	rules, err := s.ruleRepo.FindTransitionRules(&model.TransitionRule{}, 0, orm.NoLimit, []string{"from_board_id", "to_board_id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find transition rules")
+	}
+	result := make([]model.TransitionRule, 0, len(rules))
+	for _, rule := range rules {
+		if visibles[rule.FromBoardID] {
+			result = append(result, rule)
+		}
+	}
+	return result, nil
+}
+
+// CreateTransitionRule creates new transition rule
+func (s *TransitionRuleService) CreateTransitionRule(rule *model.TransitionRule) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := s.validateTransitionRule(rule); serr != nil {
+		return serr
+	}
+	err := s.ruleRepo.CreateTransitionRule(rule)
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to create transition rule")
+	}
+	return nil
+}
+
+// UpdateTransitionRule updates specifed transition rule
+func (s *TransitionRuleService) UpdateTransitionRule(rule *model.TransitionRule) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := s.validateTransitionRule(rule); serr != nil {
+		return serr
+	}
+	err := s.ruleRepo.UpdateTransitionRule(rule)
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return NewSvcErrorf(ErrorCodeOptimisticLockFailure, err, "Transition rule has been updated by other request. ID:%s", rule.ID)
+		}
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update transition rule. ID:%s", rule.ID)
+	}
+	return nil
+}
+
+// DeleteTransitionRule deletes specifed transition rule
+func (s *TransitionRuleService) DeleteTransitionRule(rule *model.TransitionRule) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	err := s.ruleRepo.DeleteTransitionRule(rule)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete transition rule. ID:%s", rule.ID)
+	}
+	return nil
+}
+
+// CheckTransition checks whether the task can be moved from a board to another board.
+// Tasks on a board which has no rules can be moved to any board. Otherwise the rule to the board must exist,
+// login user must have its required role, and the task must have its required fields.
+// Returned error lists allowed boards, required role or missing fields in its details.
+func (s *TransitionRuleService) CheckTransition(task *model.Task, fromBoardID, toBoardID string) error {
+	rules, err := s.ruleRepo.FindTransitionRules(&model.TransitionRule{FromBoardID: fromBoardID}, 0, orm.NoLimit,
+		[]string{"to_board_id"})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to find transition rules. BoardID:%s", fromBoardID)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	var rule *model.TransitionRule
+	allowed := make([]string, 0, len(rules))
+	for i := range rules {
+		if rules[i].ToBoardID == toBoardID {
+			rule = &rules[i]
+		}
+		allowed = append(allowed, fmt.Sprintf("allowedBoardID:%s", rules[i].ToBoardID))
+	}
+	if rule == nil {
+		return NewSvcErrorWithDetailsf(ErrorCodePreconditionInvalid, nil,
+			"Task can not be moved from board [%s] to board [%s]", allowed, fromBoardID, toBoardID)
+	}
+	details := []string{}
+	if rule.RequiredRole != "" && !s.hasRequiredRole(rule.RequiredRole) {
+		details = append(details, fmt.Sprintf("requiredRole:%s", rule.RequiredRole))
+	}
+	for _, field := range rule.MissingFields(task) {
+		details = append(details, fmt.Sprintf("missingField:%s", field))
+	}
+	if len(details) > 0 {
+		return NewSvcErrorWithDetailsf(ErrorCodePreconditionInvalid, nil,
+			"Task does not satisfy transition rule from board [%s] to board [%s]", details, fromBoardID, toBoardID)
+	}
+	return nil
+}
+
+// hasRequiredRole checks whether login user has the role or a stronger role, admin has every role.
+// Internal call has every role.
+func (s *TransitionRuleService) hasRequiredRole(role string) bool {
+	if s.loginUser == nil || s.loginUser.HasRole(model.RoleAdmin) {
+		return true
+	}
+	return s.loginUser.HasRole(role)
+}
+
+// validateTransitionRule checks boards of rule are different boards of the same project,
+// and required role and fields are valid
+func (s *TransitionRuleService) validateTransitionRule(rule *model.TransitionRule) error {
+	if rule.FromBoardID == rule.ToBoardID {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Transition rule must be between different boards. BoardID:%s",
+			rule.FromBoardID)
+	}
+	boardSrvc := NewBoardService(s.tx, s.loginUser)
+	from, serr := boardSrvc.FindBoard(&model.Board{ID: rule.FromBoardID})
+	if serr != nil {
+		return serr
+	}
+	to, serr := boardSrvc.FindBoard(&model.Board{ID: rule.ToBoardID})
+	if serr != nil {
+		return serr
+	}
+	if from.ProjectID != to.ProjectID {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil,
+			"Transition rule must be between boards of the same project. BoardID:%s,%s", from.ID, to.ID)
+	}
+	if rule.RequiredRole != "" && rule.RequiredRole != model.RoleAdmin && rule.RequiredRole != model.RoleMember {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Required role [%s] must be empty or one of [%s,%s]",
+			rule.RequiredRole, model.RoleAdmin, model.RoleMember)
+	}
+	fields := map[string]bool{}
+	for _, field := range rule.GetRequiredFields() {
+		if !model.IsValidRequiredField(field) {
+			return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Required field [%s] must be one of [%s,%s,%s,%s,%s]", field,
+				model.RequiredFieldAssignee, model.RequiredFieldEstimate, model.RequiredFieldStartDate,
+				model.RequiredFieldDueDate, model.RequiredFieldDescription)
+		}
+		if fields[field] {
+			return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Required field [%s] is duplicated", field)
+		}
+		fields[field] = true
+	}
+	rules, err := s.ruleRepo.FindTransitionRules(&model.TransitionRule{FromBoardID: rule.FromBoardID, ToBoardID: rule.ToBoardID},
+		0, orm.NoLimit, []string{})
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to find transition rules")
+	}
+	for _, other := range rules {
+		if other.ID != rule.ID {
+			return NewSvcErrorf(ErrorCodeAlreadyExist, nil, "Transition rule from board [%s] to board [%s] already exists",
+				from.Name, to.Name)
+		}
+	}
+	return nil
+}
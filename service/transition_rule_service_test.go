@@ -0,0 +1,175 @@
+package service
+
+import (
+	"database/sql"
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+// transitionTestMoves are the ways to move a task to another board, transition rules must be checked in both
+var transitionTestMoves = []struct {
+	name string
+	move func(srvc *TaskService, task *model.Task, toBoardID string) error
+}{
+	{"MoveTask", func(srvc *TaskService, task *model.Task, toBoardID string) error {
+		_, serr := srvc.MoveTask(task.ID, toBoardID, "", "")
+		return serr
+	}},
+	{"UpdateTask", func(srvc *TaskService, task *model.Task, toBoardID string) error {
+		task.BoardID = toBoardID
+		return srvc.UpdateTask(task)
+	}},
+}
+
+func createTransitionTestBoards(t *testing.T, tx *gorm.DB, names ...string) []*model.Board {
+	boards := make([]*model.Board, 0, len(names))
+	for _, name := range names {
+		board := model.NewBoard(name, false, false, time.Now().UTC())
+		if serr := NewBoardService(tx, nil).CreateBoard(board); serr != nil {
+			t.Fatalf("Failed to create board: %+v", serr)
+		}
+		boards = append(boards, board)
+	}
+	return boards
+}
+
+func createTransitionTestRule(t *testing.T, tx *gorm.DB, from, to *model.Board, requiredRole string, requiredFields ...string) {
+	rule := model.NewTransitionRule(from.ID, to.ID, requiredRole, requiredFields, time.Now().UTC())
+	if serr := NewTransitionRuleService(tx, nil).CreateTransitionRule(rule); serr != nil {
+		t.Fatalf("Failed to create transition rule: %+v", serr)
+	}
+}
+
+func createTransitionTestTask(t *testing.T, tx *gorm.DB, name string, board *model.Board) *model.Task {
+	task := model.NewTask(name, "", false, time.Now().UTC())
+	task.BoardID = board.ID
+	if serr := NewTaskService(tx, nil).CreateTask(task); serr != nil {
+		t.Fatalf("Failed to create task: %+v", serr)
+	}
+	return task
+}
+
+func assertTransitionError(t *testing.T, err error, details []string) {
+	if serr, ok := err.(*SvcError); assert.True(t, ok, "SvcError must be returned: %v", err) {
+		assert.Equal(t, ErrorCodePreconditionInvalid, serr.Code)
+		assert.Equal(t, details, serr.Details)
+	}
+}
+
+func TestTransitionRuleService_BoardWithoutRules(t *testing.T) {
+	for _, m := range transitionTestMoves {
+		t.Run(m.name, func(t *testing.T) {
+			tx := orm.GetDB().Begin()
+			defer tx.Rollback()
+
+			// Rules of other boards do not restrict boards without rules
+			boards := createTransitionTestBoards(t, tx, "transition-free", "transition-other", "transition-any")
+			createTransitionTestRule(t, tx, boards[1], boards[0], "")
+			task := createTransitionTestTask(t, tx, "free", boards[0])
+
+			assert.Nil(t, m.move(NewTaskService(tx, nil), task, boards[2].ID))
+		})
+	}
+}
+
+func TestTransitionRuleService_DisallowedBoard(t *testing.T) {
+	for _, m := range transitionTestMoves {
+		t.Run(m.name, func(t *testing.T) {
+			tx := orm.GetDB().Begin()
+			defer tx.Rollback()
+
+			boards := createTransitionTestBoards(t, tx, "transition-from", "transition-allowed1", "transition-allowed2",
+				"transition-disallowed")
+			createTransitionTestRule(t, tx, boards[0], boards[1], "")
+			createTransitionTestRule(t, tx, boards[0], boards[2], "")
+			task := createTransitionTestTask(t, tx, "disallowed", boards[0])
+			srvc := NewTaskService(tx, nil)
+
+			// Details list allowed boards in order of ID
+			allowed := []string{"allowedBoardID:" + boards[1].ID, "allowedBoardID:" + boards[2].ID}
+			if boards[2].ID < boards[1].ID {
+				allowed[0], allowed[1] = allowed[1], allowed[0]
+			}
+			assertTransitionError(t, m.move(srvc, task, boards[3].ID), allowed)
+
+			find, serr := srvc.FindTask(&model.Task{ID: task.ID})
+			if serr != nil {
+				t.Fatalf("Failed to find task: %+v", serr)
+			}
+			assert.Equal(t, boards[0].ID, find.BoardID)
+			assert.Nil(t, m.move(srvc, find, boards[1].ID))
+		})
+	}
+}
+
+func TestTransitionRuleService_RequiredRole(t *testing.T) {
+	for _, m := range transitionTestMoves {
+		t.Run(m.name, func(t *testing.T) {
+			tx := orm.GetDB().Begin()
+			defer tx.Rollback()
+
+			member := model.NewUser("transition-member", "password", "")
+			admin := model.NewUser("transition-admin", "password", "")
+			admin.Role = model.RoleAdmin
+			for _, record := range []interface{}{member, admin,
+				&model.ProjectMember{ProjectID: model.DefaultProjectID, UserID: member.ID}} {
+				if err := tx.Create(record).Error; err != nil {
+					t.Fatalf("Failed to create record: %+v", err)
+				}
+			}
+			boards := createTransitionTestBoards(t, tx, "transition-role-from", "transition-role-to")
+			createTransitionTestRule(t, tx, boards[0], boards[1], model.RoleAdmin)
+			task := createTransitionTestTask(t, tx, "role", boards[0])
+
+			err := m.move(NewTaskService(tx, member), task, boards[1].ID)
+			assertTransitionError(t, err, []string{"requiredRole:admin"})
+
+			srvc := NewTaskService(tx, admin)
+			find, serr := srvc.FindTask(&model.Task{ID: task.ID})
+			if serr != nil {
+				t.Fatalf("Failed to find task: %+v", serr)
+			}
+			assert.Nil(t, m.move(srvc, find, boards[1].ID))
+		})
+	}
+}
+
+func TestTransitionRuleService_RequiredFields(t *testing.T) {
+	for _, m := range transitionTestMoves {
+		t.Run(m.name, func(t *testing.T) {
+			tx := orm.GetDB().Begin()
+			defer tx.Rollback()
+
+			user := model.NewUser("transition-assignee", "password", "")
+			if err := tx.Create(user).Error; err != nil {
+				t.Fatalf("Failed to create user: %+v", err)
+			}
+			boards := createTransitionTestBoards(t, tx, "transition-fields-from", "transition-fields-to")
+			createTransitionTestRule(t, tx, boards[0], boards[1], "",
+				model.RequiredFieldAssignee, model.RequiredFieldEstimate, model.RequiredFieldDescription)
+			task := createTransitionTestTask(t, tx, "fields", boards[0])
+			srvc := NewTaskService(tx, nil)
+
+			err := m.move(srvc, task, boards[1].ID)
+			assertTransitionError(t, err, []string{"missingField:assignee", "missingField:estimate", "missingField:description"})
+
+			// Task which has all required fields can be moved
+			find, serr := srvc.FindTask(&model.Task{ID: task.ID})
+			if serr != nil {
+				t.Fatalf("Failed to find task: %+v", serr)
+			}
+			find.AssigneeUserID = sql.NullString{String: user.ID, Valid: true}
+			find.EstimateSize = 3
+			find.Description = "described"
+			if serr = srvc.UpdateTask(find); serr != nil {
+				t.Fatalf("Failed to update task: %+v", serr)
+			}
+			assert.Nil(t, m.move(srvc, find, boards[1].ID))
+		})
+	}
+}
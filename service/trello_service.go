@@ -0,0 +1,304 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TrelloBoard is a board exported from Trello as JSON, only imported fields are defined
+type TrelloBoard struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Lists      []TrelloList      `json:"lists"`
+	Cards      []TrelloCard      `json:"cards"`
+	Members    []TrelloMember    `json:"members"`
+	Checklists []TrelloChecklist `json:"checklists"`
+}
+
+// TrelloList is a list of Trello, it is imported as a board
+type TrelloList struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+// TrelloCard is a card of Trello, it is imported as a task
+type TrelloCard struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Desc         string        `json:"desc"`
+	Closed       bool          `json:"closed"`
+	IDList       string        `json:"idList"`
+	Pos          float64       `json:"pos"`
+	Due          *time.Time    `json:"due"`
+	IDMembers    []string      `json:"idMembers"`
+	Labels       []TrelloLabel `json:"labels"`
+	IDChecklists []string      `json:"idChecklists"`
+}
+
+// TrelloMember is a member of Trello board, it is mapped to the user whose name is username or full name
+type TrelloMember struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"fullName"`
+}
+
+// TrelloLabel is a label of Trello card, it is mapped to the label which has the same name
+type TrelloLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// TrelloChecklist is a checklist of Trello card, it is not imported
+type TrelloChecklist struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	IDCard string `json:"idCard"`
+}
+
+// Types of Trello items which are not imported as they are
+const (
+	TrelloItemList      = "list"
+	TrelloItemCard      = "card"
+	TrelloItemMember    = "member"
+	TrelloItemLabel     = "label"
+	TrelloItemChecklist = "checklist"
+)
+
+// TrelloUnmappedItem presents an item of Trello which is not imported or partially imported
+type TrelloUnmappedItem struct {
+	Type   string
+	ID     string // ID in Trello
+	Name   string
+	Reason string
+}
+
+// TrelloImportResult presents created boards and tasks, and items which are not mapped
+type TrelloImportResult struct {
+	Boards   []*model.Board
+	Tasks    []*model.Task
+	Unmapped []TrelloUnmappedItem
+}
+
+// TrelloService provides apis for importing boards exported from Trello.
+type TrelloService struct {
+	tx        *gorm.DB
+	loginUser *model.User
+	userRepo  *repository.UserRepository
+	labelRepo *repository.LabelRepository
+	boardSrvc *BoardService
+	taskSrvc  *TaskService
+}
+
+// trelloMapping has IDs mapped from Trello items and items which are not mapped
+type trelloMapping struct {
+	boardIDs map[string]string // List ID to board ID
+	userIDs  map[string]string // Member ID to user ID
+	labelIDs map[string]string // Label name to label ID
+	unmapped []TrelloUnmappedItem
+}
+
+// NewTrelloService return new instance of TrelloService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewTrelloService(tx *gorm.DB, loginUser *model.User) *TrelloService {
+	return &TrelloService{
+		tx:        tx,
+		loginUser: loginUser,
+		userRepo:  repository.NewUserRepository(tx),
+		labelRepo: repository.NewLabelRepository(tx),
+		boardSrvc: NewBoardService(tx, loginUser),
+		taskSrvc:  NewTaskService(tx, loginUser),
+	}
+}
+
+// Import creates a board in the project for each list and a task for each card keeping their positions.
+// Members and labels are mapped to existing users and labels by name, items which can not be mapped are reported.
+// Only the first mapped member of a card is assigned, because task has one assignee.
+func (s *TrelloService) Import(projectID string, trello *TrelloBoard) (*TrelloImportResult, error) {
+	if serr := authorizeEditor(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	if serr := s.boardSrvc.findVisibleProject(projectID); serr != nil {
+		return nil, serr
+	}
+	if len(trello.Lists) == 0 {
+		return nil, NewSvcError(ErrorCodeInvalidArguments, nil, "Trello board has no lists, it may not be a Trello export")
+	}
+	mapping := &trelloMapping{boardIDs: map[string]string{}, unmapped: []TrelloUnmappedItem{}}
+	var serr error
+	if mapping.userIDs, serr = s.mapMembers(trello.Members, mapping); serr != nil {
+		return nil, serr
+	}
+	if mapping.labelIDs, serr = s.findLabelIDs(); serr != nil {
+		return nil, serr
+	}
+
+	boardNames, serr := s.findBoardNames(projectID)
+	if serr != nil {
+		return nil, serr
+	}
+
+	result := &TrelloImportResult{}
+	lists := append([]TrelloList{}, trello.Lists...)
+	sort.SliceStable(lists, func(i, j int) bool { return lists[i].Pos < lists[j].Pos })
+	for _, list := range lists {
+		name := uniqueTrelloBoardName(list.Name, trello.Name, boardNames)
+		if name != list.Name {
+			mapping.addUnmapped(TrelloItemList, list.ID, list.Name, "Board is named [%s] because name of board must be unique", name)
+		}
+		boardNames[name] = true
+		board := model.NewBoard(name, false, list.Closed, trelloCreatedDate(list.ID))
+		board.ProjectID = projectID
+		if serr = s.boardSrvc.CreateBoard(board); serr != nil {
+			return nil, serr
+		}
+		mapping.boardIDs[list.ID] = board.ID
+		result.Boards = append(result.Boards, board)
+	}
+
+	cards := append([]TrelloCard{}, trello.Cards...)
+	sort.SliceStable(cards, func(i, j int) bool { return cards[i].Pos < cards[j].Pos })
+	for _, card := range cards {
+		boardID, ok := mapping.boardIDs[card.IDList]
+		if !ok {
+			mapping.addUnmapped(TrelloItemCard, card.ID, card.Name, "List [%s] of card is not found", card.IDList)
+			continue
+		}
+		task, serr := s.importCard(&card, boardID, mapping)
+		if serr != nil {
+			return nil, serr
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+	for _, checklist := range trello.Checklists {
+		mapping.addUnmapped(TrelloItemChecklist, checklist.ID, checklist.Name, "Checklists are not supported")
+	}
+	result.Unmapped = mapping.unmapped
+	return result, nil
+}
+
+// importCard creates a task of the card at the end of the board
+func (s *TrelloService) importCard(card *TrelloCard, boardID string, mapping *trelloMapping) (*model.Task, error) {
+	task := model.NewTask(card.Name, card.Desc, card.Closed, trelloCreatedDate(card.ID))
+	task.BoardID = boardID
+	task.DueDate = card.Due
+	for _, memberID := range card.IDMembers {
+		userID, ok := mapping.userIDs[memberID]
+		if !ok {
+			continue // Unmapped member is reported once
+		}
+		if task.AssigneeUserID.Valid {
+			mapping.addUnmapped(TrelloItemCard, card.ID, card.Name, "Only the first member is assigned, member [%s] is not", memberID)
+			continue
+		}
+		task.SetAssigneeUserID(userID)
+	}
+	taskLabelIDs := []string{}
+	for _, label := range card.Labels {
+		labelID, ok := mapping.labelIDs[label.Name]
+		if !ok {
+			// Labels of Trello may have only color
+			mapping.addUnmapped(TrelloItemLabel, label.ID, label.Name, "Label named [%s] (color [%s]) of card [%s] does not exist",
+				label.Name, label.Color, card.Name)
+			continue
+		}
+		taskLabelIDs = append(taskLabelIDs, labelID)
+	}
+	// Labels are set with task, so that automation rules of created trigger can match them
+	if serr := s.taskSrvc.CreateTaskWithLabels("", task, taskLabelIDs); serr != nil {
+		return nil, serr
+	}
+	return task, nil
+}
+
+// mapMembers returns map from member ID to ID of user whose name is username or full name of member
+func (s *TrelloService) mapMembers(members []TrelloMember, mapping *trelloMapping) (map[string]string, error) {
+	users, err := s.userRepo.FindUsers(&model.User{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find users")
+	}
+	userIDs := map[string]string{}
+	for _, user := range users {
+		userIDs[user.Name] = user.ID
+	}
+	memberUserIDs := map[string]string{}
+	for _, member := range members {
+		if userID, ok := userIDs[member.Username]; ok {
+			memberUserIDs[member.ID] = userID
+		} else if userID, ok := userIDs[member.FullName]; ok {
+			memberUserIDs[member.ID] = userID
+		} else {
+			mapping.addUnmapped(TrelloItemMember, member.ID, member.Username, "User named [%s] or [%s] does not exist",
+				member.Username, member.FullName)
+		}
+	}
+	return memberUserIDs, nil
+}
+
+// findBoardNames returns names of all boards in the project where lists are imported
+func (s *TrelloService) findBoardNames(projectID string) (map[string]bool, error) {
+	boards, serr := s.boardSrvc.FindBoards(&model.Board{ProjectID: projectID}, []string{"id"})
+	if serr != nil {
+		return nil, serr
+	}
+	names := map[string]bool{}
+	for _, board := range boards {
+		names[board.Name] = true
+	}
+	return names, nil
+}
+
+// uniqueTrelloBoardName returns name of list if it is not used,
+// otherwise name qualified by Trello board name and number if needed
+func uniqueTrelloBoardName(listName, trelloBoardName string, usedNames map[string]bool) string {
+	if !usedNames[listName] {
+		return listName
+	}
+	qualified := fmt.Sprintf("%s (%s)", listName, trelloBoardName)
+	name := qualified
+	for i := 2; usedNames[name]; i++ {
+		name = fmt.Sprintf("%s %d", qualified, i)
+	}
+	return name
+}
+
+// findLabelIDs returns map from label name to ID
+func (s *TrelloService) findLabelIDs() (map[string]string, error) {
+	labels, err := s.labelRepo.FindLabels(&model.Label{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find labels")
+	}
+	labelIDs := map[string]string{}
+	for _, label := range labels {
+		labelIDs[label.Name] = label.ID
+	}
+	return labelIDs, nil
+}
+
+func (mapping *trelloMapping) addUnmapped(itemType, id, name, format string, values ...interface{}) {
+	mapping.unmapped = append(mapping.unmapped, TrelloUnmappedItem{
+		Type:   itemType,
+		ID:     id,
+		Name:   name,
+		Reason: fmt.Sprintf(format, values...),
+	})
+}
+
+// trelloCreatedDate returns created date embedded in the first 8 hex digits of Trello ID, or now if it is not valid
+func trelloCreatedDate(id string) time.Time {
+	if len(id) >= 8 {
+		if seconds, err := strconv.ParseInt(id[:8], 16, 64); err == nil {
+			return time.Unix(seconds, 0).UTC()
+		}
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrelloService_ImportCardLabels(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	label := model.NewLabel("trello-label", "#ff0000", time.Now().UTC())
+	if err := tx.Create(label).Error; err != nil {
+		t.Fatalf("Failed to create label: %+v", err)
+	}
+	rule := createAutomationTestRule(t, tx, "trello-labeled", model.AutomationTriggerCreated, "label:trello-label",
+		model.AutomationAction{Type: model.AutomationActionAddComment, Value: "Labeled"})
+	trello := &TrelloBoard{
+		ID:    "5e0000000000000000000001",
+		Name:  "trello",
+		Lists: []TrelloList{{ID: "5e0000000000000000000002", Name: "trello-list", Pos: 1}},
+		Cards: []TrelloCard{
+			{ID: "5e0000000000000000000003", Name: "labeled", IDList: "5e0000000000000000000002", Pos: 1,
+				Labels: []TrelloLabel{{ID: "5e0000000000000000000004", Name: "trello-label", Color: "red"}}},
+			{ID: "5e0000000000000000000005", Name: "not labeled", IDList: "5e0000000000000000000002", Pos: 2},
+		},
+	}
+
+	result, serr := NewTrelloService(tx, nil).Import(model.DefaultProjectID, trello)
+	if serr != nil {
+		t.Fatalf("Failed to import: %+v", serr)
+	}
+	if len(result.Tasks) != 2 {
+		t.Fatalf("Expected tasks = %d, but got %d", 2, len(result.Tasks))
+	}
+	taskLabelIDs, serr := NewLabelService(tx, nil).FindTaskLabelIDs([]string{result.Tasks[0].ID, result.Tasks[1].ID})
+	if serr != nil {
+		t.Fatalf("Failed to find labels: %+v", serr)
+	}
+	assert.Equal(t, []string{label.ID}, taskLabelIDs[result.Tasks[0].ID])
+	assert.Equal(t, 0, len(taskLabelIDs[result.Tasks[1].ID]))
+
+	// Rule of created trigger matches labels of imported card
+	executions := findAutomationTestExecutions(t, tx, rule.ID)
+	if len(executions) != 1 {
+		t.Fatalf("Expected executions = %d, but got %d", 1, len(executions))
+	}
+	assert.Equal(t, result.Tasks[0].ID, executions[0].TaskID)
+	assert.Equal(t, model.ExecutionStatusSucceeded, executions[0].Status)
+}
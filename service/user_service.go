@@ -10,15 +10,18 @@ import (
 
 // UserService provides apis for user management.
 type UserService struct {
-	tx       *gorm.DB
-	userRepo *repository.UserRepository
+	tx        *gorm.DB
+	loginUser *model.User
+	userRepo  *repository.UserRepository
 }
 
 // NewUserService return new instance of UserService.
-func NewUserService(tx *gorm.DB) *UserService {
+// loginUser is used for authorization, set nil when service is called internally.
+func NewUserService(tx *gorm.DB, loginUser *model.User) *UserService {
 	return &UserService{
-		tx:       tx,
-		userRepo: repository.NewUserRepository(tx),
+		tx:        tx,
+		loginUser: loginUser,
+		userRepo:  repository.NewUserRepository(tx),
 	}
 }
 
@@ -43,30 +46,91 @@ func (s *UserService) FindUsers(condition interface{}, sortOrders []string) ([]m
 	return users, nil
 }
 
+// FindUsersPage finds a page of users and returns it with the number of all users matching condition
+func (s *UserService) FindUsersPage(condition interface{}, after *orm.Keyset, limit int, sortOrders []string) ([]model.User, int, error) {
+	users, err := repository.NewUserRepository(orm.WhereAfter(s.tx, after)).FindUsers(condition, 0, limit, sortOrders)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to find users")
+	}
+	count, err := s.userRepo.CountUsers(condition)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to count users")
+	}
+	return users, count, nil
+}
+
 // CreateUser creates new user
 func (s *UserService) CreateUser(user *model.User) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if !model.IsValidRole(user.Role) {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Role [%s] is invalid", user.Role)
+	}
 	err := s.userRepo.CreateUser(user)
 	if err != nil {
 		return NewSvcError(ErrorCodeDB, err, "Failed to create user")
 	}
+	// New users join default project so that they can see existing boards
+	err = repository.NewProjectRepository(s.tx).CreateProjectMembers(model.DefaultProjectID, []string{user.ID})
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to add user to default project")
+	}
 	return nil
 }
 
-// UpdateUser updates specifed user
+// UpdateUser updates specifed user, sessions of the user are revoked if password is changed
 func (s *UserService) UpdateUser(user *model.User) error {
+	find, serr := s.FindUser(&model.User{ID: user.ID})
+	if serr != nil {
+		return serr
+	}
+	// Users can update own profile, but only admin can change other users and roles
+	isSelf := s.loginUser != nil && s.loginUser.ID == user.ID
+	if !isSelf || find.Role != user.Role {
+		if serr = authorizeAdmin(s.loginUser); serr != nil {
+			return serr
+		}
+	}
+	if !model.IsValidRole(user.Role) {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Role [%s] is invalid", user.Role)
+	}
 	err := s.userRepo.UpdateUser(user)
 	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return NewSvcErrorf(ErrorCodeOptimisticLockFailure, err, "User has been updated by other request. ID:%s", user.ID)
+		}
 		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update user. ID:%s", user.ID)
 	}
+	// Sessions logged in by old password can not be used any more
+	if user.PasswordHash != "" && user.PasswordHash != find.PasswordHash {
+		if serr = NewSessionService(s.tx).RevokeUserSessions(user.ID); serr != nil {
+			return serr
+		}
+	}
 	return nil
 }
 
-// DeleteUser deletes specifed user
+// DeleteUser deletes specifed user and revokes sessions of the user
 func (s *UserService) DeleteUser(user *model.User) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := NewSessionService(s.tx).RevokeUserSessions(user.ID); serr != nil {
+		return serr
+	}
 	err := s.userRepo.DeleteUser(user)
 	if err != nil {
 		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete user. ID:%s", user.ID)
 	}
+	err = repository.NewSavedViewRepository(s.tx).DeleteUserSavedViews(user.ID)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete saved views of user. ID:%s", user.ID)
+	}
+	err = repository.NewProjectRepository(s.tx).DeleteProjectMembers(&model.ProjectMember{UserID: user.ID})
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to remove user from projects. ID:%s", user.ID)
+	}
 	return nil
 }
 
@@ -83,3 +147,30 @@ func (s *UserService) Login(name, password string) (*model.User, error) {
 	}
 	return &find, nil
 }
+
+// CreateAdminUser creates admin user with specified password if there is no admin user.
+// Returns nil user if admin user already exists.
+func (s *UserService) CreateAdminUser(name, password string) (*model.User, error) {
+	count, err := s.userRepo.CountUsers(&model.User{Role: model.RoleAdmin})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to count admin users")
+	}
+	if count > 0 {
+		return nil, nil
+	}
+	count, err = s.userRepo.CountUsers(&model.User{Name: name})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to count users")
+	}
+	if count > 0 {
+		return nil, NewSvcErrorf(ErrorCodeAlreadyExist, nil,
+			"User [%s] already exists but is not admin, change the role by database directly", name)
+	}
+	user := model.NewUser(name, password, "")
+	user.Role = model.RoleAdmin
+	err = s.userRepo.CreateUser(user)
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to create admin user")
+	}
+	return user, nil
+}
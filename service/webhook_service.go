@@ -0,0 +1,299 @@
+package service
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"taskboard/event"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+	"taskboard/webhook"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// WebhookService provides apis for webhook management and delivery.
+type WebhookService struct {
+	tx           *gorm.DB
+	loginUser    *model.User
+	webhookRepo  *repository.WebhookRepository
+	deliveryRepo *repository.WebhookDeliveryRepository
+}
+
+// NewWebhookService return new instance of WebhookService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewWebhookService(tx *gorm.DB, loginUser *model.User) *WebhookService {
+	return &WebhookService{
+		tx:           tx,
+		loginUser:    loginUser,
+		webhookRepo:  repository.NewWebhookRepository(tx),
+		deliveryRepo: repository.NewWebhookDeliveryRepository(tx),
+	}
+}
+
+// FindWebhook returns webhook matching specified condition
+func (s *WebhookService) FindWebhook(condition interface{}) (*model.Webhook, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	find, err := s.webhookRepo.FindFirstWebhook(condition, []string{"id"})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, err, "Webhook not found")
+		}
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find webhook")
+	}
+	return &find, nil
+}
+
+// FindWebhooks finds all webhooks
+func (s *WebhookService) FindWebhooks(condition interface{}, sortOrders []string) ([]model.Webhook, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	webhooks, err := s.webhookRepo.FindWebhooks(condition, 0, orm.NoLimit, sortOrders)
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find webhooks")
+	}
+	return webhooks, nil
+}
+
+// FindWebhooksPage finds a page of webhooks and returns it with the number of all webhooks matching condition
+func (s *WebhookService) FindWebhooksPage(condition interface{}, after *orm.Keyset, limit int, sortOrders []string) ([]model.Webhook, int, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, 0, serr
+	}
+	webhooks, err := repository.NewWebhookRepository(orm.WhereAfter(s.tx, after)).FindWebhooks(condition, 0, limit, sortOrders)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to find webhooks")
+	}
+	count, err := s.webhookRepo.CountWebhooks(condition)
+	if err != nil {
+		return nil, 0, NewSvcError(ErrorCodeDB, err, "Failed to count webhooks")
+	}
+	return webhooks, count, nil
+}
+
+// CreateWebhook creates new webhook
+func (s *WebhookService) CreateWebhook(webhook *model.Webhook) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := validateWebhook(webhook); serr != nil {
+		return serr
+	}
+	err := s.webhookRepo.CreateWebhook(webhook)
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to create webhook")
+	}
+	return nil
+}
+
+// UpdateWebhook updates specifed webhook
+func (s *WebhookService) UpdateWebhook(webhook *model.Webhook) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := validateWebhook(webhook); serr != nil {
+		return serr
+	}
+	err := s.webhookRepo.UpdateWebhook(webhook)
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return NewSvcErrorf(ErrorCodeOptimisticLockFailure, err, "Webhook has been updated by other request. ID:%s", webhook.ID)
+		}
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update webhook. ID:%s", webhook.ID)
+	}
+	return nil
+}
+
+// DeleteWebhook deletes specifed webhook and its deliveries
+func (s *WebhookService) DeleteWebhook(webhook *model.Webhook) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	err := s.webhookRepo.DeleteWebhook(webhook)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete webhook. ID:%s", webhook.ID)
+	}
+	err = s.deliveryRepo.DeleteDeliveriesOfWebhook(webhook.ID)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to delete deliveries of webhook. ID:%s", webhook.ID)
+	}
+	return nil
+}
+
+// FindWebhookDelivery returns delivery matching specified condition
+func (s *WebhookService) FindWebhookDelivery(condition interface{}) (*model.WebhookDelivery, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	find, err := s.deliveryRepo.FindFirstWebhookDelivery(condition, []string{"id"})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcErrorf(ErrorCodeNotFound, err, "Webhook delivery not found")
+		}
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find webhook delivery")
+	}
+	return &find, nil
+}
+
+// FindWebhookDeliveries finds all deliveries of specified webhook, newest first
+func (s *WebhookService) FindWebhookDeliveries(webhookID string) ([]model.WebhookDelivery, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	deliveries, err := s.deliveryRepo.FindWebhookDeliveries(&model.WebhookDelivery{WebhookID: webhookID},
+		0, orm.NoLimit, []string{"created_date desc, id desc"})
+	if err != nil {
+		return nil, NewSvcErrorf(ErrorCodeDB, err, "Failed to find deliveries of webhook. ID:%s", webhookID)
+	}
+	return deliveries, nil
+}
+
+// FindWebhookDeliveriesPage finds a page of deliveries of webhook and returns it with the number of all deliveries
+func (s *WebhookService) FindWebhookDeliveriesPage(webhookID string, after *orm.Keyset, limit int, sortOrders []string,
+) ([]model.WebhookDelivery, int, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, 0, serr
+	}
+	condition := &model.WebhookDelivery{WebhookID: webhookID}
+	deliveries, err := repository.NewWebhookDeliveryRepository(orm.WhereAfter(s.tx, after)).
+		FindWebhookDeliveries(condition, 0, limit, sortOrders)
+	if err != nil {
+		return nil, 0, NewSvcErrorf(ErrorCodeDB, err, "Failed to find deliveries of webhook. ID:%s", webhookID)
+	}
+	count, err := s.deliveryRepo.CountWebhookDeliveries(condition)
+	if err != nil {
+		return nil, 0, NewSvcErrorf(ErrorCodeDB, err, "Failed to count deliveries of webhook. ID:%s", webhookID)
+	}
+	return deliveries, count, nil
+}
+
+// EnqueueDeliveries creates pending deliveries of event for all webhooks receiving it
+func (s *WebhookService) EnqueueDeliveries(e *event.Event) error {
+	webhooks, err := s.webhookRepo.FindWebhooks(&model.Webhook{IsActive: true}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to find webhooks")
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeUnexpected, err, "Failed to encode event. ID:%s", e.ID)
+	}
+	now := time.Now().UTC()
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(e.Type) {
+			continue
+		}
+		delivery := model.NewWebhookDelivery(webhook.ID, e.ID, e.Type, string(payload), now)
+		err = s.deliveryRepo.CreateWebhookDelivery(delivery)
+		if err != nil {
+			return NewSvcErrorf(ErrorCodeDB, err, "Failed to create delivery of webhook. ID:%s", webhook.ID)
+		}
+	}
+	return nil
+}
+
+// EnqueueDelivery creates pending delivery of event for specified webhook, whether it receives the type of event or not.
+// This is used to send events which are not published, such as events of automation.
+func (s *WebhookService) EnqueueDelivery(webhookID string, e *event.Event) error {
+	webhook, serr := s.FindWebhook(&model.Webhook{ID: webhookID})
+	if serr != nil {
+		return serr
+	}
+	if !webhook.IsActive {
+		return NewSvcErrorf(ErrorCodePreconditionInvalid, nil, "Webhook is not active. ID:%s", webhookID)
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeUnexpected, err, "Failed to encode event. ID:%s", e.ID)
+	}
+	delivery := model.NewWebhookDelivery(webhook.ID, e.ID, e.Type, string(payload), time.Now().UTC())
+	err = s.deliveryRepo.CreateWebhookDelivery(delivery)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to create delivery of webhook. ID:%s", webhook.ID)
+	}
+	return nil
+}
+
+// Redeliver creates new pending delivery which has same payload as specified delivery
+func (s *WebhookService) Redeliver(delivery *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return nil, serr
+	}
+	redelivery := model.NewWebhookDelivery(delivery.WebhookID, delivery.EventID, delivery.EventType,
+		delivery.Payload, time.Now().UTC())
+	err := s.deliveryRepo.CreateWebhookDelivery(redelivery)
+	if err != nil {
+		return nil, NewSvcErrorf(ErrorCodeDB, err, "Failed to create delivery of webhook. ID:%s", delivery.WebhookID)
+	}
+	return redelivery, nil
+}
+
+// FindDueDeliveries returns pending deliveries which should be sent now
+func (s *WebhookService) FindDueDeliveries(now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	deliveries, err := s.deliveryRepo.FindDueWebhookDeliveries(now, limit)
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find webhook deliveries")
+	}
+	return deliveries, nil
+}
+
+// RecordDeliveryResult updates delivery by result of an attempt.
+// Failed delivery is retried with exponential backoff until max attempts.
+func (s *WebhookService) RecordDeliveryResult(delivery *model.WebhookDelivery, statusCode int, sendErr error, now time.Time) error {
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	if sendErr == nil {
+		delivery.Status = model.DeliveryStatusSucceeded
+		delivery.LastError = ""
+		delivery.DeliveredDate = &now
+	} else {
+		delivery.LastError = truncate(sendErr.Error(), 1000)
+		if delivery.Attempts >= webhook.MaxAttempts {
+			delivery.Status = model.DeliveryStatusFailed
+		} else {
+			delivery.NextAttemptDate = now.Add(webhook.Backoff(delivery.Attempts))
+		}
+	}
+	err := s.deliveryRepo.UpdateWebhookDelivery(delivery)
+	if err != nil {
+		return NewSvcErrorf(ErrorCodeDB, err, "Failed to update webhook delivery. ID:%s", delivery.ID)
+	}
+	return nil
+}
+
+func validateWebhook(webhook *model.Webhook) error {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, err, "Webhook url must be http or https url. URL:%s", webhook.URL)
+	}
+	if webhook.Secret == "" {
+		return NewSvcError(ErrorCodeInvalidArguments, nil, "Webhook secret must not be empty")
+	}
+	eventTypes := webhook.GetEventTypes()
+	if len(eventTypes) == 0 {
+		return NewSvcError(ErrorCodeInvalidArguments, nil, "Webhook must have one or more event types")
+	}
+	invalids := []string{}
+	for _, eventType := range eventTypes {
+		if !event.IsValidType(eventType) {
+			invalids = append(invalids, eventType)
+		}
+	}
+	if len(invalids) > 0 {
+		return NewSvcErrorWithDetailsf(ErrorCodeInvalidArguments, nil, "Unknown event types. Valid types are [%s]",
+			invalids, strings.Join(event.Types, ","))
+	}
+	return nil
+}
+
+// truncate returns value shortened to max bytes
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return value[:max]
+}
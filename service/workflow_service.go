@@ -0,0 +1,150 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/repository"
+
+	"github.com/jinzhu/gorm"
+)
+
+// WorkflowService provides apis for workflow states which define system boards of every project.
+type WorkflowService struct {
+	tx          *gorm.DB
+	loginUser   *model.User
+	stateRepo   *repository.WorkflowStateRepository
+	projectRepo *repository.ProjectRepository
+}
+
+// NewWorkflowService return new instance of WorkflowService.
+// loginUser is used for authorization, set nil when service is called internally.
+func NewWorkflowService(tx *gorm.DB, loginUser *model.User) *WorkflowService {
+	return &WorkflowService{
+		tx:          tx,
+		loginUser:   loginUser,
+		stateRepo:   repository.NewWorkflowStateRepository(tx),
+		projectRepo: repository.NewProjectRepository(tx),
+	}
+}
+
+// FindWorkflowStates returns all workflow states in order of workflow
+func (s *WorkflowService) FindWorkflowStates() ([]model.WorkflowState, error) {
+	states, err := s.stateRepo.FindWorkflowStates(&model.WorkflowState{}, 0, orm.NoLimit, []string{"position"})
+	if err != nil {
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find workflow states")
+	}
+	return states, nil
+}
+
+// FindInboxState returns the workflow state whose boards receive tasks which have no board
+func (s *WorkflowService) FindInboxState() (*model.WorkflowState, error) {
+	find, err := s.stateRepo.FindFirstWorkflowState(&model.WorkflowState{IsInbox: true}, []string{"position"})
+	if err != nil {
+		if err == orm.ErrorRecordNotFound {
+			return nil, NewSvcError(ErrorCodePreconditionInvalid, err, "Workflow has no inbox state")
+		}
+		return nil, NewSvcError(ErrorCodeDB, err, "Failed to find inbox state")
+	}
+	return &find, nil
+}
+
+// SetWorkflowStates replaces workflow with specified states in order of workflow,
+// and system boards of all projects follow the new workflow.
+// version is the version of the workflow which states are based on, it is the version of current states,
+// and new states have the next version.
+func (s *WorkflowService) SetWorkflowStates(states []*model.WorkflowState, version int) error {
+	if serr := authorizeAdmin(s.loginUser); serr != nil {
+		return serr
+	}
+	if serr := validateWorkflowStates(states); serr != nil {
+		return serr
+	}
+	current, serr := s.FindWorkflowStates()
+	if serr != nil {
+		return serr
+	}
+	currentVersion := workflowVersion(current)
+	if version != currentVersion {
+		return NewSvcErrorf(ErrorCodeOptimisticLockFailure, nil,
+			"Workflow has been updated by other request. Version:%d, but current version:%d", version, currentVersion)
+	}
+	deleted := make([]*model.WorkflowState, 0, len(current))
+	for i := range current {
+		deleted = append(deleted, &current[i])
+	}
+	if err := s.stateRepo.DeleteWorkflowStates(deleted); err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to delete workflow states")
+	}
+	for i, state := range states {
+		state.Position = i
+		state.Version = currentVersion + 1
+	}
+	if err := s.stateRepo.CreateWorkflowStates(states); err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to create workflow states")
+	}
+	return s.SyncSystemBoards()
+}
+
+// SyncSystemBoards makes system boards of all projects follow the workflow
+func (s *WorkflowService) SyncSystemBoards() error {
+	projects, err := s.projectRepo.FindProjects(&model.Project{}, 0, orm.NoLimit, []string{"id"})
+	if err != nil {
+		return NewSvcError(ErrorCodeDB, err, "Failed to find projects")
+	}
+	boardSrvc := NewBoardService(s.tx, s.loginUser)
+	for _, project := range projects {
+		if serr := boardSrvc.SyncSystemBoards(project.ID); serr != nil {
+			return serr
+		}
+	}
+	return nil
+}
+
+// workflowVersion returns version of workflow which is the version of its states, all of them are replaced together
+func workflowVersion(states []model.WorkflowState) int {
+	version := 0
+	for _, state := range states {
+		if state.Version > version {
+			version = state.Version
+		}
+	}
+	return version
+}
+
+// validateWorkflowStates checks IDs and names of states are valid and unique,
+// and exactly one state which is not done is inbox
+func validateWorkflowStates(states []*model.WorkflowState) error {
+	if len(states) == 0 {
+		return NewSvcError(ErrorCodeInvalidArguments, nil, "Workflow must have at least one state")
+	}
+	ids := map[string]bool{}
+	names := map[string]bool{}
+	inboxCount := 0
+	for _, state := range states {
+		if !model.IsValidWorkflowStateID(state.ID) {
+			return NewSvcErrorf(ErrorCodeInvalidArguments, nil,
+				"ID of workflow state [%s] must be 1-16 lower case letters, digits, _ or -, starting with a letter", state.ID)
+		}
+		if ids[state.ID] {
+			return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "ID of workflow state [%s] is duplicated", state.ID)
+		}
+		ids[state.ID] = true
+		if state.Name == "" {
+			return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Name of workflow state [%s] must not be empty", state.ID)
+		}
+		if names[state.Name] {
+			return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Name of workflow state [%s] is duplicated", state.Name)
+		}
+		names[state.Name] = true
+		if state.IsInbox {
+			if state.IsDone {
+				return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Inbox state [%s] can not be done", state.ID)
+			}
+			inboxCount++
+		}
+	}
+	if inboxCount != 1 {
+		return NewSvcErrorf(ErrorCodeInvalidArguments, nil, "Workflow must have exactly one inbox state, but has %d", inboxCount)
+	}
+	return nil
+}
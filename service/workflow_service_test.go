@@ -0,0 +1,48 @@
+package service
+
+import (
+	"taskboard/model"
+	"taskboard/orm"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowService_SetWorkflowStates_Version(t *testing.T) {
+	tx := orm.GetDB().Begin()
+	defer tx.Rollback()
+
+	srvc := NewWorkflowService(tx, nil)
+	current, serr := srvc.FindWorkflowStates()
+	if serr != nil {
+		t.Fatalf("Failed to find workflow states: %+v", serr)
+	}
+	version := current[0].Version
+	newStates := func(doneName string) []*model.WorkflowState {
+		return []*model.WorkflowState{
+			model.NewWorkflowState("todo", "To Do", true, false),
+			model.NewWorkflowState("done", doneName, false, true),
+		}
+	}
+	if serr := srvc.SetWorkflowStates(newStates("Done"), version); serr != nil {
+		t.Fatalf("Failed to set workflow states: %+v", serr)
+	}
+	states, serr := srvc.FindWorkflowStates()
+	if serr != nil {
+		t.Fatalf("Failed to find workflow states: %+v", serr)
+	}
+	for _, state := range states {
+		assert.Equal(t, version+1, state.Version)
+	}
+
+	// Workflow based on the replaced one is rejected
+	err := srvc.SetWorkflowStates(newStates("Closed"), version)
+	if serr, ok := err.(*SvcError); assert.True(t, ok, "SvcError must be returned: %v", err) {
+		assert.Equal(t, ErrorCodeOptimisticLockFailure, serr.Code)
+	}
+	states, serr = srvc.FindWorkflowStates()
+	if serr != nil {
+		t.Fatalf("Failed to find workflow states: %+v", serr)
+	}
+	assert.Equal(t, "Done", states[1].Name)
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Headers of webhook request
+const (
+	HeaderEvent     = "X-Taskboard-Event"
+	HeaderDelivery  = "X-Taskboard-Delivery"
+	HeaderSignature = "X-Taskboard-Signature"
+)
+
+// signaturePrefix is prefix of signature header value which presents algorithm
+const signaturePrefix = "sha256="
+
+// Retry settings of delivery
+const (
+	MaxAttempts    = 8
+	initialBackoff = 10 * time.Second
+	maxBackoff     = 1 * time.Hour
+)
+
+// Sign returns signature header value of payload, which is hex encoded HMAC-SHA256 of payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature header value of payload, receivers can use this to check requests
+func Verify(secret string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, payload)))
+}
+
+// Send posts signed payload to url, returns status code of response (0 if no response).
+// Error is returned if request failed or response status is not 2xx.
+func Send(client *http.Client, url, secret, eventType, deliveryID string, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderSignature, Sign(secret, payload))
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// Read body to reuse connection
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// Backoff returns delay before next attempt after specified number of failed attempts
+func Backoff(attempts int) time.Duration {
+	backoff := initialBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSend(t *testing.T) {
+	var received *http.Request
+	var receivedBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	payload := []byte(`{"type":"task.created"}`)
+	statusCode, err := Send(receiver.Client(), receiver.URL, "secret", "task.created", "deliveryID", payload)
+	if err != nil {
+		t.Fatalf("Failed to send: %+v", err)
+	}
+	assert.Equal(t, http.StatusNoContent, statusCode)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "task.created", received.Header.Get(HeaderEvent))
+	assert.Equal(t, "deliveryID", received.Header.Get(HeaderDelivery))
+	assert.Equal(t, payload, receivedBody)
+	assert.True(t, Verify("secret", receivedBody, received.Header.Get(HeaderSignature)))
+	assert.False(t, Verify("wrongSecret", receivedBody, received.Header.Get(HeaderSignature)))
+}
+
+func TestSend_ErrorStatus(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	statusCode, err := Send(receiver.Client(), receiver.URL, "secret", "task.created", "deliveryID", []byte(`{}`))
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, statusCode)
+}
+
+func TestSend_NoResponse(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := receiver.URL
+	receiver.Close()
+
+	statusCode, err := Send(http.DefaultClient, url, "secret", "task.created", "deliveryID", []byte(`{}`))
+	assert.Error(t, err)
+	assert.Equal(t, 0, statusCode)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, Backoff(1))
+	assert.Equal(t, 20*time.Second, Backoff(2))
+	assert.Equal(t, 80*time.Second, Backoff(4))
+	assert.Equal(t, 1*time.Hour, Backoff(20))
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"fmt"
+	"taskboard/controller/api"
+	"taskboard/controller/tasks"
+	"taskboard/event"
+	"taskboard/orm"
+	"taskboard/service"
+	"time"
+)
+
+// automationPollInterval is interval to check tasks whose due date has passed
+const automationPollInterval = time.Minute
+
+// StartAutomationWorker starts running automation rules of duePassed trigger in background.
+// Other triggers are run by TaskService in the transaction which caused them.
+func StartAutomationWorker() {
+	go func() {
+		ticker := time.NewTicker(automationPollInterval)
+		defer ticker.Stop()
+		for {
+			runDuePassedRules()
+			<-ticker.C
+		}
+	}()
+}
+
+// runDuePassedRules runs rules for overdue tasks in a transaction, and publishes changes of tasks after commit
+func runDuePassedRules() {
+	tx := orm.GetDB().Begin()
+	updated, serr := service.NewAutomationService(tx, nil).RunDuePassedRules(time.Now().UTC())
+	if serr != nil {
+		api.Rollback(tx)
+		fmt.Printf("Failed to run automation rules of overdue tasks. Error:%+v\n", serr)
+		return
+	}
+	// Labels are part of task events, find them before commit as controllers do
+	taskIDs := make([]string, 0, len(updated))
+	for _, task := range updated {
+		taskIDs = append(taskIDs, task.ID)
+	}
+	taskLabelIDs, serr := service.NewLabelService(tx, nil).FindTaskLabelIDs(taskIDs)
+	if serr != nil {
+		api.Rollback(tx)
+		fmt.Printf("Failed to run automation rules of overdue tasks. Error:%+v\n", serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		fmt.Printf("Failed to run automation rules of overdue tasks. Error:%+v\n", serr)
+		return
+	}
+	for i := range updated {
+		task := &updated[i]
+		// Task may have been moved by rules, the event relates to the board where it is now
+		event.Publish(event.TypeTaskUpdated, []string{task.BoardID}, tasks.ConvertTaskEvent(task, taskLabelIDs[task.ID]))
+	}
+}
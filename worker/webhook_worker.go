@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"fmt"
+	"net/http"
+	"taskboard/controller/api"
+	"taskboard/event"
+	"taskboard/model"
+	"taskboard/orm"
+	"taskboard/service"
+	"taskboard/webhook"
+	"time"
+)
+
+// Settings of webhook worker
+const (
+	webhookPollInterval   = 5 * time.Second
+	webhookRequestTimeout = 10 * time.Second
+	webhookBatchSize      = 100
+)
+
+var webhookNotify = make(chan struct{}, 1)
+
+// StartWebhookWorker registers listener which enqueues deliveries for published events,
+// and starts delivering pending deliveries in background.
+func StartWebhookWorker() {
+	event.AddListener(enqueueWebhookDeliveries)
+	client := &http.Client{Timeout: webhookRequestTimeout}
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+		for {
+			deliverWebhooks(client)
+			select {
+			case <-ticker.C:
+			case <-webhookNotify:
+			}
+		}
+	}()
+}
+
+// enqueueWebhookDeliveries is called after transaction of event is committed
+func enqueueWebhookDeliveries(e *event.Event) {
+	tx := orm.GetDB().Begin()
+	serr := service.NewWebhookService(tx, nil).EnqueueDeliveries(e)
+	if serr != nil {
+		api.Rollback(tx)
+		fmt.Printf("Failed to enqueue webhook deliveries. EventID:%s Error:%+v\n", e.ID, serr)
+		return
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		fmt.Printf("Failed to enqueue webhook deliveries. EventID:%s Error:%+v\n", e.ID, serr)
+		return
+	}
+	NotifyWebhookWorker()
+}
+
+// NotifyWebhookWorker wakes up worker to send pending deliveries without waiting next polling
+func NotifyWebhookWorker() {
+	select {
+	case webhookNotify <- struct{}{}:
+	default:
+		// Worker has been notified already
+	}
+}
+
+// deliverWebhooks sends all due deliveries, transaction is not kept while sending requests
+func deliverWebhooks(client *http.Client) {
+	for {
+		srvc := service.NewWebhookService(orm.GetDB(), nil)
+		deliveries, serr := srvc.FindDueDeliveries(time.Now().UTC(), webhookBatchSize)
+		if serr != nil {
+			fmt.Printf("Failed to find webhook deliveries. Error:%+v\n", serr)
+			return
+		}
+		for i := range deliveries {
+			if !deliver(client, srvc, &deliveries[i]) {
+				// Stop not to send same delivery repeatedly, it will be retried at next polling
+				return
+			}
+		}
+		if len(deliveries) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// deliver sends a delivery and records the result, returns false if failed to record
+func deliver(client *http.Client, srvc *service.WebhookService, delivery *model.WebhookDelivery) bool {
+	find, serr := srvc.FindWebhook(&model.Webhook{ID: delivery.WebhookID})
+	var statusCode int
+	var err error
+	if serr != nil {
+		err = serr
+	} else {
+		statusCode, err = webhook.Send(client, find.URL, find.Secret, delivery.EventType, delivery.ID, []byte(delivery.Payload))
+	}
+	tx := orm.GetDB().Begin()
+	serr = service.NewWebhookService(tx, nil).RecordDeliveryResult(delivery, statusCode, err, time.Now().UTC())
+	if serr != nil {
+		api.Rollback(tx)
+		fmt.Printf("Failed to record webhook delivery. ID:%s Error:%+v\n", delivery.ID, serr)
+		return false
+	}
+	serr = api.Commit(tx)
+	if serr != nil {
+		fmt.Printf("Failed to record webhook delivery. ID:%s Error:%+v\n", delivery.ID, serr)
+		return false
+	}
+	return true
+}